@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/ezjuanify/wallet/internal/db"
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/service"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	pgconfig, err := utils.GetPGConfig()
+	if err != nil {
+		logger.Warn("Failed to get DB config, falling back to default config", zap.String("error", err.Error()))
+	}
+
+	store, err := db.NewStore(pgconfig)
+	if err != nil {
+		logger.Fatal("Failed to establish connection with DB", zap.String("error", err.Error()))
+	}
+
+	rs := service.NewReconciliationService(store)
+	report, appErr := rs.DoReconcile(context.Background())
+	if appErr != nil {
+		logger.Fatal("Failed to reconcile wallet balances", zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(report); err != nil {
+		logger.Fatal("Failed to encode reconciliation report", zap.Error(err))
+	}
+
+	if !report.Reconciled {
+		logger.Error("Wallet balances drifted from transaction log", zap.Int("mismatched", report.Mismatched))
+		logger.Sync()
+		os.Exit(1)
+	}
+	logger.Info("Wallet balances reconciled", zap.Int("checked", report.Checked))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/ezjuanify/wallet/internal/db"
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/service"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	pgconfig, err := utils.GetPGConfig()
+	if err != nil {
+		logger.Warn("Failed to get DB config, falling back to default config", zap.String("error", err.Error()))
+	}
+
+	store, err := db.NewStore(pgconfig)
+	if err != nil {
+		logger.Fatal("Failed to establish connection with DB", zap.String("error", err.Error()))
+	}
+
+	ls := service.NewLedgerService(store)
+	verification, appErr := ls.DoVerifyChain(context.Background())
+	if appErr != nil {
+		logger.Fatal("Failed to verify transaction chain", zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(verification); err != nil {
+		logger.Fatal("Failed to encode verification report", zap.Error(err))
+	}
+
+	if !verification.Verified {
+		logger.Error("Transaction chain broken",
+			zap.Int64("transactionID", verification.FirstBreak.TransactionID),
+			zap.String("reason", string(verification.FirstBreak.Reason)),
+		)
+		logger.Sync()
+		os.Exit(1)
+	}
+	logger.Info("Transaction chain verified", zap.Int64("checked", verification.Checked))
+}
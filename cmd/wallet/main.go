@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"net/http"
 	"time"
 
 	"github.com/ezjuanify/wallet/internal/appserv"
@@ -40,28 +42,273 @@ func main() {
 	}
 	logger.Info("Successfully connected to DB", dbFields...)
 
+	fxconfig, err := utils.GetFXConfig()
+	if err != nil {
+		logger.Warn("Failed to get FX config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched FX config", zap.Duration("quote_ttl", fxconfig.QuoteTTL), zap.Int("default_spread_bps", fxconfig.DefaultSpreadBps))
+
+	reconconfig, err := utils.GetReconciliationConfig()
+	if err != nil {
+		logger.Warn("Failed to get reconciliation config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched reconciliation config", zap.Duration("interval", reconconfig.Interval))
+
+	holdconfig, err := utils.GetHoldConfig()
+	if err != nil {
+		logger.Warn("Failed to get hold config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched hold config", zap.Duration("default_ttl", holdconfig.DefaultTTL), zap.Duration("sweep_interval", holdconfig.SweepInterval))
+
+	escrowconfig, err := utils.GetEscrowConfig()
+	if err != nil {
+		logger.Warn("Failed to get escrow config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched escrow config", zap.Duration("default_ttl", escrowconfig.DefaultTTL), zap.Duration("sweep_interval", escrowconfig.SweepInterval))
+
+	interestconfig, err := utils.GetInterestConfig()
+	if err != nil {
+		logger.Warn("Failed to get interest config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched interest config", zap.Int("annual_rate_bps", interestconfig.AnnualRateBps), zap.Int("day_count", interestconfig.DayCount), zap.String("rounding", string(interestconfig.Rounding)), zap.Duration("run_interval", interestconfig.RunInterval))
+
+	overdraftconfig, err := utils.GetOverdraftConfig()
+	if err != nil {
+		logger.Warn("Failed to get overdraft config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched overdraft config", zap.Duration("run_interval", overdraftconfig.RunInterval))
+
+	statementconfig, err := utils.GetStatementConfig()
+	if err != nil {
+		logger.Warn("Failed to get statement config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched statement config", zap.Duration("run_interval", statementconfig.RunInterval))
+
+	snapshotconfig, err := utils.GetBalanceSnapshotConfig()
+	if err != nil {
+		logger.Warn("Failed to get balance snapshot config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched balance snapshot config", zap.Duration("run_interval", snapshotconfig.RunInterval))
+
+	scheduledconfig, err := utils.GetScheduledTransferConfig()
+	if err != nil {
+		logger.Warn("Failed to get scheduled transfer config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched scheduled transfer config", zap.Duration("poll_interval", scheduledconfig.PollInterval), zap.Duration("retry_delay", scheduledconfig.RetryDelay), zap.Int("max_attempts", scheduledconfig.MaxAttempts))
+
+	approvalconfig, err := utils.GetApprovalConfig()
+	if err != nil {
+		logger.Warn("Failed to get approval config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched approval config", zap.Duration("ttl", approvalconfig.TTL))
+
+	walletconfig, err := utils.GetWalletConfig()
+	if err != nil {
+		logger.Warn("Failed to get wallet config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched wallet config", zap.Bool("auto_create", walletconfig.AutoCreate))
+
+	idempotencyconfig, err := utils.GetIdempotencyConfig()
+	if err != nil {
+		logger.Warn("Failed to get idempotency config, falling back to default config", zap.String("error", err.Error()))
+	}
+	logger.Info("Successfully fetched idempotency config", zap.Duration("retention", idempotencyconfig.Retention), zap.Duration("sweep_interval", idempotencyconfig.SweepInterval))
+
 	s := service.NewWalletService(store)
-	ds := service.NewDepositService(store)
-	ws := service.NewWithdrawService(store)
+	js := service.NewJournalService(store)
+	vs := service.NewVelocityService(store)
+	ds := service.NewDepositService(store, js, walletconfig)
+	ws := service.NewWithdrawService(store, js, vs)
+	trs := service.NewTransferService(ws, ds, js, vs)
 	ts := service.NewTransactionService(store)
-	wh := handler.NewWalletHandler(store, s, ds, ws, ts)
+	fxs := service.NewFXService(store, fxconfig)
+	ls := service.NewLedgerService(store)
+	rs := service.NewReconciliationService(store)
+	rvs := service.NewReversalService(store)
+	hs := service.NewHoldService(store, holdconfig)
+	sts := service.NewScheduledTransferService(store, scheduledconfig)
+	sos := service.NewStandingOrderService(store)
+	bts := service.NewBatchTransferService(store)
+	es := service.NewEscrowService(store, escrowconfig)
+	fs := service.NewFeeService(store, js)
+	is := service.NewInterestService(store, interestconfig)
+	ods := service.NewOverdraftService(store)
+	lms := service.NewLimitService(store)
+	sms := service.NewStatementService(store)
+	bss := service.NewSnapshotService(store)
+	ps := service.NewPocketService(store)
+	jws := service.NewJointWalletService(store, approvalconfig)
+	lcs := service.NewLifecycleService(store)
+	ids := service.NewIdempotencyService(store, idempotencyconfig)
+	wh := handler.NewWalletHandler(store, s, ds, ws, trs, ts, js, fxs, ls, rs, rvs, hs, sts, sos, bts, es, fs, is, ods, lms, vs, sms, bss, ps, jws, lcs, ids)
 	logger.Info("All services initialized")
 
+	if reconconfig.Interval > 0 {
+		go rs.RunScheduler(context.Background(), reconconfig.Interval)
+	} else {
+		logger.Info("Scheduled reconciliation disabled")
+	}
+
+	if holdconfig.SweepInterval > 0 {
+		go hs.RunExpirySweeper(context.Background(), holdconfig.SweepInterval)
+	} else {
+		logger.Info("Hold expiry sweeper disabled")
+	}
+
+	if escrowconfig.SweepInterval > 0 {
+		go es.RunExpirySweeper(context.Background(), escrowconfig.SweepInterval, wh.SettleEscrow)
+	} else {
+		logger.Info("Escrow expiry sweeper disabled")
+	}
+
+	if interestconfig.AnnualRateBps > 0 && interestconfig.RunInterval > 0 {
+		go is.RunAccrualJob(context.Background(), interestconfig.RunInterval, wh.BookInterest)
+	} else {
+		logger.Info("Interest accrual disabled")
+	}
+
+	if overdraftconfig.RunInterval > 0 {
+		go ods.RunChargeJob(context.Background(), overdraftconfig.RunInterval, wh.ChargeOverdraft)
+	} else {
+		logger.Info("Overdraft charging disabled")
+	}
+
+	if statementconfig.RunInterval > 0 {
+		go sms.RunGenerateJob(context.Background(), statementconfig.RunInterval)
+	} else {
+		logger.Info("Statement generation disabled")
+	}
+
+	if snapshotconfig.RunInterval > 0 {
+		go bss.RunSnapshotJob(context.Background(), snapshotconfig.RunInterval)
+	} else {
+		logger.Info("Balance snapshots disabled")
+	}
+
+	if idempotencyconfig.SweepInterval > 0 {
+		go ids.RunExpirySweeper(context.Background(), idempotencyconfig.SweepInterval)
+	} else {
+		logger.Info("Idempotency key sweeper disabled")
+	}
+
+	if scheduledconfig.PollInterval > 0 {
+		go sos.RunMaterializer(context.Background(), scheduledconfig.PollInterval)
+		go sts.RunScheduler(context.Background(), scheduledconfig.PollInterval, wh.ExecuteTransfer)
+	} else {
+		logger.Info("Scheduled transfer execution disabled")
+	}
+
 	ap := appserv.NewAppServer()
 	logger.Debug("Attaching HealthHandler")
 	ap.Mux.HandleFunc(appserv.HEALTH, handler.HealthHandler)
+	logger.Debug("Attaching OpenWalletHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.WALLETS, wh.OpenWalletHandler)
 	logger.Debug("Attaching DepositHandler")
 	ap.Mux.HandleFunc(appserv.DEPOSIT, wh.DepositHandler)
 	logger.Debug("Attaching WithdrawHandler")
 	ap.Mux.HandleFunc(appserv.WITHDRAW, wh.WithdrawHandler)
 	logger.Debug("Attaching TransferHandler")
 	ap.Mux.HandleFunc(appserv.TRANSFER, wh.TransferHandler)
+	logger.Debug("Attaching BatchTransferHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.TRANSFER_BATCH, wh.BatchTransferHandler)
 	logger.Debug("Attaching TransactionHandler")
 	ap.Mux.HandleFunc(appserv.TRANSACTION, wh.TransactionHandler)
 	logger.Debug("Attaching BalanceHandler")
 	ap.Mux.HandleFunc(appserv.BALANCE, wh.BalanceHandler)
+	logger.Debug("Attaching CreatePocketHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.POCKETS, wh.CreatePocketHandler)
+	logger.Debug("Attaching PocketHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.POCKETS, wh.PocketHandler)
+	logger.Debug("Attaching MovePocketFundsHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.POCKETS_MOVE, wh.MovePocketFundsHandler)
+	logger.Debug("Attaching CreateJointWalletHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.JOINT_WALLETS, wh.CreateJointWalletHandler)
+	logger.Debug("Attaching JointWalletHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.JOINT_WALLETS, wh.JointWalletHandler)
+	logger.Debug("Attaching SetWalletMemberHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.JOINT_WALLET_MEMBERS, wh.SetWalletMemberHandler)
+	logger.Debug("Attaching SetSigningRuleHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.JOINT_WALLET_RULES, wh.SetSigningRuleHandler)
+	logger.Debug("Attaching ApprovalsHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.APPROVALS, wh.ApprovalsHandler)
+	logger.Debug("Attaching ApproveHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.APPROVAL_APPROVE, wh.ApproveHandler)
+	logger.Debug("Attaching RejectHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.APPROVAL_REJECT, wh.RejectHandler)
+	logger.Debug("Attaching StatementHandler")
+	ap.Mux.HandleFunc(appserv.STATEMENTS, wh.StatementHandler)
 	logger.Debug("Attaching AdminBalanceHandler")
 	ap.Mux.HandleFunc(appserv.ADMIN_BALANCES, wh.AdminBalanceHandler)
+	logger.Debug("Attaching AdminLoadFXRatesHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_FX_RATES, wh.AdminLoadFXRatesHandler)
+	logger.Debug("Attaching AdminFXRatesHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.ADMIN_FX_RATES, wh.AdminFXRatesHandler)
+	logger.Debug("Attaching AdminLoadFeeScheduleHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_FEES, wh.AdminLoadFeeScheduleHandler)
+	logger.Debug("Attaching AdminFeeScheduleHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.ADMIN_FEES, wh.AdminFeeScheduleHandler)
+	logger.Debug("Attaching AdminRunInterestHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_INTEREST_RUN, wh.AdminRunInterestHandler)
+	logger.Debug("Attaching AdminInterestHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.ADMIN_INTEREST, wh.AdminInterestHandler)
+	logger.Debug("Attaching AdminSetCreditLimitHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_CREDIT_LIMITS, wh.AdminSetCreditLimitHandler)
+	logger.Debug("Attaching AdminOverdraftHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.ADMIN_OVERDRAFTS, wh.AdminOverdraftHandler)
+	logger.Debug("Attaching AdminChargeOverdraftsHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_OVERDRAFTS_RUN, wh.AdminChargeOverdraftsHandler)
+	logger.Debug("Attaching AdminSetWalletLimitsHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_LIMITS, wh.AdminSetWalletLimitsHandler)
+	logger.Debug("Attaching AdminWalletLimitsHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.ADMIN_LIMITS, wh.AdminWalletLimitsHandler)
+	logger.Debug("Attaching AdminSetVelocityRuleHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_VELOCITY, wh.AdminSetVelocityRuleHandler)
+	logger.Debug("Attaching AdminVelocityRulesHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.ADMIN_VELOCITY, wh.AdminVelocityRulesHandler)
+	logger.Debug("Attaching AdminWalletStatusHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.ADMIN_WALLET_STATUS, wh.AdminWalletStatusHandler)
+	logger.Debug("Attaching AdminFreezeWalletHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_WALLET_FREEZE, wh.AdminFreezeWalletHandler)
+	logger.Debug("Attaching AdminUnfreezeWalletHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_WALLET_THAW, wh.AdminUnfreezeWalletHandler)
+	logger.Debug("Attaching AdminCloseWalletHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_WALLET_CLOSE, wh.AdminCloseWalletHandler)
+	logger.Debug("Attaching AdminRunStatementsHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ADMIN_STATEMENTS_RUN, wh.AdminRunStatementsHandler)
+	logger.Debug("Attaching FXQuoteHandler")
+	ap.Mux.HandleFunc(appserv.FX_QUOTES, wh.FXQuoteHandler)
+	logger.Debug("Attaching AdminLedgerVerifyHandler")
+	ap.Mux.HandleFunc(appserv.ADMIN_LEDGER_VERIFY, wh.AdminLedgerVerifyHandler)
+	logger.Debug("Attaching AdminReconciliationHandler")
+	ap.Mux.HandleFunc(appserv.ADMIN_RECONCILIATION, wh.AdminReconciliationHandler)
+	logger.Debug("Attaching ReversalHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.TRANSACTION_REVERSE, wh.ReversalHandler)
+	logger.Debug("Attaching PlaceHoldHandler")
+	ap.Mux.HandleFunc(appserv.HOLDS, wh.PlaceHoldHandler)
+	logger.Debug("Attaching CaptureHoldHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.HOLD_CAPTURE, wh.CaptureHoldHandler)
+	logger.Debug("Attaching VoidHoldHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.HOLD_VOID, wh.VoidHoldHandler)
+	logger.Debug("Attaching CreateScheduledTransferHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.SCHEDULED_TRANSFERS, wh.CreateScheduledTransferHandler)
+	logger.Debug("Attaching ScheduledTransferHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.SCHEDULED_TRANSFERS, wh.ScheduledTransferHandler)
+	logger.Debug("Attaching CancelScheduledTransferHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.SCHEDULED_CANCEL, wh.CancelScheduledTransferHandler)
+	logger.Debug("Attaching CreateStandingOrderHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.STANDING_ORDERS, wh.CreateStandingOrderHandler)
+	logger.Debug("Attaching StandingOrderHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.STANDING_ORDERS, wh.StandingOrderHandler)
+	logger.Debug("Attaching CancelStandingOrderHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.STANDING_CANCEL, wh.CancelStandingOrderHandler)
+	logger.Debug("Attaching CreateEscrowHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ESCROWS, wh.CreateEscrowHandler)
+	logger.Debug("Attaching EscrowHandler")
+	ap.Mux.HandleFunc(http.MethodGet+" "+appserv.ESCROWS, wh.EscrowHandler)
+	logger.Debug("Attaching ReleaseEscrowHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ESCROW_RELEASE, wh.ReleaseEscrowHandler)
+	logger.Debug("Attaching RefundEscrowHandler")
+	ap.Mux.HandleFunc(http.MethodPost+" "+appserv.ESCROW_REFUND, wh.RefundEscrowHandler)
 	logger.Info("All API handlers attached")
 
 	if err := ap.GetEnvPort(); err != nil {
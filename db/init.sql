@@ -1,20 +1,436 @@
 CREATE TABLE IF NOT EXISTS wallets (
     id                    SERIAL  PRIMARY KEY,
-    username              TEXT    UNIQUE         NOT NULL,
+    username              TEXT                   NOT NULL,
+    currency              TEXT                   NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$'),
+    pocket                TEXT                   NOT NULL DEFAULT 'MAIN' CHECK (pocket ~ '^[A-Z0-9_]{1,32}$'),
+    status                TEXT                   NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'frozen_debit', 'frozen_all', 'closed')),
+    opened_via            TEXT                   NOT NULL DEFAULT 'explicit' CHECK (opened_via IN ('explicit', 'auto', 'legacy')),
+    opened_at             TIMESTAMP              NOT NULL DEFAULT now(),
     balance               BIGINT                 NOT NULL DEFAULT 0,
+    ledger_balance        BIGINT                 NOT NULL DEFAULT 0,
+    held_balance          BIGINT                 NOT NULL DEFAULT 0,
+    credit_limit          BIGINT                 NOT NULL DEFAULT 0 CHECK (credit_limit >= 0),
+    overdrawn_since       TIMESTAMP,
+    min_amount            BIGINT                 NOT NULL CHECK (min_amount > 0),
+    max_amount            BIGINT                 NOT NULL,
+    max_balance           BIGINT                 NOT NULL CHECK (max_balance >= 0),
     last_deposit_amount   BIGINT,
     last_deposit_updated  TIMESTAMP,
     last_withdraw_amount  BIGINT,
-    last_withdraw_updated TIMESTAMP
+    last_withdraw_updated TIMESTAMP,
+    CONSTRAINT uq_wallet_username_currency_pocket UNIQUE (username, currency, pocket)
 );
-ALTER TABLE wallets ADD CONSTRAINT chk_wallet_balance CHECK (balance >= 0 AND balance <= 999999);
+ALTER TABLE wallets ADD CONSTRAINT chk_wallet_balance CHECK (balance >= -credit_limit AND (balance <= max_balance OR username LIKE 'SYS\_%'));
+ALTER TABLE wallets ADD CONSTRAINT chk_wallet_amount_limits CHECK (max_amount >= min_amount);
+ALTER TABLE wallets ADD CONSTRAINT chk_wallet_held_balance CHECK (held_balance >= 0 AND held_balance <= balance + credit_limit);
+
+CREATE OR REPLACE FUNCTION track_wallet_overdraft() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.balance >= 0 THEN
+        NEW.overdrawn_since := NULL;
+    ELSIF NEW.overdrawn_since IS NULL THEN
+        NEW.overdrawn_since := now();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_wallet_overdraft
+    BEFORE INSERT OR UPDATE OF balance ON wallets
+    FOR EACH ROW EXECUTE FUNCTION track_wallet_overdraft();
 
 CREATE TABLE IF NOT EXISTS transactions (
     id           SERIAL  PRIMARY KEY,
     username     TEXT                  NOT NULL,
-    type         TEXT                  NOT NULL CHECK (type IN ('deposit', 'withdraw', 'transfer_in', 'transfer_out')),
+    type         TEXT                  NOT NULL CHECK (type IN ('deposit', 'withdraw', 'transfer_in', 'transfer_out', 'reversal', 'escrow_fund', 'escrow_release', 'escrow_refund', 'fee', 'interest', 'pocket_in', 'pocket_out')),
+    direction    TEXT                  NOT NULL CHECK (direction IN ('debit', 'credit')),
+    currency     TEXT                  NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$'),
+    pocket       TEXT                  NOT NULL DEFAULT 'MAIN',
     amount       BIGINT                NOT NULL CHECK (amount > 0),
     counterparty TEXT,
+    fx_rate      NUMERIC(20, 10),
+    quote_id     TEXT,
+    journal_entry_id INTEGER,
+    reversal_of  INTEGER               REFERENCES transactions(id),
+    memo         TEXT                  CHECK (char_length(memo) BETWEEN 1 AND 140),
+    reference    TEXT                  CHECK (reference ~ '^[A-Za-z0-9][A-Za-z0-9._:/-]{0,63}$'),
+    metadata     JSONB                 CHECK (jsonb_typeof(metadata) = 'object'),
     timestamp    TIMESTAMP             NOT NULL DEFAULT now(),
-    hash         TEXT                  NOT NULL
+    prev_hash    TEXT                  NOT NULL,
+    hash         TEXT                  NOT NULL UNIQUE,
+    hash_version SMALLINT              NOT NULL,
+    CONSTRAINT chk_transaction_reversal CHECK ((type = 'reversal') = (reversal_of IS NOT NULL)),
+    CONSTRAINT chk_transaction_hash_version CHECK (hash_version BETWEEN 1 AND 6)
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of ON transactions (reversal_of);
+CREATE INDEX IF NOT EXISTS idx_transactions_journal_entry_id ON transactions (journal_entry_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_velocity ON transactions (username, currency, type, timestamp);
+CREATE INDEX IF NOT EXISTS idx_transactions_reference ON transactions (reference) WHERE reference IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id        SERIAL    PRIMARY KEY,
+    type      TEXT      NOT NULL,
+    timestamp TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS journal_postings (
+    id             SERIAL  PRIMARY KEY,
+    entry_id       INTEGER NOT NULL REFERENCES journal_entries(id),
+    wallet_id      INTEGER REFERENCES wallets(id),
+    system_account TEXT,
+    currency       TEXT    NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    direction      TEXT    NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount         BIGINT  NOT NULL CHECK (amount > 0),
+    CONSTRAINT chk_posting_account CHECK ((wallet_id IS NULL) <> (system_account IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_journal_postings_wallet_id ON journal_postings (wallet_id);
+ALTER TABLE transactions ADD CONSTRAINT fk_transaction_journal_entry FOREIGN KEY (journal_entry_id) REFERENCES journal_entries(id);
+
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced() RETURNS TRIGGER AS $$
+DECLARE
+    imbalance BIGINT;
+BEGIN
+    SELECT COALESCE(SUM(CASE WHEN direction = 'debit' THEN amount ELSE -amount END), 0)
+    INTO imbalance
+    FROM journal_postings
+    WHERE entry_id = NEW.entry_id
+    AND currency = NEW.currency;
+
+    IF imbalance <> 0 THEN
+        RAISE EXCEPTION 'journal entry % is unbalanced by % %', NEW.entry_id, imbalance, NEW.currency;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trg_journal_entry_balanced
+    AFTER INSERT ON journal_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+CREATE OR REPLACE FUNCTION apply_wallet_posting() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE wallets
+    SET ledger_balance = ledger_balance + CASE WHEN NEW.direction = 'credit' THEN NEW.amount ELSE -NEW.amount END
+    WHERE id = NEW.wallet_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_wallet_posting
+    AFTER INSERT ON journal_postings
+    FOR EACH ROW WHEN (NEW.wallet_id IS NOT NULL) EXECUTE FUNCTION apply_wallet_posting();
+
+CREATE TABLE IF NOT EXISTS fx_rates (
+    id             SERIAL          PRIMARY KEY,
+    base_currency  TEXT            NOT NULL CHECK (base_currency ~ '^[A-Z]{3}$'),
+    quote_currency TEXT            NOT NULL CHECK (quote_currency ~ '^[A-Z]{3}$'),
+    rate           NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    spread_bps     INTEGER         NOT NULL DEFAULT 0 CHECK (spread_bps >= 0 AND spread_bps < 10000),
+    valid_from     TIMESTAMP       NOT NULL DEFAULT now(),
+    valid_to       TIMESTAMP,
+    created_at     TIMESTAMP       NOT NULL DEFAULT now(),
+    CONSTRAINT chk_fx_rate_pair CHECK (base_currency <> quote_currency),
+    CONSTRAINT chk_fx_rate_window CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+CREATE INDEX IF NOT EXISTS idx_fx_rates_pair ON fx_rates (base_currency, quote_currency, valid_from DESC);
+
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id             TEXT            PRIMARY KEY,
+    rate_id        INTEGER         NOT NULL REFERENCES fx_rates(id),
+    username       TEXT            NOT NULL,
+    base_currency  TEXT            NOT NULL,
+    quote_currency TEXT            NOT NULL,
+    base_amount    BIGINT          NOT NULL CHECK (base_amount > 0),
+    quote_amount   BIGINT          NOT NULL CHECK (quote_amount > 0),
+    rate           NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    expires_at     TIMESTAMP       NOT NULL,
+    used_at        TIMESTAMP,
+    created_at     TIMESTAMP       NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS holds (
+    id              SERIAL    PRIMARY KEY,
+    wallet_id       INTEGER   NOT NULL REFERENCES wallets(id),
+    username        TEXT      NOT NULL,
+    actor           TEXT      NOT NULL,
+    currency        TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    pocket          TEXT      NOT NULL DEFAULT 'MAIN',
+    amount          BIGINT    NOT NULL CHECK (amount > 0),
+    captured_amount BIGINT    CHECK (captured_amount > 0 AND captured_amount <= amount),
+    status          TEXT      NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'captured', 'voided', 'expired')),
+    expires_at      TIMESTAMP NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT now(),
+    resolved_at     TIMESTAMP,
+    CONSTRAINT chk_hold_captured CHECK ((status = 'captured') = (captured_amount IS NOT NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_holds_active_expires_at ON holds (expires_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS escrows (
+    id              SERIAL    PRIMARY KEY,
+    payer           TEXT      NOT NULL,
+    payee           TEXT      NOT NULL,
+    currency        TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    amount          BIGINT    NOT NULL CHECK (amount > 0),
+    released_amount BIGINT    NOT NULL DEFAULT 0 CHECK (released_amount >= 0),
+    refunded_amount BIGINT    NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0),
+    status          TEXT      NOT NULL DEFAULT 'funded' CHECK (status IN ('funded', 'released', 'refunded', 'expired')),
+    expires_at      TIMESTAMP NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT now(),
+    resolved_at     TIMESTAMP,
+    CONSTRAINT chk_escrow_parties CHECK (payer <> payee),
+    CONSTRAINT chk_escrow_resolved CHECK ((status = 'funded') = (resolved_at IS NULL)),
+    CONSTRAINT chk_escrow_settlement CHECK (released_amount + refunded_amount = CASE WHEN status = 'funded' THEN 0 ELSE amount END)
+);
+CREATE INDEX IF NOT EXISTS idx_escrows_funded_expires_at ON escrows (expires_at) WHERE status = 'funded';
+CREATE INDEX IF NOT EXISTS idx_escrows_payer ON escrows (payer);
+CREATE INDEX IF NOT EXISTS idx_escrows_payee ON escrows (payee);
+
+CREATE TABLE IF NOT EXISTS fee_rules (
+    id         SERIAL    PRIMARY KEY,
+    txn_type   TEXT      NOT NULL CHECK (txn_type IN ('withdraw', 'transfer', 'overdraft')),
+    currency   TEXT      CHECK (currency ~ '^[A-Z]{3}$'),
+    min_amount BIGINT    NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+    max_amount BIGINT,
+    flat_fee   BIGINT    NOT NULL DEFAULT 0 CHECK (flat_fee >= 0),
+    rate_bps   INTEGER   NOT NULL DEFAULT 0 CHECK (rate_bps >= 0 AND rate_bps <= 10000),
+    min_fee    BIGINT    CHECK (min_fee >= 0),
+    max_fee    BIGINT    CHECK (max_fee >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    retired_at TIMESTAMP,
+    CONSTRAINT chk_fee_rule_band CHECK (max_amount IS NULL OR max_amount > min_amount),
+    CONSTRAINT chk_fee_rule_caps CHECK (min_fee IS NULL OR max_fee IS NULL OR min_fee <= max_fee)
+);
+CREATE INDEX IF NOT EXISTS idx_fee_rules_active ON fee_rules (txn_type, currency) WHERE retired_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS velocity_rules (
+    id          SERIAL    PRIMARY KEY,
+    username    TEXT,
+    txn_type    TEXT      NOT NULL CHECK (txn_type IN ('withdraw', 'transfer')),
+    currency    TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    time_window TEXT      NOT NULL CHECK (time_window IN ('daily', 'weekly', 'monthly')),
+    max_amount  BIGINT    NOT NULL CHECK (max_amount > 0),
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    retired_at  TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_velocity_rules_active ON velocity_rules (COALESCE(username, ''), txn_type, currency, time_window) WHERE retired_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS overdraft_charges (
+    id             SERIAL    PRIMARY KEY,
+    wallet_id      INTEGER   NOT NULL REFERENCES wallets(id),
+    username       TEXT      NOT NULL,
+    currency       TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    charge_date    DATE      NOT NULL,
+    usage          BIGINT    NOT NULL CHECK (usage > 0),
+    fee            BIGINT    NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    transaction_id INTEGER   REFERENCES transactions(id),
+    created_at     TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT uq_overdraft_charge_day UNIQUE (wallet_id, charge_date),
+    CONSTRAINT chk_overdraft_charge_transaction CHECK ((fee > 0) = (transaction_id IS NOT NULL))
+);
+
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    id          SERIAL    PRIMARY KEY,
+    username    TEXT      NOT NULL,
+    currency    TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    pocket      TEXT      NOT NULL DEFAULT 'MAIN',
+    snapshot_at TIMESTAMP NOT NULL,
+    balance     BIGINT    NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT uq_balance_snapshot UNIQUE (username, currency, pocket, snapshot_at)
+);
+
+CREATE TABLE IF NOT EXISTS statements (
+    id              SERIAL    PRIMARY KEY,
+    username        TEXT      NOT NULL,
+    currency        TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    period          DATE      NOT NULL CHECK (period = date_trunc('month', period)),
+    period_start    TIMESTAMP NOT NULL,
+    period_end      TIMESTAMP NOT NULL,
+    opening_balance BIGINT    NOT NULL,
+    closing_balance BIGINT    NOT NULL,
+    generated_at    TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT uq_statement_period UNIQUE (username, currency, period),
+    CONSTRAINT chk_statement_range CHECK (period_end > period_start)
+);
+
+CREATE TABLE IF NOT EXISTS statement_lines (
+    id             SERIAL    PRIMARY KEY,
+    statement_id   INTEGER   NOT NULL REFERENCES statements(id),
+    transaction_id INTEGER   NOT NULL REFERENCES transactions(id),
+    pocket         TEXT      NOT NULL DEFAULT 'MAIN',
+    type           TEXT      NOT NULL,
+    direction      TEXT      NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount         BIGINT    NOT NULL CHECK (amount > 0),
+    counterparty   TEXT,
+    reversal_of    INTEGER,
+    timestamp      TIMESTAMP NOT NULL,
+    balance        BIGINT    NOT NULL,
+    CONSTRAINT uq_statement_line UNIQUE (statement_id, transaction_id)
+);
+
+CREATE TABLE IF NOT EXISTS interest_postings (
+    id             SERIAL          PRIMARY KEY,
+    wallet_id      INTEGER         NOT NULL REFERENCES wallets(id),
+    username       TEXT            NOT NULL,
+    currency       TEXT            NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    period         DATE            NOT NULL,
+    days           INTEGER         NOT NULL CHECK (days > 0),
+    accrued        NUMERIC(30, 10) NOT NULL CHECK (accrued >= 0),
+    amount         BIGINT          NOT NULL CHECK (amount >= 0),
+    forfeited      BIGINT          NOT NULL DEFAULT 0 CHECK (forfeited >= 0),
+    transaction_id INTEGER         REFERENCES transactions(id),
+    created_at     TIMESTAMP       NOT NULL DEFAULT now(),
+    CONSTRAINT uq_interest_posting_period UNIQUE (wallet_id, period),
+    CONSTRAINT chk_interest_posting_period CHECK (period = date_trunc('month', period)),
+    CONSTRAINT chk_interest_posting_transaction CHECK ((amount > 0) = (transaction_id IS NOT NULL))
+);
+
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    id           SERIAL          PRIMARY KEY,
+    wallet_id    INTEGER         NOT NULL REFERENCES wallets(id),
+    accrual_date DATE            NOT NULL,
+    balance      BIGINT          NOT NULL CHECK (balance > 0),
+    rate_bps     INTEGER         NOT NULL CHECK (rate_bps >= 0),
+    amount       NUMERIC(30, 10) NOT NULL CHECK (amount >= 0),
+    posting_id   INTEGER         REFERENCES interest_postings(id),
+    created_at   TIMESTAMP       NOT NULL DEFAULT now(),
+    CONSTRAINT uq_interest_accrual_day UNIQUE (wallet_id, accrual_date)
+);
+CREATE INDEX IF NOT EXISTS idx_interest_accruals_unbooked ON interest_accruals (accrual_date) WHERE posting_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS standing_orders (
+    id                 SERIAL    PRIMARY KEY,
+    username           TEXT      NOT NULL,
+    actor              TEXT      NOT NULL,
+    currency           TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    amount             BIGINT    NOT NULL CHECK (amount > 0),
+    counterparty       TEXT      NOT NULL,
+    frequency          TEXT      NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+    day_of_month       INTEGER   CHECK (day_of_month BETWEEN 1 AND 31),
+    start_at           TIMESTAMP NOT NULL,
+    end_at             TIMESTAMP,
+    max_occurrences    INTEGER   CHECK (max_occurrences > 0),
+    occurrence_count   INTEGER   NOT NULL DEFAULT 0 CHECK (occurrence_count >= 0),
+    next_occurrence_at TIMESTAMP,
+    status             TEXT      NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'cancelled')),
+    created_at         TIMESTAMP NOT NULL DEFAULT now(),
+    cancelled_at       TIMESTAMP,
+    CONSTRAINT chk_standing_order_day_of_month CHECK ((frequency = 'monthly') = (day_of_month IS NOT NULL)),
+    CONSTRAINT chk_standing_order_next CHECK ((status = 'active') = (next_occurrence_at IS NOT NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_standing_orders_due ON standing_orders (next_occurrence_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_standing_orders_username ON standing_orders (username);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id                SERIAL    PRIMARY KEY,
+    username          TEXT      NOT NULL,
+    actor             TEXT      NOT NULL,
+    currency          TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    amount            BIGINT    NOT NULL CHECK (amount > 0),
+    counterparty      TEXT      NOT NULL,
+    execute_at        TIMESTAMP NOT NULL,
+    next_attempt_at   TIMESTAMP NOT NULL,
+    status            TEXT      NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed', 'cancelled')),
+    attempt_count     INTEGER   NOT NULL DEFAULT 0 CHECK (attempt_count >= 0),
+    last_error        TEXT,
+    executed_at       TIMESTAMP,
+    cancelled_at      TIMESTAMP,
+    created_at        TIMESTAMP NOT NULL DEFAULT now(),
+    standing_order_id INTEGER   REFERENCES standing_orders(id),
+    occurrence        INTEGER,
+    CONSTRAINT uq_scheduled_transfer_occurrence UNIQUE (standing_order_id, occurrence),
+    CONSTRAINT chk_scheduled_transfer_occurrence CHECK ((standing_order_id IS NULL) = (occurrence IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_username ON scheduled_transfers (username);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_attempts (
+    id                    SERIAL    PRIMARY KEY,
+    scheduled_transfer_id INTEGER   NOT NULL REFERENCES scheduled_transfers(id),
+    attempted_at          TIMESTAMP NOT NULL,
+    succeeded             BOOLEAN   NOT NULL,
+    transaction_id        INTEGER   REFERENCES transactions(id),
+    error_code            TEXT,
+    error_message         TEXT,
+    CONSTRAINT chk_scheduled_attempt_outcome CHECK (succeeded = (transaction_id IS NOT NULL) AND succeeded = (error_code IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_attempts_transfer_id ON scheduled_transfer_attempts (scheduled_transfer_id);
+
+CREATE TABLE IF NOT EXISTS wallet_members (
+    wallet_username TEXT      NOT NULL,
+    member          TEXT      NOT NULL,
+    role            TEXT      NOT NULL CHECK (role IN ('owner', 'spender', 'viewer')),
+    created_at      TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (wallet_username, member),
+    CONSTRAINT chk_wallet_member_self CHECK (wallet_username <> member)
+);
+CREATE INDEX IF NOT EXISTS idx_wallet_members_member ON wallet_members (member);
+
+CREATE TABLE IF NOT EXISTS signing_rules (
+    wallet_username    TEXT      NOT NULL,
+    currency           TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    approval_threshold BIGINT    NOT NULL CHECK (approval_threshold >= 0),
+    required_approvals INTEGER   NOT NULL CHECK (required_approvals >= 2),
+    updated_at         TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (wallet_username, currency)
+);
+
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id                    SERIAL    PRIMARY KEY,
+    wallet_username       TEXT      NOT NULL,
+    currency              TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    pocket                TEXT      NOT NULL DEFAULT 'MAIN',
+    type                  TEXT      NOT NULL CHECK (type IN ('withdraw', 'transfer')),
+    amount                BIGINT    NOT NULL CHECK (amount > 0),
+    counterparty          TEXT,
+    counterparty_currency TEXT,
+    quote_id              TEXT,
+    memo                  TEXT,
+    reference             TEXT,
+    metadata              JSONB,
+    initiator             TEXT      NOT NULL,
+    required_approvals    INTEGER   NOT NULL CHECK (required_approvals >= 2),
+    status                TEXT      NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'executed', 'rejected')),
+    transaction_id        INTEGER   REFERENCES transactions(id),
+    expires_at            TIMESTAMP NOT NULL,
+    created_at            TIMESTAMP NOT NULL DEFAULT now(),
+    resolved_at           TIMESTAMP,
+    CONSTRAINT chk_approval_counterparty CHECK ((type = 'transfer') = (counterparty IS NOT NULL)),
+    CONSTRAINT chk_approval_executed CHECK ((status = 'executed') = (transaction_id IS NOT NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_approval_requests_wallet ON approval_requests (wallet_username, status);
+
+CREATE TABLE IF NOT EXISTS approval_votes (
+    approval_id INTEGER   NOT NULL REFERENCES approval_requests(id),
+    member      TEXT      NOT NULL,
+    approved_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (approval_id, member)
+);
+
+CREATE TABLE IF NOT EXISTS wallet_status_changes (
+    id          SERIAL    PRIMARY KEY,
+    username    TEXT      NOT NULL,
+    currency    TEXT      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    from_status TEXT      NOT NULL,
+    to_status   TEXT      NOT NULL CHECK (to_status IN ('active', 'frozen_debit', 'frozen_all', 'closed')),
+    reason      TEXT      NOT NULL,
+    note        TEXT,
+    actor       TEXT      NOT NULL,
+    changed_at  TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT chk_wallet_status_change CHECK (from_status <> to_status)
+);
+CREATE INDEX IF NOT EXISTS idx_wallet_status_changes_wallet ON wallet_status_changes (username, currency, changed_at);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    username    TEXT      NOT NULL,
+    key         TEXT      NOT NULL,
+    fingerprint TEXT      NOT NULL,
+    status_code INTEGER,
+    response    TEXT,
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at  TIMESTAMP NOT NULL,
+    PRIMARY KEY (username, key),
+    CONSTRAINT chk_idempotency_response CHECK ((status_code IS NULL) = (response IS NULL))
 );
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
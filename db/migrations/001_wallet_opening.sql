@@ -0,0 +1,32 @@
+-- Brings a database created before explicit wallet opening up to date.
+-- init.sql already contains these columns, so fresh installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/001_wallet_opening.sql
+--
+-- Wallets that already exist are marked 'legacy' because there is no record
+-- of whether they were opened on purpose or by a first deposit. opened_at is
+-- backfilled from the wallet's earliest transaction where there is one.
+BEGIN;
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS opened_via TEXT NOT NULL DEFAULT 'legacy' CHECK (opened_via IN ('explicit', 'auto', 'legacy'));
+ALTER TABLE wallets ALTER COLUMN opened_via SET DEFAULT 'explicit';
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS opened_at TIMESTAMP;
+UPDATE wallets w
+SET opened_at = COALESCE(
+    (
+        SELECT MIN(t.timestamp)
+        FROM transactions t
+        WHERE
+            t.username = w.username
+        AND t.currency = w.currency
+        AND t.pocket = w.pocket
+    ),
+    w.last_deposit_updated,
+    now()
+)
+WHERE opened_at IS NULL;
+ALTER TABLE wallets ALTER COLUMN opened_at SET DEFAULT now();
+ALTER TABLE wallets ALTER COLUMN opened_at SET NOT NULL;
+
+COMMIT;
@@ -0,0 +1,20 @@
+-- Adds the optional memo, reference and metadata columns to a database
+-- created before they existed. init.sql already contains them, so fresh
+-- installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/002_transaction_details.sql
+--
+-- Existing rows keep NULL in every new column, which is also how their hashes
+-- were computed, so GET /admin/ledger/verify still passes afterwards.
+BEGIN;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS memo TEXT CHECK (char_length(memo) BETWEEN 1 AND 140);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reference TEXT CHECK (reference ~ '^[A-Za-z0-9][A-Za-z0-9._:/-]{0,63}$');
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS metadata JSONB CHECK (jsonb_typeof(metadata) = 'object');
+CREATE INDEX IF NOT EXISTS idx_transactions_reference ON transactions (reference) WHERE reference IS NOT NULL;
+
+ALTER TABLE approval_requests ADD COLUMN IF NOT EXISTS memo TEXT;
+ALTER TABLE approval_requests ADD COLUMN IF NOT EXISTS reference TEXT;
+ALTER TABLE approval_requests ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+COMMIT;
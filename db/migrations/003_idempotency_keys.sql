@@ -0,0 +1,18 @@
+-- Adds the idempotency_keys table to a database created before it existed.
+-- init.sql already contains it, so fresh installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/003_idempotency_keys.sql
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key         TEXT      PRIMARY KEY,
+    fingerprint TEXT      NOT NULL,
+    status_code INTEGER,
+    response    TEXT,
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at  TIMESTAMP NOT NULL,
+    CONSTRAINT chk_idempotency_response CHECK ((status_code IS NULL) = (response IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+COMMIT;
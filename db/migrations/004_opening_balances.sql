@@ -0,0 +1,52 @@
+-- Posts opening balances for wallets that hold money the journal does not
+-- know about. Wallets funded before the journal existed have a balance but no
+-- postings, and JournalService refuses to post against a wallet whose balance
+-- does not match its postings, so every such wallet would fail its next
+-- deposit or withdraw until this has run.
+--
+--   psql -d db_wallet_app -f db/migrations/004_opening_balances.sql
+--
+-- Each out-of-line wallet gets one 'opening_balance' entry for the difference,
+-- with SYSTEM_CASH on the other side, the same way a deposit or withdraw is
+-- posted. Running it again is a no-op because balanced wallets are skipped.
+BEGIN;
+
+DO $$
+DECLARE
+    w          RECORD;
+    difference BIGINT;
+    new_entry  INTEGER;
+BEGIN
+    FOR w IN
+        SELECT
+            wl.id,
+            wl.currency,
+            wl.balance - COALESCE(SUM(CASE WHEN p.direction = 'credit' THEN p.amount ELSE -p.amount END), 0) AS difference
+        FROM wallets wl
+        LEFT JOIN journal_postings p ON p.wallet_id = wl.id
+        GROUP BY wl.id, wl.currency, wl.balance
+        ORDER BY wl.id
+    LOOP
+        difference := w.difference;
+        CONTINUE WHEN difference = 0;
+
+        INSERT INTO journal_entries (type)
+        VALUES ('opening_balance')
+        RETURNING id INTO new_entry;
+
+        IF difference > 0 THEN
+            INSERT INTO journal_postings (entry_id, wallet_id, system_account, currency, direction, amount)
+            VALUES
+                (new_entry, NULL, 'SYSTEM_CASH', w.currency, 'debit', difference),
+                (new_entry, w.id, NULL, w.currency, 'credit', difference);
+        ELSE
+            INSERT INTO journal_postings (entry_id, wallet_id, system_account, currency, direction, amount)
+            VALUES
+                (new_entry, w.id, NULL, w.currency, 'debit', -difference),
+                (new_entry, NULL, 'SYSTEM_CASH', w.currency, 'credit', -difference);
+        END IF;
+    END LOOP;
+END;
+$$;
+
+COMMIT;
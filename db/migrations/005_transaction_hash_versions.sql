@@ -0,0 +1,97 @@
+-- Brings the transaction hash chain of an existing database up to date.
+-- init.sql already contains these columns, so fresh installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/005_transaction_hash_versions.sql
+--
+-- Rows written before the chain existed get a prev_hash linking them in id
+-- order. Every row then gets the hash_version whose preimage reproduces its
+-- stored hash, so verification recomputes it the way it was written (see
+-- model.HashVersion* for what each version covers). Version 6 is only given
+-- when the hash including memo, reference and metadata reproduces; a row
+-- without them hashes the same under 5 and 6 and gets 6. A row that matches
+-- no version has been edited; it is given the current version and
+-- verification reports it as hash_mismatch.
+BEGIN;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS prev_hash TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS hash_version SMALLINT;
+
+UPDATE transactions t
+SET prev_hash = chain.prev_hash
+FROM (
+    SELECT id, COALESCE(LAG(hash) OVER (ORDER BY id), repeat('0', 64)) AS prev_hash
+    FROM transactions
+) chain
+WHERE
+    t.id = chain.id
+AND t.prev_hash IS NULL;
+
+CREATE FUNCTION pg_temp.hash_hex(raw TEXT) RETURNS TEXT AS $$
+    SELECT encode(sha256(convert_to(raw, 'UTF8')), 'hex');
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Go's time.RFC3339 and time.RFC3339Nano in UTC. The nano form drops
+-- trailing zeros from the fraction, and the fraction itself when it is zero.
+CREATE FUNCTION pg_temp.rfc3339(ts TIMESTAMP) RETURNS TEXT AS $$
+    SELECT to_char(ts, 'YYYY-MM-DD"T"HH24:MI:SS') || 'Z';
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE FUNCTION pg_temp.rfc3339_nano(ts TIMESTAMP) RETURNS TEXT AS $$
+    SELECT regexp_replace(to_char(ts, 'YYYY-MM-DD"T"HH24:MI:SS.US'), '\.?0+$', '') || 'Z';
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Go's json.Marshal of a string, which also escapes <, >, &, U+2028 and
+-- U+2029.
+CREATE FUNCTION pg_temp.json_string(raw TEXT) RETURNS TEXT AS $$
+    SELECT replace(replace(replace(replace(replace(to_json(raw)::TEXT,
+        '<', '\u003c'), '>', '\u003e'), '&', '\u0026'), U&'\2028', '\u2028'), U&'\2029', '\u2029');
+$$ LANGUAGE sql IMMUTABLE;
+
+-- utils.transactionDetailsForHash: memo, reference and metadata as JSON with
+-- metadata keys in byte order, or NULL when none are set so concat_ws leaves
+-- the field out.
+CREATE FUNCTION pg_temp.details(memo TEXT, reference TEXT, metadata JSONB) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN memo IS NULL AND reference IS NULL AND (metadata IS NULL OR metadata = '{}'::JSONB) THEN NULL
+        ELSE '{"memo":' || COALESCE(pg_temp.json_string(memo), 'null')
+            || ',"reference":' || COALESCE(pg_temp.json_string(reference), 'null')
+            || ',"metadata":' || CASE
+                WHEN metadata IS NULL THEN 'null'
+                ELSE COALESCE((
+                    SELECT '{' || string_agg(pg_temp.json_string(key) || ':' || pg_temp.json_string(value), ',' ORDER BY key COLLATE "C") || '}'
+                    FROM jsonb_each_text(metadata)
+                ), '{}')
+            END
+            || '}'
+    END;
+$$ LANGUAGE sql IMMUTABLE;
+
+UPDATE transactions t
+SET hash_version = CASE
+    WHEN t.hash = pg_temp.hash_hex(concat_ws('|', t.prev_hash, t.username, t.type, t.direction, t.currency, t.pocket, t.amount, f.counterparty, f.fx_rate, f.quote_id, f.journal_entry_id, f.reversal_of, pg_temp.rfc3339_nano(t.timestamp), pg_temp.details(t.memo, t.reference, t.metadata))) THEN 6
+    WHEN t.hash = pg_temp.hash_hex(concat_ws('|', t.prev_hash, t.username, t.type, t.direction, t.currency, t.amount, f.counterparty, f.fx_rate, f.quote_id, f.journal_entry_id, f.reversal_of, pg_temp.rfc3339_nano(t.timestamp))) THEN 4
+    WHEN t.hash = pg_temp.hash_hex(concat_ws('|', t.prev_hash, t.username, t.type, t.currency, t.amount, f.counterparty, f.fx_rate, f.quote_id, pg_temp.rfc3339_nano(t.timestamp))) THEN 3
+    WHEN t.hash = pg_temp.hash_hex(concat_ws('|', t.username, t.type, t.currency, t.amount, f.counterparty, pg_temp.rfc3339(t.timestamp))) THEN 2
+    WHEN t.hash = pg_temp.hash_hex(concat_ws('|', t.username, t.type, t.amount, f.counterparty, pg_temp.rfc3339(t.timestamp))) THEN 1
+    ELSE 6
+END
+FROM (
+    SELECT
+        id,
+        COALESCE(counterparty, '') AS counterparty,
+        COALESCE(fx_rate::TEXT, '') AS fx_rate,
+        COALESCE(quote_id, '') AS quote_id,
+        COALESCE(journal_entry_id::TEXT, '') AS journal_entry_id,
+        COALESCE(reversal_of::TEXT, '') AS reversal_of
+    FROM transactions
+) f
+WHERE
+    t.id = f.id
+AND t.hash_version IS NULL;
+
+ALTER TABLE transactions ALTER COLUMN prev_hash SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN hash_version SET NOT NULL;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_hash_version;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_hash_version CHECK (hash_version BETWEEN 1 AND 6);
+
+COMMIT;
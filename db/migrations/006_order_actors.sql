@@ -0,0 +1,20 @@
+-- Adds the acting user to scheduled transfers and standing orders created
+-- before they stored one. init.sql already contains the column, so fresh
+-- installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/006_order_actors.sql
+--
+-- Existing rows get their own username as actor, which is what they ran as
+-- before. On a personal wallet that keeps them working. On a joint wallet it
+-- fails at execution with ERR_WALLET_ACCESS_DENIED, as it already did.
+BEGIN;
+
+ALTER TABLE standing_orders ADD COLUMN IF NOT EXISTS actor TEXT;
+UPDATE standing_orders SET actor = username WHERE actor IS NULL;
+ALTER TABLE standing_orders ALTER COLUMN actor SET NOT NULL;
+
+ALTER TABLE scheduled_transfers ADD COLUMN IF NOT EXISTS actor TEXT;
+UPDATE scheduled_transfers SET actor = username WHERE actor IS NULL;
+ALTER TABLE scheduled_transfers ALTER COLUMN actor SET NOT NULL;
+
+COMMIT;
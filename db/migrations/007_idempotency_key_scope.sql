@@ -0,0 +1,28 @@
+-- Scopes idempotency keys to the username they were sent for, so two users
+-- choosing the same key no longer collide. init.sql already has the new
+-- primary key, so fresh installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/007_idempotency_key_scope.sql
+--
+-- Every committed key has a stored response naming the wallet it acted on,
+-- and that wallet's username becomes the key's owner. A row whose response
+-- names no wallet is dropped, so a retry with its key runs as a new request.
+BEGIN;
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS username TEXT;
+
+UPDATE idempotency_keys
+SET username = COALESCE(
+    response::jsonb -> 'wallet' ->> 'username',
+    response::jsonb -> 'approval' ->> 'username'
+)
+WHERE username IS NULL
+AND response IS NOT NULL;
+
+DELETE FROM idempotency_keys WHERE username IS NULL;
+
+ALTER TABLE idempotency_keys ALTER COLUMN username SET NOT NULL;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (username, key);
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Adds the acting user to holds placed before they stored one. init.sql
+-- already contains the column, so fresh installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/008_hold_actors.sql
+--
+-- Existing rows get their own username as actor. Holds could not be placed
+-- on joint wallets before, so that is who placed every one of them.
+BEGIN;
+
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS actor TEXT;
+UPDATE holds SET actor = username WHERE actor IS NULL;
+ALTER TABLE holds ALTER COLUMN actor SET NOT NULL;
+
+COMMIT;
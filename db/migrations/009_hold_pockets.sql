@@ -0,0 +1,19 @@
+-- Records the pocket a hold reserves funds in. init.sql already contains the
+-- column, so fresh installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/009_hold_pockets.sql
+--
+-- A hold points at the wallet row it reserved funds on, and each pocket is
+-- its own row, so existing holds take the pocket of that row.
+BEGIN;
+
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS pocket TEXT;
+UPDATE holds h
+SET pocket = w.pocket
+FROM wallets w
+WHERE w.id = h.wallet_id
+AND h.pocket IS NULL;
+ALTER TABLE holds ALTER COLUMN pocket SET DEFAULT 'MAIN';
+ALTER TABLE holds ALTER COLUMN pocket SET NOT NULL;
+
+COMMIT;
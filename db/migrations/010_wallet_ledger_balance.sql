@@ -0,0 +1,35 @@
+-- Keeps a running ledger balance on every wallet row so JournalService can
+-- check a posting against it without re-summing the wallet's whole posting
+-- history. init.sql already contains the column and trigger, so fresh
+-- installs do not need it.
+--
+--   psql -d db_wallet_app -f db/migrations/010_wallet_ledger_balance.sql
+--
+-- Run it after 004_opening_balances.sql. Existing wallets start from the sum
+-- of their postings, and the trigger keeps the column in step from then on.
+BEGIN;
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS ledger_balance BIGINT NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION apply_wallet_posting() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE wallets
+    SET ledger_balance = ledger_balance + CASE WHEN NEW.direction = 'credit' THEN NEW.amount ELSE -NEW.amount END
+    WHERE id = NEW.wallet_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_wallet_posting ON journal_postings;
+CREATE TRIGGER trg_wallet_posting
+    AFTER INSERT ON journal_postings
+    FOR EACH ROW WHEN (NEW.wallet_id IS NOT NULL) EXECUTE FUNCTION apply_wallet_posting();
+
+UPDATE wallets w
+SET ledger_balance = COALESCE((
+    SELECT SUM(CASE WHEN p.direction = 'credit' THEN p.amount ELSE -p.amount END)
+    FROM journal_postings p
+    WHERE p.wallet_id = w.id
+), 0);
+
+COMMIT;
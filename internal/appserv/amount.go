@@ -0,0 +1,330 @@
+package appserv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	AMOUNT_FORMAT_HEADER  = "Amount-Format"
+	AMOUNT_FORMAT_DECIMAL = "decimal"
+
+	MAX_REQUEST_BODY_BYTES = 1 << 20
+)
+
+// amountFields lists every JSON key that carries an amount in minor units.
+// An amount is in the currency of the nearest enclosing object with a
+// currency key, except where the key names its own currency field.
+var amountFields = map[string]string{
+	"amount":             "",
+	"approvalThreshold":  "",
+	"availableBalance":   "",
+	"balance":            "",
+	"capturedAmount":     "",
+	"closingBalance":     "",
+	"creditLimit":        "",
+	"credits":            "",
+	"debits":             "",
+	"drift":              "",
+	"fee":                "",
+	"fees":               "",
+	"flatFee":            "",
+	"forfeited":          "",
+	"heldBalance":        "",
+	"lastDepositAmount":  "",
+	"lastWithdrawAmount": "",
+	"maxAmount":          "",
+	"maxBalance":         "",
+	"maxFee":             "",
+	"minAmount":          "",
+	"minFee":             "",
+	"net":                "",
+	"openingBalance":     "",
+	"overdraftAvailable": "",
+	"overdraftUsed":      "",
+	"percentageFee":      "",
+	"refundedAmount":     "",
+	"releasedAmount":     "",
+	"remaining":          "",
+	"total":              "",
+	"transactionBalance": "",
+	"usage":              "",
+	"walletBalance":      "",
+	"baseAmount":         "baseCurrency",
+	"quoteAmount":        "quoteCurrency",
+}
+
+// storedCurrencyEndpoints take an amount in the currency of a record that
+// already exists, so their requests have no currency to default. Every other
+// request defaults to model.DefaultCurrency, the same as its handler.
+var storedCurrencyEndpoints = map[string]struct{}{
+	HOLD_CAPTURE:        {},
+	ESCROW_RELEASE:      {},
+	ESCROW_REFUND:       {},
+	TRANSACTION_REVERSE: {},
+}
+
+// opaqueFields hold client data that is passed through untouched, even where
+// it happens to use a key from amountFields.
+var opaqueFields = map[string]struct{}{
+	"metadata": {},
+}
+
+type amountFormatError struct {
+	err error
+}
+
+func (e *amountFormatError) Error() string { return e.err.Error() }
+
+type amountConverter func(value json.RawMessage, currency *model.Currency) (json.RawMessage, error)
+
+// decimalToMinor turns a decimal string such as "12.34" into minor units.
+// Numbers are taken to be minor units already and pass through unchanged.
+func decimalToMinor(value json.RawMessage, currency *model.Currency) (json.RawMessage, error) {
+	if value[0] != '"' {
+		return value, nil
+	}
+	var raw string
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, err
+	}
+	if currency == nil {
+		return nil, &amountFormatError{fmt.Errorf("%q is a decimal string, which needs a supported currency in the request", raw)}
+	}
+	amount, err := validation.ParseDecimalAmount(raw, *currency)
+	if err != nil {
+		return nil, &amountFormatError{err}
+	}
+	return json.Marshal(amount)
+}
+
+// minorToDecimal turns a whole number of minor units into a decimal string.
+// Amounts with no currency in scope are left as numbers.
+func minorToDecimal(value json.RawMessage, currency *model.Currency) (json.RawMessage, error) {
+	if currency == nil || value[0] == '"' || value[0] == 'n' {
+		return value, nil
+	}
+	var amount int64
+	if err := json.Unmarshal(value, &amount); err != nil {
+		return value, nil
+	}
+	return json.Marshal(currency.FormatAmount(amount))
+}
+
+func convertAmounts(value json.RawMessage, currency *model.Currency, convert amountConverter) (json.RawMessage, error) {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 {
+		return value, nil
+	}
+	switch value[0] {
+	case '{':
+		return convertObject(value, currency, convert)
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(value, &items); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, item := range items {
+			converted, err := convertAmounts(item, currency, convert)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(converted)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+	default:
+		return value, nil
+	}
+}
+
+// convertObject walks the object's fields in their original order so the
+// rewritten body reads the same as the one the handler produced.
+func convertObject(value json.RawMessage, inherited *model.Currency, convert amountConverter) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	fields := map[string]json.RawMessage{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		var field json.RawMessage
+		if err := decoder.Decode(&field); err != nil {
+			return nil, err
+		}
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+		fields[key] = field
+	}
+
+	currency := inherited
+	if _, ok := fields["currency"]; ok {
+		currency = currencyField(fields, "currency", inherited)
+	} else if _, ok := fields["baseCurrency"]; ok {
+		currency = currencyField(fields, "baseCurrency", inherited)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range keys {
+		field := bytes.TrimSpace(fields[key])
+		var err error
+		_, opaque := opaqueFields[key]
+		currencyKey, isAmount := amountFields[key]
+		switch {
+		case opaque:
+		case isAmount && len(field) > 0 && field[0] != '{' && field[0] != '[':
+			fieldCurrency := currency
+			if currencyKey != "" {
+				fieldCurrency = currencyField(fields, currencyKey, nil)
+			}
+			field, err = convert(field, fieldCurrency)
+		default:
+			field, err = convertAmounts(field, currency, convert)
+		}
+		if err != nil {
+			if fieldErr := (*amountFormatError)(nil); errors.As(err, &fieldErr) {
+				return nil, &amountFormatError{fmt.Errorf("%s: %w", key, fieldErr.err)}
+			}
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(field)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// currencyField reads the currency named by key. A blank or null code falls
+// back to the given currency, the way handlers default an empty one. A
+// missing key or an unsupported code leaves no currency at all.
+func currencyField(fields map[string]json.RawMessage, key string, fallback *model.Currency) *model.Currency {
+	var code string
+	if err := json.Unmarshal(fields[key], &code); err != nil {
+		return nil
+	}
+	if strings.TrimSpace(code) == "" {
+		return fallback
+	}
+	currency, err := validation.SanitizeAndValidateCurrency(code)
+	if err != nil {
+		return nil
+	}
+	return &currency
+}
+
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponseWriter) Header() http.Header         { return b.header }
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *bufferedResponseWriter) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// withAmountFormat accepts decimal string amounts in request bodies and, when
+// the client sends Amount-Format: decimal, renders response amounts the same
+// way. Everything else about the request and response is left alone.
+func withAmountFormat(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Method == http.MethodPost {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MAX_REQUEST_BODY_BYTES))
+			r.Body.Close()
+			if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+				logger.Warn("Rejected request body", zap.String("path", r.URL.Path), zap.Int64("limit", tooLarge.Limit))
+				writeErrorResponse(w, http.StatusRequestEntityTooLarge, validation.ERR_REQUEST_BODY_TOO_LARGE, fmt.Sprintf("Request body is larger than %d bytes", tooLarge.Limit))
+				return
+			}
+			if err != nil {
+				body = nil
+			}
+
+			var currency *model.Currency
+			if _, ok := storedCurrencyEndpoints[routePattern(mux, r)]; !ok {
+				defaultCurrency, _ := model.LookupCurrency(model.DefaultCurrency)
+				currency = &defaultCurrency
+			}
+			converted, err := convertAmounts(body, currency, decimalToMinor)
+			var formatErr *amountFormatError
+			switch {
+			case errors.As(err, &formatErr):
+				logger.Warn("Rejected decimal amount", zap.String("path", r.URL.Path), zap.Error(err))
+				writeErrorResponse(w, http.StatusBadRequest, validation.ERR_AMOUNT_FORMAT_INVALID, formatErr.Error())
+				return
+			case err == nil:
+				body = converted
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+
+		if !strings.EqualFold(r.Header.Get(AMOUNT_FORMAT_HEADER), AMOUNT_FORMAT_DECIMAL) {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		buffered := &bufferedResponseWriter{header: w.Header()}
+		mux.ServeHTTP(buffered, r)
+		if buffered.status == 0 {
+			buffered.status = http.StatusOK
+		}
+
+		body := buffered.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			if converted, err := convertAmounts(body, nil, minorToDecimal); err == nil {
+				body = append(converted, '\n')
+				w.Header().Set(AMOUNT_FORMAT_HEADER, AMOUNT_FORMAT_DECIMAL)
+			} else {
+				logger.Warn("Failed to format response amounts", zap.String("path", r.URL.Path), zap.Error(err))
+			}
+		}
+		w.WriteHeader(buffered.status)
+		w.Write(body)
+	})
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, code validation.WalletErrorCode, message string) {
+	resp := response.ErrorResponse{
+		Status:  status,
+		Code:    string(code),
+		Message: message,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.Status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Error("Failed to encode error response", zap.Error(err))
+	}
+}
@@ -0,0 +1,387 @@
+package appserv
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+func mustCurrency(t *testing.T, code string) model.Currency {
+	t.Helper()
+	currency, ok := model.LookupCurrency(code)
+	if !ok {
+		t.Fatalf("currency %s is not supported", code)
+	}
+	return currency
+}
+
+func TestParseDecimalAmount(t *testing.T) {
+	type testCase struct {
+		name      string
+		raw       string
+		currency  string
+		expected  int64
+		expectErr bool
+	}
+
+	tests := []testCase{
+		{name: "Successful Parse - Exact decimal places", raw: "12.34", currency: "USD", expected: 1234},
+		{name: "Successful Parse - Fewer decimal places are padded", raw: "12.3", currency: "USD", expected: 1230},
+		{name: "Successful Parse - Whole number", raw: "12", currency: "USD", expected: 1200},
+		{name: "Successful Parse - Smallest unit", raw: "0.01", currency: "USD", expected: 1},
+		{name: "Successful Parse - Negative amount", raw: "-1.50", currency: "USD", expected: -150},
+		{name: "Successful Parse - Zero exponent", raw: "100", currency: "JPY", expected: 100},
+		{name: "Successful Parse - Three decimal places", raw: "1.5", currency: "KWD", expected: 1500},
+		{name: "Failed Parse - Too many decimal places are not rounded", raw: "12.345", currency: "USD", expectErr: true},
+		{name: "Failed Parse - Half unit is not rounded", raw: "0.005", currency: "USD", expectErr: true},
+		{name: "Failed Parse - Decimal places on zero exponent", raw: "100.0", currency: "JPY", expectErr: true},
+		{name: "Failed Parse - Missing whole part", raw: ".5", currency: "USD", expectErr: true},
+		{name: "Failed Parse - Missing fraction", raw: "5.", currency: "USD", expectErr: true},
+		{name: "Failed Parse - Exponent notation", raw: "1e3", currency: "USD", expectErr: true},
+		{name: "Failed Parse - Plus sign", raw: "+1", currency: "USD", expectErr: true},
+		{name: "Failed Parse - Padded", raw: " 1.00", currency: "USD", expectErr: true},
+		{name: "Failed Parse - Empty", raw: "", currency: "USD", expectErr: true},
+		{name: "Failed Parse - Out of range", raw: "99999999999999999999", currency: "USD", expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := validation.ParseDecimalAmount(test.raw, mustCurrency(t, test.currency))
+
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected error but got %d", actual)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %d but got %d instead", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	type testCase struct {
+		name     string
+		amount   int64
+		currency string
+		expected string
+	}
+
+	tests := []testCase{
+		{name: "Format - Two decimal places", amount: 1234, currency: "USD", expected: "12.34"},
+		{name: "Format - Below one major unit", amount: 5, currency: "USD", expected: "0.05"},
+		{name: "Format - Zero", amount: 0, currency: "USD", expected: "0.00"},
+		{name: "Format - Negative below one major unit", amount: -5, currency: "USD", expected: "-0.05"},
+		{name: "Format - Negative", amount: -123456, currency: "EUR", expected: "-1234.56"},
+		{name: "Format - Three decimal places", amount: 1500, currency: "KWD", expected: "1.500"},
+		{name: "Format - Smallest three decimal unit", amount: 1, currency: "KWD", expected: "0.001"},
+		{name: "Format - Zero exponent", amount: 1234, currency: "JPY", expected: "1234"},
+		{name: "Format - Negative zero exponent", amount: -1234, currency: "JPY", expected: "-1234"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			currency := mustCurrency(t, test.currency)
+
+			actual := currency.FormatAmount(test.amount)
+			if actual != test.expected {
+				t.Errorf("expected %s but got %s instead", test.expected, actual)
+			}
+
+			parsed, err := validation.ParseDecimalAmount(actual, currency)
+			if err != nil {
+				t.Fatalf("expected %s to parse back but got error: %v", actual, err)
+			}
+			if parsed != test.amount {
+				t.Errorf("expected %s to parse back to %d but got %d instead", actual, test.amount, parsed)
+			}
+		})
+	}
+}
+
+func TestConvertRequestAmounts(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name          string
+		body          string
+		storedRecord  bool
+		expected      string
+		expectedField string
+	}
+
+	tests := []testCase{
+		{
+			name:     "Convert - Currency omitted uses the default like the handlers",
+			body:     `{"username":"juan","amount":"12.34"}`,
+			expected: `{"username":"juan","amount":1234}`,
+		},
+		{
+			name:     "Convert - Blank currency uses the default",
+			body:     `{"username":"juan","currency":"","amount":"12.34"}`,
+			expected: `{"username":"juan","currency":"","amount":1234}`,
+		},
+		{
+			name:     "Convert - Named currency sets the exponent",
+			body:     `{"username":"juan","currency":"kwd","amount":"1.5"}`,
+			expected: `{"username":"juan","currency":"kwd","amount":1500}`,
+		},
+		{
+			name:     "Convert - Minor units pass through",
+			body:     `{"currency":"USD","amount":1234,"memo":"12.34"}`,
+			expected: `{"currency":"USD","amount":1234,"memo":"12.34"}`,
+		},
+		{
+			name:     "Convert - Array items inherit the enclosing currency",
+			body:     `{"username":"juan","currency":"JPY","items":[{"counterparty":"mary","amount":"100"},{"counterparty":"pedro","amount":5}]}`,
+			expected: `{"username":"juan","currency":"JPY","items":[{"counterparty":"mary","amount":100},{"counterparty":"pedro","amount":5}]}`,
+		},
+		{
+			name:     "Convert - Nested object uses its own currency",
+			body:     `{"currency":"USD","amount":"1.00","limits":{"currency":"KWD","maxAmount":"2.5"},"rule":{"minFee":"0.10"}}`,
+			expected: `{"currency":"USD","amount":100,"limits":{"currency":"KWD","maxAmount":2500},"rule":{"minFee":10}}`,
+		},
+		{
+			name:     "Convert - Amount keyed to its own currency field",
+			body:     `{"baseCurrency":"USD","quoteCurrency":"JPY","baseAmount":"1.50","quoteAmount":"150"}`,
+			expected: `{"baseCurrency":"USD","quoteCurrency":"JPY","baseAmount":150,"quoteAmount":150}`,
+		},
+		{
+			name:     "Convert - Metadata is left alone",
+			body:     `{"amount":"1.00","metadata":{"amount":"12.345"}}`,
+			expected: `{"amount":100,"metadata":{"amount":"12.345"}}`,
+		},
+		{
+			name:          "Failed Convert - Too many decimal places",
+			body:          `{"username":"juan","amount":"12.345"}`,
+			expectedField: "amount",
+		},
+		{
+			name:          "Failed Convert - Too many decimal places in array item",
+			body:          `{"currency":"JPY","items":[{"amount":"1.5"}]}`,
+			expectedField: "items",
+		},
+		{
+			name:          "Failed Convert - Unsupported currency",
+			body:          `{"currency":"XYZ","amount":"1.00"}`,
+			expectedField: "amount",
+		},
+		{
+			name:          "Failed Convert - Not a decimal",
+			body:          `{"amount":"ten"}`,
+			expectedField: "amount",
+		},
+		{
+			name:          "Failed Convert - Stored record has no currency to default",
+			body:          `{"amount":"1.00"}`,
+			storedRecord:  true,
+			expectedField: "amount",
+		},
+		{
+			name:         "Convert - Stored record still takes minor units",
+			body:         `{"amount":100}`,
+			storedRecord: true,
+			expected:     `{"amount":100}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var currency *model.Currency
+			if !test.storedRecord {
+				defaultCurrency := mustCurrency(t, model.DefaultCurrency)
+				currency = &defaultCurrency
+			}
+
+			actual, err := convertAmounts(json.RawMessage(test.body), currency, decimalToMinor)
+
+			if test.expectedField != "" {
+				var formatErr *amountFormatError
+				if !errors.As(err, &formatErr) {
+					t.Fatalf("expected amount format error but got %v", err)
+				}
+				if !strings.HasPrefix(formatErr.Error(), test.expectedField+":") {
+					t.Errorf("expected error for field %s but got %q instead", test.expectedField, formatErr.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual) != test.expected {
+				t.Errorf("expected %s but got %s instead", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConvertResponseAmounts(t *testing.T) {
+	type testCase struct {
+		name     string
+		body     string
+		expected string
+	}
+
+	tests := []testCase{
+		{
+			name:     "Format - Nested objects use the nearest currency",
+			body:     `{"status":200,"wallet":{"username":"JUAN","currency":"KWD","balance":1500,"limits":{"minAmount":1,"maxAmount":299999}}}`,
+			expected: `{"status":200,"wallet":{"username":"JUAN","currency":"KWD","balance":"1.500","limits":{"minAmount":"0.001","maxAmount":"299.999"}}}`,
+		},
+		{
+			name:     "Format - Array items each use their own currency",
+			body:     `{"transactions":[{"currency":"USD","amount":5},{"currency":"JPY","amount":5}]}`,
+			expected: `{"transactions":[{"currency":"USD","amount":"0.05"},{"currency":"JPY","amount":"5"}]}`,
+		},
+		{
+			name:     "Format - Amount keyed to its own currency field",
+			body:     `{"quote":{"baseCurrency":"USD","quoteCurrency":"JPY","baseAmount":150,"quoteAmount":150}}`,
+			expected: `{"quote":{"baseCurrency":"USD","quoteCurrency":"JPY","baseAmount":"1.50","quoteAmount":"150"}}`,
+		},
+		{
+			name:     "Format - Batch total and fees use the batch currency",
+			body:     `{"status":200,"mode":"atomic","currency":"USD","total":1500,"fees":30,"succeeded":2,"failed":0}`,
+			expected: `{"status":200,"mode":"atomic","currency":"USD","total":"15.00","fees":"0.30","succeeded":2,"failed":0}`,
+		},
+		{
+			name:     "Format - No currency in scope leaves numbers",
+			body:     `{"status":200,"amount":1234}`,
+			expected: `{"status":200,"amount":1234}`,
+		},
+		{
+			name:     "Format - Nulls and metadata are left alone",
+			body:     `{"currency":"USD","creditLimit":null,"metadata":{"amount":"7"}}`,
+			expected: `{"currency":"USD","creditLimit":null,"metadata":{"amount":"7"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := convertAmounts(json.RawMessage(test.body), nil, minorToDecimal)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual) != test.expected {
+				t.Errorf("expected %s but got %s instead", test.expected, actual)
+			}
+		})
+	}
+}
+
+func newEchoMux() *http.ServeMux {
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+DEPOSIT, echo)
+	mux.HandleFunc("POST "+HOLD_CAPTURE, echo)
+	return mux
+}
+
+func TestWithAmountFormat(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name           string
+		path           string
+		body           string
+		decimalHeader  bool
+		expectedStatus int
+		expectedCode   validation.WalletErrorCode
+		expectedBody   string
+	}
+
+	tests := []testCase{
+		{
+			name:           "Request - Decimal amount reaches the handler in minor units",
+			path:           DEPOSIT,
+			body:           `{"username":"juan","amount":"12.34"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"username":"juan","amount":1234}`,
+		},
+		{
+			name:           "Request - Decimal header formats the response",
+			path:           DEPOSIT,
+			body:           `{"username":"juan","currency":"KWD","amount":1500}`,
+			decimalHeader:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"username":"juan","currency":"KWD","amount":"1.500"}` + "\n",
+		},
+		{
+			name:           "Failed Request - Stored record endpoint rejects decimal amount",
+			path:           "/holds/7/capture",
+			body:           `{"amount":"1.00"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   validation.ERR_AMOUNT_FORMAT_INVALID,
+		},
+		{
+			name:           "Failed Request - Too many decimal places",
+			path:           DEPOSIT,
+			body:           `{"username":"juan","amount":"1.001"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   validation.ERR_AMOUNT_FORMAT_INVALID,
+		},
+		{
+			name:           "Failed Request - Body over the size limit",
+			path:           DEPOSIT,
+			body:           `{"username":"` + strings.Repeat("a", MAX_REQUEST_BODY_BYTES) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   validation.ERR_REQUEST_BODY_TOO_LARGE,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			if test.decimalHeader {
+				r.Header.Set(AMOUNT_FORMAT_HEADER, AMOUNT_FORMAT_DECIMAL)
+			}
+			w := httptest.NewRecorder()
+
+			withAmountFormat(newEchoMux()).ServeHTTP(w, r)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status %d but got %d instead: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if test.expectedCode != "" {
+				var resp response.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode error response: %v", err)
+				}
+				if resp.Code != string(test.expectedCode) {
+					t.Errorf("expected error %s but got %s instead", test.expectedCode, resp.Code)
+				}
+				return
+			}
+
+			if w.Body.String() != test.expectedBody {
+				t.Errorf("expected body %s but got %s instead", test.expectedBody, w.Body.String())
+			}
+			if test.decimalHeader && w.Header().Get(AMOUNT_FORMAT_HEADER) != AMOUNT_FORMAT_DECIMAL {
+				t.Errorf("expected %s header to be echoed back", AMOUNT_FORMAT_HEADER)
+			}
+		})
+	}
+}
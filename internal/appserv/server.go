@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ezjuanify/wallet/internal/logger"
@@ -22,29 +23,124 @@ type AppServer struct {
 }
 
 const (
-	DEPOSIT        = "/deposit"
-	WITHDRAW       = "/withdraw"
-	TRANSFER       = "/transfer"
-	HEALTH         = "/health"
-	TRANSACTION    = "/transactions"
-	BALANCE        = "/balance"
-	ADMIN_BALANCES = "/admin/balances"
+	WALLETS              = "/wallets"
+	DEPOSIT              = "/deposit"
+	WITHDRAW             = "/withdraw"
+	TRANSFER             = "/transfer"
+	TRANSFER_BATCH       = "/transfers/batch"
+	HEALTH               = "/health"
+	TRANSACTION          = "/transactions"
+	BALANCE              = "/balance"
+	POCKETS              = "/pockets"
+	POCKETS_MOVE         = "/pockets/move"
+	JOINT_WALLETS        = "/joint-wallets"
+	JOINT_WALLET_MEMBERS = "/joint-wallets/{username}/members"
+	JOINT_WALLET_RULES   = "/joint-wallets/{username}/rules"
+	APPROVALS            = "/approvals"
+	APPROVAL_APPROVE     = "/approvals/{id}/approve"
+	APPROVAL_REJECT      = "/approvals/{id}/reject"
+	STATEMENTS           = "/statements"
+	ADMIN_BALANCES       = "/admin/balances"
+	ADMIN_FX_RATES       = "/admin/fx/rates"
+	ADMIN_FEES           = "/admin/fees"
+	ADMIN_INTEREST       = "/admin/interest"
+	ADMIN_INTEREST_RUN   = "/admin/interest/run"
+	ADMIN_CREDIT_LIMITS  = "/admin/credit-limits"
+	ADMIN_LIMITS         = "/admin/limits"
+	ADMIN_VELOCITY       = "/admin/velocity-limits"
+	ADMIN_STATEMENTS_RUN = "/admin/statements/run"
+	ADMIN_OVERDRAFTS     = "/admin/overdrafts"
+	ADMIN_OVERDRAFTS_RUN = "/admin/overdrafts/charge"
+	ADMIN_WALLET_STATUS  = "/admin/wallets/status"
+	ADMIN_WALLET_FREEZE  = "/admin/wallets/freeze"
+	ADMIN_WALLET_THAW    = "/admin/wallets/unfreeze"
+	ADMIN_WALLET_CLOSE   = "/admin/wallets/close"
+	FX_QUOTES            = "/fx/quotes"
+	ADMIN_LEDGER_VERIFY  = "/admin/ledger/verify"
+	ADMIN_RECONCILIATION = "/admin/reconciliation"
+	TRANSACTION_REVERSE  = "/transactions/{id}/reverse"
+	HOLDS                = "/holds"
+	HOLD_CAPTURE         = "/holds/{id}/capture"
+	HOLD_VOID            = "/holds/{id}/void"
+	SCHEDULED_TRANSFERS  = "/scheduled-transfers"
+	SCHEDULED_CANCEL     = "/scheduled-transfers/{id}/cancel"
+	STANDING_ORDERS      = "/standing-orders"
+	STANDING_CANCEL      = "/standing-orders/{id}/cancel"
+	ESCROWS              = "/escrows"
+	ESCROW_RELEASE       = "/escrows/{id}/release"
+	ESCROW_REFUND        = "/escrows/{id}/refund"
 )
 
 var POSTEndpoint = map[string]struct{}{
-	DEPOSIT:  {},
-	WITHDRAW: {},
-	TRANSFER: {},
+	WALLETS:              {},
+	DEPOSIT:              {},
+	WITHDRAW:             {},
+	TRANSFER:             {},
+	TRANSFER_BATCH:       {},
+	POCKETS:              {},
+	POCKETS_MOVE:         {},
+	JOINT_WALLETS:        {},
+	JOINT_WALLET_MEMBERS: {},
+	JOINT_WALLET_RULES:   {},
+	APPROVAL_APPROVE:     {},
+	APPROVAL_REJECT:      {},
+	ADMIN_FX_RATES:       {},
+	ADMIN_FEES:           {},
+	ADMIN_INTEREST_RUN:   {},
+	ADMIN_CREDIT_LIMITS:  {},
+	ADMIN_LIMITS:         {},
+	ADMIN_VELOCITY:       {},
+	ADMIN_STATEMENTS_RUN: {},
+	ADMIN_OVERDRAFTS_RUN: {},
+	ADMIN_WALLET_FREEZE:  {},
+	ADMIN_WALLET_THAW:    {},
+	ADMIN_WALLET_CLOSE:   {},
+	FX_QUOTES:            {},
+	TRANSACTION_REVERSE:  {},
+	HOLDS:                {},
+	HOLD_CAPTURE:         {},
+	HOLD_VOID:            {},
+	SCHEDULED_TRANSFERS:  {},
+	SCHEDULED_CANCEL:     {},
+	STANDING_ORDERS:      {},
+	STANDING_CANCEL:      {},
+	ESCROWS:              {},
+	ESCROW_RELEASE:       {},
+	ESCROW_REFUND:        {},
 }
 
 var GETEndpoint = map[string]struct{}{
-	TRANSACTION:    {},
-	HEALTH:         {},
-	BALANCE:        {},
-	ADMIN_BALANCES: {},
+	TRANSACTION:          {},
+	HEALTH:               {},
+	BALANCE:              {},
+	POCKETS:              {},
+	JOINT_WALLETS:        {},
+	APPROVALS:            {},
+	STATEMENTS:           {},
+	ADMIN_BALANCES:       {},
+	ADMIN_FX_RATES:       {},
+	ADMIN_FEES:           {},
+	ADMIN_INTEREST:       {},
+	ADMIN_OVERDRAFTS:     {},
+	ADMIN_LIMITS:         {},
+	ADMIN_VELOCITY:       {},
+	ADMIN_WALLET_STATUS:  {},
+	ADMIN_LEDGER_VERIFY:  {},
+	ADMIN_RECONCILIATION: {},
+	SCHEDULED_TRANSFERS:  {},
+	STANDING_ORDERS:      {},
+	ESCROWS:              {},
 }
 
-func requestLogger(next http.Handler) http.Handler {
+func routePattern(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+	return pattern
+}
+
+func requestLogger(mux *http.ServeMux) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ip, _, _ := net.SplitHostPort(r.RemoteAddr)
 
@@ -56,13 +152,13 @@ func requestLogger(next http.Handler) http.Handler {
 
 		switch r.Method {
 		case http.MethodPost:
-			if _, ok := POSTEndpoint[r.URL.Path]; !ok {
+			if _, ok := POSTEndpoint[routePattern(mux, r)]; !ok {
 				logger.Warn(fmt.Sprintf("No %s method for %s endpoint", r.Method, r.URL.Path))
 				http.Error(w, "Invalid POST endpoint", http.StatusNotFound)
 				return
 			}
 		case http.MethodGet:
-			if _, ok := GETEndpoint[r.URL.Path]; !ok {
+			if _, ok := GETEndpoint[routePattern(mux, r)]; !ok {
 				logger.Warn(fmt.Sprintf("No %s method for %s endpoint", r.Method, r.URL.Path))
 				http.Error(w, "Invalid GET endpoint", http.StatusNotFound)
 				return
@@ -74,7 +170,7 @@ func requestLogger(next http.Handler) http.Handler {
 		}
 
 		start := time.Now()
-		next.ServeHTTP(w, r)
+		withAmountFormat(mux).ServeHTTP(w, r)
 
 		logger.Info("Request completed",
 			zap.String("method", r.Method),
@@ -3,6 +3,8 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -16,6 +18,88 @@ type Store struct {
 	DB *sql.DB
 }
 
+const walletColumns = "id, username, currency, pocket, status, opened_via, opened_at, balance, held_balance, balance - held_balance + credit_limit, credit_limit, overdrawn_since, min_amount, max_amount, max_balance, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated"
+
+func scanWallet(row interface{ Scan(dest ...any) error }, wallet *model.Wallet) error {
+	return row.Scan(
+		&wallet.ID,
+		&wallet.Username,
+		&wallet.Currency,
+		&wallet.Pocket,
+		&wallet.Status,
+		&wallet.OpenedVia,
+		&wallet.OpenedAt,
+		&wallet.Balance,
+		&wallet.HeldBalance,
+		&wallet.AvailableBalance,
+		&wallet.CreditLimit,
+		&wallet.OverdrawnSince,
+		&wallet.Limits.MinAmount,
+		&wallet.Limits.MaxAmount,
+		&wallet.Limits.MaxBalance,
+		&wallet.LastDepositAmount,
+		&wallet.LastDepositUpdated,
+		&wallet.LastWithdrawAmount,
+		&wallet.LastWithdrawUpdated,
+	)
+}
+
+const transactionColumns = "id, username, type, direction, currency, pocket, amount, counterparty, fx_rate::TEXT, quote_id, journal_entry_id, reversal_of, memo, reference, metadata, timestamp, prev_hash, hash, hash_version"
+
+func scanTransaction(row interface{ Scan(dest ...any) error }, txn *model.Transaction) error {
+	var metadata []byte
+	err := row.Scan(
+		&txn.ID,
+		&txn.Username,
+		&txn.TxnType,
+		&txn.Direction,
+		&txn.Currency,
+		&txn.Pocket,
+		&txn.Amount,
+		&txn.Counterparty,
+		&txn.FXRate,
+		&txn.QuoteID,
+		&txn.JournalEntryID,
+		&txn.ReversalOf,
+		&txn.Memo,
+		&txn.Reference,
+		&metadata,
+		&txn.Timestamp,
+		&txn.PrevHash,
+		&txn.Hash,
+		&txn.HashVersion,
+	)
+	if err != nil {
+		return err
+	}
+	txn.Metadata, err = decodeMetadata(metadata)
+	return err
+}
+
+// encodeMetadata stores an empty map as NULL so it reads back the same way
+// it was hashed.
+func encodeMetadata(metadata map[string]string) (any, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func decodeMetadata(raw []byte) (map[string]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
 type PGConfig struct {
 	Host string
 	Port int64
@@ -74,7 +158,7 @@ func (s *Store) FetchTransaction(ctx context.Context, criteria *model.Criteria)
 		argPos     = 1
 	)
 
-	query.WriteString("SELECT id, username, type, amount, counterparty, timestamp, hash FROM transactions")
+	query.WriteString("SELECT " + transactionColumns + " FROM transactions")
 
 	if criteria.Username != "" {
 		conditions = append(conditions, fmt.Sprintf("username = $%d", argPos))
@@ -91,6 +175,21 @@ func (s *Store) FetchTransaction(ctx context.Context, criteria *model.Criteria)
 		args = append(args, criteria.TxnType)
 		argPos++
 	}
+	if criteria.Currency != "" {
+		conditions = append(conditions, fmt.Sprintf("currency = $%d", argPos))
+		args = append(args, criteria.Currency)
+		argPos++
+	}
+	if criteria.Pocket != "" {
+		conditions = append(conditions, fmt.Sprintf("pocket = $%d", argPos))
+		args = append(args, criteria.Pocket)
+		argPos++
+	}
+	if criteria.Reference != "" {
+		conditions = append(conditions, fmt.Sprintf("reference = $%d", argPos))
+		args = append(args, criteria.Reference)
+		argPos++
+	}
 	if len(conditions) > 0 {
 		query.WriteString(" WHERE ")
 		query.WriteString(strings.Join(conditions, " AND "))
@@ -120,15 +219,7 @@ func (s *Store) FetchTransaction(ctx context.Context, criteria *model.Criteria)
 
 	for rows.Next() {
 		var txn model.Transaction
-		err := rows.Scan(
-			&txn.ID,
-			&txn.Username,
-			&txn.TxnType,
-			&txn.Amount,
-			&txn.Counterparty,
-			&txn.Timestamp,
-			&txn.Hash,
-		)
+		err := scanTransaction(rows, &txn)
 		if err != nil {
 			return nil, err
 		}
@@ -145,52 +236,88 @@ func (s *Store) FetchTransaction(ctx context.Context, criteria *model.Criteria)
 	return transactions, nil
 }
 
-func (s *Store) InsertTransaction(ctx context.Context, tx *sql.Tx, txn model.Transaction) error {
+func (s *Store) InsertTransaction(ctx context.Context, tx *sql.Tx, txn *model.Transaction) error {
 	fnName := "DBStore.InsertTransaction"
 	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("transaction", txn))
+	metadata, err := encodeMetadata(txn.Metadata)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO transactions (username, type, amount, counterparty, timestamp, hash) 
-		VALUES ($1, $2, $3, $4, $5, $6);
+		INSERT INTO transactions (username, type, direction, currency, pocket, amount, counterparty, fx_rate, quote_id, journal_entry_id, reversal_of, memo, reference, metadata, timestamp, prev_hash, hash, hash_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id;
 	`
 	logger.Debug("InsertTransaction - query", zap.String("query", query))
 
-	_, err := tx.ExecContext(
+	return tx.QueryRowContext(
 		ctx,
 		query,
 		txn.Username,
 		txn.TxnType,
+		txn.Direction,
+		txn.Currency,
+		txn.Pocket,
 		txn.Amount,
 		txn.Counterparty,
+		txn.FXRate,
+		txn.QuoteID,
+		txn.JournalEntryID,
+		txn.ReversalOf,
+		txn.Memo,
+		txn.Reference,
+		metadata,
 		txn.Timestamp,
+		txn.PrevHash,
 		txn.Hash,
-	)
-	return err
+		txn.HashVersion,
+	).Scan(&txn.ID)
 }
 
-func (s *Store) FetchWallet(ctx context.Context, username string) (*model.Wallet, error) {
+func (s *Store) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
 	fnName := "DBStore.FetchWallet"
-	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username))
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket))
 	query := `
-		SELECT username, balance, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated
+		SELECT ` + walletColumns + `
 		FROM wallets
-		WHERE username = $1;
+		WHERE username = $1
+		AND currency = $2
+		AND pocket = $3;
 	`
 	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
 
-	row := s.DB.QueryRowContext(ctx, query, username)
+	row := s.DB.QueryRowContext(ctx, query, username, currency, pocket)
 
 	var wallet model.Wallet
-	err := row.Scan(
-		&wallet.Username,
-		&wallet.Balance,
-		&wallet.LastDepositAmount,
-		&wallet.LastDepositUpdated,
-		&wallet.LastWithdrawAmount,
-		&wallet.LastWithdrawUpdated,
-	)
+	err := scanWallet(row, &wallet)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Debug(fmt.Sprintf("%s - No wallet found for username", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket))
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("wallet", wallet))
+	return &wallet, nil
+}
+
+func (s *Store) FetchWalletForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Wallet, error) {
+	fnName := "DBStore.FetchWalletForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id))
+	query := `
+		SELECT ` + walletColumns + `
+		FROM wallets
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var wallet model.Wallet
+	err := scanWallet(tx.QueryRowContext(ctx, query, id), &wallet)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			logger.Debug(fmt.Sprintf("%s - No wallet found for username", fnName), zap.String("username", username))
+			logger.Debug(fmt.Sprintf("%s - No wallet found for id", fnName), zap.Int64("id", id))
 			return nil, nil
 		}
 		return nil, err
@@ -203,8 +330,9 @@ func (s *Store) FetchAllWallet(ctx context.Context) ([]model.Wallet, error) {
 	fnName := "DBStore.FetchAllWallet"
 	logger.Debug(fmt.Sprintf("%s - no params to receive", fnName))
 	query := `
-		SELECT username, balance, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated
-		FROM wallets;
+		SELECT ` + walletColumns + `
+		FROM wallets
+		ORDER BY username, currency, pocket;
 	`
 	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
 
@@ -218,14 +346,7 @@ func (s *Store) FetchAllWallet(ctx context.Context) ([]model.Wallet, error) {
 
 	for rows.Next() {
 		var wallet model.Wallet
-		err := rows.Scan(
-			&wallet.Username,
-			&wallet.Balance,
-			&wallet.LastDepositAmount,
-			&wallet.LastDepositUpdated,
-			&wallet.LastWithdrawAmount,
-			&wallet.LastWithdrawUpdated,
-		)
+		err := scanWallet(rows, &wallet)
 		if err != nil {
 			return nil, err
 		}
@@ -235,36 +356,72 @@ func (s *Store) FetchAllWallet(ctx context.Context) ([]model.Wallet, error) {
 	return wallets, nil
 }
 
-func (s *Store) UpsertWallet(ctx context.Context, tx *sql.Tx, username string, amount int64) (*model.Wallet, error) {
+func (s *Store) InsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, limits model.WalletLimits) (*model.Wallet, error) {
+	fnName := "DBStore.InsertWallet"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.Any("limits", limits))
+	query := `
+		INSERT INTO wallets (username, currency, pocket, opened_via, min_amount, max_amount, max_balance)
+		VALUES ($1, $2, 'MAIN', 'explicit', $3, $4, $5)
+		ON CONFLICT (username, currency, pocket) DO NOTHING
+		RETURNING ` + walletColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var wallet model.Wallet
+	err := scanWallet(tx.QueryRowContext(
+		ctx,
+		query,
+		username,
+		currency,
+		limits.MinAmount,
+		limits.MaxAmount,
+		limits.MaxBalance,
+	), &wallet)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("wallet", wallet))
+	return &wallet, nil
+}
+
+func (s *Store) UpsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
 	fnName := "DBStore.UpsertWallet"
-	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.Int64("amount", amount))
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket), zap.Int64("amount", amount))
+	currencyInfo, ok := model.LookupCurrency(currency)
+	if !ok {
+		return nil, fmt.Errorf("unsupported currency %s", currency)
+	}
+	limits := model.DefaultWalletLimits(currencyInfo)
+
 	query := `
-		INSERT INTO wallets (username, balance, last_deposit_amount, last_deposit_updated)
-		VALUES ($1, $2, $3, now())
-		ON CONFLICT (username)
+		INSERT INTO wallets (username, currency, pocket, opened_via, balance, last_deposit_amount, last_deposit_updated, min_amount, max_amount, max_balance)
+		VALUES ($1, $2, $3, 'auto', $4, $5, now(), $6, $7, $8)
+		ON CONFLICT (username, currency, pocket)
 		DO UPDATE SET 
 		balance              = wallets.balance + EXCLUDED.balance,
 		last_deposit_amount  = EXCLUDED.last_deposit_amount,
 		last_deposit_updated = now()
-		RETURNING username, balance, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated;
+		WHERE wallets.status IN ('active', 'frozen_debit')
+		RETURNING ` + walletColumns + `;
 	`
 	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
 
 	var wallet model.Wallet
-	err := tx.QueryRowContext(
+	err := scanWallet(tx.QueryRowContext(
 		ctx,
 		query,
 		username,
+		currency,
+		pocket,
 		amount,
 		amount,
-	).Scan(
-		&wallet.Username,
-		&wallet.Balance,
-		&wallet.LastDepositAmount,
-		&wallet.LastDepositUpdated,
-		&wallet.LastWithdrawAmount,
-		&wallet.LastWithdrawUpdated,
-	)
+		limits.MinAmount,
+		limits.MaxAmount,
+		limits.MaxBalance,
+	), &wallet)
 	if err != nil {
 		return nil, err
 	}
@@ -272,9 +429,9 @@ func (s *Store) UpsertWallet(ctx context.Context, tx *sql.Tx, username string, a
 	return &wallet, nil
 }
 
-func (s *Store) WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, amount int64) (*model.Wallet, error) {
+func (s *Store) WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
 	fnName := "DBStore.WithdrawWallet"
-	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.Int64("amount", amount))
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket), zap.Int64("amount", amount))
 	query := `
 		UPDATE wallets
 		SET
@@ -283,25 +440,23 @@ func (s *Store) WithdrawWallet(ctx context.Context, tx *sql.Tx, username string,
 			last_withdraw_updated = now()
 		WHERE
 			username = $2
-		AND balance >= $1
-		RETURNING username, balance, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated;
+		AND currency = $3
+		AND pocket = $4
+		AND status = 'active'
+		AND balance - held_balance + credit_limit >= $1
+		RETURNING ` + walletColumns + `;
 	`
 	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
 
 	var wallet model.Wallet
-	err := tx.QueryRowContext(
+	err := scanWallet(tx.QueryRowContext(
 		ctx,
 		query,
 		amount,
 		username,
-	).Scan(
-		&wallet.Username,
-		&wallet.Balance,
-		&wallet.LastDepositAmount,
-		&wallet.LastDepositUpdated,
-		&wallet.LastWithdrawAmount,
-		&wallet.LastWithdrawUpdated,
-	)
+		currency,
+		pocket,
+	), &wallet)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const escrowColumns = "id, payer, payee, currency, amount, released_amount, refunded_amount, status, expires_at, created_at, resolved_at"
+
+func scanEscrow(row interface{ Scan(dest ...any) error }, escrow *model.Escrow) error {
+	return row.Scan(
+		&escrow.ID,
+		&escrow.Payer,
+		&escrow.Payee,
+		&escrow.Currency,
+		&escrow.Amount,
+		&escrow.ReleasedAmount,
+		&escrow.RefundedAmount,
+		&escrow.Status,
+		&escrow.ExpiresAt,
+		&escrow.CreatedAt,
+		&escrow.ResolvedAt,
+	)
+}
+
+func (s *Store) InsertEscrow(ctx context.Context, tx *sql.Tx, escrow *model.Escrow) error {
+	fnName := "DBStore.InsertEscrow"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("escrow", escrow))
+	query := `
+		INSERT INTO escrows (payer, payee, currency, amount, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + escrowColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return scanEscrow(tx.QueryRowContext(
+		ctx,
+		query,
+		escrow.Payer,
+		escrow.Payee,
+		escrow.Currency,
+		escrow.Amount,
+		escrow.ExpiresAt,
+	), escrow)
+}
+
+func (s *Store) FetchEscrowForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Escrow, error) {
+	fnName := "DBStore.FetchEscrowForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id))
+	query := `
+		SELECT ` + escrowColumns + `
+		FROM escrows
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var escrow model.Escrow
+	if err := scanEscrow(tx.QueryRowContext(ctx, query, id), &escrow); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("escrow", escrow))
+	return &escrow, nil
+}
+
+func (s *Store) ClaimExpiredEscrow(ctx context.Context, tx *sql.Tx, at time.Time) (*model.Escrow, error) {
+	fnName := "DBStore.ClaimExpiredEscrow"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("at", at))
+	query := `
+		SELECT ` + escrowColumns + `
+		FROM escrows
+		WHERE status = 'funded'
+		AND expires_at <= $1
+		ORDER BY expires_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var escrow model.Escrow
+	if err := scanEscrow(tx.QueryRowContext(ctx, query, at), &escrow); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("escrow", escrow))
+	return &escrow, nil
+}
+
+func (s *Store) ResolveEscrow(ctx context.Context, tx *sql.Tx, escrow *model.Escrow) error {
+	fnName := "DBStore.ResolveEscrow"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("escrow", escrow))
+	query := `
+		UPDATE escrows
+		SET
+			released_amount = $2,
+			refunded_amount = $3,
+			status          = $4,
+			resolved_at     = $5
+		WHERE id = $1
+		AND status = 'funded';
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		escrow.ID,
+		escrow.ReleasedAmount,
+		escrow.RefundedAmount,
+		escrow.Status,
+		escrow.ResolvedAt,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("escrow %d is no longer funded", escrow.ID)
+	}
+	return nil
+}
+
+func (s *Store) FetchEscrows(ctx context.Context, criteria *model.EscrowCriteria) ([]model.Escrow, error) {
+	fnName := "DBStore.FetchEscrows"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("criteria", criteria))
+	var (
+		query      strings.Builder
+		args       []interface{}
+		conditions []string
+		argPos     = 1
+	)
+
+	query.WriteString("SELECT " + escrowColumns + " FROM escrows")
+
+	if criteria.Username != "" {
+		conditions = append(conditions, fmt.Sprintf("(payer = $%d OR payee = $%d)", argPos, argPos))
+		args = append(args, criteria.Username)
+		argPos++
+	}
+	if criteria.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, criteria.Status)
+		argPos++
+	}
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE ")
+		query.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	query.WriteString(" ORDER BY created_at DESC, id DESC")
+
+	if criteria.Limit > 0 {
+		query.WriteString(fmt.Sprintf(" LIMIT $%d ", argPos))
+		args = append(args, criteria.Limit)
+		argPos++
+	}
+
+	logger.Info(fmt.Sprintf("%s - Query built", fnName), zap.String("query", query.String()))
+
+	rows, err := s.DB.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	escrows := []model.Escrow{}
+	for rows.Next() {
+		var escrow model.Escrow
+		if err := scanEscrow(rows, &escrow); err != nil {
+			return nil, err
+		}
+		escrows = append(escrows, escrow)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug(fmt.Sprintf("%s - Escrows found", fnName), zap.Int("count", len(escrows)))
+	return escrows, nil
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const feeRuleColumns = "id, txn_type, currency, min_amount, max_amount, flat_fee, rate_bps, min_fee, max_fee, created_at, retired_at"
+
+func scanFeeRule(row interface{ Scan(dest ...any) error }, rule *model.FeeRule) error {
+	return row.Scan(
+		&rule.ID,
+		&rule.TxnType,
+		&rule.Currency,
+		&rule.MinAmount,
+		&rule.MaxAmount,
+		&rule.FlatFee,
+		&rule.RateBps,
+		&rule.MinFee,
+		&rule.MaxFee,
+		&rule.CreatedAt,
+		&rule.RetiredAt,
+	)
+}
+
+func (s *Store) RetireFeeRules(ctx context.Context, tx *sql.Tx, txnType model.TxnType) (int64, error) {
+	fnName := "DBStore.RetireFeeRules"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("txnType", string(txnType)))
+	query := `
+		UPDATE fee_rules
+		SET retired_at = now()
+		WHERE txn_type = $1
+		AND retired_at IS NULL;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, txnType)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) InsertFeeRule(ctx context.Context, tx *sql.Tx, rule *model.FeeRule) error {
+	fnName := "DBStore.InsertFeeRule"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("rule", rule))
+	query := `
+		INSERT INTO fee_rules (txn_type, currency, min_amount, max_amount, flat_fee, rate_bps, min_fee, max_fee)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + feeRuleColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return scanFeeRule(tx.QueryRowContext(
+		ctx,
+		query,
+		rule.TxnType,
+		rule.Currency,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.FlatFee,
+		rule.RateBps,
+		rule.MinFee,
+		rule.MaxFee,
+	), rule)
+}
+
+func (s *Store) FetchFeeRules(ctx context.Context, txnType string) ([]model.FeeRule, error) {
+	fnName := "DBStore.FetchFeeRules"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("txnType", txnType))
+	query := `
+		SELECT ` + feeRuleColumns + `
+		FROM fee_rules
+		WHERE retired_at IS NULL
+		AND ($1 = '' OR txn_type = $1)
+		ORDER BY txn_type, currency NULLS FIRST, min_amount;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, txnType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.FeeRule{}
+	for rows.Next() {
+		var rule model.FeeRule
+		if err := scanFeeRule(rows, &rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Fee rules found", fnName), zap.Int("count", len(rules)))
+	return rules, nil
+}
+
+func (s *Store) FetchApplicableFeeRule(ctx context.Context, tx *sql.Tx, txnType model.TxnType, currency string, amount int64) (*model.FeeRule, error) {
+	fnName := "DBStore.FetchApplicableFeeRule"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("txnType", string(txnType)), zap.String("currency", currency), zap.Int64("amount", amount))
+	query := `
+		SELECT ` + feeRuleColumns + `
+		FROM fee_rules
+		WHERE retired_at IS NULL
+		AND txn_type = $1
+		AND (currency = $2 OR currency IS NULL)
+		AND min_amount <= $3
+		AND (max_amount IS NULL OR max_amount > $3)
+		ORDER BY currency IS NULL, min_amount DESC, id DESC
+		LIMIT 1;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var rule model.FeeRule
+	if err := scanFeeRule(tx.QueryRowContext(ctx, query, txnType, currency, amount), &rule); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Debug(fmt.Sprintf("%s - No applicable fee rule found", fnName))
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("rule", rule))
+	return &rule, nil
+}
+
+func (s *Store) DebitWalletFee(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
+	fnName := "DBStore.DebitWalletFee"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket), zap.Int64("amount", amount))
+	query := `
+		UPDATE wallets
+		SET balance = balance - $1
+		WHERE
+			username = $2
+		AND currency = $3
+		AND pocket = $4
+		AND status = 'active'
+		AND balance - held_balance + credit_limit >= $1
+		RETURNING ` + walletColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var wallet model.Wallet
+	err := scanWallet(tx.QueryRowContext(
+		ctx,
+		query,
+		amount,
+		username,
+		currency,
+		pocket,
+	), &wallet)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("wallet", wallet))
+	return &wallet, nil
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) InsertFXRate(ctx context.Context, tx *sql.Tx, rate *model.FXRate) error {
+	fnName := "DBStore.InsertFXRate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("rate", rate))
+	query := `
+		INSERT INTO fx_rates (base_currency, quote_currency, rate, spread_bps, valid_from, valid_to)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, rate, created_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		rate.BaseCurrency,
+		rate.QuoteCurrency,
+		rate.Rate,
+		rate.SpreadBps,
+		rate.ValidFrom,
+		rate.ValidTo,
+	).Scan(&rate.ID, &rate.Rate, &rate.CreatedAt)
+}
+
+func (s *Store) FetchFXRates(ctx context.Context, baseCurrency string, quoteCurrency string) ([]model.FXRate, error) {
+	fnName := "DBStore.FetchFXRates"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("baseCurrency", baseCurrency), zap.String("quoteCurrency", quoteCurrency))
+	query := `
+		SELECT id, base_currency, quote_currency, rate, spread_bps, valid_from, valid_to, created_at
+		FROM fx_rates
+		WHERE ($1 = '' OR base_currency = $1)
+		AND ($2 = '' OR quote_currency = $2)
+		AND (valid_to IS NULL OR valid_to > now())
+		ORDER BY base_currency, quote_currency, valid_from DESC;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, baseCurrency, quoteCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []model.FXRate{}
+	for rows.Next() {
+		var rate model.FXRate
+		err := rows.Scan(
+			&rate.ID,
+			&rate.BaseCurrency,
+			&rate.QuoteCurrency,
+			&rate.Rate,
+			&rate.SpreadBps,
+			&rate.ValidFrom,
+			&rate.ValidTo,
+			&rate.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Rates found", fnName), zap.Int("count", len(rates)))
+	return rates, nil
+}
+
+func (s *Store) FetchActiveFXRate(ctx context.Context, baseCurrency string, quoteCurrency string, at time.Time) (*model.FXRate, error) {
+	fnName := "DBStore.FetchActiveFXRate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("baseCurrency", baseCurrency), zap.String("quoteCurrency", quoteCurrency), zap.Time("at", at))
+	query := `
+		SELECT id, base_currency, quote_currency, rate, spread_bps, valid_from, valid_to, created_at
+		FROM fx_rates
+		WHERE base_currency = $1
+		AND quote_currency = $2
+		AND valid_from <= $3
+		AND (valid_to IS NULL OR valid_to > $3)
+		ORDER BY valid_from DESC, id DESC
+		LIMIT 1;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var rate model.FXRate
+	err := s.DB.QueryRowContext(ctx, query, baseCurrency, quoteCurrency, at).Scan(
+		&rate.ID,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.SpreadBps,
+		&rate.ValidFrom,
+		&rate.ValidTo,
+		&rate.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Debug(fmt.Sprintf("%s - No active rate found", fnName))
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("rate", rate))
+	return &rate, nil
+}
+
+func (s *Store) InsertFXQuote(ctx context.Context, quote *model.FXQuote) error {
+	fnName := "DBStore.InsertFXQuote"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("quote", quote))
+	query := `
+		INSERT INTO fx_quotes (id, rate_id, username, base_currency, quote_currency, base_amount, quote_amount, rate, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	_, err := s.DB.ExecContext(
+		ctx,
+		query,
+		quote.ID,
+		quote.RateID,
+		quote.Username,
+		quote.BaseCurrency,
+		quote.QuoteCurrency,
+		quote.BaseAmount,
+		quote.QuoteAmount,
+		quote.Rate,
+		quote.ExpiresAt,
+		quote.CreatedAt,
+	)
+	return err
+}
+
+func (s *Store) FetchFXQuoteForUpdate(ctx context.Context, tx *sql.Tx, id string) (*model.FXQuote, error) {
+	fnName := "DBStore.FetchFXQuoteForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("id", id))
+	query := `
+		SELECT id, rate_id, username, base_currency, quote_currency, base_amount, quote_amount, rate, expires_at, used_at, created_at
+		FROM fx_quotes
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var quote model.FXQuote
+	err := tx.QueryRowContext(ctx, query, id).Scan(
+		&quote.ID,
+		&quote.RateID,
+		&quote.Username,
+		&quote.BaseCurrency,
+		&quote.QuoteCurrency,
+		&quote.BaseAmount,
+		&quote.QuoteAmount,
+		&quote.Rate,
+		&quote.ExpiresAt,
+		&quote.UsedAt,
+		&quote.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Debug(fmt.Sprintf("%s - No quote found", fnName), zap.String("id", id))
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("quote", quote))
+	return &quote, nil
+}
+
+func (s *Store) MarkFXQuoteUsed(ctx context.Context, tx *sql.Tx, id string, usedAt time.Time) error {
+	fnName := "DBStore.MarkFXQuoteUsed"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("id", id), zap.Time("usedAt", usedAt))
+	query := `
+		UPDATE fx_quotes
+		SET used_at = $2
+		WHERE id = $1
+		AND used_at IS NULL;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, id, usedAt)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return fmt.Errorf("quote %s was already used", id)
+	}
+	return nil
+}
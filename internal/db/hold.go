@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) ReserveWalletFunds(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) error {
+	fnName := "DBStore.ReserveWalletFunds"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("walletID", walletID), zap.Int64("amount", amount))
+	query := `
+		UPDATE wallets
+		SET held_balance = held_balance + $2
+		WHERE id = $1
+		AND balance - held_balance + credit_limit >= $2;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, walletID, amount)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("wallet %d has less than %d available", walletID, amount)
+	}
+	return nil
+}
+
+func (s *Store) ReleaseWalletFunds(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) error {
+	fnName := "DBStore.ReleaseWalletFunds"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("walletID", walletID), zap.Int64("amount", amount))
+	query := `
+		UPDATE wallets
+		SET held_balance = held_balance - $2
+		WHERE id = $1
+		AND held_balance >= $2;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, walletID, amount)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("wallet %d has less than %d held", walletID, amount)
+	}
+	return nil
+}
+
+func (s *Store) InsertHold(ctx context.Context, tx *sql.Tx, hold *model.Hold) error {
+	fnName := "DBStore.InsertHold"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("hold", hold))
+	query := `
+		INSERT INTO holds (wallet_id, username, actor, currency, pocket, amount, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, created_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		hold.WalletID,
+		hold.Username,
+		hold.Actor,
+		hold.Currency,
+		hold.Pocket,
+		hold.Amount,
+		hold.ExpiresAt,
+	).Scan(&hold.ID, &hold.Status, &hold.CreatedAt)
+}
+
+func (s *Store) FetchHoldForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Hold, error) {
+	fnName := "DBStore.FetchHoldForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id))
+	query := `
+		SELECT id, wallet_id, username, actor, currency, pocket, amount, captured_amount, status, expires_at, created_at, resolved_at
+		FROM holds
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var hold model.Hold
+	err := tx.QueryRowContext(ctx, query, id).Scan(
+		&hold.ID,
+		&hold.WalletID,
+		&hold.Username,
+		&hold.Actor,
+		&hold.Currency,
+		&hold.Pocket,
+		&hold.Amount,
+		&hold.CapturedAmount,
+		&hold.Status,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.ResolvedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("hold", hold))
+	return &hold, nil
+}
+
+func (s *Store) ResolveHold(ctx context.Context, tx *sql.Tx, hold *model.Hold) error {
+	fnName := "DBStore.ResolveHold"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("hold", hold))
+	query := `
+		UPDATE holds
+		SET
+			status          = $2,
+			captured_amount = $3,
+			resolved_at     = $4
+		WHERE id = $1
+		AND status = 'active';
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, hold.ID, hold.Status, hold.CapturedAmount, hold.ResolvedAt)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("hold %d is no longer active", hold.ID)
+	}
+	return nil
+}
+
+func (s *Store) ExpireHolds(ctx context.Context, at time.Time) ([]model.Hold, error) {
+	fnName := "DBStore.ExpireHolds"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("at", at))
+	query := `
+		WITH expired AS (
+			UPDATE holds
+			SET
+				status      = 'expired',
+				resolved_at = $1
+			WHERE status = 'active'
+			AND expires_at <= $1
+			RETURNING id, wallet_id, username, actor, currency, pocket, amount, captured_amount, status, expires_at, created_at, resolved_at
+		), released AS (
+			UPDATE wallets w
+			SET held_balance = w.held_balance - e.total
+			FROM (
+				SELECT wallet_id, SUM(amount) AS total
+				FROM expired
+				GROUP BY wallet_id
+			) e
+			WHERE w.id = e.wallet_id
+		)
+		SELECT id, wallet_id, username, actor, currency, pocket, amount, captured_amount, status, expires_at, created_at, resolved_at
+		FROM expired
+		ORDER BY id;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []model.Hold{}
+	for rows.Next() {
+		var hold model.Hold
+		err := rows.Scan(
+			&hold.ID,
+			&hold.WalletID,
+			&hold.Username,
+			&hold.Actor,
+			&hold.Currency,
+			&hold.Pocket,
+			&hold.Amount,
+			&hold.CapturedAmount,
+			&hold.Status,
+			&hold.ExpiresAt,
+			&hold.CreatedAt,
+			&hold.ResolvedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Holds expired", fnName), zap.Int("count", len(holds)))
+	return holds, nil
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+// ClaimIdempotencyKey inserts the user's key, or takes over one that has
+// expired. While another transaction holds an uncommitted claim on the same
+// key the insert waits for it, so a retry never runs alongside the original
+// request.
+func (s *Store) ClaimIdempotencyKey(ctx context.Context, tx *sql.Tx, username string, key string, fingerprint string, at time.Time, expiresAt time.Time) (bool, error) {
+	fnName := "DBStore.ClaimIdempotencyKey"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("key", key), zap.String("fingerprint", fingerprint), zap.Time("at", at), zap.Time("expiresAt", expiresAt))
+	query := `
+		INSERT INTO idempotency_keys (username, key, fingerprint, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (username, key) DO UPDATE
+		SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = NULL,
+			response    = NULL,
+			created_at  = EXCLUDED.created_at,
+			expires_at  = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= $4
+		RETURNING key;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var claimed string
+	err := tx.QueryRowContext(ctx, query, username, key, fingerprint, at, expiresAt).Scan(&claimed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Store) FetchIdempotencyKey(ctx context.Context, tx *sql.Tx, username string, key string) (*model.IdempotencyRecord, error) {
+	fnName := "DBStore.FetchIdempotencyKey"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("key", key))
+	query := `
+		SELECT username, key, fingerprint, status_code, response, created_at, expires_at
+		FROM idempotency_keys
+		WHERE username = $1
+		AND key = $2;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var record model.IdempotencyRecord
+	var response []byte
+	err := tx.QueryRowContext(ctx, query, username, key).Scan(
+		&record.Username,
+		&record.Key,
+		&record.Fingerprint,
+		&record.StatusCode,
+		&response,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	record.Response = response
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.String("username", record.Username), zap.String("key", record.Key), zap.Intp("statusCode", record.StatusCode))
+	return &record, nil
+}
+
+func (s *Store) SaveIdempotentResponse(ctx context.Context, tx *sql.Tx, username string, key string, statusCode int, response []byte) error {
+	fnName := "DBStore.SaveIdempotentResponse"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("key", key), zap.Int("statusCode", statusCode))
+	query := `
+		UPDATE idempotency_keys
+		SET
+			status_code = $3,
+			response    = $4
+		WHERE username = $1
+		AND key = $2;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, username, key, statusCode, string(response))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("idempotency key %q is not claimed for %s", key, username)
+	}
+	return nil
+}
+
+func (s *Store) DeleteExpiredIdempotencyKeys(ctx context.Context, at time.Time) (int64, error) {
+	fnName := "DBStore.DeleteExpiredIdempotencyKeys"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("at", at))
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= $1;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := s.DB.ExecContext(ctx, query, at)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
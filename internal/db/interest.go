@@ -0,0 +1,325 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const interestPostingColumns = "id, wallet_id, username, currency, period, days, accrued, amount, forfeited, transaction_id, created_at"
+
+func scanInterestPosting(row interface{ Scan(dest ...any) error }, posting *model.InterestPosting) error {
+	return row.Scan(
+		&posting.ID,
+		&posting.WalletID,
+		&posting.Username,
+		&posting.Currency,
+		&posting.Period,
+		&posting.Days,
+		&posting.Accrued,
+		&posting.Amount,
+		&posting.Forfeited,
+		&posting.TransactionID,
+		&posting.CreatedAt,
+	)
+}
+
+func (s *Store) FetchLastInterestAccrualDate(ctx context.Context) (*time.Time, error) {
+	fnName := "DBStore.FetchLastInterestAccrualDate"
+	query := `
+		SELECT MAX(accrual_date)
+		FROM interest_accruals;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var last *time.Time
+	if err := s.DB.QueryRowContext(ctx, query).Scan(&last); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("last", last))
+	return last, nil
+}
+
+func (s *Store) FetchEndOfDayBalances(ctx context.Context, tx *sql.Tx, cutoff time.Time) ([]model.EndOfDayBalance, error) {
+	fnName := "DBStore.FetchEndOfDayBalances"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("cutoff", cutoff))
+	query := `
+		SELECT w.id, w.username, w.currency, SUM(CASE WHEN p.direction = 'credit' THEN p.amount ELSE -p.amount END)
+		FROM wallets w
+		JOIN journal_postings p ON p.wallet_id = w.id
+		JOIN journal_entries e ON e.id = p.entry_id
+		WHERE e.timestamp < $1
+		AND w.username NOT LIKE 'SYS\_%'
+		GROUP BY w.id, w.username, w.currency
+		HAVING SUM(CASE WHEN p.direction = 'credit' THEN p.amount ELSE -p.amount END) > 0
+		ORDER BY w.id;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := tx.QueryContext(ctx, query, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []model.EndOfDayBalance{}
+	for rows.Next() {
+		var balance model.EndOfDayBalance
+		if err := rows.Scan(&balance.WalletID, &balance.Username, &balance.Currency, &balance.Balance); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Balances found", fnName), zap.Int("count", len(balances)))
+	return balances, nil
+}
+
+func (s *Store) InsertInterestAccrual(ctx context.Context, tx *sql.Tx, accrual *model.InterestAccrual) (bool, error) {
+	fnName := "DBStore.InsertInterestAccrual"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("accrual", accrual))
+	query := `
+		INSERT INTO interest_accruals (wallet_id, accrual_date, balance, rate_bps, amount)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (wallet_id, accrual_date) DO NOTHING
+		RETURNING id, created_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	err := tx.QueryRowContext(
+		ctx,
+		query,
+		accrual.WalletID,
+		accrual.AccrualDate,
+		accrual.Balance,
+		accrual.RateBps,
+		accrual.Amount,
+	).Scan(&accrual.ID, &accrual.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Debug(fmt.Sprintf("%s - Accrual already exists", fnName), zap.Int64("walletID", accrual.WalletID), zap.Time("accrualDate", accrual.AccrualDate))
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Store) FetchUnbookedInterestPeriods(ctx context.Context, before time.Time) ([]model.InterestPeriod, error) {
+	fnName := "DBStore.FetchUnbookedInterestPeriods"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("before", before))
+	query := `
+		SELECT wallet_id, date_trunc('month', accrual_date)::date AS period
+		FROM interest_accruals
+		WHERE posting_id IS NULL
+		AND accrual_date < $1
+		GROUP BY wallet_id, period
+		ORDER BY period, wallet_id;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []model.InterestPeriod{}
+	for rows.Next() {
+		var period model.InterestPeriod
+		if err := rows.Scan(&period.WalletID, &period.Period); err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Periods found", fnName), zap.Int("count", len(periods)))
+	return periods, nil
+}
+
+func (s *Store) ClaimUnbookedInterestAccruals(ctx context.Context, tx *sql.Tx, walletID int64, period time.Time) ([]model.InterestAccrual, error) {
+	fnName := "DBStore.ClaimUnbookedInterestAccruals"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("walletID", walletID), zap.Time("period", period))
+	query := `
+		SELECT id, wallet_id, accrual_date, balance, rate_bps, amount, posting_id, created_at
+		FROM interest_accruals
+		WHERE wallet_id = $1
+		AND posting_id IS NULL
+		AND accrual_date >= $2
+		AND accrual_date < ($2::date + interval '1 month')
+		ORDER BY accrual_date
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := tx.QueryContext(ctx, query, walletID, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accruals := []model.InterestAccrual{}
+	for rows.Next() {
+		var accrual model.InterestAccrual
+		err := rows.Scan(
+			&accrual.ID,
+			&accrual.WalletID,
+			&accrual.AccrualDate,
+			&accrual.Balance,
+			&accrual.RateBps,
+			&accrual.Amount,
+			&accrual.PostingID,
+			&accrual.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		accruals = append(accruals, accrual)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Accruals claimed", fnName), zap.Int("count", len(accruals)))
+	return accruals, nil
+}
+
+func (s *Store) CreditWalletInterest(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) (*model.Wallet, error) {
+	fnName := "DBStore.CreditWalletInterest"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("walletID", walletID), zap.Int64("amount", amount))
+	query := `
+		UPDATE wallets
+		SET balance = balance + $1
+		WHERE id = $2
+		AND status IN ('active', 'frozen_debit')
+		RETURNING ` + walletColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var wallet model.Wallet
+	err := scanWallet(tx.QueryRowContext(ctx, query, amount, walletID), &wallet)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("wallet", wallet))
+	return &wallet, nil
+}
+
+func (s *Store) InsertInterestPosting(ctx context.Context, tx *sql.Tx, posting *model.InterestPosting) error {
+	fnName := "DBStore.InsertInterestPosting"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("posting", posting))
+	query := `
+		INSERT INTO interest_postings (wallet_id, username, currency, period, days, accrued, amount, forfeited, transaction_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + interestPostingColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return scanInterestPosting(tx.QueryRowContext(
+		ctx,
+		query,
+		posting.WalletID,
+		posting.Username,
+		posting.Currency,
+		posting.Period,
+		posting.Days,
+		posting.Accrued,
+		posting.Amount,
+		posting.Forfeited,
+		posting.TransactionID,
+	), posting)
+}
+
+func (s *Store) MarkInterestAccrualsBooked(ctx context.Context, tx *sql.Tx, ids []int64, postingID int64) error {
+	fnName := "DBStore.MarkInterestAccrualsBooked"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64s("ids", ids), zap.Int64("postingID", postingID))
+	query := `
+		UPDATE interest_accruals
+		SET posting_id = $2
+		WHERE id = ANY($1)
+		AND posting_id IS NULL;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, ids, postingID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != int64(len(ids)) {
+		return fmt.Errorf("expected to book %d accruals but booked %d", len(ids), rows)
+	}
+	return nil
+}
+
+func (s *Store) FetchInterestPostings(ctx context.Context, criteria *model.InterestCriteria) ([]model.InterestPosting, error) {
+	fnName := "DBStore.FetchInterestPostings"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("criteria", criteria))
+	var (
+		query      strings.Builder
+		args       []interface{}
+		conditions []string
+		argPos     = 1
+	)
+
+	query.WriteString("SELECT " + interestPostingColumns + " FROM interest_postings")
+
+	if criteria.Username != "" {
+		conditions = append(conditions, fmt.Sprintf("username = $%d", argPos))
+		args = append(args, criteria.Username)
+		argPos++
+	}
+	if criteria.Period != nil {
+		conditions = append(conditions, fmt.Sprintf("period = $%d", argPos))
+		args = append(args, *criteria.Period)
+		argPos++
+	}
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE ")
+		query.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	query.WriteString(" ORDER BY period DESC, id DESC")
+
+	if criteria.Limit > 0 {
+		query.WriteString(fmt.Sprintf(" LIMIT $%d ", argPos))
+		args = append(args, criteria.Limit)
+		argPos++
+	}
+
+	logger.Info(fmt.Sprintf("%s - Query built", fnName), zap.String("query", query.String()))
+
+	rows, err := s.DB.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	postings := []model.InterestPosting{}
+	for rows.Next() {
+		var posting model.InterestPosting
+		if err := scanInterestPosting(rows, &posting); err != nil {
+			return nil, err
+		}
+		postings = append(postings, posting)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug(fmt.Sprintf("%s - Interest postings found", fnName), zap.Int("count", len(postings)))
+	return postings, nil
+}
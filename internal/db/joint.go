@@ -0,0 +1,375 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const approvalColumns = "a.id, a.wallet_username, a.currency, a.pocket, a.type, a.amount, a.counterparty, a.counterparty_currency, a.quote_id, a.memo, a.reference, a.metadata, a.initiator, a.required_approvals, a.status, a.transaction_id, a.expires_at, a.created_at, a.resolved_at, COALESCE((SELECT string_agg(v.member, ',' ORDER BY v.approved_at, v.member) FROM approval_votes v WHERE v.approval_id = a.id), '')"
+
+func scanApproval(row interface{ Scan(dest ...any) error }, approval *model.ApprovalRequest) error {
+	var approvals string
+	var metadata []byte
+	if err := row.Scan(
+		&approval.ID,
+		&approval.WalletUsername,
+		&approval.Currency,
+		&approval.Pocket,
+		&approval.TxnType,
+		&approval.Amount,
+		&approval.Counterparty,
+		&approval.CounterpartyCurrency,
+		&approval.QuoteID,
+		&approval.Memo,
+		&approval.Reference,
+		&metadata,
+		&approval.Initiator,
+		&approval.RequiredApprovals,
+		&approval.Status,
+		&approval.TransactionID,
+		&approval.ExpiresAt,
+		&approval.CreatedAt,
+		&approval.ResolvedAt,
+		&approvals,
+	); err != nil {
+		return err
+	}
+	approval.Approvals = []string{}
+	if approvals != "" {
+		approval.Approvals = strings.Split(approvals, ",")
+	}
+	var err error
+	approval.Metadata, err = decodeMetadata(metadata)
+	return err
+}
+
+func (s *Store) CountWallets(ctx context.Context, username string) (int64, error) {
+	fnName := "DBStore.CountWallets"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username))
+	query := `
+		SELECT COUNT(*)
+		FROM wallets
+		WHERE username = $1;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var count int64
+	if err := s.DB.QueryRowContext(ctx, query, username).Scan(&count); err != nil {
+		return 0, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int64("count", count))
+	return count, nil
+}
+
+func (s *Store) FetchWalletMembers(ctx context.Context, walletUsername string) ([]model.WalletMember, error) {
+	fnName := "DBStore.FetchWalletMembers"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("walletUsername", walletUsername))
+	query := `
+		SELECT wallet_username, member, role, created_at
+		FROM wallet_members
+		WHERE wallet_username = $1
+		ORDER BY created_at, member;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, walletUsername)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []model.WalletMember{}
+	for rows.Next() {
+		var member model.WalletMember
+		if err := rows.Scan(&member.WalletUsername, &member.Member, &member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("count", len(members)))
+	return members, nil
+}
+
+func (s *Store) UpsertWalletMember(ctx context.Context, tx *sql.Tx, member *model.WalletMember) error {
+	fnName := "DBStore.UpsertWalletMember"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("member", member))
+	query := `
+		INSERT INTO wallet_members (wallet_username, member, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (wallet_username, member)
+		DO UPDATE SET role = EXCLUDED.role
+		RETURNING created_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return tx.QueryRowContext(ctx, query, member.WalletUsername, member.Member, member.Role).Scan(&member.CreatedAt)
+}
+
+func (s *Store) DeleteWalletMember(ctx context.Context, tx *sql.Tx, walletUsername string, member string) (bool, error) {
+	fnName := "DBStore.DeleteWalletMember"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("walletUsername", walletUsername), zap.String("member", member))
+	query := `
+		DELETE FROM wallet_members
+		WHERE wallet_username = $1
+		AND member = $2;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, walletUsername, member)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (s *Store) FetchSigningRule(ctx context.Context, walletUsername string, currency string) (*model.SigningRule, error) {
+	fnName := "DBStore.FetchSigningRule"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("walletUsername", walletUsername), zap.String("currency", currency))
+	query := `
+		SELECT wallet_username, currency, approval_threshold, required_approvals, updated_at
+		FROM signing_rules
+		WHERE wallet_username = $1
+		AND currency = $2;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var rule model.SigningRule
+	err := s.DB.QueryRowContext(ctx, query, walletUsername, currency).Scan(
+		&rule.WalletUsername,
+		&rule.Currency,
+		&rule.ApprovalThreshold,
+		&rule.RequiredApprovals,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("rule", rule))
+	return &rule, nil
+}
+
+func (s *Store) FetchSigningRules(ctx context.Context, walletUsername string) ([]model.SigningRule, error) {
+	fnName := "DBStore.FetchSigningRules"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("walletUsername", walletUsername))
+	query := `
+		SELECT wallet_username, currency, approval_threshold, required_approvals, updated_at
+		FROM signing_rules
+		WHERE wallet_username = $1
+		ORDER BY currency;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, walletUsername)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.SigningRule{}
+	for rows.Next() {
+		var rule model.SigningRule
+		if err := rows.Scan(&rule.WalletUsername, &rule.Currency, &rule.ApprovalThreshold, &rule.RequiredApprovals, &rule.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("count", len(rules)))
+	return rules, nil
+}
+
+func (s *Store) UpsertSigningRule(ctx context.Context, tx *sql.Tx, rule *model.SigningRule) error {
+	fnName := "DBStore.UpsertSigningRule"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("rule", rule))
+	query := `
+		INSERT INTO signing_rules (wallet_username, currency, approval_threshold, required_approvals)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (wallet_username, currency)
+		DO UPDATE SET
+			approval_threshold = EXCLUDED.approval_threshold,
+			required_approvals = EXCLUDED.required_approvals,
+			updated_at         = now()
+		RETURNING updated_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return tx.QueryRowContext(ctx, query, rule.WalletUsername, rule.Currency, rule.ApprovalThreshold, rule.RequiredApprovals).Scan(&rule.UpdatedAt)
+}
+
+func (s *Store) DeleteSigningRule(ctx context.Context, tx *sql.Tx, walletUsername string, currency string) (bool, error) {
+	fnName := "DBStore.DeleteSigningRule"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("walletUsername", walletUsername), zap.String("currency", currency))
+	query := `
+		DELETE FROM signing_rules
+		WHERE wallet_username = $1
+		AND currency = $2;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, walletUsername, currency)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (s *Store) InsertApprovalRequest(ctx context.Context, tx *sql.Tx, approval *model.ApprovalRequest) error {
+	fnName := "DBStore.InsertApprovalRequest"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("approval", approval))
+	metadata, err := encodeMetadata(approval.Metadata)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO approval_requests (wallet_username, currency, pocket, type, amount, counterparty, counterparty_currency, quote_id, memo, reference, metadata, initiator, required_approvals, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, status, created_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		approval.WalletUsername,
+		approval.Currency,
+		approval.Pocket,
+		approval.TxnType,
+		approval.Amount,
+		approval.Counterparty,
+		approval.CounterpartyCurrency,
+		approval.QuoteID,
+		approval.Memo,
+		approval.Reference,
+		metadata,
+		approval.Initiator,
+		approval.RequiredApprovals,
+		approval.ExpiresAt,
+	).Scan(&approval.ID, &approval.Status, &approval.CreatedAt)
+}
+
+func (s *Store) InsertApprovalVote(ctx context.Context, tx *sql.Tx, approvalID int64, member string) (bool, error) {
+	fnName := "DBStore.InsertApprovalVote"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("approvalID", approvalID), zap.String("member", member))
+	query := `
+		INSERT INTO approval_votes (approval_id, member)
+		VALUES ($1, $2)
+		ON CONFLICT (approval_id, member) DO NOTHING;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, approvalID, member)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (s *Store) FetchApprovalRequestForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.ApprovalRequest, error) {
+	fnName := "DBStore.FetchApprovalRequestForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id))
+	query := `
+		SELECT ` + approvalColumns + `
+		FROM approval_requests a
+		WHERE a.id = $1
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var approval model.ApprovalRequest
+	if err := scanApproval(tx.QueryRowContext(ctx, query, id), &approval); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("approval", approval))
+	return &approval, nil
+}
+
+func (s *Store) FetchApprovalRequests(ctx context.Context, walletUsername string) ([]model.ApprovalRequest, error) {
+	fnName := "DBStore.FetchApprovalRequests"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("walletUsername", walletUsername))
+	query := `
+		SELECT ` + approvalColumns + `
+		FROM approval_requests a
+		WHERE a.wallet_username = $1
+		ORDER BY a.id DESC;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, walletUsername)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := []model.ApprovalRequest{}
+	for rows.Next() {
+		var approval model.ApprovalRequest
+		if err := scanApproval(rows, &approval); err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("count", len(approvals)))
+	return approvals, nil
+}
+
+func (s *Store) ResolveApprovalRequest(ctx context.Context, tx *sql.Tx, approval *model.ApprovalRequest) error {
+	fnName := "DBStore.ResolveApprovalRequest"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("approval", approval))
+	query := `
+		UPDATE approval_requests
+		SET
+			status         = $2,
+			transaction_id = $3,
+			resolved_at    = $4
+		WHERE id = $1
+		AND status = 'pending';
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, approval.ID, approval.Status, approval.TransactionID, approval.ResolvedAt)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("approval request %d is no longer pending", approval.ID)
+	}
+	return nil
+}
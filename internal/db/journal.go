@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) InsertJournalEntry(ctx context.Context, tx *sql.Tx, entry *model.JournalEntry) error {
+	fnName := "DBStore.InsertJournalEntry"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("entry", entry))
+	entryQuery := `
+		INSERT INTO journal_entries (type, timestamp)
+		VALUES ($1, $2)
+		RETURNING id;
+	`
+	logger.Debug(fmt.Sprintf("%s - entry query", fnName), zap.String("query", entryQuery))
+
+	if err := tx.QueryRowContext(ctx, entryQuery, entry.EntryType, entry.Timestamp).Scan(&entry.ID); err != nil {
+		return err
+	}
+
+	postingQuery := `
+		INSERT INTO journal_postings (entry_id, wallet_id, system_account, currency, direction, amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+	logger.Debug(fmt.Sprintf("%s - posting query", fnName), zap.String("query", postingQuery))
+
+	for i := range entry.Postings {
+		posting := &entry.Postings[i]
+		posting.EntryID = entry.ID
+		err := tx.QueryRowContext(
+			ctx,
+			postingQuery,
+			posting.EntryID,
+			posting.WalletID,
+			posting.SystemAccount,
+			posting.Currency,
+			posting.Direction,
+			posting.Amount,
+		).Scan(&posting.ID)
+		if err != nil {
+			return err
+		}
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("entry", entry))
+	return nil
+}
+
+func (s *Store) FetchWalletLedgerBalance(ctx context.Context, tx *sql.Tx, walletID int64) (int64, int64, error) {
+	fnName := "DBStore.FetchWalletLedgerBalance"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("walletID", walletID))
+	query := `
+		SELECT balance, ledger_balance
+		FROM wallets
+		WHERE id = $1;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var walletBalance, ledgerBalance int64
+	if err := tx.QueryRowContext(ctx, query, walletID).Scan(&walletBalance, &ledgerBalance); err != nil {
+		return 0, 0, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int64("walletBalance", walletBalance), zap.Int64("ledgerBalance", ledgerBalance))
+	return walletBalance, ledgerBalance, nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const transactionChainLockKey = 7_000_001
+
+func (s *Store) FetchLastTransactionHash(ctx context.Context, tx *sql.Tx) (*string, error) {
+	fnName := "DBStore.FetchLastTransactionHash"
+	lockQuery := `SELECT pg_advisory_xact_lock($1);`
+	logger.Debug(fmt.Sprintf("%s - lock query", fnName), zap.String("query", lockQuery))
+
+	if _, err := tx.ExecContext(ctx, lockQuery, transactionChainLockKey); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT hash
+		FROM transactions
+		ORDER BY id DESC
+		LIMIT 1;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var hash string
+	err := tx.QueryRowContext(ctx, query).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.String("hash", hash))
+	return &hash, nil
+}
+
+func (s *Store) FetchTransactionChain(ctx context.Context, afterID int64, limit int) ([]model.Transaction, error) {
+	fnName := "DBStore.FetchTransactionChain"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("afterID", afterID), zap.Int("limit", limit))
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []model.Transaction{}
+	for rows.Next() {
+		var txn model.Transaction
+		err := scanTransaction(rows, &txn)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, txn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Transactions found", fnName), zap.Int("count", len(transactions)))
+	return transactions, nil
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) FetchPocketsForUpdate(ctx context.Context, tx *sql.Tx, username string, currency string) ([]model.Wallet, error) {
+	fnName := "DBStore.FetchPocketsForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency))
+	query := `
+		SELECT ` + walletColumns + `
+		FROM wallets
+		WHERE
+			username = $1
+		AND currency = $2
+		ORDER BY id
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := tx.QueryContext(ctx, query, username, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := []model.Wallet{}
+	for rows.Next() {
+		var wallet model.Wallet
+		if err := scanWallet(rows, &wallet); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("count", len(wallets)))
+	return wallets, nil
+}
+
+func (s *Store) UpdateWalletStatus(ctx context.Context, tx *sql.Tx, username string, currency string, status model.WalletStatus) ([]model.Wallet, error) {
+	fnName := "DBStore.UpdateWalletStatus"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("status", string(status)))
+	query := `
+		UPDATE wallets
+		SET status = $3
+		WHERE
+			username = $1
+		AND currency = $2
+		RETURNING ` + walletColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := tx.QueryContext(ctx, query, username, currency, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := []model.Wallet{}
+	for rows.Next() {
+		var wallet model.Wallet
+		if err := scanWallet(rows, &wallet); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("count", len(wallets)))
+	return wallets, nil
+}
+
+func (s *Store) InsertWalletStatusChange(ctx context.Context, tx *sql.Tx, change *model.WalletStatusChange) error {
+	fnName := "DBStore.InsertWalletStatusChange"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("change", change))
+	query := `
+		INSERT INTO wallet_status_changes (username, currency, from_status, to_status, reason, note, actor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, changed_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		change.Username,
+		change.Currency,
+		change.FromStatus,
+		change.ToStatus,
+		change.Reason,
+		change.Note,
+		change.Actor,
+	).Scan(&change.ID, &change.ChangedAt)
+}
+
+func (s *Store) FetchWalletStatusChanges(ctx context.Context, username string, currency string) ([]model.WalletStatusChange, error) {
+	fnName := "DBStore.FetchWalletStatusChanges"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency))
+	query := `
+		SELECT id, username, currency, from_status, to_status, reason, note, actor, changed_at
+		FROM wallet_status_changes
+		WHERE
+			username = $1
+		AND currency = $2
+		ORDER BY changed_at DESC, id DESC;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, username, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []model.WalletStatusChange{}
+	for rows.Next() {
+		var change model.WalletStatusChange
+		if err := rows.Scan(
+			&change.ID,
+			&change.Username,
+			&change.Currency,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Reason,
+			&change.Note,
+			&change.Actor,
+			&change.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("count", len(changes)))
+	return changes, nil
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) UpdateWalletLimits(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, limits model.WalletLimits) (*model.Wallet, error) {
+	fnName := "DBStore.UpdateWalletLimits"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket), zap.Any("limits", limits))
+	query := `
+		UPDATE wallets
+		SET
+			min_amount  = $3,
+			max_amount  = $4,
+			max_balance = $5
+		WHERE
+			username = $1
+		AND currency = $2
+		AND pocket = $6
+		AND balance <= $5
+		RETURNING ` + walletColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var wallet model.Wallet
+	err := scanWallet(tx.QueryRowContext(
+		ctx,
+		query,
+		username,
+		currency,
+		limits.MinAmount,
+		limits.MaxAmount,
+		limits.MaxBalance,
+		pocket,
+	), &wallet)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("wallet", wallet))
+	return &wallet, nil
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) UpdateWalletCreditLimit(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, creditLimit int64) (*model.Wallet, error) {
+	fnName := "DBStore.UpdateWalletCreditLimit"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket), zap.Int64("creditLimit", creditLimit))
+	query := `
+		UPDATE wallets
+		SET credit_limit = $3
+		WHERE
+			username = $1
+		AND currency = $2
+		AND pocket = $4
+		AND balance - held_balance + $3 >= 0
+		RETURNING ` + walletColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var wallet model.Wallet
+	if err := scanWallet(tx.QueryRowContext(ctx, query, username, currency, creditLimit, pocket), &wallet); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("wallet", wallet))
+	return &wallet, nil
+}
+
+func (s *Store) FetchOverdrawnWallets(ctx context.Context) ([]model.Wallet, error) {
+	fnName := "DBStore.FetchOverdrawnWallets"
+	logger.Debug(fmt.Sprintf("%s - no params to receive", fnName))
+	query := `
+		SELECT ` + walletColumns + `
+		FROM wallets
+		WHERE balance < 0
+		ORDER BY overdrawn_since, username, currency, pocket;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := []model.Wallet{}
+	for rows.Next() {
+		var wallet model.Wallet
+		if err := scanWallet(rows, &wallet); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Overdrawn wallets found", fnName), zap.Int("count", len(wallets)))
+	return wallets, nil
+}
+
+func (s *Store) InsertOverdraftCharge(ctx context.Context, tx *sql.Tx, charge *model.OverdraftCharge) (bool, error) {
+	fnName := "DBStore.InsertOverdraftCharge"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("charge", charge))
+	query := `
+		INSERT INTO overdraft_charges (wallet_id, username, currency, charge_date, usage)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (wallet_id, charge_date) DO NOTHING
+		RETURNING id, created_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	err := tx.QueryRowContext(
+		ctx,
+		query,
+		charge.WalletID,
+		charge.Username,
+		charge.Currency,
+		charge.ChargeDate,
+		charge.Usage,
+	).Scan(&charge.ID, &charge.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Debug(fmt.Sprintf("%s - Overdraft already charged for day", fnName), zap.Int64("walletID", charge.WalletID), zap.Time("chargeDate", charge.ChargeDate))
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Store) UpdateOverdraftCharge(ctx context.Context, tx *sql.Tx, id int64, fee int64, transactionID *int64) error {
+	fnName := "DBStore.UpdateOverdraftCharge"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id), zap.Int64("fee", fee), zap.Any("transactionID", transactionID))
+	query := `
+		UPDATE overdraft_charges
+		SET fee = $2, transaction_id = $3
+		WHERE id = $1;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, id, fee, transactionID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("overdraft charge %d not found", id)
+	}
+	return nil
+}
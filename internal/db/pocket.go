@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) InsertPocket(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, limits model.WalletLimits) (*model.Wallet, error) {
+	fnName := "DBStore.InsertPocket"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket), zap.Any("limits", limits))
+	query := `
+		INSERT INTO wallets (username, currency, pocket, min_amount, max_amount, max_balance)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (username, currency, pocket) DO NOTHING
+		RETURNING ` + walletColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var wallet model.Wallet
+	err := scanWallet(tx.QueryRowContext(
+		ctx,
+		query,
+		username,
+		currency,
+		pocket,
+		limits.MinAmount,
+		limits.MaxAmount,
+		limits.MaxBalance,
+	), &wallet)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("wallet", wallet))
+	return &wallet, nil
+}
+
+func (s *Store) FetchPockets(ctx context.Context, username string, currency string) ([]model.Wallet, error) {
+	fnName := "DBStore.FetchPockets"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency))
+	query := `
+		SELECT ` + walletColumns + `
+		FROM wallets
+		WHERE
+			username = $1
+		AND currency = $2
+		ORDER BY pocket <> 'MAIN', pocket;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, username, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := []model.Wallet{}
+	for rows.Next() {
+		var wallet model.Wallet
+		if err := scanWallet(rows, &wallet); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("count", len(wallets)))
+	return wallets, nil
+}
+
+func (s *Store) MovePocketFunds(ctx context.Context, tx *sql.Tx, username string, currency string, from string, to string, amount int64) (*model.Wallet, *model.Wallet, error) {
+	fnName := "DBStore.MovePocketFunds"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("from", from), zap.String("to", to), zap.Int64("amount", amount))
+	lockQuery := `
+		SELECT id
+		FROM wallets
+		WHERE
+			username = $1
+		AND currency = $2
+		AND pocket IN ($3, $4)
+		ORDER BY id
+		FOR UPDATE;
+	`
+	debitQuery := `
+		UPDATE wallets
+		SET balance = balance - $4
+		WHERE
+			username = $1
+		AND currency = $2
+		AND pocket = $3
+		AND balance - held_balance >= $4
+		RETURNING ` + walletColumns + `;
+	`
+	creditQuery := `
+		UPDATE wallets
+		SET balance = balance + $4
+		WHERE
+			username = $1
+		AND currency = $2
+		AND pocket = $3
+		AND balance + $4 <= max_balance
+		RETURNING ` + walletColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("lockQuery", lockQuery), zap.String("debitQuery", debitQuery), zap.String("creditQuery", creditQuery))
+
+	rows, err := tx.QueryContext(ctx, lockQuery, username, currency, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var debited model.Wallet
+	if err := scanWallet(tx.QueryRowContext(ctx, debitQuery, username, currency, from, amount), &debited); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	var credited model.Wallet
+	if err := scanWallet(tx.QueryRowContext(ctx, creditQuery, username, currency, to, amount), &credited); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("from", debited), zap.Any("to", credited))
+	return &debited, &credited, nil
+}
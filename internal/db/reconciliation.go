@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) FetchReconciliationSnapshot(ctx context.Context) ([]model.Wallet, []model.TransactionTotal, []model.PostingTotal, error) {
+	fnName := "DBStore.FetchReconciliationSnapshot"
+	tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer tx.Rollback()
+
+	walletQuery := `
+		SELECT id, username, currency, pocket, balance
+		FROM wallets
+		ORDER BY username, currency, pocket;
+	`
+	logger.Debug(fmt.Sprintf("%s - wallet query", fnName), zap.String("query", walletQuery))
+
+	wallets := []model.Wallet{}
+	walletRows, err := tx.QueryContext(ctx, walletQuery)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer walletRows.Close()
+
+	for walletRows.Next() {
+		var wallet model.Wallet
+		if err := walletRows.Scan(&wallet.ID, &wallet.Username, &wallet.Currency, &wallet.Pocket, &wallet.Balance); err != nil {
+			return nil, nil, nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err := walletRows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	totalQuery := `
+		SELECT username, currency, pocket, direction, SUM(amount)
+		FROM transactions
+		GROUP BY username, currency, pocket, direction
+		ORDER BY username, currency, pocket, direction;
+	`
+	logger.Debug(fmt.Sprintf("%s - total query", fnName), zap.String("query", totalQuery))
+
+	totals := []model.TransactionTotal{}
+	totalRows, err := tx.QueryContext(ctx, totalQuery)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer totalRows.Close()
+
+	for totalRows.Next() {
+		var total model.TransactionTotal
+		if err := totalRows.Scan(&total.Username, &total.Currency, &total.Pocket, &total.Direction, &total.Amount); err != nil {
+			return nil, nil, nil, err
+		}
+		totals = append(totals, total)
+	}
+	if err := totalRows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	postingQuery := `
+		SELECT w.username, w.currency, w.pocket, p.direction, SUM(p.amount)
+		FROM journal_postings p
+		JOIN wallets w ON w.id = p.wallet_id
+		GROUP BY w.username, w.currency, w.pocket, p.direction
+		ORDER BY w.username, w.currency, w.pocket, p.direction;
+	`
+	logger.Debug(fmt.Sprintf("%s - posting query", fnName), zap.String("query", postingQuery))
+
+	postings := []model.PostingTotal{}
+	postingRows, err := tx.QueryContext(ctx, postingQuery)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer postingRows.Close()
+
+	for postingRows.Next() {
+		var posting model.PostingTotal
+		if err := postingRows.Scan(&posting.Username, &posting.Currency, &posting.Pocket, &posting.Direction, &posting.Amount); err != nil {
+			return nil, nil, nil, err
+		}
+		postings = append(postings, posting)
+	}
+	if err := postingRows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("wallets", len(wallets)), zap.Int("totals", len(totals)), zap.Int("postings", len(postings)))
+	return wallets, totals, postings, nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) FetchTransactionForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Transaction, error) {
+	fnName := "DBStore.FetchTransactionForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id))
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var txn model.Transaction
+	err := scanTransaction(tx.QueryRowContext(ctx, query, id), &txn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("transaction", txn))
+	return &txn, nil
+}
+
+func (s *Store) FetchJournalEntryTransactionsForUpdate(ctx context.Context, tx *sql.Tx, entryID int64) ([]model.Transaction, error) {
+	fnName := "DBStore.FetchJournalEntryTransactionsForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("entryID", entryID))
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE journal_entry_id = $1
+		ORDER BY id
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := tx.QueryContext(ctx, query, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []model.Transaction{}
+	for rows.Next() {
+		var txn model.Transaction
+		err := scanTransaction(rows, &txn)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, txn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Transactions found", fnName), zap.Int("count", len(transactions)))
+	return transactions, nil
+}
+
+func (s *Store) FetchReversedAmount(ctx context.Context, tx *sql.Tx, id int64) (int64, error) {
+	fnName := "DBStore.FetchReversedAmount"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id))
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE reversal_of = $1;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var reversed int64
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&reversed); err != nil {
+		return 0, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int64("reversed", reversed))
+	return reversed, nil
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const scheduledTransferColumns = "id, username, actor, currency, amount, counterparty, execute_at, next_attempt_at, status, attempt_count, last_error, executed_at, cancelled_at, created_at, standing_order_id, occurrence"
+
+func scanScheduledTransfer(row interface{ Scan(dest ...any) error }, st *model.ScheduledTransfer) error {
+	return row.Scan(
+		&st.ID,
+		&st.Username,
+		&st.Actor,
+		&st.Currency,
+		&st.Amount,
+		&st.Counterparty,
+		&st.ExecuteAt,
+		&st.NextAttemptAt,
+		&st.Status,
+		&st.AttemptCount,
+		&st.LastError,
+		&st.ExecutedAt,
+		&st.CancelledAt,
+		&st.CreatedAt,
+		&st.StandingOrderID,
+		&st.Occurrence,
+	)
+}
+
+func (s *Store) InsertScheduledTransfer(ctx context.Context, tx *sql.Tx, st *model.ScheduledTransfer) error {
+	fnName := "DBStore.InsertScheduledTransfer"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("scheduledTransfer", st))
+	query := `
+		INSERT INTO scheduled_transfers (username, actor, currency, amount, counterparty, execute_at, next_attempt_at, standing_order_id, occurrence)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $7, $8)
+		RETURNING ` + scheduledTransferColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return scanScheduledTransfer(tx.QueryRowContext(
+		ctx,
+		query,
+		st.Username,
+		st.Actor,
+		st.Currency,
+		st.Amount,
+		st.Counterparty,
+		st.ExecuteAt,
+		st.StandingOrderID,
+		st.Occurrence,
+	), st)
+}
+
+func (s *Store) FetchScheduledTransferForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.ScheduledTransfer, error) {
+	fnName := "DBStore.FetchScheduledTransferForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id))
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var st model.ScheduledTransfer
+	if err := scanScheduledTransfer(tx.QueryRowContext(ctx, query, id), &st); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("scheduledTransfer", st))
+	return &st, nil
+}
+
+func (s *Store) ClaimDueScheduledTransfer(ctx context.Context, tx *sql.Tx, at time.Time) (*model.ScheduledTransfer, error) {
+	fnName := "DBStore.ClaimDueScheduledTransfer"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("at", at))
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE status = 'pending'
+		AND next_attempt_at <= $1
+		ORDER BY next_attempt_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var st model.ScheduledTransfer
+	if err := scanScheduledTransfer(tx.QueryRowContext(ctx, query, at), &st); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("scheduledTransfer", st))
+	return &st, nil
+}
+
+func (s *Store) UpdateScheduledTransfer(ctx context.Context, tx *sql.Tx, st *model.ScheduledTransfer) error {
+	fnName := "DBStore.UpdateScheduledTransfer"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("scheduledTransfer", st))
+	query := `
+		UPDATE scheduled_transfers
+		SET
+			status          = $2,
+			next_attempt_at = $3,
+			attempt_count   = $4,
+			last_error      = $5,
+			executed_at     = $6,
+			cancelled_at    = $7
+		WHERE id = $1
+		AND status = 'pending';
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		st.ID,
+		st.Status,
+		st.NextAttemptAt,
+		st.AttemptCount,
+		st.LastError,
+		st.ExecutedAt,
+		st.CancelledAt,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("scheduled transfer %d is no longer pending", st.ID)
+	}
+	return nil
+}
+
+func (s *Store) CancelStandingOrderTransfers(ctx context.Context, tx *sql.Tx, standingOrderID int64, at time.Time) (int64, error) {
+	fnName := "DBStore.CancelStandingOrderTransfers"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("standingOrderID", standingOrderID), zap.Time("at", at))
+	query := `
+		UPDATE scheduled_transfers
+		SET
+			status       = 'cancelled',
+			cancelled_at = $2
+		WHERE standing_order_id = $1
+		AND status = 'pending';
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, standingOrderID, at)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) InsertScheduledTransferAttempt(ctx context.Context, tx *sql.Tx, attempt *model.ScheduledTransferAttempt) error {
+	fnName := "DBStore.InsertScheduledTransferAttempt"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("attempt", attempt))
+	query := `
+		INSERT INTO scheduled_transfer_attempts (scheduled_transfer_id, attempted_at, succeeded, transaction_id, error_code, error_message)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		attempt.ScheduledTransferID,
+		attempt.AttemptedAt,
+		attempt.Succeeded,
+		attempt.TransactionID,
+		attempt.ErrorCode,
+		attempt.ErrorMessage,
+	).Scan(&attempt.ID)
+}
+
+func (s *Store) FetchScheduledTransfers(ctx context.Context, criteria *model.ScheduledCriteria) ([]model.ScheduledTransfer, error) {
+	fnName := "DBStore.FetchScheduledTransfers"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("criteria", criteria))
+	var (
+		query      strings.Builder
+		args       []interface{}
+		conditions []string
+		argPos     = 1
+	)
+
+	query.WriteString("SELECT " + scheduledTransferColumns + " FROM scheduled_transfers")
+
+	if criteria.Username != "" {
+		conditions = append(conditions, fmt.Sprintf("username = $%d", argPos))
+		args = append(args, criteria.Username)
+		argPos++
+	}
+	if criteria.StandingOrderID > 0 {
+		conditions = append(conditions, fmt.Sprintf("standing_order_id = $%d", argPos))
+		args = append(args, criteria.StandingOrderID)
+		argPos++
+	}
+	if criteria.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, criteria.Status)
+		argPos++
+	}
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE ")
+		query.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	query.WriteString(" ORDER BY execute_at DESC, id DESC")
+
+	if criteria.Limit > 0 {
+		query.WriteString(fmt.Sprintf(" LIMIT $%d ", argPos))
+		args = append(args, criteria.Limit)
+		argPos++
+	}
+
+	logger.Info(fmt.Sprintf("%s - Query built", fnName), zap.String("query", query.String()))
+
+	rows, err := s.DB.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scheduled := []model.ScheduledTransfer{}
+	for rows.Next() {
+		var st model.ScheduledTransfer
+		if err := scanScheduledTransfer(rows, &st); err != nil {
+			return nil, err
+		}
+		scheduled = append(scheduled, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug(fmt.Sprintf("%s - Scheduled transfers found", fnName), zap.Int("count", len(scheduled)))
+	return scheduled, nil
+}
+
+func (s *Store) FetchScheduledTransferAttempts(ctx context.Context, ids []int64) ([]model.ScheduledTransferAttempt, error) {
+	fnName := "DBStore.FetchScheduledTransferAttempts"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64s("ids", ids))
+	query := `
+		SELECT id, scheduled_transfer_id, attempted_at, succeeded, transaction_id, error_code, error_message
+		FROM scheduled_transfer_attempts
+		WHERE scheduled_transfer_id = ANY($1)
+		ORDER BY scheduled_transfer_id, id;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []model.ScheduledTransferAttempt{}
+	for rows.Next() {
+		var attempt model.ScheduledTransferAttempt
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.ScheduledTransferID,
+			&attempt.AttemptedAt,
+			&attempt.Succeeded,
+			&attempt.TransactionID,
+			&attempt.ErrorCode,
+			&attempt.ErrorMessage,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (s *Store) Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	logger.Debug("DBStore.Savepoint - parameters", zap.String("name", name))
+	_, err := tx.ExecContext(ctx, "SAVEPOINT "+name)
+	return err
+}
+
+func (s *Store) RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	logger.Debug("DBStore.RollbackToSavepoint - parameters", zap.String("name", name))
+	_, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	return err
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const balanceAsOfQuery = `
+	WITH as_of_snapshot AS (
+		SELECT DISTINCT ON (username, currency, pocket) username, currency, pocket, snapshot_at, balance
+		FROM balance_snapshots
+		WHERE snapshot_at <= $1
+		AND ($2 = '' OR username = $2)
+		AND ($3 = '' OR currency = $3)
+		AND ($4 = '' OR pocket = $4)
+		ORDER BY username, currency, pocket, snapshot_at DESC
+	)
+	SELECT
+		w.username,
+		w.currency,
+		w.pocket,
+		w.balance,
+		s.snapshot_at,
+		COALESCE(s.balance, 0) + d.delta,
+		d.replayed
+	FROM wallets w
+	LEFT JOIN as_of_snapshot s ON s.username = w.username AND s.currency = w.currency AND s.pocket = w.pocket
+	CROSS JOIN LATERAL (
+		SELECT
+			COALESCE(SUM(CASE WHEN t.direction = 'credit' THEN t.amount ELSE -t.amount END), 0) AS delta,
+			COUNT(t.id) AS replayed
+		FROM transactions t
+		WHERE t.username = w.username
+		AND t.currency = w.currency
+		AND t.pocket = w.pocket
+		AND t.timestamp <= $1
+		AND (s.snapshot_at IS NULL OR t.timestamp > s.snapshot_at)
+	) d
+	WHERE ($2 = '' OR w.username = $2)
+	AND ($3 = '' OR w.currency = $3)
+	AND ($4 = '' OR w.pocket = $4)
+`
+
+func (s *Store) FetchBalancesAsOf(ctx context.Context, username string, currency string, pocket string, asOf time.Time) ([]model.HistoricalBalance, error) {
+	fnName := "DBStore.FetchBalancesAsOf"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket), zap.Time("asOf", asOf))
+	tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := balanceAsOfQuery + `
+		ORDER BY w.username, w.currency, w.pocket;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	current := map[string]int64{}
+	now := time.Now().UTC()
+	currentRows, err := tx.QueryContext(ctx, query, now, username, currency, pocket)
+	if err != nil {
+		return nil, err
+	}
+	defer currentRows.Close()
+
+	for currentRows.Next() {
+		var balance model.HistoricalBalance
+		if err := scanHistoricalBalance(currentRows, &balance); err != nil {
+			return nil, err
+		}
+		current[balance.Username+":"+balance.Currency+":"+balance.Pocket] = balance.Balance
+	}
+	if err := currentRows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, asOf, username, currency, pocket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []model.HistoricalBalance{}
+	for rows.Next() {
+		balance := model.HistoricalBalance{AsOf: asOf}
+		if err := scanHistoricalBalance(rows, &balance); err != nil {
+			return nil, err
+		}
+		balance.LogBalance = current[balance.Username+":"+balance.Currency+":"+balance.Pocket]
+		balances = append(balances, balance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int("count", len(balances)))
+	return balances, nil
+}
+
+func scanHistoricalBalance(row interface{ Scan(dest ...any) error }, balance *model.HistoricalBalance) error {
+	return row.Scan(
+		&balance.Username,
+		&balance.Currency,
+		&balance.Pocket,
+		&balance.WalletBalance,
+		&balance.SnapshotAt,
+		&balance.Balance,
+		&balance.Replayed,
+	)
+}
+
+func (s *Store) FetchTransactionLogStart(ctx context.Context) (*time.Time, error) {
+	fnName := "DBStore.FetchTransactionLogStart"
+	logger.Debug(fmt.Sprintf("%s - no params to receive", fnName))
+	query := `
+		SELECT MIN(timestamp)
+		FROM transactions;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var start *time.Time
+	if err := s.DB.QueryRowContext(ctx, query).Scan(&start); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Timep("start", start))
+	return start, nil
+}
+
+func (s *Store) InsertBalanceSnapshots(ctx context.Context, snapshotAt time.Time) (int64, error) {
+	fnName := "DBStore.InsertBalanceSnapshots"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("snapshotAt", snapshotAt))
+	query := `
+		INSERT INTO balance_snapshots (username, currency, pocket, snapshot_at, balance)
+		SELECT username, currency, pocket, $1, balance
+		FROM (` + balanceAsOfQuery + `
+		) AS b (username, currency, pocket, wallet_balance, snapshot_at, balance, replayed)
+		WHERE snapshot_at IS NOT NULL OR replayed > 0
+		ON CONFLICT ON CONSTRAINT uq_balance_snapshot DO NOTHING;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := s.DB.ExecContext(ctx, query, snapshotAt, "", "", "")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const standingOrderColumns = "id, username, actor, currency, amount, counterparty, frequency, day_of_month, start_at, end_at, max_occurrences, occurrence_count, next_occurrence_at, status, created_at, cancelled_at"
+
+func scanStandingOrder(row interface{ Scan(dest ...any) error }, order *model.StandingOrder) error {
+	return row.Scan(
+		&order.ID,
+		&order.Username,
+		&order.Actor,
+		&order.Currency,
+		&order.Amount,
+		&order.Counterparty,
+		&order.Frequency,
+		&order.DayOfMonth,
+		&order.StartAt,
+		&order.EndAt,
+		&order.MaxOccurrences,
+		&order.OccurrenceCount,
+		&order.NextOccurrenceAt,
+		&order.Status,
+		&order.CreatedAt,
+		&order.CancelledAt,
+	)
+}
+
+func (s *Store) InsertStandingOrder(ctx context.Context, tx *sql.Tx, order *model.StandingOrder) error {
+	fnName := "DBStore.InsertStandingOrder"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("standingOrder", order))
+	query := `
+		INSERT INTO standing_orders (username, actor, currency, amount, counterparty, frequency, day_of_month, start_at, end_at, max_occurrences, next_occurrence_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + standingOrderColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return scanStandingOrder(tx.QueryRowContext(
+		ctx,
+		query,
+		order.Username,
+		order.Actor,
+		order.Currency,
+		order.Amount,
+		order.Counterparty,
+		order.Frequency,
+		order.DayOfMonth,
+		order.StartAt,
+		order.EndAt,
+		order.MaxOccurrences,
+		order.NextOccurrenceAt,
+	), order)
+}
+
+func (s *Store) FetchStandingOrderForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.StandingOrder, error) {
+	fnName := "DBStore.FetchStandingOrderForUpdate"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("id", id))
+	query := `
+		SELECT ` + standingOrderColumns + `
+		FROM standing_orders
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var order model.StandingOrder
+	if err := scanStandingOrder(tx.QueryRowContext(ctx, query, id), &order); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("standingOrder", order))
+	return &order, nil
+}
+
+func (s *Store) ClaimDueStandingOrder(ctx context.Context, tx *sql.Tx, at time.Time) (*model.StandingOrder, error) {
+	fnName := "DBStore.ClaimDueStandingOrder"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("at", at))
+	query := `
+		SELECT ` + standingOrderColumns + `
+		FROM standing_orders
+		WHERE status = 'active'
+		AND next_occurrence_at <= $1
+		ORDER BY next_occurrence_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var order model.StandingOrder
+	if err := scanStandingOrder(tx.QueryRowContext(ctx, query, at), &order); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Any("standingOrder", order))
+	return &order, nil
+}
+
+func (s *Store) UpdateStandingOrder(ctx context.Context, tx *sql.Tx, order *model.StandingOrder) error {
+	fnName := "DBStore.UpdateStandingOrder"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("standingOrder", order))
+	query := `
+		UPDATE standing_orders
+		SET
+			occurrence_count   = $2,
+			next_occurrence_at = $3,
+			status             = $4,
+			cancelled_at       = $5
+		WHERE id = $1
+		AND status = 'active';
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		order.ID,
+		order.OccurrenceCount,
+		order.NextOccurrenceAt,
+		order.Status,
+		order.CancelledAt,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return fmt.Errorf("standing order %d is no longer active", order.ID)
+	}
+	return nil
+}
+
+func (s *Store) FetchStandingOrders(ctx context.Context, criteria *model.StandingOrderCriteria) ([]model.StandingOrder, error) {
+	fnName := "DBStore.FetchStandingOrders"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("criteria", criteria))
+	var (
+		query      strings.Builder
+		args       []interface{}
+		conditions []string
+		argPos     = 1
+	)
+
+	query.WriteString("SELECT " + standingOrderColumns + " FROM standing_orders")
+
+	if criteria.Username != "" {
+		conditions = append(conditions, fmt.Sprintf("username = $%d", argPos))
+		args = append(args, criteria.Username)
+		argPos++
+	}
+	if criteria.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, criteria.Status)
+		argPos++
+	}
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE ")
+		query.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	query.WriteString(" ORDER BY created_at DESC, id DESC")
+
+	if criteria.Limit > 0 {
+		query.WriteString(fmt.Sprintf(" LIMIT $%d ", argPos))
+		args = append(args, criteria.Limit)
+		argPos++
+	}
+
+	logger.Info(fmt.Sprintf("%s - Query built", fnName), zap.String("query", query.String()))
+
+	rows, err := s.DB.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []model.StandingOrder{}
+	for rows.Next() {
+		var order model.StandingOrder
+		if err := scanStandingOrder(rows, &order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug(fmt.Sprintf("%s - Standing orders found", fnName), zap.Int("count", len(orders)))
+	return orders, nil
+}
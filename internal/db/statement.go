@@ -0,0 +1,252 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+func (s *Store) FetchStatementActivity(ctx context.Context, username string, currency string, from time.Time, to time.Time) (int64, []model.StatementLine, error) {
+	fnName := "DBStore.FetchStatementActivity"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.Time("from", from), zap.Time("to", to))
+	tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	openingQuery := `
+		SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)
+		FROM transactions
+		WHERE username = $1
+		AND currency = $2
+		AND timestamp < $3;
+	`
+	logger.Debug(fmt.Sprintf("%s - opening query", fnName), zap.String("query", openingQuery))
+
+	var opening int64
+	if err := tx.QueryRowContext(ctx, openingQuery, username, currency, from).Scan(&opening); err != nil {
+		return 0, nil, err
+	}
+
+	linesQuery := `
+		SELECT id, pocket, type, direction, amount, counterparty, reversal_of, timestamp
+		FROM transactions
+		WHERE username = $1
+		AND currency = $2
+		AND timestamp >= $3
+		AND timestamp < $4
+		ORDER BY timestamp, id;
+	`
+	logger.Debug(fmt.Sprintf("%s - lines query", fnName), zap.String("query", linesQuery))
+
+	rows, err := tx.QueryContext(ctx, linesQuery, username, currency, from, to)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	lines := []model.StatementLine{}
+	for rows.Next() {
+		var line model.StatementLine
+		if err := rows.Scan(
+			&line.TransactionID,
+			&line.Pocket,
+			&line.TxnType,
+			&line.Direction,
+			&line.Amount,
+			&line.Counterparty,
+			&line.ReversalOf,
+			&line.Timestamp,
+		); err != nil {
+			return 0, nil, err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int64("opening", opening), zap.Int("lines", len(lines)))
+	return opening, lines, nil
+}
+
+func (s *Store) FetchStatementAccounts(ctx context.Context, before time.Time) ([]model.StatementAccount, error) {
+	fnName := "DBStore.FetchStatementAccounts"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Time("before", before))
+	query := `
+		SELECT DISTINCT username, currency
+		FROM transactions
+		WHERE timestamp < $1
+		AND username NOT LIKE 'SYS\_%'
+		ORDER BY username, currency;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []model.StatementAccount{}
+	for rows.Next() {
+		var account model.StatementAccount
+		if err := rows.Scan(&account.Username, &account.Currency); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Statement accounts found", fnName), zap.Int("count", len(accounts)))
+	return accounts, nil
+}
+
+func (s *Store) InsertStatement(ctx context.Context, tx *sql.Tx, statement *model.Statement) (bool, error) {
+	fnName := "DBStore.InsertStatement"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", statement.Username), zap.String("currency", statement.Currency), zap.Timep("period", statement.Period))
+	query := `
+		INSERT INTO statements (username, currency, period, period_start, period_end, opening_balance, closing_balance)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT ON CONSTRAINT uq_statement_period DO NOTHING
+		RETURNING id, generated_at;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var id int64
+	var generatedAt time.Time
+	err := tx.QueryRowContext(
+		ctx,
+		query,
+		statement.Username,
+		statement.Currency,
+		statement.Period,
+		statement.From,
+		statement.To,
+		statement.OpeningBalance,
+		statement.ClosingBalance,
+	).Scan(&id, &generatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Debug(fmt.Sprintf("%s - Statement already generated", fnName))
+			return false, nil
+		}
+		return false, err
+	}
+	statement.ID = &id
+	statement.GeneratedAt = &generatedAt
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int64("id", id))
+	return true, nil
+}
+
+func (s *Store) InsertStatementLine(ctx context.Context, tx *sql.Tx, statementID int64, line *model.StatementLine) error {
+	fnName := "DBStore.InsertStatementLine"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Int64("statementID", statementID), zap.Any("line", line))
+	query := `
+		INSERT INTO statement_lines (statement_id, transaction_id, pocket, type, direction, amount, counterparty, reversal_of, timestamp, balance)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		statementID,
+		line.TransactionID,
+		line.Pocket,
+		line.TxnType,
+		line.Direction,
+		line.Amount,
+		line.Counterparty,
+		line.ReversalOf,
+		line.Timestamp,
+		line.Balance,
+	)
+	return err
+}
+
+func (s *Store) FetchStatement(ctx context.Context, username string, currency string, period time.Time) (*model.Statement, error) {
+	fnName := "DBStore.FetchStatement"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.Time("period", period))
+	query := `
+		SELECT id, username, currency, period, period_start, period_end, opening_balance, closing_balance, generated_at
+		FROM statements
+		WHERE username = $1
+		AND currency = $2
+		AND period = $3;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	var statement model.Statement
+	var id int64
+	var storedPeriod, generatedAt time.Time
+	err := s.DB.QueryRowContext(ctx, query, username, currency, period).Scan(
+		&id,
+		&statement.Username,
+		&statement.Currency,
+		&storedPeriod,
+		&statement.From,
+		&statement.To,
+		&statement.OpeningBalance,
+		&statement.ClosingBalance,
+		&generatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Debug(fmt.Sprintf("%s - No statement found", fnName))
+			return nil, nil
+		}
+		return nil, err
+	}
+	statement.ID = &id
+	statement.Period = &storedPeriod
+	statement.GeneratedAt = &generatedAt
+
+	linesQuery := `
+		SELECT transaction_id, pocket, type, direction, amount, counterparty, reversal_of, timestamp, balance
+		FROM statement_lines
+		WHERE statement_id = $1
+		ORDER BY id;
+	`
+	logger.Debug(fmt.Sprintf("%s - lines query", fnName), zap.String("query", linesQuery))
+
+	rows, err := s.DB.QueryContext(ctx, linesQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statement.Lines = []model.StatementLine{}
+	for rows.Next() {
+		var line model.StatementLine
+		if err := rows.Scan(
+			&line.TransactionID,
+			&line.Pocket,
+			&line.TxnType,
+			&line.Direction,
+			&line.Amount,
+			&line.Counterparty,
+			&line.ReversalOf,
+			&line.Timestamp,
+			&line.Balance,
+		); err != nil {
+			return nil, err
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug(fmt.Sprintf("%s - after query execution", fnName), zap.Int64("id", id), zap.Int("lines", len(statement.Lines)))
+	return &statement, nil
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"go.uber.org/zap"
+)
+
+const velocityRuleColumns = "id, username, txn_type, currency, time_window, max_amount, created_at, retired_at"
+
+func scanVelocityRule(row interface{ Scan(dest ...any) error }, rule *model.VelocityRule) error {
+	return row.Scan(
+		&rule.ID,
+		&rule.Username,
+		&rule.TxnType,
+		&rule.Currency,
+		&rule.Window,
+		&rule.MaxAmount,
+		&rule.CreatedAt,
+		&rule.RetiredAt,
+	)
+}
+
+func (s *Store) RetireVelocityRule(ctx context.Context, tx *sql.Tx, username *string, txnType model.TxnType, currency string, window model.VelocityWindow) (int64, error) {
+	fnName := "DBStore.RetireVelocityRule"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("username", username), zap.String("txnType", string(txnType)), zap.String("currency", currency), zap.String("window", string(window)))
+	query := `
+		UPDATE velocity_rules
+		SET retired_at = now()
+		WHERE username IS NOT DISTINCT FROM $1
+		AND txn_type = $2
+		AND currency = $3
+		AND time_window = $4
+		AND retired_at IS NULL;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	result, err := tx.ExecContext(ctx, query, username, txnType, currency, window)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) InsertVelocityRule(ctx context.Context, tx *sql.Tx, rule *model.VelocityRule) error {
+	fnName := "DBStore.InsertVelocityRule"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.Any("rule", rule))
+	query := `
+		INSERT INTO velocity_rules (username, txn_type, currency, time_window, max_amount)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + velocityRuleColumns + `;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	return scanVelocityRule(tx.QueryRowContext(
+		ctx,
+		query,
+		rule.Username,
+		rule.TxnType,
+		rule.Currency,
+		rule.Window,
+		rule.MaxAmount,
+	), rule)
+}
+
+func (s *Store) FetchVelocityRules(ctx context.Context, username string, txnType string) ([]model.VelocityRule, error) {
+	fnName := "DBStore.FetchVelocityRules"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("txnType", txnType))
+	query := `
+		SELECT ` + velocityRuleColumns + `
+		FROM velocity_rules
+		WHERE retired_at IS NULL
+		AND ($1 = '' OR username = $1)
+		AND ($2 = '' OR txn_type = $2)
+		ORDER BY username NULLS FIRST, txn_type, currency, time_window;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := s.DB.QueryContext(ctx, query, username, txnType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.VelocityRule{}
+	for rows.Next() {
+		var rule model.VelocityRule
+		if err := scanVelocityRule(rows, &rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Velocity rules found", fnName), zap.Int("count", len(rules)))
+	return rules, nil
+}
+
+func (s *Store) FetchApplicableVelocityRules(ctx context.Context, tx *sql.Tx, username string, txnType model.TxnType, currency string) ([]model.VelocityRule, error) {
+	fnName := "DBStore.FetchApplicableVelocityRules"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("txnType", string(txnType)), zap.String("currency", currency))
+	query := `
+		SELECT DISTINCT ON (time_window) ` + velocityRuleColumns + `
+		FROM velocity_rules
+		WHERE retired_at IS NULL
+		AND (username = $1 OR username IS NULL)
+		AND txn_type = $2
+		AND currency = $3
+		ORDER BY time_window, username NULLS LAST;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	rows, err := tx.QueryContext(ctx, query, username, txnType, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.VelocityRule{}
+	for rows.Next() {
+		var rule model.VelocityRule
+		if err := scanVelocityRule(rows, &rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Applicable velocity rules found", fnName), zap.Int("count", len(rules)))
+	return rules, nil
+}
+
+func (s *Store) FetchVelocityUsage(ctx context.Context, tx *sql.Tx, username string, currency string, txnTypes []model.TxnType, since time.Time) ([]model.VelocityUsage, error) {
+	fnName := "DBStore.FetchVelocityUsage"
+	logger.Debug(fmt.Sprintf("%s - parameters", fnName), zap.String("username", username), zap.String("currency", currency), zap.Any("txnTypes", txnTypes), zap.Time("since", since))
+	lockQuery := `
+		SELECT id
+		FROM wallets
+		WHERE username = $1
+		AND currency = $2
+		FOR UPDATE;
+	`
+	logger.Debug(fmt.Sprintf("%s - lock query", fnName), zap.String("query", lockQuery))
+
+	if _, err := tx.ExecContext(ctx, lockQuery, username, currency); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT t.amount - COALESCE((
+			SELECT SUM(r.amount) FROM transactions r WHERE r.reversal_of = t.id
+		), 0), t.timestamp
+		FROM transactions t
+		WHERE t.username = $1
+		AND t.currency = $2
+		AND t.type = ANY($3)
+		AND t.timestamp > $4
+		ORDER BY t.timestamp, t.id;
+	`
+	logger.Debug(fmt.Sprintf("%s - query", fnName), zap.String("query", query))
+
+	types := make([]string, len(txnTypes))
+	for i, txnType := range txnTypes {
+		types[i] = string(txnType)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, username, currency, types, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []model.VelocityUsage{}
+	for rows.Next() {
+		var u model.VelocityUsage
+		if err := rows.Scan(&u.Amount, &u.Timestamp); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("%s - Velocity usage found", fnName), zap.Int("count", len(usage)))
+	return usage, nil
+}
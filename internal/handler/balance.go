@@ -24,9 +24,31 @@ func (h *WalletHandler) BalanceHandler(w http.ResponseWriter, r *http.Request) {
 
 	q := r.URL.Query()
 	username := q.Get("username")
-	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.String("username", username))
+	currency := q.Get("currency")
+	pocket := q.Get("pocket")
+	asOf := q.Get("as_of")
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.String("username", username), zap.String("currency", currency), zap.String("pocket", pocket), zap.String("asOf", asOf))
 
-	wallet, appErr := h.walletService.DoFetchWallet(ctx, username)
+	if q.Has("as_of") {
+		balance, appErr := h.snapshotService.DoFetchBalanceAsOf(ctx, username, currency, pocket, asOf)
+		if appErr != nil {
+			appErr.Status = http.StatusInternalServerError
+			appErrs.AddError(*appErr)
+			return
+		}
+		logger.Info(fmt.Sprintf("%s - Historical balance rebuilt successfully", fnName), zap.Any("balance", balance))
+
+		resp := &response.HistoricalBalanceResponse{
+			Status:  http.StatusOK,
+			AsOf:    balance.AsOf,
+			Balance: balance,
+		}
+		logger.Info(fmt.Sprintf("%s - Sending historical balance response", fnName), zap.Any("balance", balance))
+		SendJSONResponse(fnName, w, resp.Status, resp)
+		return
+	}
+
+	wallet, appErr := h.walletService.DoFetchWallet(ctx, username, currency, pocket)
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
 		appErrs.AddError(*appErr)
@@ -53,6 +75,32 @@ func (h *WalletHandler) AdminBalanceHandler(w http.ResponseWriter, r *http.Reque
 		FinalizeTransactionResponse(fnName, nil, w, appErrs)
 	}()
 
+	q := r.URL.Query()
+	if q.Has("as_of") {
+		asOf := q.Get("as_of")
+		logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.String("asOf", asOf))
+
+		parsed, balances, appErr := h.snapshotService.DoFetchAllBalancesAsOf(ctx, asOf)
+		if appErr != nil {
+			appErr.Status = http.StatusInternalServerError
+			appErrs.AddError(*appErr)
+			return
+		}
+		logger.Info(fmt.Sprintf("%s - Historical balances rebuilt successfully", fnName), zap.Int("count", len(balances)))
+
+		resp := &response.HistoricalBalanceResponse{
+			Status:   http.StatusOK,
+			AsOf:     parsed,
+			Balances: balances,
+		}
+		if len(balances) == 0 {
+			resp.Message = utils.Ptr("No wallets existed at as_of")
+		}
+		logger.Info(fmt.Sprintf("%s - Sending historical balances response", fnName), zap.Int("count", len(balances)))
+		SendJSONResponse(fnName, w, resp.Status, resp)
+		return
+	}
+
 	wallets, appErr := h.walletService.DoFetchAllWallets(ctx)
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const batchItemSavepoint = "batch_item"
+
+func (h *WalletHandler) BatchTransferHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.BatchTransferHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.BatchTransferPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded batch transfer payload", fnName), zap.Any("payload", payload))
+
+	batch, appErr := h.batchTransferService.DoPrepareBatch(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Batch prepared", fnName), zap.Int64("total", batch.Total), zap.Int("items", len(batch.Items)))
+
+	auth, appErr := h.withdrawService.DoAuthorizeSpend(ctx, payload.Actor, batch.Username, batch.Currency, batch.Total)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	if auth.RequiresApproval() {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_APPROVAL_REQUIRED,
+				Message:   fmt.Sprintf("Batch total needs %d approvals, submit the transfers individually", auth.RequiredApprovals),
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("%s requires %d approvals for %d", auth.Username, auth.RequiredApprovals, batch.Total),
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
+
+	if batch.Mode == model.BatchAtomic {
+		for _, item := range batch.Items {
+			if item.Status == model.BatchItemFailed {
+				appErrs.AddError(
+					validation.WalletError{
+						Name:      fnName,
+						Status:    http.StatusBadRequest,
+						Code:      validation.WalletErrorCode(*item.ErrorCode),
+						Message:   fmt.Sprintf("Item %d: %s", item.Index, *item.ErrorMessage),
+						Timestamp: time.Now().UTC(),
+						Err:       nil,
+					},
+				)
+				return
+			}
+		}
+	}
+
+	var wallet *model.Wallet
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		if item.Status != model.BatchItemPending {
+			continue
+		}
+
+		if batch.Mode == model.BatchBestEffort {
+			if err := h.store.Savepoint(ctx, tx, batchItemSavepoint); err != nil {
+				appErrs.AddError(
+					validation.WalletError{
+						Name:      fnName,
+						Status:    http.StatusInternalServerError,
+						Code:      validation.ERR_BATCH_EXECUTION_FAILED,
+						Message:   "Failed to create batch item savepoint",
+						Timestamp: time.Now().UTC(),
+						Err:       err,
+					},
+				)
+				return
+			}
+		}
+
+		counterparty := item.Counterparty
+		result, appErr := h.transfer(ctx, tx, &request.RequestPayload{
+			Username:     batch.Username,
+			Actor:        auth.Actor,
+			Currency:     batch.Currency,
+			Pocket:       batch.Pocket,
+			Amount:       item.Amount,
+			Counterparty: &counterparty,
+		}, auth)
+		if appErr != nil {
+			if batch.Mode == model.BatchAtomic {
+				appErr.Message = fmt.Sprintf("Item %d: %s", item.Index, appErr.Message)
+				appErrs.AddError(*appErr)
+				return
+			}
+
+			if err := h.store.RollbackToSavepoint(ctx, tx, batchItemSavepoint); err != nil {
+				appErrs.AddError(
+					validation.WalletError{
+						Name:      fnName,
+						Status:    http.StatusInternalServerError,
+						Code:      validation.ERR_BATCH_EXECUTION_FAILED,
+						Message:   "Failed to roll back batch item",
+						Timestamp: time.Now().UTC(),
+						Err:       err,
+					},
+				)
+				return
+			}
+			logger.Warn(fmt.Sprintf("%s - Batch item failed", fnName), zap.Int("index", item.Index), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			h.batchTransferService.RecordItemResult(item, nil, appErr)
+			continue
+		}
+
+		h.batchTransferService.RecordItemResult(item, result.outTransaction, nil)
+		item.Fee = 0
+		if result.fee != nil {
+			item.Fee = result.fee.Fee
+		}
+		wallet = result.wallet
+		logger.Info(fmt.Sprintf("%s - Batch item transferred", fnName), zap.Int("index", item.Index), zap.Int64("transactionID", result.outTransaction.ID))
+	}
+
+	resp := &response.BatchTransferResponse{
+		Status:   http.StatusOK,
+		Mode:     batch.Mode,
+		Currency: batch.Currency,
+		Items:    batch.Items,
+		Wallet:   wallet,
+	}
+	for _, item := range batch.Items {
+		if item.Status == model.BatchItemSucceeded {
+			resp.Succeeded++
+			resp.Total += item.Amount
+			resp.Fees += item.Fee
+		} else {
+			resp.Failed++
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Sending batch transfer response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -56,15 +56,34 @@ func (h *WalletHandler) DepositHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info(fmt.Sprintf("%s - Decoded deposit payload", fnName), zap.Any("payload", payload))
 
-	wallet, appErr := h.depositService.DoDeposit(ctx, tx, payload.Username, payload.Amount, false)
+	replayed, appErr := h.replayIdempotentRequest(ctx, tx, fnName, w, r, payload.Username, payload)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	if replayed {
+		return
+	}
+
+	wallet, entry, appErr := h.depositService.DoPostedDeposit(ctx, tx, payload.Username, payload.Currency, payload.Pocket, payload.Amount)
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
 		appErrs.AddError(*appErr)
 		return
 	}
-	logger.Info(fmt.Sprintf("%s - Deposit successful", fnName), zap.Any("wallet", wallet))
+	logger.Info(fmt.Sprintf("%s - Deposit successful", fnName), zap.Any("wallet", wallet), zap.Int64("entryID", entry.ID))
 
-	transaction, appErr := h.transactionService.LogTransaction(ctx, tx, payload.Username, model.TypeDeposit, payload.Amount, nil)
+	transaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       payload.Username,
+		TxnType:        model.TypeDeposit,
+		Currency:       wallet.Currency,
+		Pocket:         wallet.Pocket,
+		Amount:         payload.Amount,
+		JournalEntryID: &entry.ID,
+		Memo:           payload.Memo,
+		Reference:      payload.Reference,
+		Metadata:       payload.Metadata,
+	})
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
 		appErrs.AddError(*appErr)
@@ -78,5 +97,7 @@ func (h *WalletHandler) DepositHandler(w http.ResponseWriter, r *http.Request) {
 		Wallet:          *wallet,
 	}
 	logger.Info(fmt.Sprintf("%s - Sending deposit response", fnName), zap.Any("response", resp))
-	SendJSONResponse(fnName, w, resp.Status, resp)
+	if appErr := h.sendIdempotentResponse(ctx, tx, fnName, w, r, payload.Username, resp.Status, resp); appErr != nil {
+		appErrs.AddError(*appErr)
+	}
 }
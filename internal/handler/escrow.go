@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) CreateEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.CreateEscrowHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.EscrowPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded escrow payload", fnName), zap.Any("payload", payload))
+
+	auth, appErr := h.authorizeUnattendedSpend(ctx, fnName, "Escrow", payload.Actor, payload.Payer, payload.Currency, payload.Amount)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
+
+	escrow, escrowWallet, appErr := h.escrowService.DoCreateEscrow(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Escrow created", fnName), zap.Any("escrow", escrow))
+
+	// Funding is where the payer's money leaves, so it counts against their
+	// transfer velocity. Release and refund pay out of SYS_ESCROW and are not
+	// checked again.
+	if appErr := h.velocityService.DoCheckVelocity(ctx, tx, model.TypeTransfer, escrow.Payer, escrow.Currency, escrow.Amount); appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity limits checked", fnName))
+
+	wallet, appErr := h.withdrawService.DoWithdraw(ctx, tx, escrow.Payer, escrow.Currency, model.DefaultPocket, escrow.Amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Escrow amount withdrawn from payer", fnName), zap.Any("wallet", wallet))
+
+	entry, appErr := h.journalService.PostEntry(ctx, tx, model.TypeEscrowFund, []model.Posting{
+		model.WalletPosting(wallet.ID, escrow.Currency, model.DirectionDebit, escrow.Amount),
+		model.WalletPosting(escrowWallet.ID, escrow.Currency, model.DirectionCredit, escrow.Amount),
+	})
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+
+	if _, appErr := h.logSystemLegs(ctx, tx, model.TypeEscrowFund, model.DirectionDebit, model.EscrowWallet, escrow.Payer, escrow.Currency, model.DefaultPocket, escrow.Amount, entry.ID); appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+
+	// The payer's money leaves their wallet here, so funding is priced as a
+	// transfer of the full amount. Release, refund and expiry only move money
+	// out of SYS_ESCROW and are not charged again.
+	fee, wallet, appErr := h.chargeFee(ctx, tx, model.TypeTransfer, wallet, escrow.Amount)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+
+	resp := &response.EscrowResponse{
+		Status: http.StatusOK,
+		Escrow: escrow,
+		Wallet: wallet,
+		Fee:    fee,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending escrow response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) EscrowHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.EscrowHandler"
+
+	ctx := r.Context()
+
+	aErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, aErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	status := queries.Get("status")
+	limit := queries.Get("limit")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("status", status),
+		zap.String("limit", limit),
+	)
+
+	escrows, criteria, appErr := h.escrowService.DoFetchEscrows(ctx, username, status, limit)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		aErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Escrows fetched successfully", fnName), zap.Int("count", len(escrows)))
+
+	resp := &response.EscrowQueryResponse{
+		Status:   http.StatusOK,
+		Criteria: criteria,
+		Escrows:  escrows,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending escrow response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) ReleaseEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.ReleaseEscrowHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_ESCROW_ID,
+				Message:   "Invalid escrow ID",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+
+	payload, err := utils.DecodeJSON[request.EscrowReleasePayload](r)
+	if errors.Is(err, io.EOF) {
+		payload, err = &request.EscrowReleasePayload{}, nil
+	}
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded release payload", fnName), zap.Int64("id", id), zap.Any("payload", payload))
+
+	escrow, escrowWallet, appErr := h.escrowService.DoReleaseEscrow(ctx, tx, id, payload.Amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Escrow released", fnName), zap.Any("escrow", escrow))
+
+	if appErr := h.SettleEscrow(ctx, tx, escrow, escrowWallet); appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+
+	resp := &response.EscrowResponse{
+		Status: http.StatusOK,
+		Escrow: escrow,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending release response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) RefundEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.RefundEscrowHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_ESCROW_ID,
+				Message:   "Invalid escrow ID",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.Int64("id", id))
+
+	escrow, escrowWallet, appErr := h.escrowService.DoRefundEscrow(ctx, tx, id)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Escrow refunded", fnName), zap.Any("escrow", escrow))
+
+	if appErr := h.SettleEscrow(ctx, tx, escrow, escrowWallet); appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+
+	resp := &response.EscrowResponse{
+		Status: http.StatusOK,
+		Escrow: escrow,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending refund response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) SettleEscrow(ctx context.Context, tx *sql.Tx, escrow *model.Escrow, escrowWallet *model.Wallet) *validation.WalletError {
+	fnName := "WalletHandler.SettleEscrow"
+
+	postings := []model.Posting{
+		model.WalletPosting(escrowWallet.ID, escrow.Currency, model.DirectionDebit, escrow.Amount),
+	}
+
+	if escrow.ReleasedAmount > 0 {
+		payeeWallet, appErr := h.depositService.DoDeposit(ctx, tx, escrow.Payee, escrow.Currency, model.DefaultPocket, escrow.ReleasedAmount, true)
+		if appErr != nil {
+			appErr.Status = http.StatusInternalServerError
+			return appErr
+		}
+		postings = append(postings, model.WalletPosting(payeeWallet.ID, escrow.Currency, model.DirectionCredit, escrow.ReleasedAmount))
+		logger.Info(fmt.Sprintf("%s - Released amount deposited to payee", fnName), zap.Any("wallet", payeeWallet))
+	}
+
+	if escrow.RefundedAmount > 0 {
+		payerWallet, appErr := h.depositService.DoDeposit(ctx, tx, escrow.Payer, escrow.Currency, model.DefaultPocket, escrow.RefundedAmount, true)
+		if appErr != nil {
+			appErr.Status = http.StatusInternalServerError
+			return appErr
+		}
+		postings = append(postings, model.WalletPosting(payerWallet.ID, escrow.Currency, model.DirectionCredit, escrow.RefundedAmount))
+		logger.Info(fmt.Sprintf("%s - Refunded amount deposited to payer", fnName), zap.Any("wallet", payerWallet))
+	}
+
+	entryType := model.TypeEscrowRefund
+	if escrow.ReleasedAmount > 0 {
+		entryType = model.TypeEscrowRelease
+	}
+	entry, appErr := h.journalService.PostEntry(ctx, tx, entryType, postings)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+
+	if escrow.ReleasedAmount > 0 {
+		if _, appErr := h.logSystemLegs(ctx, tx, model.TypeEscrowRelease, model.DirectionCredit, model.EscrowWallet, escrow.Payee, escrow.Currency, model.DefaultPocket, escrow.ReleasedAmount, entry.ID); appErr != nil {
+			return appErr
+		}
+	}
+	if escrow.RefundedAmount > 0 {
+		if _, appErr := h.logSystemLegs(ctx, tx, model.TypeEscrowRefund, model.DirectionCredit, model.EscrowWallet, escrow.Payer, escrow.Currency, model.DefaultPocket, escrow.RefundedAmount, entry.ID); appErr != nil {
+			return appErr
+		}
+	}
+	return nil
+}
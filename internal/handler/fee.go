@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) AdminLoadFeeScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminLoadFeeScheduleHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.FeeScheduleLoadPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded fee schedule payload", fnName), zap.Any("payload", payload))
+
+	rules, appErr := h.feeService.DoLoadFeeSchedule(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Fee schedule loaded", fnName), zap.Any("rules", rules))
+
+	resp := &response.FeeRuleResponse{
+		Status: http.StatusOK,
+		Rules:  rules,
+	}
+	if len(rules) == 0 {
+		resp.Message = utils.Ptr(fmt.Sprintf("Fee schedule for %s cleared", payload.TxnType))
+	}
+	logger.Info(fmt.Sprintf("%s - Sending fee rule response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) AdminFeeScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminFeeScheduleHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	txnType := r.URL.Query().Get("txnType")
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.String("txnType", txnType))
+
+	rules, appErr := h.feeService.DoFetchFeeRules(ctx, txnType)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Fee rules fetched successfully", fnName), zap.Any("rules", rules))
+
+	resp := &response.FeeRuleResponse{
+		Status: http.StatusOK,
+		Rules:  rules,
+	}
+	if len(rules) == 0 {
+		resp.Message = utils.Ptr("No fee rules found")
+	}
+	logger.Info(fmt.Sprintf("%s - Sending fee rule response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) chargeFee(ctx context.Context, tx *sql.Tx, txnType model.TxnType, wallet *model.Wallet, amount int64) (*model.FeeBreakdown, *model.Wallet, *validation.WalletError) {
+	fnName := "WalletHandler.chargeFee"
+
+	fee, debited, entry, appErr := h.feeService.DoPostedCharge(ctx, tx, txnType, wallet, amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, nil, appErr
+	}
+	if entry == nil {
+		return fee, debited, nil
+	}
+	logger.Info(fmt.Sprintf("%s - Fee charged", fnName), zap.Any("fee", fee), zap.Int64("entryID", entry.ID))
+
+	transaction, appErr := h.logSystemLegs(ctx, tx, model.TypeFee, model.DirectionDebit, model.FeeWallet, debited.Username, debited.Currency, debited.Pocket, fee.Fee, entry.ID)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	fee.TransactionID = &transaction.ID
+	return fee, debited, nil
+}
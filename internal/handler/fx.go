@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) AdminLoadFXRatesHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminLoadFXRatesHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.FXRateLoadPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded FX rate payload", fnName), zap.Any("payload", payload))
+
+	rates, appErr := h.fxService.DoLoadFXRates(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - FX rates loaded", fnName), zap.Any("rates", rates))
+
+	resp := &response.FXRateResponse{
+		Status: http.StatusOK,
+		Rates:  rates,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending FX rate response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) AdminFXRatesHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminFXRatesHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	q := r.URL.Query()
+	base := q.Get("base")
+	quote := q.Get("quote")
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.String("base", base), zap.String("quote", quote))
+
+	rates, appErr := h.fxService.DoFetchFXRates(ctx, base, quote)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - FX rates fetched successfully", fnName), zap.Any("rates", rates))
+
+	resp := &response.FXRateResponse{
+		Status: http.StatusOK,
+		Rates:  rates,
+	}
+	if len(rates) == 0 {
+		resp.Message = utils.Ptr("No FX rates found")
+	}
+	logger.Info(fmt.Sprintf("%s - Sending FX rate response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) FXQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.FXQuoteHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.FXQuotePayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded FX quote payload", fnName), zap.Any("payload", payload))
+
+	quote, appErr := h.fxService.DoCreateQuote(ctx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - FX quote created", fnName), zap.Any("quote", quote))
+
+	resp := &response.FXQuoteResponse{
+		Status: http.StatusOK,
+		Quote:  quote,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending FX quote response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -16,11 +16,33 @@ import (
 )
 
 type WalletHandler struct {
-	store              *db.Store
-	walletService      *service.WalletService
-	depositService     *service.DepositService
-	withdrawService    *service.WithdrawService
-	transactionService *service.TransactionService
+	store                    *db.Store
+	walletService            *service.WalletService
+	depositService           *service.DepositService
+	withdrawService          *service.WithdrawService
+	transferService          *service.TransferService
+	transactionService       *service.TransactionService
+	journalService           *service.JournalService
+	fxService                *service.FXService
+	ledgerService            *service.LedgerService
+	reconciliationService    *service.ReconciliationService
+	reversalService          *service.ReversalService
+	holdService              *service.HoldService
+	scheduledTransferService *service.ScheduledTransferService
+	standingOrderService     *service.StandingOrderService
+	batchTransferService     *service.BatchTransferService
+	escrowService            *service.EscrowService
+	feeService               *service.FeeService
+	interestService          *service.InterestService
+	overdraftService         *service.OverdraftService
+	limitService             *service.LimitService
+	velocityService          *service.VelocityService
+	statementService         *service.StatementService
+	snapshotService          *service.SnapshotService
+	pocketService            *service.PocketService
+	jointWalletService       *service.JointWalletService
+	lifecycleService         *service.LifecycleService
+	idempotencyService       *service.IdempotencyService
 }
 
 func NewWalletHandler(
@@ -28,15 +50,59 @@ func NewWalletHandler(
 	s *service.WalletService,
 	ds *service.DepositService,
 	ws *service.WithdrawService,
+	trs *service.TransferService,
 	ts *service.TransactionService,
+	js *service.JournalService,
+	fxs *service.FXService,
+	ls *service.LedgerService,
+	rs *service.ReconciliationService,
+	rvs *service.ReversalService,
+	hs *service.HoldService,
+	sts *service.ScheduledTransferService,
+	sos *service.StandingOrderService,
+	bts *service.BatchTransferService,
+	es *service.EscrowService,
+	fs *service.FeeService,
+	is *service.InterestService,
+	ods *service.OverdraftService,
+	lms *service.LimitService,
+	vs *service.VelocityService,
+	sms *service.StatementService,
+	bss *service.SnapshotService,
+	ps *service.PocketService,
+	jws *service.JointWalletService,
+	lcs *service.LifecycleService,
+	ids *service.IdempotencyService,
 ) *WalletHandler {
 	logger.Debug("Initializing WalletHandler")
 	return &WalletHandler{
-		store:              store,
-		walletService:      s,
-		depositService:     ds,
-		withdrawService:    ws,
-		transactionService: ts,
+		store:                    store,
+		walletService:            s,
+		depositService:           ds,
+		withdrawService:          ws,
+		transferService:          trs,
+		transactionService:       ts,
+		journalService:           js,
+		fxService:                fxs,
+		ledgerService:            ls,
+		reconciliationService:    rs,
+		reversalService:          rvs,
+		holdService:              hs,
+		scheduledTransferService: sts,
+		standingOrderService:     sos,
+		batchTransferService:     bts,
+		escrowService:            es,
+		feeService:               fs,
+		interestService:          is,
+		overdraftService:         ods,
+		limitService:             lms,
+		velocityService:          vs,
+		statementService:         sms,
+		snapshotService:          bss,
+		pocketService:            ps,
+		jointWalletService:       jws,
+		lifecycleService:         lcs,
+		idempotencyService:       ids,
 	}
 }
 
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) PlaceHoldHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.PlaceHoldHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.HoldPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded hold payload", fnName), zap.Any("payload", payload))
+
+	auth, appErr := h.authorizeUnattendedSpend(ctx, fnName, "Hold", payload.Actor, payload.Username, payload.Currency, payload.Amount)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
+	payload.Actor = auth.Actor
+
+	hold, appErr := h.holdService.DoPlaceHold(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Hold placed", fnName), zap.Any("hold", hold))
+
+	resp := &response.HoldResponse{
+		Status: http.StatusOK,
+		Hold:   hold,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending hold response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) CaptureHoldHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.CaptureHoldHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_HOLD_ID,
+				Message:   "Invalid hold ID",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+
+	payload, err := utils.DecodeJSON[request.HoldCapturePayload](r)
+	if errors.Is(err, io.EOF) {
+		payload, err = &request.HoldCapturePayload{}, nil
+	}
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded capture payload", fnName), zap.Int64("id", id), zap.Any("payload", payload))
+
+	hold, appErr := h.holdService.DoCaptureHold(ctx, tx, id, payload.Amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	amount := *hold.CapturedAmount
+	logger.Info(fmt.Sprintf("%s - Hold released for capture", fnName), zap.Any("hold", hold))
+
+	auth, appErr := h.authorizeUnattendedSpend(ctx, fnName, "Capture", hold.Actor, hold.Username, hold.Currency, amount)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
+
+	spend := &request.RequestPayload{
+		Username:             hold.Username,
+		Actor:                hold.Actor,
+		Amount:               amount,
+		Currency:             hold.Currency,
+		Pocket:               hold.Pocket,
+		Counterparty:         payload.Counterparty,
+		CounterpartyCurrency: payload.CounterpartyCurrency,
+		QuoteID:              payload.QuoteID,
+		Memo:                 payload.Memo,
+		Reference:            payload.Reference,
+		Metadata:             payload.Metadata,
+	}
+
+	resp := &response.HoldResponse{
+		Status: http.StatusOK,
+		Hold:   hold,
+	}
+
+	if spend.Counterparty == nil {
+		result, appErr := h.withdraw(ctx, tx, spend)
+		if appErr != nil {
+			appErrs.AddError(*appErr)
+			return
+		}
+		logger.Info(fmt.Sprintf("%s - Captured amount withdrawn", fnName), zap.Any("wallet", result.wallet))
+
+		resp.Wallet = result.wallet
+		resp.Fee = result.fee
+		logger.Info(fmt.Sprintf("%s - Sending capture response", fnName), zap.Any("response", resp))
+		SendJSONResponse(fnName, w, resp.Status, resp)
+		return
+	}
+
+	result, appErr := h.transfer(ctx, tx, spend, auth)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Captured amount transferred", fnName), zap.Any("wallet", result.wallet), zap.String("counterparty", result.counterparty))
+
+	resp.Wallet = result.wallet
+	resp.Fee = result.fee
+	resp.Counterparty = &result.counterparty
+	resp.Quote = result.quote
+	logger.Info(fmt.Sprintf("%s - Sending capture response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) VoidHoldHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.VoidHoldHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_HOLD_ID,
+				Message:   "Invalid hold ID",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.Int64("id", id))
+
+	hold, appErr := h.holdService.DoVoidHold(ctx, tx, id)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Hold voided", fnName), zap.Any("hold", hold))
+
+	resp := &response.HoldResponse{
+		Status: http.StatusOK,
+		Hold:   hold,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending void response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
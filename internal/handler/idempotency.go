@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+var idempotencyErrorStatus = map[validation.WalletErrorCode]int{
+	validation.ERR_IDEMPOTENCY_KEY_INVALID:     http.StatusBadRequest,
+	validation.ERR_IDEMPOTENCY_KEY_IN_PROGRESS: http.StatusConflict,
+	validation.ERR_IDEMPOTENCY_KEY_REUSED:      http.StatusUnprocessableEntity,
+}
+
+// replayIdempotentRequest claims the request's Idempotency-Key for username,
+// if it sent one. It returns true after writing the stored response of an
+// earlier identical request, and the handler must then stop without doing
+// any work.
+func (h *WalletHandler) replayIdempotentRequest(ctx context.Context, tx *sql.Tx, fnName string, w http.ResponseWriter, r *http.Request, username string, payload any) (bool, *validation.WalletError) {
+	record, appErr := h.idempotencyService.DoClaimKey(ctx, tx, username, r.Header.Get(model.IdempotencyKeyHeader), r.URL.Path, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		if status, ok := idempotencyErrorStatus[appErr.Code]; ok {
+			appErr.Status = status
+		}
+		return false, appErr
+	}
+	if record == nil {
+		return false, nil
+	}
+
+	logger.Info(fmt.Sprintf("%s - Replaying idempotent response", fnName), zap.String("key", record.Key), zap.Int("status", *record.StatusCode))
+	w.Header().Set(model.IdempotentReplayHeader, "true")
+	SendJSONResponse(fnName, w, *record.StatusCode, json.RawMessage(record.Response))
+	return true, nil
+}
+
+// sendIdempotentResponse stores resp against username's Idempotency-Key
+// before sending it, so it is committed together with the request.
+func (h *WalletHandler) sendIdempotentResponse(ctx context.Context, tx *sql.Tx, fnName string, w http.ResponseWriter, r *http.Request, username string, status int, resp any) *validation.WalletError {
+	if appErr := h.idempotencyService.DoSaveResponse(ctx, tx, username, r.Header.Get(model.IdempotencyKeyHeader), status, resp); appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return appErr
+	}
+	SendJSONResponse(fnName, w, status, resp)
+	return nil
+}
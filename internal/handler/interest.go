@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) AdminRunInterestHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminRunInterestHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.InterestRunPayload](r)
+	if errors.Is(err, io.EOF) {
+		payload, err = &request.InterestRunPayload{}, nil
+	}
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded interest run payload", fnName), zap.Any("payload", payload))
+
+	run, appErr := h.interestService.DoRunAccrual(ctx, payload.Through, h.BookInterest)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Interest run completed", fnName), zap.Any("run", run))
+
+	resp := &response.InterestRunResponse{
+		Status: http.StatusOK,
+		Run:    run,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending interest run response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) AdminInterestHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminInterestHandler"
+
+	ctx := r.Context()
+
+	aErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, aErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	period := queries.Get("period")
+	limit := queries.Get("limit")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("period", period),
+		zap.String("limit", limit),
+	)
+
+	postings, criteria, appErr := h.interestService.DoFetchInterestPostings(ctx, username, period, limit)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		aErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Interest postings fetched successfully", fnName), zap.Int("count", len(postings)))
+
+	resp := &response.InterestQueryResponse{
+		Status:   http.StatusOK,
+		Criteria: criteria,
+		Postings: postings,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending interest response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) BookInterest(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, amount int64) (*model.Transaction, *validation.WalletError) {
+	fnName := "WalletHandler.BookInterest"
+
+	entry, appErr := h.journalService.PostEntry(ctx, tx, model.TypeInterest, []model.Posting{
+		model.SystemPosting(model.AccountInterest, wallet.Currency, model.DirectionDebit, amount),
+		model.WalletPosting(wallet.ID, wallet.Currency, model.DirectionCredit, amount),
+	})
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+
+	transaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       wallet.Username,
+		TxnType:        model.TypeInterest,
+		Currency:       wallet.Currency,
+		Pocket:         wallet.Pocket,
+		Amount:         amount,
+		JournalEntryID: &entry.ID,
+	})
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Interest transaction logged", fnName), zap.Any("transaction", transaction))
+	return transaction, nil
+}
@@ -0,0 +1,472 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) CreateJointWalletHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.CreateJointWalletHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.JointWalletPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded joint wallet payload", fnName), zap.Any("payload", payload))
+
+	joint, appErr := h.jointWalletService.DoCreateJointWallet(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Joint wallet created", fnName), zap.Any("joint", joint))
+
+	resp := &response.JointWalletResponse{
+		Status: http.StatusOK,
+		Joint:  joint,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending joint wallet response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) JointWalletHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.JointWalletHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	username := r.URL.Query().Get("username")
+	logger.Info(fmt.Sprintf("%s - Query values", fnName), zap.String("username", username))
+
+	joint, appErr := h.jointWalletService.DoFetchJointWallet(ctx, username)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Joint wallet fetched successfully", fnName), zap.Any("joint", joint))
+
+	resp := &response.JointWalletResponse{
+		Status: http.StatusOK,
+		Joint:  joint,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending joint wallet response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) SetWalletMemberHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.SetWalletMemberHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.WalletMemberPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	username := r.PathValue("username")
+	logger.Info(fmt.Sprintf("%s - Decoded member payload", fnName), zap.String("username", username), zap.Any("payload", payload))
+
+	joint, appErr := h.jointWalletService.DoSetMember(ctx, tx, username, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Joint wallet member updated", fnName), zap.Any("joint", joint))
+
+	resp := &response.JointWalletResponse{
+		Status: http.StatusOK,
+		Joint:  joint,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending joint wallet response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) SetSigningRuleHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.SetSigningRuleHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.SigningRulePayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	username := r.PathValue("username")
+	logger.Info(fmt.Sprintf("%s - Decoded signing rule payload", fnName), zap.String("username", username), zap.Any("payload", payload))
+
+	joint, appErr := h.jointWalletService.DoSetSigningRule(ctx, tx, username, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Signing rule updated", fnName), zap.Any("joint", joint))
+
+	resp := &response.JointWalletResponse{
+		Status: http.StatusOK,
+		Joint:  joint,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending joint wallet response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) ApprovalsHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.ApprovalsHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	status := queries.Get("status")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("status", status),
+	)
+
+	approvals, appErr := h.jointWalletService.DoFetchApprovals(ctx, username, status)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Approval requests fetched successfully", fnName), zap.Int("count", len(approvals)))
+
+	resp := &response.ApprovalResponse{
+		Status:    http.StatusOK,
+		Approvals: approvals,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending approvals response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) ApproveHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.ApproveHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, payload, appErr := decodeApprovalDecision(fnName, r)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded approval payload", fnName), zap.Int64("id", id), zap.Any("payload", payload))
+
+	approval, ready, appErr := h.jointWalletService.DoApprove(ctx, tx, id, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Approval recorded", fnName), zap.Any("approval", approval), zap.Bool("ready", ready))
+
+	if !ready {
+		message := fmt.Sprintf("Awaiting approval: %d of %d", len(approval.Approvals), approval.RequiredApprovals)
+		resp := &response.ApprovalResponse{
+			Status:   http.StatusOK,
+			Message:  &message,
+			Approval: approval,
+		}
+		logger.Info(fmt.Sprintf("%s - Sending approval response", fnName), zap.Any("response", resp))
+		SendJSONResponse(fnName, w, resp.Status, resp)
+		return
+	}
+
+	auth, appErr := h.withdrawService.DoAuthorizeSpend(ctx, approval.Initiator, approval.WalletUsername, approval.Currency, approval.Amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Initiator still authorized", fnName), zap.Any("authorization", auth))
+
+	spend := &request.RequestPayload{
+		Username:             approval.WalletUsername,
+		Actor:                approval.Initiator,
+		Amount:               approval.Amount,
+		Currency:             approval.Currency,
+		Pocket:               approval.Pocket,
+		Counterparty:         approval.Counterparty,
+		CounterpartyCurrency: approval.CounterpartyCurrency,
+		QuoteID:              approval.QuoteID,
+		Memo:                 approval.Memo,
+		Reference:            approval.Reference,
+		Metadata:             approval.Metadata,
+	}
+
+	var wallet *model.Wallet
+	var fee *model.FeeBreakdown
+	var transactionID int64
+	if approval.TxnType == model.TypeTransfer {
+		result, appErr := h.transfer(ctx, tx, spend, auth)
+		if appErr != nil {
+			appErrs.AddError(*appErr)
+			return
+		}
+		wallet, fee, transactionID = result.wallet, result.fee, result.outTransaction.ID
+	} else {
+		result, appErr := h.withdraw(ctx, tx, spend)
+		if appErr != nil {
+			appErrs.AddError(*appErr)
+			return
+		}
+		wallet, fee, transactionID = result.wallet, result.fee, result.transaction.ID
+	}
+	logger.Info(fmt.Sprintf("%s - Approved %s executed", fnName, approval.TxnType), zap.Int64("transactionID", transactionID))
+
+	if appErr := h.jointWalletService.DoMarkExecuted(ctx, tx, approval, transactionID); appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+
+	message := "Approved and executed"
+	resp := &response.ApprovalResponse{
+		Status:   http.StatusOK,
+		Message:  &message,
+		Approval: approval,
+		Wallet:   wallet,
+		Fee:      fee,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending approval response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) RejectHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.RejectHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, payload, appErr := decodeApprovalDecision(fnName, r)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded rejection payload", fnName), zap.Int64("id", id), zap.Any("payload", payload))
+
+	approval, appErr := h.jointWalletService.DoReject(ctx, tx, id, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Approval request rejected", fnName), zap.Any("approval", approval))
+
+	resp := &response.ApprovalResponse{
+		Status:   http.StatusOK,
+		Approval: approval,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending approval response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) requestApproval(ctx context.Context, tx *sql.Tx, auth *model.SpendAuthorization, txnType model.TxnType, payload *request.RequestPayload) (*response.ApprovalResponse, *validation.WalletError) {
+	fnName := "WalletHandler.requestApproval"
+
+	approval, appErr := h.jointWalletService.DoRequestApproval(ctx, tx, auth, txnType, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Approval requested", fnName), zap.Any("approval", approval))
+
+	message := fmt.Sprintf("Awaiting approval: %d of %d", len(approval.Approvals), approval.RequiredApprovals)
+	return &response.ApprovalResponse{
+		Status:   http.StatusOK,
+		Message:  &message,
+		Approval: approval,
+	}, nil
+}
+
+func decodeApprovalDecision(fnName string, r *http.Request) (int64, *request.ApprovalDecisionPayload, *validation.WalletError) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, nil, &validation.WalletError{
+			Name:      fnName,
+			Status:    http.StatusBadRequest,
+			Code:      validation.ERR_INVALID_APPROVAL_ID,
+			Message:   "Invalid approval ID",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+
+	payload, err := utils.DecodeJSON[request.ApprovalDecisionPayload](r)
+	if err != nil {
+		return 0, nil, &validation.WalletError{
+			Name:      fnName,
+			Status:    http.StatusBadRequest,
+			Code:      validation.ERR_INVALID_JSON_BODY,
+			Message:   "Failed to decode JSON body",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	return id, payload, nil
+}
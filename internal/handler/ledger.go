@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) AdminLedgerVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminLedgerVerifyHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	verification, appErr := h.ledgerService.DoVerifyChain(ctx)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction chain walked", fnName), zap.Bool("verified", verification.Verified), zap.Int64("checked", verification.Checked))
+
+	resp := &response.LedgerVerifyResponse{
+		Status:       http.StatusOK,
+		Verification: verification,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending ledger verification response", fnName), zap.Any("verification", verification))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type walletStatusAction func(ctx context.Context, tx *sql.Tx, payload *request.WalletStatusPayload) (*model.WalletStatusChange, *validation.WalletError)
+
+func (h *WalletHandler) AdminFreezeWalletHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus("WalletHandler.AdminFreezeWalletHandler", w, r, h.lifecycleService.DoFreezeWallet)
+}
+
+func (h *WalletHandler) AdminUnfreezeWalletHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus("WalletHandler.AdminUnfreezeWalletHandler", w, r, h.lifecycleService.DoUnfreezeWallet)
+}
+
+func (h *WalletHandler) AdminCloseWalletHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus("WalletHandler.AdminCloseWalletHandler", w, r, h.lifecycleService.DoCloseWallet)
+}
+
+func (h *WalletHandler) AdminWalletStatusHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminWalletStatusHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	currency := queries.Get("currency")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("currency", currency),
+	)
+
+	wallet, history, appErr := h.lifecycleService.DoFetchWalletStatus(ctx, username, currency)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet status fetched successfully", fnName), zap.String("status", string(wallet.Status)), zap.Int("changes", len(history)))
+
+	resp := &response.WalletStatusResponse{
+		Status:       http.StatusOK,
+		Username:     wallet.Username,
+		Currency:     wallet.Currency,
+		WalletStatus: wallet.Status,
+		History:      history,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending wallet status response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) changeWalletStatus(fnName string, w http.ResponseWriter, r *http.Request, action walletStatusAction) {
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.WalletStatusPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded wallet status payload", fnName), zap.Any("payload", payload))
+
+	change, appErr := action(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet status changed", fnName), zap.Any("change", change))
+
+	resp := &response.WalletStatusResponse{
+		Status:       http.StatusOK,
+		Username:     change.Username,
+		Currency:     change.Currency,
+		WalletStatus: change.ToStatus,
+		Change:       change,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending wallet status response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) AdminSetWalletLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminSetWalletLimitsHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.WalletLimitsPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded wallet limits payload", fnName), zap.Any("payload", payload))
+
+	wallet, appErr := h.limitService.DoSetWalletLimits(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet limits set", fnName), zap.Any("wallet", wallet))
+
+	resp := &response.WalletResponse{
+		Status: http.StatusOK,
+		Wallet: wallet,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending wallet response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) AdminWalletLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminWalletLimitsHandler"
+
+	ctx := r.Context()
+
+	aErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, aErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	currency := queries.Get("currency")
+	pocket := queries.Get("pocket")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("currency", currency),
+		zap.String("pocket", pocket),
+	)
+
+	wallet, defaults, appErr := h.limitService.DoFetchWalletLimits(ctx, username, currency, pocket)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		aErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet limits fetched successfully", fnName), zap.Any("limits", wallet.Limits))
+
+	resp := &response.WalletLimitsResponse{
+		Status:   http.StatusOK,
+		Username: wallet.Username,
+		Currency: wallet.Currency,
+		Limits:   wallet.Limits,
+		Defaults: defaults,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending wallet limits response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) AdminSetCreditLimitHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminSetCreditLimitHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.CreditLimitPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded credit limit payload", fnName), zap.Any("payload", payload))
+
+	wallet, appErr := h.overdraftService.DoSetCreditLimit(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Credit limit set", fnName), zap.Any("wallet", wallet))
+
+	resp := &response.WalletResponse{
+		Status: http.StatusOK,
+		Wallet: wallet,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending wallet response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) AdminOverdraftHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminOverdraftHandler"
+
+	ctx := r.Context()
+
+	aErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, aErrs)
+	}()
+
+	overdrafts, appErr := h.overdraftService.DoFetchOverdrafts(ctx)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		aErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Overdrawn wallets fetched successfully", fnName), zap.Int("count", len(overdrafts)))
+
+	resp := &response.OverdraftResponse{
+		Status:  http.StatusOK,
+		Wallets: overdrafts,
+	}
+	if len(overdrafts) == 0 {
+		resp.Message = utils.Ptr("No wallets in overdraft")
+	}
+	logger.Info(fmt.Sprintf("%s - Sending overdraft response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) AdminChargeOverdraftsHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminChargeOverdraftsHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	run, appErr := h.overdraftService.DoChargeOverdrafts(ctx, h.ChargeOverdraft)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Overdraft charge run completed", fnName), zap.Any("run", run))
+
+	resp := &response.OverdraftRunResponse{
+		Status: http.StatusOK,
+		Run:    run,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending overdraft run response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) ChargeOverdraft(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, usage int64) (*model.FeeBreakdown, *validation.WalletError) {
+	fnName := "WalletHandler.ChargeOverdraft"
+
+	fee, _, appErr := h.chargeFee(ctx, tx, model.FeeOverdraft, wallet, usage)
+	if appErr != nil {
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Overdraft charge evaluated", fnName), zap.String("username", wallet.Username), zap.Int64("usage", usage), zap.Any("fee", fee))
+	return fee, nil
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) CreatePocketHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.CreatePocketHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.PocketPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded pocket payload", fnName), zap.Any("payload", payload))
+
+	pocket, appErr := h.pocketService.DoCreatePocket(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket created", fnName), zap.Any("pocket", pocket))
+
+	resp := &response.PocketResponse{
+		Status: http.StatusOK,
+		Pocket: pocket,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending pocket response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) PocketHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.PocketHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	currency := queries.Get("currency")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("currency", currency),
+	)
+
+	pockets, appErr := h.pocketService.DoFetchPockets(ctx, username, currency)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Pockets fetched successfully", fnName), zap.Int("count", len(pockets)))
+
+	resp := &response.PocketResponse{
+		Status:  http.StatusOK,
+		Pockets: pockets,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending pockets response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) MovePocketFundsHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.MovePocketFundsHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.PocketMovePayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded pocket move payload", fnName), zap.Any("payload", payload))
+
+	from, to, appErr := h.pocketService.DoMovePocketFunds(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Funds moved between pockets", fnName), zap.Any("from", from), zap.Any("to", to))
+
+	entry, appErr := h.journalService.PostPocketMove(ctx, tx, from, to, payload.Amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+
+	outTransaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       from.Username,
+		TxnType:        model.TypePocketOut,
+		Currency:       from.Currency,
+		Pocket:         from.Pocket,
+		Amount:         payload.Amount,
+		Counterparty:   &to.Username,
+		JournalEntryID: &entry.ID,
+	})
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket out transaction logged", fnName), zap.Any("transaction", outTransaction))
+
+	inTransaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       to.Username,
+		TxnType:        model.TypePocketIn,
+		Currency:       to.Currency,
+		Pocket:         to.Pocket,
+		Amount:         payload.Amount,
+		Counterparty:   &from.Username,
+		JournalEntryID: &entry.ID,
+	})
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket in transaction logged", fnName), zap.Any("transaction", inTransaction))
+
+	resp := &response.PocketMoveResponse{
+		Status:   http.StatusOK,
+		Currency: from.Currency,
+		Amount:   payload.Amount,
+		From:     from,
+		To:       to,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending pocket move response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
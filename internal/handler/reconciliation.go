@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) AdminReconciliationHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminReconciliationHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	refresh := r.URL.Query().Get("refresh") == "true"
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.Bool("refresh", refresh))
+
+	var (
+		report *model.ReconciliationReport
+		appErr *validation.WalletError
+	)
+	if refresh {
+		report, appErr = h.reconciliationService.DoReconcile(ctx)
+	} else {
+		report, appErr = h.reconciliationService.DoFetchLatestReport(ctx)
+	}
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Reconciliation report fetched", fnName), zap.Bool("reconciled", report.Reconciled), zap.Int("mismatched", report.Mismatched))
+
+	resp := &response.ReconciliationResponse{
+		Status: http.StatusOK,
+		Report: report,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending reconciliation response", fnName), zap.Any("report", report))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
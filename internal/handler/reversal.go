@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) ReversalHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.ReversalHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_TRANSACTION_ID,
+				Message:   "Invalid transaction ID",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+
+	payload, err := utils.DecodeJSON[request.ReversalPayload](r)
+	if errors.Is(err, io.EOF) {
+		payload, err = &request.ReversalPayload{}, nil
+	}
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded reversal payload", fnName), zap.Int64("id", id), zap.Any("payload", payload))
+
+	legs, appErr := h.reversalService.DoPrepareReversal(ctx, tx, id, payload.Amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Reversal prepared", fnName), zap.Int("legs", len(legs)))
+
+	wallets := []model.Wallet{}
+	for i := range legs {
+		leg := &legs[i]
+		if leg.Direction == model.DirectionDebit {
+			leg.Wallet, appErr = h.withdrawService.DoWithdraw(ctx, tx, leg.Original.Username, leg.Original.Currency, leg.Original.Pocket, leg.Amount)
+		} else {
+			leg.Wallet, appErr = h.depositService.DoDeposit(ctx, tx, leg.Original.Username, leg.Original.Currency, leg.Original.Pocket, leg.Amount, true)
+		}
+		if appErr != nil {
+			appErr.Status = http.StatusInternalServerError
+			appErrs.AddError(*appErr)
+			return
+		}
+		wallets = append(wallets, *leg.Wallet)
+		logger.Info(fmt.Sprintf("%s - Reversal leg applied", fnName), zap.Int64("originalID", leg.Original.ID), zap.Any("wallet", leg.Wallet))
+	}
+
+	entry, appErr := h.journalService.PostReversal(ctx, tx, legs)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+
+	reversals := []model.Transaction{}
+	for _, leg := range legs {
+		transaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+			Username:       leg.Original.Username,
+			TxnType:        model.TypeReversal,
+			Direction:      leg.Direction,
+			Currency:       leg.Original.Currency,
+			Pocket:         leg.Original.Pocket,
+			Amount:         leg.Amount,
+			Counterparty:   leg.Original.Counterparty,
+			FXRate:         leg.Original.FXRate,
+			JournalEntryID: &entry.ID,
+			ReversalOf:     &leg.Original.ID,
+		})
+		if appErr != nil {
+			appErr.Status = http.StatusInternalServerError
+			appErrs.AddError(*appErr)
+			return
+		}
+		reversals = append(reversals, *transaction)
+	}
+	logger.Info(fmt.Sprintf("%s - Reversal transactions logged", fnName), zap.Any("reversals", reversals))
+
+	resp := &response.ReversalResponse{
+		Status:    http.StatusOK,
+		Reversals: reversals,
+		Wallets:   wallets,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending reversal response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) CreateScheduledTransferHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.CreateScheduledTransferHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.ScheduledTransferPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded scheduled transfer payload", fnName), zap.Any("payload", payload))
+
+	auth, appErr := h.authorizeUnattendedSpend(ctx, fnName, "Scheduled transfer", payload.Actor, payload.Username, payload.Currency, payload.Amount)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	payload.Actor = auth.Actor
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
+
+	scheduled, appErr := h.scheduledTransferService.DoCreateScheduledTransfer(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled transfer created", fnName), zap.Any("scheduledTransfer", scheduled))
+
+	resp := &response.ScheduledTransferResponse{
+		Status:            http.StatusOK,
+		ScheduledTransfer: scheduled,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending scheduled transfer response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) ScheduledTransferHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.ScheduledTransferHandler"
+
+	ctx := r.Context()
+
+	aErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, aErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	standingOrderID := queries.Get("standingOrderId")
+	status := queries.Get("status")
+	limit := queries.Get("limit")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("standingOrderId", standingOrderID),
+		zap.String("status", status),
+		zap.String("limit", limit),
+	)
+
+	scheduled, criteria, appErr := h.scheduledTransferService.DoFetchScheduledTransfers(ctx, username, standingOrderID, status, limit)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		aErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled transfers fetched successfully", fnName), zap.Int("count", len(scheduled)))
+
+	resp := &response.ScheduledTransferQueryResponse{
+		Status:             http.StatusOK,
+		Criteria:           criteria,
+		ScheduledTransfers: scheduled,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending scheduled transfer response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) CancelScheduledTransferHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.CancelScheduledTransferHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_SCHEDULED_TRANSFER_ID,
+				Message:   "Invalid scheduled transfer ID",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.Int64("id", id))
+
+	scheduled, appErr := h.scheduledTransferService.DoCancelScheduledTransfer(ctx, tx, id)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled transfer cancelled", fnName), zap.Any("scheduledTransfer", scheduled))
+
+	resp := &response.ScheduledTransferResponse{
+		Status:            http.StatusOK,
+		ScheduledTransfer: scheduled,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending cancel response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
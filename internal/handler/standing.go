@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) CreateStandingOrderHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.CreateStandingOrderHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.StandingOrderPayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded standing order payload", fnName), zap.Any("payload", payload))
+
+	auth, appErr := h.authorizeUnattendedSpend(ctx, fnName, "Standing order", payload.Actor, payload.Username, payload.Currency, payload.Amount)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+	payload.Actor = auth.Actor
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
+
+	order, appErr := h.standingOrderService.DoCreateStandingOrder(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Standing order created", fnName), zap.Any("standingOrder", order))
+
+	resp := &response.StandingOrderResponse{
+		Status:        http.StatusOK,
+		StandingOrder: order,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending standing order response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) StandingOrderHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.StandingOrderHandler"
+
+	ctx := r.Context()
+
+	aErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, aErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	status := queries.Get("status")
+	limit := queries.Get("limit")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("status", status),
+		zap.String("limit", limit),
+	)
+
+	orders, criteria, appErr := h.standingOrderService.DoFetchStandingOrders(ctx, username, status, limit)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		aErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Standing orders fetched successfully", fnName), zap.Int("count", len(orders)))
+
+	resp := &response.StandingOrderQueryResponse{
+		Status:         http.StatusOK,
+		Criteria:       criteria,
+		StandingOrders: orders,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending standing order response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) CancelStandingOrderHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.CancelStandingOrderHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_STANDING_ORDER_ID,
+				Message:   "Invalid standing order ID",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.Int64("id", id))
+
+	order, appErr := h.standingOrderService.DoCancelStandingOrder(ctx, tx, id)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Standing order cancelled", fnName), zap.Any("standingOrder", order))
+
+	resp := &response.StandingOrderResponse{
+		Status:        http.StatusOK,
+		StandingOrder: order,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending cancel response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) StatementHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.StatementHandler"
+
+	ctx := r.Context()
+
+	aErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, aErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	currency := queries.Get("currency")
+	from := queries.Get("from")
+	to := queries.Get("to")
+	period := queries.Get("period")
+
+	logger.Info(fmt.Sprintf("%s - Query values", fnName),
+		zap.String("username", username),
+		zap.String("currency", currency),
+		zap.String("from", from),
+		zap.String("to", to),
+		zap.String("period", period),
+	)
+
+	statement, appErr := h.statementService.DoFetchStatement(ctx, username, currency, from, to, period)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		aErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Statement fetched successfully", fnName), zap.Int("lines", len(statement.Lines)))
+
+	resp := &response.StatementResponse{
+		Status:    http.StatusOK,
+		Statement: statement,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending statement response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) AdminRunStatementsHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminRunStatementsHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.StatementRunPayload](r)
+	if errors.Is(err, io.EOF) {
+		payload, err = &request.StatementRunPayload{}, nil
+	}
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded statement run payload", fnName), zap.Any("payload", payload))
+
+	run, appErr := h.statementService.DoGenerateStatements(ctx, payload.Period)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Statement run completed", fnName), zap.Any("run", run))
+
+	resp := &response.StatementRunResponse{
+		Status: http.StatusOK,
+		Run:    run,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending statement run response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 
 	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
 	"github.com/ezjuanify/wallet/internal/model/response"
 	"github.com/ezjuanify/wallet/internal/validation"
 	"go.uber.org/zap"
@@ -25,16 +28,22 @@ func (h *WalletHandler) TransactionHandler(w http.ResponseWriter, r *http.Reques
 	username := queries.Get("username")
 	counterparty := queries.Get("counterparty")
 	txnType := queries.Get("type")
+	currency := queries.Get("currency")
+	pocket := queries.Get("pocket")
+	reference := queries.Get("reference")
 	limit := queries.Get("limit")
 
 	logger.Info(fmt.Sprintf("%s - Query values", fnName),
 		zap.String("username", username),
 		zap.String("counterparty", counterparty),
 		zap.String("txnType", txnType),
+		zap.String("currency", currency),
+		zap.String("pocket", pocket),
+		zap.String("reference", reference),
 		zap.String("limit", limit),
 	)
 
-	transactions, criteria, appErr := h.transactionService.DoFetchTransaction(ctx, username, counterparty, txnType, limit)
+	transactions, criteria, appErr := h.transactionService.DoFetchTransaction(ctx, username, counterparty, txnType, currency, pocket, reference, limit)
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
 		aErrs.AddError(*appErr)
@@ -50,3 +59,39 @@ func (h *WalletHandler) TransactionHandler(w http.ResponseWriter, r *http.Reques
 	logger.Info(fmt.Sprintf("%s - Sending transaction response", fnName), zap.Any("response", resp))
 	SendJSONResponse(fnName, w, resp.Status, resp)
 }
+
+func (h *WalletHandler) logSystemLegs(ctx context.Context, tx *sql.Tx, txnType model.TxnType, direction model.PostingDirection, systemWallet string, username string, currency string, pocket string, amount int64, entryID int64) (*model.Transaction, *validation.WalletError) {
+	fnName := "WalletHandler.logSystemLegs"
+
+	userTransaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       username,
+		TxnType:        txnType,
+		Direction:      direction,
+		Currency:       currency,
+		Pocket:         pocket,
+		Amount:         amount,
+		Counterparty:   &systemWallet,
+		JournalEntryID: &entryID,
+	})
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - User transaction logged successfully", fnName), zap.Any("transaction", userTransaction))
+
+	systemTransaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       systemWallet,
+		TxnType:        txnType,
+		Direction:      direction.Opposite(),
+		Currency:       currency,
+		Amount:         amount,
+		Counterparty:   &username,
+		JournalEntryID: &entryID,
+	})
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - System transaction logged successfully", fnName), zap.Any("transaction", systemTransaction))
+	return userTransaction, nil
+}
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/ezjuanify/wallet/internal/logger"
 	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
 	"github.com/ezjuanify/wallet/internal/model/response"
 	"github.com/ezjuanify/wallet/internal/utils"
 	"github.com/ezjuanify/wallet/internal/validation"
@@ -56,76 +59,224 @@ func (h *WalletHandler) TransferHandler(w http.ResponseWriter, r *http.Request)
 	}
 	logger.Debug("Decoded transfer payload", zap.Any("payload", payload))
 
-	wallet, appErr := h.withdrawService.DoWithdraw(ctx, tx, payload.Username, payload.Amount)
+	replayed, appErr := h.replayIdempotentRequest(ctx, tx, fnName, w, r, payload.Username, payload)
 	if appErr != nil {
-		appErr.Status = http.StatusInternalServerError
 		appErrs.AddError(*appErr)
 		return
 	}
-	logger.Info(fmt.Sprintf("%s - Transfer out successful", fnName), zap.Any("wallet", wallet))
+	if replayed {
+		return
+	}
 
-	_, appErr = h.depositService.DoDeposit(ctx, tx, *payload.Counterparty, payload.Amount, true)
+	auth, appErr := h.withdrawService.DoAuthorizeSpend(ctx, payload.Actor, payload.Username, payload.Currency, payload.Amount)
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
 		appErrs.AddError(*appErr)
 		return
 	}
-	logger.Info(fmt.Sprintf("%s - Transfer in successful", fnName), zap.Any("wallet", wallet))
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
 
-	username, err := validation.SanitizeAndValidateUsername(payload.Username)
-	if err != nil {
-		appErrs.AddError(
-			validation.WalletError{
+	if auth.RequiresApproval() {
+		resp, appErr := h.requestApproval(ctx, tx, auth, model.TypeTransfer, payload)
+		if appErr != nil {
+			appErrs.AddError(*appErr)
+			return
+		}
+		logger.Info(fmt.Sprintf("%s - Sending approval response", fnName), zap.Any("response", resp))
+		if appErr := h.sendIdempotentResponse(ctx, tx, fnName, w, r, payload.Username, resp.Status, resp); appErr != nil {
+			appErrs.AddError(*appErr)
+		}
+		return
+	}
+
+	result, appErr := h.transfer(ctx, tx, payload, auth)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
+
+	resp := &response.TransactionResponse{
+		Status:          http.StatusOK,
+		TransactionType: model.TypeTransfer,
+		Wallet:          *result.wallet,
+		Counterparty:    &result.counterparty,
+		Quote:           result.quote,
+		Fee:             result.fee,
+	}
+	logger.Info(fmt.Sprintf("%s - Sending transfer response", fnName), zap.Any("response", resp))
+	if appErr := h.sendIdempotentResponse(ctx, tx, fnName, w, r, payload.Username, resp.Status, resp); appErr != nil {
+		appErrs.AddError(*appErr)
+	}
+}
+
+type transferResult struct {
+	wallet         *model.Wallet
+	counterparty   string
+	quote          *model.FXQuote
+	fee            *model.FeeBreakdown
+	outTransaction *model.Transaction
+}
+
+func (h *WalletHandler) ExecuteTransfer(ctx context.Context, tx *sql.Tx, payload *request.RequestPayload) (*model.Transaction, *validation.WalletError) {
+	result, appErr := h.transfer(ctx, tx, payload, nil)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return result.outTransaction, nil
+}
+
+func (h *WalletHandler) transfer(ctx context.Context, tx *sql.Tx, payload *request.RequestPayload, auth *model.SpendAuthorization) (*transferResult, *validation.WalletError) {
+	fnName := "WalletHandler.transfer"
+
+	if auth == nil {
+		var appErr *validation.WalletError
+		auth, appErr = h.authorizeUnattendedSpend(ctx, fnName, "Transfer", payload.Actor, payload.Username, payload.Currency, payload.Amount)
+		if appErr != nil {
+			return nil, appErr
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
+
+	var quote *model.FXQuote
+	if payload.QuoteID != nil {
+		var quoteCurrency string
+		if payload.CounterpartyCurrency != nil {
+			quoteCurrency = *payload.CounterpartyCurrency
+		}
+
+		var appErr *validation.WalletError
+		quote, appErr = h.fxService.DoConsumeQuote(ctx, tx, *payload.QuoteID, payload.Username, payload.Currency, quoteCurrency, payload.Amount)
+		if appErr != nil {
+			appErr.Status = http.StatusInternalServerError
+			return nil, appErr
+		}
+		logger.Info(fmt.Sprintf("%s - FX quote consumed", fnName), zap.Any("quote", quote))
+	} else if payload.CounterpartyCurrency != nil {
+		currency, _ := validation.SanitizeAndValidateCurrency(payload.Currency)
+		counterpartyCurrency, _ := validation.SanitizeAndValidateCurrency(*payload.CounterpartyCurrency)
+		if currency.Code != counterpartyCurrency.Code {
+			return nil, &validation.WalletError{
 				Name:      fnName,
-				Status:    http.StatusInternalServerError,
-				Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
-				Message:   "Failed to sanitize username",
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_CROSS_CURRENCY_TRANSFER,
+				Message:   "Cross-currency transfers require an FX quote",
 				Timestamp: time.Now().UTC(),
-				Err:       err,
-			},
-		)
-		return
+				Err:       fmt.Errorf("cannot transfer %q to %q without conversion", payload.Currency, *payload.CounterpartyCurrency),
+			}
+		}
+	}
+
+	wallet, counterpartyWallet, entry, appErr := h.transferService.DoTransfer(ctx, tx, payload.Username, payload.Currency, payload.Pocket, payload.Amount, *payload.Counterparty, quote)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Transfer successful", fnName), zap.Any("wallet", wallet), zap.Any("counterpartyWallet", counterpartyWallet), zap.Int64("entryID", entry.ID))
+
+	username, err := validation.SanitizeAndValidateUsername(payload.Username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Status:    http.StatusInternalServerError,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize username",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
 	}
 	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.Any("username", username))
 
 	counterparty, err := validation.SanitizeAndValidateUsername(*payload.Counterparty)
 	if err != nil {
-		appErrs.AddError(
-			validation.WalletError{
-				Name:      fnName,
-				Status:    http.StatusInternalServerError,
-				Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
-				Message:   "Failed to sanitize counterparty",
-				Timestamp: time.Now().UTC(),
-				Err:       err,
-			},
-		)
-		return
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Status:    http.StatusInternalServerError,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize counterparty",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
 	}
 	logger.Info(fmt.Sprintf("%s - Counterparty sanitized", fnName), zap.Any("counterparty", counterparty))
 
-	outTransaction, appErr := h.transactionService.LogTransaction(ctx, tx, username, model.TypeTransferOut, payload.Amount, &counterparty)
+	var fxRate, quoteID *string
+	counterpartyAmount := payload.Amount
+	if quote != nil {
+		fxRate, quoteID = &quote.Rate, &quote.ID
+		counterpartyAmount = quote.QuoteAmount
+	}
+
+	outTransaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       username,
+		TxnType:        model.TypeTransferOut,
+		Currency:       wallet.Currency,
+		Pocket:         wallet.Pocket,
+		Amount:         payload.Amount,
+		Counterparty:   &counterparty,
+		FXRate:         fxRate,
+		QuoteID:        quoteID,
+		JournalEntryID: &entry.ID,
+		Memo:           payload.Memo,
+		Reference:      payload.Reference,
+		Metadata:       payload.Metadata,
+	})
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
-		appErrs.AddError(*appErr)
-		return
+		return nil, appErr
 	}
 	logger.Info(fmt.Sprintf("%s - Transfer out transaction logged successfully", fnName), zap.Any("outTransaction", outTransaction))
 
-	inTransaction, appErr := h.transactionService.LogTransaction(ctx, tx, counterparty, model.TypeTransferIn, payload.Amount, &username)
+	inTransaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       counterparty,
+		TxnType:        model.TypeTransferIn,
+		Currency:       counterpartyWallet.Currency,
+		Pocket:         counterpartyWallet.Pocket,
+		Amount:         counterpartyAmount,
+		Counterparty:   &username,
+		FXRate:         fxRate,
+		QuoteID:        quoteID,
+		JournalEntryID: &entry.ID,
+		Memo:           payload.Memo,
+		Reference:      payload.Reference,
+		Metadata:       payload.Metadata,
+	})
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
-		appErrs.AddError(*appErr)
-		return
+		return nil, appErr
 	}
 	logger.Info(fmt.Sprintf("%s - Transfer in transaction logged successfully", fnName), zap.Any("inTransaction", inTransaction))
 
-	resp := &response.TransactionResponse{
-		Status:          http.StatusOK,
-		TransactionType: model.TypeTransfer,
-		Wallet:          *wallet,
-		Counterparty:    &counterparty,
+	fee, wallet, appErr := h.chargeFee(ctx, tx, model.TypeTransfer, wallet, payload.Amount)
+	if appErr != nil {
+		return nil, appErr
 	}
-	logger.Info(fmt.Sprintf("%s - Sending transfer response", fnName), zap.Any("response", resp))
-	SendJSONResponse(fnName, w, resp.Status, resp)
+
+	return &transferResult{
+		wallet:         wallet,
+		counterparty:   counterparty,
+		quote:          quote,
+		fee:            fee,
+		outTransaction: outTransaction,
+	}, nil
+}
+
+// authorizeUnattendedSpend authorizes a spend that runs with nobody around to
+// collect approvals, so one that needs more than the actor's is rejected.
+func (h *WalletHandler) authorizeUnattendedSpend(ctx context.Context, fnName string, what string, actor string, username string, currency string, amount int64) (*model.SpendAuthorization, *validation.WalletError) {
+	auth, appErr := h.withdrawService.DoAuthorizeSpend(ctx, actor, username, currency, amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	if auth.RequiresApproval() {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Status:    http.StatusBadRequest,
+			Code:      validation.ERR_APPROVAL_REQUIRED,
+			Message:   fmt.Sprintf("%s needs %d approvals and cannot run unattended", what, auth.RequiredApprovals),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("%s requires %d approvals for %d", auth.Username, auth.RequiredApprovals, amount),
+		}
+	}
+	return auth, nil
 }
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/model/response"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+func (h *WalletHandler) AdminSetVelocityRuleHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminSetVelocityRuleHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusInternalServerError,
+				Code:      validation.ERR_TRANSACTION_START_FAILED,
+				Message:   "Failed to start transaction",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction Started", fnName))
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, tx, w, appErrs)
+	}()
+
+	payload, err := utils.DecodeJSON[request.VelocityRulePayload](r)
+	if err != nil {
+		appErrs.AddError(
+			validation.WalletError{
+				Name:      fnName,
+				Status:    http.StatusBadRequest,
+				Code:      validation.ERR_INVALID_JSON_BODY,
+				Message:   "Failed to decode JSON body",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			},
+		)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Decoded velocity rule payload", fnName), zap.Any("payload", payload))
+
+	rule, appErr := h.velocityService.DoSetVelocityRule(ctx, tx, payload)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity rule set", fnName), zap.Any("rule", rule))
+
+	resp := &response.VelocityRuleResponse{
+		Status: http.StatusOK,
+		Rules:  []model.VelocityRule{},
+	}
+	if rule != nil {
+		resp.Rules = append(resp.Rules, *rule)
+	} else {
+		resp.Message = utils.Ptr(fmt.Sprintf("%s %s velocity limit removed", payload.Window, payload.TxnType))
+	}
+	logger.Info(fmt.Sprintf("%s - Sending velocity rule response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
+
+func (h *WalletHandler) AdminVelocityRulesHandler(w http.ResponseWriter, r *http.Request) {
+	fnName := "WalletHandler.AdminVelocityRulesHandler"
+
+	ctx := r.Context()
+
+	appErrs := validation.NewHandlerErrors()
+
+	defer func() {
+		FinalizeTransactionResponse(fnName, nil, w, appErrs)
+	}()
+
+	queries := r.URL.Query()
+	username := queries.Get("username")
+	txnType := queries.Get("txnType")
+	logger.Info(fmt.Sprintf("%s - Request received", fnName), zap.String("username", username), zap.String("txnType", txnType))
+
+	rules, appErr := h.velocityService.DoFetchVelocityRules(ctx, username, txnType)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		appErrs.AddError(*appErr)
+		return
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity rules fetched successfully", fnName), zap.Any("rules", rules))
+
+	resp := &response.VelocityRuleResponse{
+		Status: http.StatusOK,
+		Rules:  rules,
+	}
+	if len(rules) == 0 {
+		resp.Message = utils.Ptr("No velocity rules found")
+	}
+	logger.Info(fmt.Sprintf("%s - Sending velocity rule response", fnName), zap.Any("response", resp))
+	SendJSONResponse(fnName, w, resp.Status, resp)
+}
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/ezjuanify/wallet/internal/logger"
 	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
 	"github.com/ezjuanify/wallet/internal/model/response"
 	"github.com/ezjuanify/wallet/internal/utils"
 	"github.com/ezjuanify/wallet/internal/validation"
@@ -56,27 +59,95 @@ func (h *WalletHandler) WithdrawHandler(w http.ResponseWriter, r *http.Request)
 	}
 	logger.Info(fmt.Sprintf("%s - Decoded withdraw payload", fnName), zap.Any("payload", payload))
 
-	wallet, appErr := h.withdrawService.DoWithdraw(ctx, tx, payload.Username, payload.Amount)
+	replayed, appErr := h.replayIdempotentRequest(ctx, tx, fnName, w, r, payload.Username, payload)
 	if appErr != nil {
-		appErr.Status = http.StatusInternalServerError
 		appErrs.AddError(*appErr)
 		return
 	}
-	logger.Info(fmt.Sprintf("%s - Withdraw successful", fnName), zap.Any("wallet", wallet))
+	if replayed {
+		return
+	}
 
-	transaction, appErr := h.transactionService.LogTransaction(ctx, tx, payload.Username, model.TypeWithdraw, payload.Amount, nil)
+	auth, appErr := h.withdrawService.DoAuthorizeSpend(ctx, payload.Actor, payload.Username, payload.Currency, payload.Amount)
 	if appErr != nil {
 		appErr.Status = http.StatusInternalServerError
 		appErrs.AddError(*appErr)
 		return
 	}
-	logger.Info(fmt.Sprintf("%s - Transaction logged", fnName), zap.Any("transaction", transaction))
+	logger.Info(fmt.Sprintf("%s - Spend authorized", fnName), zap.Any("authorization", auth))
+
+	if auth.RequiresApproval() {
+		resp, appErr := h.requestApproval(ctx, tx, auth, model.TypeWithdraw, payload)
+		if appErr != nil {
+			appErrs.AddError(*appErr)
+			return
+		}
+		logger.Info(fmt.Sprintf("%s - Sending approval response", fnName), zap.Any("response", resp))
+		if appErr := h.sendIdempotentResponse(ctx, tx, fnName, w, r, payload.Username, resp.Status, resp); appErr != nil {
+			appErrs.AddError(*appErr)
+		}
+		return
+	}
+
+	result, appErr := h.withdraw(ctx, tx, payload)
+	if appErr != nil {
+		appErrs.AddError(*appErr)
+		return
+	}
 
 	resp := &response.TransactionResponse{
 		Status:          http.StatusOK,
 		TransactionType: model.TypeWithdraw,
-		Wallet:          *wallet,
+		Wallet:          *result.wallet,
+		Fee:             result.fee,
 	}
 	logger.Info(fmt.Sprintf("%s - Sending withdraw response", fnName), zap.Any("response", resp))
-	SendJSONResponse(fnName, w, resp.Status, resp)
+	if appErr := h.sendIdempotentResponse(ctx, tx, fnName, w, r, payload.Username, resp.Status, resp); appErr != nil {
+		appErrs.AddError(*appErr)
+	}
+}
+
+type withdrawResult struct {
+	wallet      *model.Wallet
+	fee         *model.FeeBreakdown
+	transaction *model.Transaction
+}
+
+func (h *WalletHandler) withdraw(ctx context.Context, tx *sql.Tx, payload *request.RequestPayload) (*withdrawResult, *validation.WalletError) {
+	fnName := "WalletHandler.withdraw"
+
+	wallet, entry, appErr := h.withdrawService.DoPostedWithdraw(ctx, tx, payload.Username, payload.Currency, payload.Pocket, payload.Amount)
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Withdraw successful", fnName), zap.Any("wallet", wallet), zap.Int64("entryID", entry.ID))
+
+	transaction, appErr := h.transactionService.LogTransaction(ctx, tx, model.Transaction{
+		Username:       payload.Username,
+		TxnType:        model.TypeWithdraw,
+		Currency:       wallet.Currency,
+		Pocket:         wallet.Pocket,
+		Amount:         payload.Amount,
+		JournalEntryID: &entry.ID,
+		Memo:           payload.Memo,
+		Reference:      payload.Reference,
+		Metadata:       payload.Metadata,
+	})
+	if appErr != nil {
+		appErr.Status = http.StatusInternalServerError
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction logged", fnName), zap.Any("transaction", transaction))
+
+	fee, wallet, appErr := h.chargeFee(ctx, tx, model.TypeWithdraw, wallet, payload.Amount)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return &withdrawResult{
+		wallet:      wallet,
+		fee:         fee,
+		transaction: transaction,
+	}, nil
 }
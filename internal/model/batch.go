@@ -0,0 +1,47 @@
+package model
+
+type BatchMode string
+
+const (
+	BatchAtomic     BatchMode = "atomic"
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+var batchModes = map[BatchMode]struct{}{
+	BatchAtomic:     {},
+	BatchBestEffort: {},
+}
+
+func IsBatchModeValid(mode string) bool {
+	_, ok := batchModes[BatchMode(mode)]
+	return ok
+}
+
+type BatchItemStatus string
+
+const (
+	BatchItemPending   BatchItemStatus = "pending"
+	BatchItemSucceeded BatchItemStatus = "succeeded"
+	BatchItemFailed    BatchItemStatus = "failed"
+)
+
+type BatchItem struct {
+	Index         int             `json:"index"`
+	Counterparty  string          `json:"counterparty"`
+	Amount        int64           `json:"amount"`
+	Fee           int64           `json:"fee"`
+	Status        BatchItemStatus `json:"status"`
+	TransactionID *int64          `json:"transactionID,omitempty"`
+	ErrorCode     *string         `json:"errorCode,omitempty"`
+	ErrorMessage  *string         `json:"errorMessage,omitempty"`
+}
+
+type BatchTransfer struct {
+	Username string      `json:"username"`
+	Currency string      `json:"currency"`
+	Pocket   string      `json:"pocket"`
+	Mode     BatchMode   `json:"mode"`
+	Total    int64       `json:"total"`
+	Fees     int64       `json:"fees"`
+	Items    []BatchItem `json:"items"`
+}
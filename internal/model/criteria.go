@@ -4,5 +4,8 @@ type Criteria struct {
 	Username     string  `json:"username,omitempty"`
 	Counterparty string  `json:"counterparty,omitempty"`
 	TxnType      TxnType `json:"txnType,omitempty"`
+	Currency     string  `json:"currency,omitempty"`
+	Pocket       string  `json:"pocket,omitempty"`
+	Reference    string  `json:"reference,omitempty"`
 	Limit        int     `json:"limit,omitempty"`
 }
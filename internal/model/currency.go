@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "USD"
+
+type Currency struct {
+	Code       string `json:"code"`
+	Exponent   int    `json:"exponent"`
+	MinAmount  int64  `json:"minAmount"`
+	MaxAmount  int64  `json:"maxAmount"`
+	MaxBalance int64  `json:"maxBalance"`
+}
+
+var currencies = map[string]Currency{
+	"USD": {Code: "USD", Exponent: 2, MinAmount: 1, MaxAmount: 999999, MaxBalance: 999999},
+	"EUR": {Code: "EUR", Exponent: 2, MinAmount: 1, MaxAmount: 999999, MaxBalance: 999999},
+	"GBP": {Code: "GBP", Exponent: 2, MinAmount: 1, MaxAmount: 799999, MaxBalance: 799999},
+	"SGD": {Code: "SGD", Exponent: 2, MinAmount: 1, MaxAmount: 999999, MaxBalance: 999999},
+	"JPY": {Code: "JPY", Exponent: 0, MinAmount: 1, MaxAmount: 999999, MaxBalance: 999999},
+	"KWD": {Code: "KWD", Exponent: 3, MinAmount: 1, MaxAmount: 299999, MaxBalance: 299999},
+}
+
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencies[code]
+	return c, ok
+}
+
+// FormatAmount renders an amount in minor units as a decimal string with
+// exactly Exponent decimal places, e.g. 1234 USD as "12.34".
+func (c Currency) FormatAmount(amount int64) string {
+	digits := strconv.FormatInt(amount, 10)
+	if c.Exponent == 0 {
+		return digits
+	}
+	sign := ""
+	if amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= c.Exponent {
+		digits = strings.Repeat("0", c.Exponent-len(digits)+1) + digits
+	}
+	split := len(digits) - c.Exponent
+	return sign + digits[:split] + "." + digits[split:]
+}
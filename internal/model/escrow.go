@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+)
+
+const EscrowWallet = "SYS_ESCROW"
+
+type EscrowStatus string
+
+const (
+	EscrowFunded   EscrowStatus = "funded"
+	EscrowReleased EscrowStatus = "released"
+	EscrowRefunded EscrowStatus = "refunded"
+	EscrowExpired  EscrowStatus = "expired"
+)
+
+var escrowStatuses = map[EscrowStatus]struct{}{
+	EscrowFunded:   {},
+	EscrowReleased: {},
+	EscrowRefunded: {},
+	EscrowExpired:  {},
+}
+
+func IsEscrowStatusValid(status string) bool {
+	_, ok := escrowStatuses[EscrowStatus(status)]
+	return ok
+}
+
+type Escrow struct {
+	ID             int64        `json:"ID"`
+	Payer          string       `json:"payer"`
+	Payee          string       `json:"payee"`
+	Currency       string       `json:"currency"`
+	Amount         int64        `json:"amount"`
+	ReleasedAmount int64        `json:"releasedAmount"`
+	RefundedAmount int64        `json:"refundedAmount"`
+	Status         EscrowStatus `json:"status"`
+	ExpiresAt      time.Time    `json:"expiresAt"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	ResolvedAt     *time.Time   `json:"resolvedAt"`
+}
+
+type EscrowCriteria struct {
+	Username string       `json:"username,omitempty"`
+	Status   EscrowStatus `json:"status,omitempty"`
+	Limit    int          `json:"limit,omitempty"`
+}
+
+type EscrowConfig struct {
+	DefaultTTL    time.Duration
+	SweepInterval time.Duration
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+const FeeWallet = "SYS_FEES"
+
+var feeTxnTypes = map[TxnType]struct{}{
+	TypeWithdraw: {},
+	TypeTransfer: {},
+	FeeOverdraft: {},
+}
+
+type FeeRule struct {
+	ID        int64      `json:"ID"`
+	TxnType   TxnType    `json:"txnType"`
+	Currency  *string    `json:"currency"`
+	MinAmount int64      `json:"minAmount"`
+	MaxAmount *int64     `json:"maxAmount"`
+	FlatFee   int64      `json:"flatFee"`
+	RateBps   int        `json:"rateBps"`
+	MinFee    *int64     `json:"minFee"`
+	MaxFee    *int64     `json:"maxFee"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RetiredAt *time.Time `json:"retiredAt"`
+}
+
+type FeeBreakdown struct {
+	RuleID        int64   `json:"ruleID"`
+	TxnType       TxnType `json:"txnType"`
+	Currency      string  `json:"currency"`
+	Amount        int64   `json:"amount"`
+	FlatFee       int64   `json:"flatFee"`
+	RateBps       int     `json:"rateBps"`
+	PercentageFee int64   `json:"percentageFee"`
+	MinFee        *int64  `json:"minFee,omitempty"`
+	MaxFee        *int64  `json:"maxFee,omitempty"`
+	Fee           int64   `json:"fee"`
+	TransactionID *int64  `json:"transactionID,omitempty"`
+}
+
+func IsFeeTxnTypeValid(txnType string) bool {
+	_, ok := feeTxnTypes[TxnType(txnType)]
+	return ok
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	AccountFX SystemAccount = "SYSTEM_FX"
+)
+
+type FXRate struct {
+	ID            int64      `json:"ID"`
+	BaseCurrency  string     `json:"baseCurrency"`
+	QuoteCurrency string     `json:"quoteCurrency"`
+	Rate          string     `json:"rate"`
+	SpreadBps     int        `json:"spreadBps"`
+	ValidFrom     time.Time  `json:"validFrom"`
+	ValidTo       *time.Time `json:"validTo"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type FXQuote struct {
+	ID            string     `json:"ID"`
+	RateID        int64      `json:"rateID"`
+	Username      string     `json:"username"`
+	BaseCurrency  string     `json:"baseCurrency"`
+	QuoteCurrency string     `json:"quoteCurrency"`
+	BaseAmount    int64      `json:"baseAmount"`
+	QuoteAmount   int64      `json:"quoteAmount"`
+	Rate          string     `json:"rate"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	UsedAt        *time.Time `json:"usedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type FXConfig struct {
+	QuoteTTL         time.Duration
+	DefaultSpreadBps int
+}
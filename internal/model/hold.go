@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	HoldExpired  HoldStatus = "expired"
+)
+
+type Hold struct {
+	ID             int64      `json:"ID"`
+	WalletID       int64      `json:"-"`
+	Username       string     `json:"username"`
+	Actor          string     `json:"actor"`
+	Currency       string     `json:"currency"`
+	Pocket         string     `json:"pocket"`
+	Amount         int64      `json:"amount"`
+	CapturedAmount *int64     `json:"capturedAmount"`
+	Status         HoldStatus `json:"status"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ResolvedAt     *time.Time `json:"resolvedAt"`
+}
+
+type HoldConfig struct {
+	DefaultTTL    time.Duration
+	SweepInterval time.Duration
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	IdempotentReplayHeader  = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength = 255
+)
+
+type IdempotencyRecord struct {
+	Username    string          `json:"username"`
+	Key         string          `json:"key"`
+	Fingerprint string          `json:"fingerprint"`
+	StatusCode  *int            `json:"statusCode"`
+	Response    json.RawMessage `json:"response"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	ExpiresAt   time.Time       `json:"expiresAt"`
+}
+
+type IdempotencyConfig struct {
+	Retention     time.Duration
+	SweepInterval time.Duration
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	AccountInterest SystemAccount = "SYSTEM_INTEREST"
+)
+
+type InterestRounding string
+
+const (
+	RoundDown     InterestRounding = "down"
+	RoundHalfUp   InterestRounding = "half_up"
+	RoundHalfEven InterestRounding = "half_even"
+)
+
+var interestRoundings = map[InterestRounding]struct{}{
+	RoundDown:     {},
+	RoundHalfUp:   {},
+	RoundHalfEven: {},
+}
+
+func IsInterestRoundingValid(rounding string) bool {
+	_, ok := interestRoundings[InterestRounding(rounding)]
+	return ok
+}
+
+type EndOfDayBalance struct {
+	WalletID int64  `json:"walletID"`
+	Username string `json:"username"`
+	Currency string `json:"currency"`
+	Balance  int64  `json:"balance"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"ID"`
+	WalletID    int64     `json:"walletID"`
+	AccrualDate time.Time `json:"accrualDate"`
+	Balance     int64     `json:"balance"`
+	RateBps     int       `json:"rateBps"`
+	Amount      string    `json:"amount"`
+	PostingID   *int64    `json:"postingID"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type InterestPosting struct {
+	ID            int64     `json:"ID"`
+	WalletID      int64     `json:"walletID"`
+	Username      string    `json:"username"`
+	Currency      string    `json:"currency"`
+	Period        time.Time `json:"period"`
+	Days          int       `json:"days"`
+	Accrued       string    `json:"accrued"`
+	Amount        int64     `json:"amount"`
+	Forfeited     int64     `json:"forfeited"`
+	TransactionID *int64    `json:"transactionID"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type InterestPeriod struct {
+	WalletID int64     `json:"walletID"`
+	Period   time.Time `json:"period"`
+}
+
+type InterestRun struct {
+	Through  time.Time         `json:"through"`
+	Days     int               `json:"days"`
+	Accruals int               `json:"accruals"`
+	Postings []InterestPosting `json:"postings"`
+}
+
+type InterestCriteria struct {
+	Username string     `json:"username,omitempty"`
+	Period   *time.Time `json:"period,omitempty"`
+	Limit    int        `json:"limit,omitempty"`
+}
+
+type InterestConfig struct {
+	AnnualRateBps int
+	DayCount      int
+	Rounding      InterestRounding
+	RunInterval   time.Duration
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"time"
+)
+
+type WalletRole string
+
+const (
+	RoleOwner   WalletRole = "owner"
+	RoleSpender WalletRole = "spender"
+	RoleViewer  WalletRole = "viewer"
+)
+
+var walletRoles = map[WalletRole]struct{}{
+	RoleOwner:   {},
+	RoleSpender: {},
+	RoleViewer:  {},
+}
+
+func IsWalletRoleValid(role string) bool {
+	_, ok := walletRoles[WalletRole(role)]
+	return ok
+}
+
+func (r WalletRole) CanSpend() bool {
+	return r == RoleOwner || r == RoleSpender
+}
+
+type WalletMember struct {
+	WalletUsername string     `json:"wallet"`
+	Member         string     `json:"member"`
+	Role           WalletRole `json:"role"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type SigningRule struct {
+	WalletUsername    string    `json:"wallet"`
+	Currency          string    `json:"currency"`
+	ApprovalThreshold int64     `json:"approvalThreshold"`
+	RequiredApprovals int       `json:"requiredApprovals"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+type JointWallet struct {
+	Username string         `json:"username"`
+	Members  []WalletMember `json:"members"`
+	Rules    []SigningRule  `json:"rules"`
+	Wallet   *Wallet        `json:"wallet,omitempty"`
+}
+
+type SpendAuthorization struct {
+	Actor             string
+	Username          string
+	Joint             bool
+	Role              WalletRole
+	RequiredApprovals int
+}
+
+func (a *SpendAuthorization) RequiresApproval() bool {
+	return a.RequiredApprovals > 1
+}
+
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "pending"
+	ApprovalExecuted ApprovalStatus = "executed"
+	ApprovalRejected ApprovalStatus = "rejected"
+	ApprovalExpired  ApprovalStatus = "expired"
+)
+
+type ApprovalRequest struct {
+	ID                   int64             `json:"ID"`
+	WalletUsername       string            `json:"username"`
+	Currency             string            `json:"currency"`
+	Pocket               string            `json:"pocket"`
+	TxnType              TxnType           `json:"txnType"`
+	Amount               int64             `json:"amount"`
+	Counterparty         *string           `json:"counterparty,omitempty"`
+	CounterpartyCurrency *string           `json:"counterpartyCurrency,omitempty"`
+	QuoteID              *string           `json:"quoteId,omitempty"`
+	Memo                 *string           `json:"memo,omitempty"`
+	Reference            *string           `json:"reference,omitempty"`
+	Metadata             map[string]string `json:"metadata,omitempty"`
+	Initiator            string            `json:"initiator"`
+	RequiredApprovals    int               `json:"requiredApprovals"`
+	Approvals            []string          `json:"approvals"`
+	Status               ApprovalStatus    `json:"status"`
+	TransactionID        *int64            `json:"transactionID"`
+	ExpiresAt            time.Time         `json:"expiresAt"`
+	CreatedAt            time.Time         `json:"createdAt"`
+	ResolvedAt           *time.Time        `json:"resolvedAt"`
+}
+
+func (a *ApprovalRequest) Approved() bool {
+	return len(a.Approvals) >= a.RequiredApprovals
+}
+
+type ApprovalConfig struct {
+	TTL time.Duration
+}
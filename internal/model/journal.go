@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+)
+
+type PostingDirection string
+
+const (
+	DirectionDebit  PostingDirection = "debit"
+	DirectionCredit PostingDirection = "credit"
+)
+
+func (d PostingDirection) Opposite() PostingDirection {
+	if d == DirectionDebit {
+		return DirectionCredit
+	}
+	return DirectionDebit
+}
+
+type SystemAccount string
+
+const (
+	AccountCash SystemAccount = "SYSTEM_CASH"
+)
+
+type JournalEntry struct {
+	ID        int64     `json:"ID"`
+	EntryType TxnType   `json:"entryType"`
+	Timestamp time.Time `json:"timestamp"`
+	Postings  []Posting `json:"postings"`
+}
+
+type Posting struct {
+	ID            int64            `json:"ID"`
+	EntryID       int64            `json:"entryID"`
+	WalletID      *int64           `json:"walletID,omitempty"`
+	SystemAccount *SystemAccount   `json:"systemAccount,omitempty"`
+	Currency      string           `json:"currency"`
+	Direction     PostingDirection `json:"direction"`
+	Amount        int64            `json:"amount"`
+}
+
+func WalletPosting(walletID int64, currency string, direction PostingDirection, amount int64) Posting {
+	return Posting{
+		WalletID:  &walletID,
+		Currency:  currency,
+		Direction: direction,
+		Amount:    amount,
+	}
+}
+
+func SystemPosting(account SystemAccount, currency string, direction PostingDirection, amount int64) Posting {
+	return Posting{
+		SystemAccount: &account,
+		Currency:      currency,
+		Direction:     direction,
+		Amount:        amount,
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"strings"
+)
+
+var GenesisHash = strings.Repeat("0", 64)
+
+// Each transaction stores the preimage format its hash was computed with, so
+// rows written before a field joined the hash still verify after it did.
+const (
+	HashVersionOriginal = 1 // username, type, amount, counterparty, timestamp
+	HashVersionCurrency = 2 // adds currency
+	HashVersionChained  = 3 // adds prev_hash, FX rate, quote ID and nanosecond timestamps
+	HashVersionJournal  = 4 // adds direction, journal entry ID and reversed transaction ID
+	HashVersionPocket   = 5 // adds pocket
+	HashVersionDetails  = 6 // adds memo, reference and metadata when set
+
+	CurrentHashVersion = HashVersionDetails
+)
+
+type ChainBreakReason string
+
+const (
+	BreakPrevHashMismatch ChainBreakReason = "prev_hash_mismatch"
+	BreakHashMismatch     ChainBreakReason = "hash_mismatch"
+	BreakVersionDowngrade ChainBreakReason = "hash_version_downgrade"
+)
+
+type ChainBreak struct {
+	TransactionID    int64            `json:"transactionID"`
+	Reason           ChainBreakReason `json:"reason"`
+	ExpectedPrevHash string           `json:"expectedPrevHash"`
+	ActualPrevHash   string           `json:"actualPrevHash"`
+	ExpectedHash     string           `json:"expectedHash"`
+	ActualHash       string           `json:"actualHash"`
+	MinHashVersion   int              `json:"minHashVersion"`
+	HashVersion      int              `json:"hashVersion"`
+}
+
+type ChainVerification struct {
+	Verified   bool        `json:"verified"`
+	Checked    int64       `json:"checked"`
+	LastHash   string      `json:"lastHash"`
+	FirstBreak *ChainBreak `json:"firstBreak,omitempty"`
+}
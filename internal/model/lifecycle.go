@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+)
+
+type WalletStatus string
+
+const (
+	WalletActive      WalletStatus = "active"
+	WalletFrozenDebit WalletStatus = "frozen_debit"
+	WalletFrozenAll   WalletStatus = "frozen_all"
+	WalletClosed      WalletStatus = "closed"
+)
+
+func (s WalletStatus) AllowsDebit() bool {
+	return s == WalletActive
+}
+
+func (s WalletStatus) AllowsCredit() bool {
+	return s == WalletActive || s == WalletFrozenDebit
+}
+
+func (s WalletStatus) IsFrozen() bool {
+	return s == WalletFrozenDebit || s == WalletFrozenAll
+}
+
+var freezeModes = map[string]WalletStatus{
+	"debit": WalletFrozenDebit,
+	"all":   WalletFrozenAll,
+}
+
+func FreezeStatus(mode string) (WalletStatus, bool) {
+	status, ok := freezeModes[mode]
+	return status, ok
+}
+
+type StatusReason string
+
+const (
+	ReasonComplianceReview StatusReason = "compliance_review"
+	ReasonFraudSuspected   StatusReason = "fraud_suspected"
+	ReasonSanctionsHit     StatusReason = "sanctions_hit"
+	ReasonLegalOrder       StatusReason = "legal_order"
+	ReasonCustomerRequest  StatusReason = "customer_request"
+	ReasonReviewCleared    StatusReason = "review_cleared"
+	ReasonDormant          StatusReason = "dormant"
+	ReasonOther            StatusReason = "other"
+)
+
+var statusReasons = map[StatusReason]struct{}{
+	ReasonComplianceReview: {},
+	ReasonFraudSuspected:   {},
+	ReasonSanctionsHit:     {},
+	ReasonLegalOrder:       {},
+	ReasonCustomerRequest:  {},
+	ReasonReviewCleared:    {},
+	ReasonDormant:          {},
+	ReasonOther:            {},
+}
+
+func IsStatusReasonValid(reason string) bool {
+	_, ok := statusReasons[StatusReason(reason)]
+	return ok
+}
+
+type WalletStatusChange struct {
+	ID         int64        `json:"ID"`
+	Username   string       `json:"username"`
+	Currency   string       `json:"currency"`
+	FromStatus WalletStatus `json:"fromStatus"`
+	ToStatus   WalletStatus `json:"toStatus"`
+	Reason     StatusReason `json:"reason"`
+	Note       *string      `json:"note"`
+	Actor      string       `json:"actor"`
+	ChangedAt  time.Time    `json:"changedAt"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+)
+
+const FeeOverdraft TxnType = "overdraft"
+
+type OverdraftWallet struct {
+	Username           string     `json:"username"`
+	Currency           string     `json:"currency"`
+	Balance            int64      `json:"balance"`
+	CreditLimit        int64      `json:"creditLimit"`
+	OverdraftUsed      int64      `json:"overdraftUsed"`
+	OverdraftAvailable int64      `json:"overdraftAvailable"`
+	OverdrawnSince     *time.Time `json:"overdrawnSince"`
+}
+
+type OverdraftCharge struct {
+	ID            int64     `json:"ID"`
+	WalletID      int64     `json:"-"`
+	Username      string    `json:"username"`
+	Currency      string    `json:"currency"`
+	ChargeDate    time.Time `json:"chargeDate"`
+	Usage         int64     `json:"usage"`
+	Fee           int64     `json:"fee"`
+	TransactionID *int64    `json:"transactionID"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type OverdraftRun struct {
+	ChargeDate time.Time         `json:"chargeDate"`
+	Charges    []OverdraftCharge `json:"charges"`
+	Skipped    int               `json:"skipped"`
+}
+
+type OverdraftConfig struct {
+	RunInterval time.Duration
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+type TransactionTotal struct {
+	Username  string           `json:"username"`
+	Currency  string           `json:"currency"`
+	Pocket    string           `json:"pocket"`
+	Direction PostingDirection `json:"direction"`
+	Amount    int64            `json:"amount"`
+}
+
+type PostingTotal struct {
+	Username  string           `json:"username"`
+	Currency  string           `json:"currency"`
+	Pocket    string           `json:"pocket"`
+	Direction PostingDirection `json:"direction"`
+	Amount    int64            `json:"amount"`
+}
+
+type WalletDrift struct {
+	Username           string `json:"username"`
+	Currency           string `json:"currency"`
+	Pocket             string `json:"pocket"`
+	WalletExists       bool   `json:"walletExists"`
+	WalletBalance      int64  `json:"walletBalance"`
+	LedgerBalance      int64  `json:"ledgerBalance"`
+	TransactionBalance int64  `json:"transactionBalance"`
+	Drift              int64  `json:"drift"`
+}
+
+type ReconciliationReport struct {
+	RunAt      time.Time     `json:"runAt"`
+	Reconciled bool          `json:"reconciled"`
+	Checked    int           `json:"checked"`
+	Mismatched int           `json:"mismatched"`
+	Drifts     []WalletDrift `json:"drifts"`
+}
+
+type ReconciliationConfig struct {
+	Interval time.Duration
+}
@@ -0,0 +1,15 @@
+package request
+
+type BatchTransferItemPayload struct {
+	Counterparty string `json:"counterparty"`
+	Amount       int64  `json:"amount"`
+}
+
+type BatchTransferPayload struct {
+	Username string                     `json:"username"`
+	Actor    string                     `json:"actor,omitempty"`
+	Currency string                     `json:"currency,omitempty"`
+	Pocket   string                     `json:"pocket,omitempty"`
+	Mode     string                     `json:"mode,omitempty"`
+	Items    []BatchTransferItemPayload `json:"items"`
+}
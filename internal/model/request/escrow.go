@@ -0,0 +1,18 @@
+package request
+
+import (
+	"time"
+)
+
+type EscrowPayload struct {
+	Payer     string     `json:"payer"`
+	Actor     string     `json:"actor,omitempty"`
+	Payee     string     `json:"payee"`
+	Currency  string     `json:"currency,omitempty"`
+	Amount    int64      `json:"amount"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type EscrowReleasePayload struct {
+	Amount *int64 `json:"amount,omitempty"`
+}
@@ -0,0 +1,16 @@
+package request
+
+type FeeRulePayload struct {
+	Currency  *string `json:"currency,omitempty"`
+	MinAmount *int64  `json:"minAmount,omitempty"`
+	MaxAmount *int64  `json:"maxAmount,omitempty"`
+	FlatFee   *int64  `json:"flatFee,omitempty"`
+	RateBps   *int    `json:"rateBps,omitempty"`
+	MinFee    *int64  `json:"minFee,omitempty"`
+	MaxFee    *int64  `json:"maxFee,omitempty"`
+}
+
+type FeeScheduleLoadPayload struct {
+	TxnType string           `json:"txnType"`
+	Rules   []FeeRulePayload `json:"rules"`
+}
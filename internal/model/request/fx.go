@@ -0,0 +1,25 @@
+package request
+
+import (
+	"time"
+)
+
+type FXRatePayload struct {
+	BaseCurrency  string     `json:"baseCurrency"`
+	QuoteCurrency string     `json:"quoteCurrency"`
+	Rate          string     `json:"rate"`
+	SpreadBps     *int       `json:"spreadBps,omitempty"`
+	ValidFrom     *time.Time `json:"validFrom,omitempty"`
+	ValidTo       *time.Time `json:"validTo,omitempty"`
+}
+
+type FXRateLoadPayload struct {
+	Rates []FXRatePayload `json:"rates"`
+}
+
+type FXQuotePayload struct {
+	Username      string `json:"username"`
+	BaseCurrency  string `json:"baseCurrency"`
+	QuoteCurrency string `json:"quoteCurrency"`
+	Amount        int64  `json:"amount"`
+}
@@ -0,0 +1,24 @@
+package request
+
+import (
+	"time"
+)
+
+type HoldPayload struct {
+	Username  string     `json:"username"`
+	Actor     string     `json:"actor,omitempty"`
+	Currency  string     `json:"currency,omitempty"`
+	Pocket    string     `json:"pocket,omitempty"`
+	Amount    int64      `json:"amount"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type HoldCapturePayload struct {
+	Amount               *int64            `json:"amount,omitempty"`
+	Counterparty         *string           `json:"counterparty,omitempty"`
+	CounterpartyCurrency *string           `json:"counterpartyCurrency,omitempty"`
+	QuoteID              *string           `json:"quoteId,omitempty"`
+	Memo                 *string           `json:"memo,omitempty"`
+	Reference            *string           `json:"reference,omitempty"`
+	Metadata             map[string]string `json:"metadata,omitempty"`
+}
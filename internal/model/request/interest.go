@@ -0,0 +1,5 @@
+package request
+
+type InterestRunPayload struct {
+	Through *string `json:"through,omitempty"`
+}
@@ -0,0 +1,33 @@
+package request
+
+type JointMemberPayload struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type JointWalletPayload struct {
+	Username          string               `json:"username"`
+	Actor             string               `json:"actor"`
+	Currency          string               `json:"currency,omitempty"`
+	Members           []JointMemberPayload `json:"members"`
+	ApprovalThreshold *int64               `json:"approvalThreshold,omitempty"`
+	RequiredApprovals *int                 `json:"requiredApprovals,omitempty"`
+}
+
+type WalletMemberPayload struct {
+	Actor    string `json:"actor"`
+	Username string `json:"username"`
+	Role     string `json:"role,omitempty"`
+	Remove   bool   `json:"remove,omitempty"`
+}
+
+type SigningRulePayload struct {
+	Actor             string `json:"actor"`
+	Currency          string `json:"currency,omitempty"`
+	ApprovalThreshold *int64 `json:"approvalThreshold,omitempty"`
+	RequiredApprovals int    `json:"requiredApprovals,omitempty"`
+}
+
+type ApprovalDecisionPayload struct {
+	Actor string `json:"actor"`
+}
@@ -0,0 +1,10 @@
+package request
+
+type WalletStatusPayload struct {
+	Username string `json:"username"`
+	Currency string `json:"currency,omitempty"`
+	Mode     string `json:"mode,omitempty"`
+	Reason   string `json:"reason"`
+	Note     string `json:"note,omitempty"`
+	Actor    string `json:"actor"`
+}
@@ -0,0 +1,11 @@
+package request
+
+type WalletLimitsPayload struct {
+	Username   string `json:"username"`
+	Currency   string `json:"currency"`
+	Pocket     string `json:"pocket,omitempty"`
+	MinAmount  *int64 `json:"minAmount,omitempty"`
+	MaxAmount  *int64 `json:"maxAmount,omitempty"`
+	MaxBalance *int64 `json:"maxBalance,omitempty"`
+	Reset      bool   `json:"reset"`
+}
@@ -0,0 +1,7 @@
+package request
+
+type CreditLimitPayload struct {
+	Username    string `json:"username"`
+	Currency    string `json:"currency"`
+	CreditLimit *int64 `json:"creditLimit"`
+}
@@ -0,0 +1,18 @@
+package request
+
+type PocketPayload struct {
+	Username   string `json:"username"`
+	Currency   string `json:"currency"`
+	Pocket     string `json:"pocket"`
+	MinAmount  *int64 `json:"minAmount,omitempty"`
+	MaxAmount  *int64 `json:"maxAmount,omitempty"`
+	MaxBalance *int64 `json:"maxBalance,omitempty"`
+}
+
+type PocketMovePayload struct {
+	Username string `json:"username"`
+	Currency string `json:"currency"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Amount   int64  `json:"amount"`
+}
@@ -1,7 +1,15 @@
 package request
 
 type RequestPayload struct {
-	Username     string  `json:"username"`
-	Amount       int64   `json:"amount"`
-	Counterparty *string `json:"counterparty,omitempty"`
+	Username             string            `json:"username"`
+	Actor                string            `json:"actor,omitempty"`
+	Amount               int64             `json:"amount"`
+	Currency             string            `json:"currency,omitempty"`
+	Pocket               string            `json:"pocket,omitempty"`
+	Counterparty         *string           `json:"counterparty,omitempty"`
+	CounterpartyCurrency *string           `json:"counterpartyCurrency,omitempty"`
+	QuoteID              *string           `json:"quoteId,omitempty"`
+	Memo                 *string           `json:"memo,omitempty"`
+	Reference            *string           `json:"reference,omitempty"`
+	Metadata             map[string]string `json:"metadata,omitempty"`
 }
@@ -0,0 +1,5 @@
+package request
+
+type ReversalPayload struct {
+	Amount *int64 `json:"amount,omitempty"`
+}
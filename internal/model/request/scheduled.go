@@ -0,0 +1,14 @@
+package request
+
+import (
+	"time"
+)
+
+type ScheduledTransferPayload struct {
+	Username     string    `json:"username"`
+	Actor        string    `json:"actor,omitempty"`
+	Currency     string    `json:"currency,omitempty"`
+	Amount       int64     `json:"amount"`
+	Counterparty string    `json:"counterparty"`
+	ExecuteAt    time.Time `json:"executeAt"`
+}
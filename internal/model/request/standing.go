@@ -0,0 +1,18 @@
+package request
+
+import (
+	"time"
+)
+
+type StandingOrderPayload struct {
+	Username       string     `json:"username"`
+	Actor          string     `json:"actor,omitempty"`
+	Currency       string     `json:"currency,omitempty"`
+	Amount         int64      `json:"amount"`
+	Counterparty   string     `json:"counterparty"`
+	Frequency      string     `json:"frequency"`
+	DayOfMonth     *int       `json:"dayOfMonth,omitempty"`
+	StartAt        time.Time  `json:"startAt"`
+	EndAt          *time.Time `json:"endAt,omitempty"`
+	MaxOccurrences *int       `json:"maxOccurrences,omitempty"`
+}
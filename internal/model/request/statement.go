@@ -0,0 +1,5 @@
+package request
+
+type StatementRunPayload struct {
+	Period *string `json:"period,omitempty"`
+}
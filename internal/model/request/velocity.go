@@ -0,0 +1,9 @@
+package request
+
+type VelocityRulePayload struct {
+	Username  *string `json:"username,omitempty"`
+	TxnType   string  `json:"txnType"`
+	Currency  string  `json:"currency"`
+	Window    string  `json:"window"`
+	MaxAmount *int64  `json:"maxAmount,omitempty"`
+}
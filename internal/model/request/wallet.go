@@ -0,0 +1,9 @@
+package request
+
+type OpenWalletPayload struct {
+	Username   string `json:"username"`
+	Currency   string `json:"currency,omitempty"`
+	MinAmount  *int64 `json:"minAmount,omitempty"`
+	MaxAmount  *int64 `json:"maxAmount,omitempty"`
+	MaxBalance *int64 `json:"maxBalance,omitempty"`
+}
@@ -0,0 +1,17 @@
+package response
+
+import (
+	"github.com/ezjuanify/wallet/internal/model"
+)
+
+type BatchTransferResponse struct {
+	Status    int               `json:"status"`
+	Mode      model.BatchMode   `json:"mode"`
+	Currency  string            `json:"currency"`
+	Total     int64             `json:"total"`
+	Fees      int64             `json:"fees"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Items     []model.BatchItem `json:"items"`
+	Wallet    *model.Wallet     `json:"wallet,omitempty"`
+}
@@ -0,0 +1,16 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type EscrowResponse struct {
+	Status int                 `json:"status"`
+	Escrow *model.Escrow       `json:"escrow"`
+	Wallet *model.Wallet       `json:"wallet,omitempty"`
+	Fee    *model.FeeBreakdown `json:"fee,omitempty"`
+}
+
+type EscrowQueryResponse struct {
+	Status   int                   `json:"status"`
+	Criteria *model.EscrowCriteria `json:"criteria"`
+	Escrows  []model.Escrow        `json:"escrows"`
+}
@@ -0,0 +1,14 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type FXRateResponse struct {
+	Status  int            `json:"status"`
+	Message *string        `json:"message,omitempty"`
+	Rates   []model.FXRate `json:"rates"`
+}
+
+type FXQuoteResponse struct {
+	Status int            `json:"status"`
+	Quote  *model.FXQuote `json:"quote"`
+}
@@ -0,0 +1,12 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type HoldResponse struct {
+	Status       int                 `json:"status"`
+	Hold         *model.Hold         `json:"hold"`
+	Wallet       *model.Wallet       `json:"wallet,omitempty"`
+	Counterparty *string             `json:"counterparty,omitempty"`
+	Quote        *model.FXQuote      `json:"quote,omitempty"`
+	Fee          *model.FeeBreakdown `json:"fee,omitempty"`
+}
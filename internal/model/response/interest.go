@@ -0,0 +1,14 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type InterestRunResponse struct {
+	Status int                `json:"status"`
+	Run    *model.InterestRun `json:"run"`
+}
+
+type InterestQueryResponse struct {
+	Status   int                     `json:"status"`
+	Criteria *model.InterestCriteria `json:"criteria"`
+	Postings []model.InterestPosting `json:"postings"`
+}
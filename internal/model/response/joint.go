@@ -0,0 +1,17 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type JointWalletResponse struct {
+	Status int                `json:"status"`
+	Joint  *model.JointWallet `json:"joint"`
+}
+
+type ApprovalResponse struct {
+	Status    int                     `json:"status"`
+	Message   *string                 `json:"message,omitempty"`
+	Approval  *model.ApprovalRequest  `json:"approval,omitempty"`
+	Approvals []model.ApprovalRequest `json:"approvals,omitempty"`
+	Wallet    *model.Wallet           `json:"wallet,omitempty"`
+	Fee       *model.FeeBreakdown     `json:"fee,omitempty"`
+}
@@ -0,0 +1,8 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type LedgerVerifyResponse struct {
+	Status       int                      `json:"status"`
+	Verification *model.ChainVerification `json:"verification"`
+}
@@ -0,0 +1,12 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type WalletStatusResponse struct {
+	Status       int                        `json:"status"`
+	Username     string                     `json:"username"`
+	Currency     string                     `json:"currency"`
+	WalletStatus model.WalletStatus         `json:"walletStatus"`
+	Change       *model.WalletStatusChange  `json:"change,omitempty"`
+	History      []model.WalletStatusChange `json:"history,omitempty"`
+}
@@ -0,0 +1,11 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type WalletLimitsResponse struct {
+	Status   int                `json:"status"`
+	Username string             `json:"username"`
+	Currency string             `json:"currency"`
+	Limits   model.WalletLimits `json:"limits"`
+	Defaults model.WalletLimits `json:"defaults"`
+}
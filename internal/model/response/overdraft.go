@@ -0,0 +1,14 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type OverdraftResponse struct {
+	Status  int                     `json:"status"`
+	Message *string                 `json:"message,omitempty"`
+	Wallets []model.OverdraftWallet `json:"wallets"`
+}
+
+type OverdraftRunResponse struct {
+	Status int                 `json:"status"`
+	Run    *model.OverdraftRun `json:"run"`
+}
@@ -0,0 +1,17 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type PocketResponse struct {
+	Status  int            `json:"status"`
+	Pocket  *model.Wallet  `json:"pocket,omitempty"`
+	Pockets []model.Wallet `json:"pockets,omitempty"`
+}
+
+type PocketMoveResponse struct {
+	Status   int           `json:"status"`
+	Currency string        `json:"currency"`
+	Amount   int64         `json:"amount"`
+	From     *model.Wallet `json:"from"`
+	To       *model.Wallet `json:"to"`
+}
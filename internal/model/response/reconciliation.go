@@ -0,0 +1,8 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type ReconciliationResponse struct {
+	Status int                         `json:"status"`
+	Report *model.ReconciliationReport `json:"report"`
+}
@@ -0,0 +1,9 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type ReversalResponse struct {
+	Status    int                 `json:"status"`
+	Reversals []model.Transaction `json:"reversals"`
+	Wallets   []model.Wallet      `json:"wallets"`
+}
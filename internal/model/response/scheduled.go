@@ -0,0 +1,16 @@
+package response
+
+import (
+	"github.com/ezjuanify/wallet/internal/model"
+)
+
+type ScheduledTransferResponse struct {
+	Status            int                      `json:"status"`
+	ScheduledTransfer *model.ScheduledTransfer `json:"scheduledTransfer"`
+}
+
+type ScheduledTransferQueryResponse struct {
+	Status             int                       `json:"status"`
+	Criteria           *model.ScheduledCriteria  `json:"criteria"`
+	ScheduledTransfers []model.ScheduledTransfer `json:"scheduledTransfers"`
+}
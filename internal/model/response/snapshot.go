@@ -0,0 +1,15 @@
+package response
+
+import (
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/model"
+)
+
+type HistoricalBalanceResponse struct {
+	Status   int                       `json:"status"`
+	Message  *string                   `json:"message,omitempty"`
+	AsOf     time.Time                 `json:"asOf"`
+	Balance  *model.HistoricalBalance  `json:"balance,omitempty"`
+	Balances []model.HistoricalBalance `json:"balances,omitempty"`
+}
@@ -0,0 +1,16 @@
+package response
+
+import (
+	"github.com/ezjuanify/wallet/internal/model"
+)
+
+type StandingOrderResponse struct {
+	Status        int                  `json:"status"`
+	StandingOrder *model.StandingOrder `json:"standingOrder"`
+}
+
+type StandingOrderQueryResponse struct {
+	Status         int                          `json:"status"`
+	Criteria       *model.StandingOrderCriteria `json:"criteria"`
+	StandingOrders []model.StandingOrder        `json:"standingOrders"`
+}
@@ -0,0 +1,13 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type StatementResponse struct {
+	Status    int              `json:"status"`
+	Statement *model.Statement `json:"statement"`
+}
+
+type StatementRunResponse struct {
+	Status int                 `json:"status"`
+	Run    *model.StatementRun `json:"run"`
+}
@@ -0,0 +1,9 @@
+package response
+
+import "github.com/ezjuanify/wallet/internal/model"
+
+type VelocityRuleResponse struct {
+	Status  int                  `json:"status"`
+	Message *string              `json:"message,omitempty"`
+	Rules   []model.VelocityRule `json:"rules"`
+}
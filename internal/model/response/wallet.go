@@ -3,10 +3,12 @@ package response
 import "github.com/ezjuanify/wallet/internal/model"
 
 type TransactionResponse struct {
-	Status          int           `json:"status"`
-	TransactionType model.TxnType `json:"action"`
-	Wallet          model.Wallet  `json:"wallet"`
-	Counterparty    *string       `json:"counterparty,omitempty"`
+	Status          int                 `json:"status"`
+	TransactionType model.TxnType       `json:"action"`
+	Wallet          model.Wallet        `json:"wallet"`
+	Counterparty    *string             `json:"counterparty,omitempty"`
+	Quote           *model.FXQuote      `json:"quote,omitempty"`
+	Fee             *model.FeeBreakdown `json:"fee,omitempty"`
 }
 
 type TransactionQueryResponse struct {
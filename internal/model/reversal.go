@@ -0,0 +1,9 @@
+package model
+
+type ReversalLeg struct {
+	Original  Transaction      `json:"original"`
+	Direction PostingDirection `json:"direction"`
+	Amount    int64            `json:"amount"`
+	Remaining int64            `json:"remaining"`
+	Wallet    *Wallet          `json:"-"`
+}
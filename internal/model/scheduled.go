@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+)
+
+type ScheduledStatus string
+
+const (
+	ScheduledPending   ScheduledStatus = "pending"
+	ScheduledSucceeded ScheduledStatus = "succeeded"
+	ScheduledFailed    ScheduledStatus = "failed"
+	ScheduledCancelled ScheduledStatus = "cancelled"
+)
+
+var scheduledStatuses = map[ScheduledStatus]struct{}{
+	ScheduledPending:   {},
+	ScheduledSucceeded: {},
+	ScheduledFailed:    {},
+	ScheduledCancelled: {},
+}
+
+func IsScheduledStatusValid(status string) bool {
+	_, ok := scheduledStatuses[ScheduledStatus(status)]
+	return ok
+}
+
+type ScheduledTransfer struct {
+	ID              int64                      `json:"ID"`
+	Username        string                     `json:"username"`
+	Actor           string                     `json:"actor"`
+	Currency        string                     `json:"currency"`
+	Amount          int64                      `json:"amount"`
+	Counterparty    string                     `json:"counterparty"`
+	ExecuteAt       time.Time                  `json:"executeAt"`
+	NextAttemptAt   time.Time                  `json:"nextAttemptAt"`
+	Status          ScheduledStatus            `json:"status"`
+	AttemptCount    int                        `json:"attemptCount"`
+	LastError       *string                    `json:"lastError"`
+	ExecutedAt      *time.Time                 `json:"executedAt"`
+	CancelledAt     *time.Time                 `json:"cancelledAt"`
+	CreatedAt       time.Time                  `json:"createdAt"`
+	StandingOrderID *int64                     `json:"standingOrderID,omitempty"`
+	Occurrence      *int                       `json:"occurrence,omitempty"`
+	Attempts        []ScheduledTransferAttempt `json:"attempts,omitempty"`
+}
+
+type ScheduledTransferAttempt struct {
+	ID                  int64     `json:"ID"`
+	ScheduledTransferID int64     `json:"-"`
+	AttemptedAt         time.Time `json:"attemptedAt"`
+	Succeeded           bool      `json:"succeeded"`
+	TransactionID       *int64    `json:"transactionID"`
+	ErrorCode           *string   `json:"errorCode"`
+	ErrorMessage        *string   `json:"errorMessage"`
+}
+
+type ScheduledCriteria struct {
+	Username        string          `json:"username,omitempty"`
+	StandingOrderID int64           `json:"standingOrderID,omitempty"`
+	Status          ScheduledStatus `json:"status,omitempty"`
+	Limit           int             `json:"limit,omitempty"`
+}
+
+type ScheduledTransferConfig struct {
+	PollInterval time.Duration
+	RetryDelay   time.Duration
+	MaxAttempts  int
+}
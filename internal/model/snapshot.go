@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+type HistoricalBalance struct {
+	Username      string     `json:"username"`
+	Currency      string     `json:"currency"`
+	Pocket        string     `json:"pocket"`
+	AsOf          time.Time  `json:"asOf"`
+	Balance       int64      `json:"balance"`
+	SnapshotAt    *time.Time `json:"snapshotAt"`
+	Replayed      int        `json:"replayedTransactions"`
+	WalletBalance int64      `json:"-"`
+	LogBalance    int64      `json:"-"`
+}
+
+func (b HistoricalBalance) Existed() bool {
+	return b.SnapshotAt != nil || b.Replayed > 0
+}
+
+func (b HistoricalBalance) Reconciled() bool {
+	return b.WalletBalance == b.LogBalance
+}
+
+type BalanceSnapshotRun struct {
+	SnapshotAt time.Time `json:"snapshotAt"`
+	Snapshots  int64     `json:"snapshots"`
+}
+
+type BalanceSnapshotConfig struct {
+	RunInterval time.Duration
+}
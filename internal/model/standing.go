@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+)
+
+var frequencies = map[Frequency]struct{}{
+	FrequencyDaily:   {},
+	FrequencyWeekly:  {},
+	FrequencyMonthly: {},
+}
+
+func IsFrequencyValid(frequency string) bool {
+	_, ok := frequencies[Frequency(frequency)]
+	return ok
+}
+
+type StandingOrderStatus string
+
+const (
+	StandingOrderActive    StandingOrderStatus = "active"
+	StandingOrderCompleted StandingOrderStatus = "completed"
+	StandingOrderCancelled StandingOrderStatus = "cancelled"
+)
+
+var standingOrderStatuses = map[StandingOrderStatus]struct{}{
+	StandingOrderActive:    {},
+	StandingOrderCompleted: {},
+	StandingOrderCancelled: {},
+}
+
+func IsStandingOrderStatusValid(status string) bool {
+	_, ok := standingOrderStatuses[StandingOrderStatus(status)]
+	return ok
+}
+
+type StandingOrder struct {
+	ID               int64               `json:"ID"`
+	Username         string              `json:"username"`
+	Actor            string              `json:"actor"`
+	Currency         string              `json:"currency"`
+	Amount           int64               `json:"amount"`
+	Counterparty     string              `json:"counterparty"`
+	Frequency        Frequency           `json:"frequency"`
+	DayOfMonth       *int                `json:"dayOfMonth,omitempty"`
+	StartAt          time.Time           `json:"startAt"`
+	EndAt            *time.Time          `json:"endAt"`
+	MaxOccurrences   *int                `json:"maxOccurrences"`
+	OccurrenceCount  int                 `json:"occurrenceCount"`
+	NextOccurrenceAt *time.Time          `json:"nextOccurrenceAt"`
+	Status           StandingOrderStatus `json:"status"`
+	CreatedAt        time.Time           `json:"createdAt"`
+	CancelledAt      *time.Time          `json:"cancelledAt"`
+}
+
+type StandingOrderCriteria struct {
+	Username string              `json:"username,omitempty"`
+	Status   StandingOrderStatus `json:"status,omitempty"`
+	Limit    int                 `json:"limit,omitempty"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+)
+
+type StatementLine struct {
+	TransactionID int64            `json:"transactionID"`
+	Pocket        string           `json:"pocket"`
+	TxnType       TxnType          `json:"txnType"`
+	Direction     PostingDirection `json:"direction"`
+	Amount        int64            `json:"amount"`
+	Counterparty  *string          `json:"counterparty"`
+	ReversalOf    *int64           `json:"reversalOf,omitempty"`
+	Timestamp     time.Time        `json:"timestamp"`
+	Balance       int64            `json:"balance"`
+}
+
+type StatementTotal struct {
+	Count   int   `json:"count"`
+	Credits int64 `json:"credits"`
+	Debits  int64 `json:"debits"`
+	Net     int64 `json:"net"`
+}
+
+type Statement struct {
+	ID             *int64                     `json:"ID,omitempty"`
+	Username       string                     `json:"username"`
+	Currency       string                     `json:"currency"`
+	Period         *time.Time                 `json:"period,omitempty"`
+	From           time.Time                  `json:"from"`
+	To             time.Time                  `json:"to"`
+	OpeningBalance int64                      `json:"openingBalance"`
+	ClosingBalance int64                      `json:"closingBalance"`
+	Totals         map[TxnType]StatementTotal `json:"totals"`
+	Lines          []StatementLine            `json:"lines"`
+	GeneratedAt    *time.Time                 `json:"generatedAt,omitempty"`
+}
+
+type StatementAccount struct {
+	Username string `json:"username"`
+	Currency string `json:"currency"`
+}
+
+type StatementRun struct {
+	Period    time.Time `json:"period"`
+	Generated int       `json:"generated"`
+	Existing  int       `json:"existing"`
+}
+
+type StatementConfig struct {
+	RunInterval time.Duration
+}
@@ -5,31 +5,89 @@ import (
 )
 
 type Transaction struct {
-	ID           int64     `json:"ID"`
-	Username     string    `json:"username"`
-	TxnType      TxnType   `json:"txnType"`
-	Amount       int64     `json:"amount"`
-	Counterparty *string   `json:"counterparty"`
-	Timestamp    time.Time `json:"timestamp"`
-	Hash         string    `json:"hash"`
+	ID             int64             `json:"ID"`
+	Username       string            `json:"username"`
+	TxnType        TxnType           `json:"txnType"`
+	Direction      PostingDirection  `json:"direction"`
+	Currency       string            `json:"currency"`
+	Pocket         string            `json:"pocket"`
+	Amount         int64             `json:"amount"`
+	Counterparty   *string           `json:"counterparty"`
+	FXRate         *string           `json:"fxRate,omitempty"`
+	QuoteID        *string           `json:"quoteId,omitempty"`
+	JournalEntryID *int64            `json:"journalEntryID,omitempty"`
+	ReversalOf     *int64            `json:"reversalOf,omitempty"`
+	Memo           *string           `json:"memo,omitempty"`
+	Reference      *string           `json:"reference,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Timestamp      time.Time         `json:"timestamp"`
+	PrevHash       string            `json:"prevHash"`
+	Hash           string            `json:"hash"`
+	HashVersion    int               `json:"hashVersion"`
 }
 
+const (
+	MaxMemoLength          = 140
+	MaxReferenceLength     = 64
+	MaxMetadataEntries     = 16
+	MaxMetadataKeyLength   = 40
+	MaxMetadataValueLength = 256
+)
+
 type TxnType string
 
 const (
-	TypeDeposit     TxnType = "deposit"
-	TypeWithdraw    TxnType = "withdraw"
-	TypeTransfer    TxnType = "transfer"
-	TypeTransferIn  TxnType = "transfer_in"
-	TypeTransferOut TxnType = "transfer_out"
+	TypeDeposit       TxnType = "deposit"
+	TypeWithdraw      TxnType = "withdraw"
+	TypeTransfer      TxnType = "transfer"
+	TypeTransferIn    TxnType = "transfer_in"
+	TypeTransferOut   TxnType = "transfer_out"
+	TypeReversal      TxnType = "reversal"
+	TypeEscrowFund    TxnType = "escrow_fund"
+	TypeEscrowRelease TxnType = "escrow_release"
+	TypeEscrowRefund  TxnType = "escrow_refund"
+	TypeFee           TxnType = "fee"
+	TypeInterest      TxnType = "interest"
+	TypePocketMove    TxnType = "pocket_move"
+	TypePocketIn      TxnType = "pocket_in"
+	TypePocketOut     TxnType = "pocket_out"
+
+	TypeOpeningBalance TxnType = "opening_balance"
 )
 
 var txnTypes = map[TxnType]struct{}{
-	TypeDeposit:     {},
-	TypeWithdraw:    {},
-	TypeTransfer:    {},
-	TypeTransferIn:  {},
-	TypeTransferOut: {},
+	TypeDeposit:       {},
+	TypeWithdraw:      {},
+	TypeTransfer:      {},
+	TypeTransferIn:    {},
+	TypeTransferOut:   {},
+	TypeReversal:      {},
+	TypeEscrowFund:    {},
+	TypeEscrowRelease: {},
+	TypeEscrowRefund:  {},
+	TypeFee:           {},
+	TypeInterest:      {},
+	TypePocketIn:      {},
+	TypePocketOut:     {},
+}
+
+var txnDirections = map[TxnType]PostingDirection{
+	TypeDeposit:       DirectionCredit,
+	TypeWithdraw:      DirectionDebit,
+	TypeTransferIn:    DirectionCredit,
+	TypeTransferOut:   DirectionDebit,
+	TypeEscrowFund:    DirectionDebit,
+	TypeEscrowRelease: DirectionCredit,
+	TypeEscrowRefund:  DirectionCredit,
+	TypeFee:           DirectionDebit,
+	TypeInterest:      DirectionCredit,
+	TypePocketIn:      DirectionCredit,
+	TypePocketOut:     DirectionDebit,
+}
+
+func TxnDirection(txnType TxnType) (PostingDirection, bool) {
+	direction, ok := txnDirections[txnType]
+	return direction, ok
 }
 
 func IsTxnTypeValid(txnType string) bool {
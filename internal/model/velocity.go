@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+)
+
+type VelocityWindow string
+
+const (
+	VelocityDaily   VelocityWindow = "daily"
+	VelocityWeekly  VelocityWindow = "weekly"
+	VelocityMonthly VelocityWindow = "monthly"
+)
+
+var velocityWindows = map[VelocityWindow]struct{}{
+	VelocityDaily:   {},
+	VelocityWeekly:  {},
+	VelocityMonthly: {},
+}
+
+// Transaction types counted against each velocity rule type. Funding an
+// escrow moves the payer's money out like a transfer, so it counts there.
+var velocityTxnTypes = map[TxnType][]TxnType{
+	TypeWithdraw: {TypeWithdraw},
+	TypeTransfer: {TypeTransferOut, TypeEscrowFund},
+}
+
+func IsVelocityWindowValid(window string) bool {
+	_, ok := velocityWindows[VelocityWindow(window)]
+	return ok
+}
+
+func IsVelocityTxnTypeValid(txnType string) bool {
+	_, ok := velocityTxnTypes[TxnType(txnType)]
+	return ok
+}
+
+func VelocityLoggedTypes(txnType TxnType) []TxnType {
+	return velocityTxnTypes[txnType]
+}
+
+func (w VelocityWindow) Start(now time.Time) time.Time {
+	switch w {
+	case VelocityWeekly:
+		return now.AddDate(0, 0, -7)
+	case VelocityMonthly:
+		return now.AddDate(0, -1, 0)
+	default:
+		return now.AddDate(0, 0, -1)
+	}
+}
+
+func (w VelocityWindow) End(start time.Time) time.Time {
+	switch w {
+	case VelocityWeekly:
+		return start.AddDate(0, 0, 7)
+	case VelocityMonthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+type VelocityRule struct {
+	ID        int64          `json:"ID"`
+	Username  *string        `json:"username"`
+	TxnType   TxnType        `json:"txnType"`
+	Currency  string         `json:"currency"`
+	Window    VelocityWindow `json:"window"`
+	MaxAmount int64          `json:"maxAmount"`
+	CreatedAt time.Time      `json:"createdAt"`
+	RetiredAt *time.Time     `json:"retiredAt"`
+}
+
+type VelocityUsage struct {
+	Amount    int64
+	Timestamp time.Time
+}
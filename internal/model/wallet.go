@@ -4,11 +4,53 @@ import (
 	"time"
 )
 
+const (
+	SystemUsernamePrefix = "SYS_"
+	DefaultPocket        = "MAIN"
+)
+
+type WalletOrigin string
+
+const (
+	WalletOpenedExplicit WalletOrigin = "explicit"
+	WalletOpenedAuto     WalletOrigin = "auto"
+	WalletOpenedLegacy   WalletOrigin = "legacy"
+)
+
+type WalletConfig struct {
+	AutoCreate bool
+}
+
 type Wallet struct {
-	Username            string     `json:"username"`
-	Balance             int64      `json:"balance"`
-	LastDepositAmount   *int64     `json:"lastDepositAmount"`
-	LastDepositUpdated  *time.Time `json:"lastDepositUpdated"`
-	LastWithdrawAmount  *int64     `json:"lastWithdrawAmount"`
-	LastWithdrawUpdated *time.Time `json:"lastWithdrawUpdated"`
+	ID                  int64        `json:"-"`
+	Username            string       `json:"username"`
+	Currency            string       `json:"currency"`
+	Pocket              string       `json:"pocket"`
+	Status              WalletStatus `json:"status"`
+	OpenedVia           WalletOrigin `json:"openedVia"`
+	OpenedAt            time.Time    `json:"openedAt"`
+	Balance             int64        `json:"balance"`
+	HeldBalance         int64        `json:"heldBalance"`
+	AvailableBalance    int64        `json:"availableBalance"`
+	CreditLimit         int64        `json:"creditLimit"`
+	OverdrawnSince      *time.Time   `json:"overdrawnSince"`
+	Limits              WalletLimits `json:"limits"`
+	LastDepositAmount   *int64       `json:"lastDepositAmount"`
+	LastDepositUpdated  *time.Time   `json:"lastDepositUpdated"`
+	LastWithdrawAmount  *int64       `json:"lastWithdrawAmount"`
+	LastWithdrawUpdated *time.Time   `json:"lastWithdrawUpdated"`
+}
+
+type WalletLimits struct {
+	MinAmount  int64 `json:"minAmount"`
+	MaxAmount  int64 `json:"maxAmount"`
+	MaxBalance int64 `json:"maxBalance"`
+}
+
+func DefaultWalletLimits(currency Currency) WalletLimits {
+	return WalletLimits{
+		MinAmount:  currency.MinAmount,
+		MaxAmount:  currency.MaxAmount,
+		MaxBalance: currency.MaxBalance,
+	}
 }
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const maxBatchItems = 1000
+
+type BatchTransferStore interface {
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	FetchApplicableFeeRule(ctx context.Context, tx *sql.Tx, txnType model.TxnType, currency string, amount int64) (*model.FeeRule, error)
+}
+
+type BatchTransferService struct {
+	store BatchTransferStore
+}
+
+func NewBatchTransferService(store BatchTransferStore) *BatchTransferService {
+	logger.Info("Initializing BatchTransferService")
+	return &BatchTransferService{store: store}
+}
+
+func (s *BatchTransferService) DoPrepareBatch(ctx context.Context, tx *sql.Tx, payload *request.BatchTransferPayload) (*model.BatchTransfer, *validation.WalletError) {
+	fnName := "BatchTransferService.DoPrepareBatch"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", payload.Username), zap.String("mode", payload.Mode), zap.Int("items", len(payload.Items)))
+
+	mode := model.BatchAtomic
+	if payload.Mode != "" {
+		mode = model.BatchMode(payload.Mode)
+	}
+	if !model.IsBatchModeValid(string(mode)) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BATCH_INVALID,
+			Message:   "Batch mode must be atomic or best_effort",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("unknown batch mode %q", payload.Mode),
+		}
+	}
+
+	if len(payload.Items) == 0 || len(payload.Items) > maxBatchItems {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BATCH_INVALID,
+			Message:   fmt.Sprintf("Batch must contain between 1 and %d items", maxBatchItems),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("batch has %d items", len(payload.Items)),
+		}
+	}
+
+	username, err := validation.SanitizeAndValidateUsername(payload.Username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize username",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", payload.Username),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	pocket, err := validation.SanitizeAndValidatePocket(payload.Pocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", payload.Pocket),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket validated", fnName), zap.String("pocket", pocket))
+
+	source, err := s.store.FetchWallet(ctx, username, currency.Code, pocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Failed to fetch wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	if source == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "No existing wallet found for user",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("pocket", pocket),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Source wallet fetched", fnName), zap.Any("wallet", source))
+
+	batch := &model.BatchTransfer{
+		Username: username,
+		Currency: currency.Code,
+		Pocket:   pocket,
+		Mode:     mode,
+		Items:    make([]model.BatchItem, len(payload.Items)),
+	}
+
+	incoming := map[string]int64{}
+	for i, itemPayload := range payload.Items {
+		item := &batch.Items[i]
+		item.Index = i
+		item.Counterparty = itemPayload.Counterparty
+		item.Amount = itemPayload.Amount
+		item.Status = model.BatchItemPending
+
+		counterparty, err := validation.SanitizeAndValidateUsername(itemPayload.Counterparty)
+		if err != nil {
+			s.RecordItemResult(item, nil, &validation.WalletError{Code: validation.ERR_SANITIZE_USERNAME_FAILED, Message: "Failed to sanitize counterparty", Err: err})
+			continue
+		}
+		item.Counterparty = counterparty
+
+		if counterparty == username {
+			s.RecordItemResult(item, nil, &validation.WalletError{Code: validation.ERR_BATCH_INVALID, Message: "Counterparty must differ from source wallet"})
+			continue
+		}
+
+		if err := validation.ValidateWalletAmount(itemPayload.Amount, source.Limits, currency.Code); err != nil {
+			s.RecordItemResult(item, nil, &validation.WalletError{Code: validation.ERR_AMOUNT_VALIDATION_FAILED, Message: "Amount validation failed", Err: err})
+			continue
+		}
+
+		rule, err := s.store.FetchApplicableFeeRule(ctx, tx, model.TypeTransfer, currency.Code, item.Amount)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_FETCH_FEE_RULE_FAILED,
+				Message:   "Failed to fetch applicable fee rule",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Int("index", i),
+					zap.Int64("amount", item.Amount),
+				},
+			}
+		}
+		if rule != nil {
+			item.Fee = calculateFee(rule, currency.Code, item.Amount).Fee
+		}
+
+		batch.Total += item.Amount
+		batch.Fees += item.Fee
+		incoming[counterparty] += item.Amount
+	}
+	logger.Info(fmt.Sprintf("%s - Items validated", fnName), zap.Int64("total", batch.Total), zap.Int64("fees", batch.Fees), zap.Int("recipients", len(incoming)))
+
+	if source.AvailableBalance < batch.Total+batch.Fees {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+			Message:   "Insufficient balance for batch total and fees",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("available %d is less than batch total %d plus fees %d", source.AvailableBalance, batch.Total, batch.Fees),
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.Int64("available", source.AvailableBalance),
+				zap.Int64("total", batch.Total),
+				zap.Int64("fees", batch.Fees),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Source balance validated", fnName), zap.Int64("available", source.AvailableBalance), zap.Int64("total", batch.Total), zap.Int64("fees", batch.Fees))
+
+	rejected := map[string]*validation.WalletError{}
+	for counterparty, amount := range incoming {
+		wallet, err := s.store.FetchWallet(ctx, counterparty, currency.Code, model.DefaultPocket)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_FETCH_WALLET_FAILED,
+				Message:   "Failed to fetch counterparty wallet",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			}
+		}
+		if wallet == nil {
+			rejected[counterparty] = &validation.WalletError{Code: validation.ERR_WALLET_DOES_NOT_EXIST, Message: "Counterparty wallet does not exist"}
+			continue
+		}
+
+		if err := validation.ValidateWalletBalance(wallet.Balance+amount, wallet); err != nil {
+			rejected[counterparty] = &validation.WalletError{Code: validation.ERR_WALLET_BALANCE_VALIDATION_FAILED, Message: "Wallet balance would exceed limit", Err: err}
+		}
+	}
+
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		if appErr, ok := rejected[item.Counterparty]; ok && item.Status == model.BatchItemPending {
+			batch.Total -= item.Amount
+			batch.Fees -= item.Fee
+			s.RecordItemResult(item, nil, appErr)
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Recipients validated", fnName), zap.Int("rejected", len(rejected)), zap.Int64("total", batch.Total))
+	return batch, nil
+}
+
+func (s *BatchTransferService) RecordItemResult(item *model.BatchItem, txn *model.Transaction, appErr *validation.WalletError) {
+	if appErr == nil {
+		item.Status = model.BatchItemSucceeded
+		item.TransactionID = &txn.ID
+		return
+	}
+
+	code := string(appErr.Code)
+	message := appErr.Message
+	if appErr.Err != nil {
+		message = fmt.Sprintf("%s: %v", appErr.Message, appErr.Err)
+	}
+	item.Status = model.BatchItemFailed
+	item.Fee = 0
+	item.ErrorCode = &code
+	item.ErrorMessage = &message
+}
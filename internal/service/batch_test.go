@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockBatchTransferStore struct {
+	wallets map[string]*model.Wallet
+	feeRule *model.FeeRule
+}
+
+func (m *mockBatchTransferStore) initializeMockWallets() {
+	m.wallets = map[string]*model.Wallet{
+		"JUAN":    {Username: "JUAN", Currency: "USD", Limits: defaultMockLimits("USD"), Balance: 1000, HeldBalance: 200, AvailableBalance: 800},
+		"MARY":    {Username: "MARY", Currency: "USD", Limits: defaultMockLimits("USD"), Balance: 500, AvailableBalance: 500},
+		"RICH":    {Username: "RICH", Currency: "USD", Limits: defaultMockLimits("USD"), Balance: 999900, AvailableBalance: 999900},
+		"J_LIMIT": {Username: "J_LIMIT", Currency: "USD", Limits: model.WalletLimits{MinAmount: 100, MaxAmount: 200, MaxBalance: 5000}, Balance: 1000, AvailableBalance: 1000},
+	}
+}
+
+func (m *mockBatchTransferStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username]
+	if !ok || wallet.Currency != currency {
+		return nil, nil
+	}
+	return wallet, nil
+}
+
+func (m *mockBatchTransferStore) FetchApplicableFeeRule(ctx context.Context, tx *sql.Tx, txnType model.TxnType, currency string, amount int64) (*model.FeeRule, error) {
+	return m.feeRule, nil
+}
+
+func TestDoPrepareBatch(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name           string
+		payload        *request.BatchTransferPayload
+		feeRule        *model.FeeRule
+		expectedMode   model.BatchMode
+		expectedTotal  int64
+		expectedFees   int64
+		expectedFailed map[int]validation.WalletErrorCode
+		expectedCode   validation.WalletErrorCode
+		expectErr      bool
+	}
+
+	tests := []testCase{
+		{
+			name: "Successful Batch - Defaults to atomic",
+			payload: &request.BatchTransferPayload{Username: "juan", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "mary", Amount: 300},
+				{Counterparty: "rich", Amount: 50},
+			}},
+			expectedMode:  model.BatchAtomic,
+			expectedTotal: 350,
+			expectErr:     false,
+		},
+		{
+			name: "Successful Batch - Invalid items marked failed",
+			payload: &request.BatchTransferPayload{Username: "juan", Mode: "best_effort", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "mary", Amount: 300},
+				{Counterparty: "mary", Amount: -1},
+				{Counterparty: "juan", Amount: 100},
+				{Counterparty: "ghost", Amount: 100},
+			}},
+			expectedMode:  model.BatchBestEffort,
+			expectedTotal: 300,
+			expectedFailed: map[int]validation.WalletErrorCode{
+				1: validation.ERR_AMOUNT_VALIDATION_FAILED,
+				2: validation.ERR_BATCH_INVALID,
+				3: validation.ERR_WALLET_DOES_NOT_EXIST,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Successful Batch - Recipient limit checked on aggregate",
+			payload: &request.BatchTransferPayload{Username: "juan", Mode: "best_effort", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "rich", Amount: 60},
+				{Counterparty: "rich", Amount: 60},
+				{Counterparty: "mary", Amount: 100},
+			}},
+			expectedMode:  model.BatchBestEffort,
+			expectedTotal: 100,
+			expectedFailed: map[int]validation.WalletErrorCode{
+				0: validation.ERR_WALLET_BALANCE_VALIDATION_FAILED,
+				1: validation.ERR_WALLET_BALANCE_VALIDATION_FAILED,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Successful Batch - Items checked against source wallet limits",
+			payload: &request.BatchTransferPayload{Username: "j_limit", Mode: "best_effort", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "mary", Amount: 150},
+				{Counterparty: "mary", Amount: 250},
+				{Counterparty: "mary", Amount: 50},
+			}},
+			expectedMode:  model.BatchBestEffort,
+			expectedTotal: 150,
+			expectedFailed: map[int]validation.WalletErrorCode{
+				1: validation.ERR_AMOUNT_VALIDATION_FAILED,
+				2: validation.ERR_AMOUNT_VALIDATION_FAILED,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Successful Batch - Fees quoted per item",
+			payload: &request.BatchTransferPayload{Username: "juan", Mode: "best_effort", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "mary", Amount: 300},
+				{Counterparty: "mary", Amount: 100},
+				{Counterparty: "ghost", Amount: 100},
+			}},
+			feeRule:       &model.FeeRule{TxnType: model.TypeTransfer, FlatFee: 10, RateBps: 100},
+			expectedMode:  model.BatchBestEffort,
+			expectedTotal: 400,
+			expectedFees:  24,
+			expectedFailed: map[int]validation.WalletErrorCode{
+				2: validation.ERR_WALLET_DOES_NOT_EXIST,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Failed Batch - Fees push total past available balance",
+			payload: &request.BatchTransferPayload{Username: "juan", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "mary", Amount: 390},
+				{Counterparty: "mary", Amount: 390},
+			}},
+			feeRule:      &model.FeeRule{TxnType: model.TypeTransfer, FlatFee: 20},
+			expectedCode: validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Batch - Total exceeds available balance",
+			payload: &request.BatchTransferPayload{Username: "juan", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "mary", Amount: 500},
+				{Counterparty: "mary", Amount: 400},
+			}},
+			expectedCode: validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Batch - No items",
+			payload:      &request.BatchTransferPayload{Username: "juan"},
+			expectedCode: validation.ERR_BATCH_INVALID,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Batch - Unknown mode",
+			payload: &request.BatchTransferPayload{Username: "juan", Mode: "eventually", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "mary", Amount: 100},
+			}},
+			expectedCode: validation.ERR_BATCH_INVALID,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Batch - Source wallet does not exist",
+			payload: &request.BatchTransferPayload{Username: "ghost", Items: []request.BatchTransferItemPayload{
+				{Counterparty: "mary", Amount: 100},
+			}},
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockBatchTransferStore{}
+			mock.initializeMockWallets()
+			mock.feeRule = test.feeRule
+			s := &BatchTransferService{store: mock}
+
+			actual, err := s.DoPrepareBatch(context.Background(), nil, test.payload)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Mode != test.expectedMode {
+				t.Errorf("expected mode %s but got %s instead", test.expectedMode, actual.Mode)
+			}
+
+			if actual.Total != test.expectedTotal {
+				t.Errorf("expected total %d but got %d instead", test.expectedTotal, actual.Total)
+			}
+
+			if actual.Fees != test.expectedFees {
+				t.Errorf("expected fees %d but got %d instead", test.expectedFees, actual.Fees)
+			}
+
+			for _, item := range actual.Items {
+				code, shouldFail := test.expectedFailed[item.Index]
+				if !shouldFail {
+					if item.Status != model.BatchItemPending {
+						t.Errorf("expected item %d to be %s but got %s instead", item.Index, model.BatchItemPending, item.Status)
+					}
+					continue
+				}
+				if item.Status != model.BatchItemFailed || item.ErrorCode == nil || *item.ErrorCode != string(code) {
+					t.Errorf("expected item %d to fail with %s but got %s (%v) instead", item.Index, code, item.Status, item.ErrorCode)
+				}
+			}
+		})
+	}
+}
@@ -13,22 +13,44 @@ import (
 )
 
 type DepositStore interface {
-	UpsertWallet(ctx context.Context, tx *sql.Tx, username string, amount int64) (*model.Wallet, error)
-	FetchWallet(ctx context.Context, username string) (*model.Wallet, error)
+	UpsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error)
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
 }
 
 type DepositService struct {
-	store DepositStore
+	store   DepositStore
+	journal *JournalService
+	config  *model.WalletConfig
 }
 
-func NewDepositService(store DepositStore) *DepositService {
+func NewDepositService(store DepositStore, journal *JournalService, config *model.WalletConfig) *DepositService {
 	logger.Debug("Initializing DepositService")
-	return &DepositService{store: store}
+	return &DepositService{store: store, journal: journal, config: config}
 }
 
-func (s *DepositService) DoDeposit(ctx context.Context, tx *sql.Tx, username string, amount int64, isCounterparty bool) (*model.Wallet, *validation.WalletError) {
+// DoPostedDeposit credits the wallet and posts it against SYSTEM_CASH in one
+// step, so a deposit can never move a balance without its journal entry.
+// DoDeposit on its own is only for callers that post the credit as one leg of
+// a larger entry, such as a transfer or an escrow settlement.
+func (s *DepositService) DoPostedDeposit(ctx context.Context, tx *sql.Tx, username string, currencyCode string, pocketName string, amount int64) (*model.Wallet, *model.JournalEntry, *validation.WalletError) {
+	fnName := "DepositService.DoPostedDeposit"
+
+	wallet, appErr := s.DoDeposit(ctx, tx, username, currencyCode, pocketName, amount, false)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	entry, appErr := s.journal.PostDeposit(ctx, tx, wallet, amount)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+	return wallet, entry, nil
+}
+
+func (s *DepositService) DoDeposit(ctx context.Context, tx *sql.Tx, username string, currencyCode string, pocketName string, amount int64, isCounterparty bool) (*model.Wallet, *validation.WalletError) {
 	fnName := "DepositService.DoDeposit"
-	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.Int64("amount", amount))
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode), zap.String("pocket", pocketName), zap.Int64("amount", amount))
 	username, err := validation.SanitizeAndValidateUsername(username)
 	if err != nil {
 		return nil, &validation.WalletError{
@@ -44,21 +66,50 @@ func (s *DepositService) DoDeposit(ctx context.Context, tx *sql.Tx, username str
 	}
 	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
 
-	if err := validation.ValidateAmount(amount); err != nil {
+	if validation.IsReservedUsername(username) {
 		return nil, &validation.WalletError{
 			Name:      fnName,
-			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
-			Message:   "Amount validation failed",
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "Username is reserved for system wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s uses reserved prefix %s", username, model.SystemUsernamePrefix),
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
 			Timestamp: time.Now().UTC(),
 			Err:       err,
 			Context: []zap.Field{
-				zap.Int64("amount", amount),
+				zap.String("currency", currencyCode),
 			},
 		}
 	}
-	logger.Info(fmt.Sprintf("%s - Amount validated", fnName), zap.Int64("amount", amount))
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
 
-	currentWallet, err := s.store.FetchWallet(ctx, username)
+	pocket, err := validation.SanitizeAndValidatePocket(pocketName)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", pocketName),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket validated", fnName), zap.String("pocket", pocket))
+
+	currentWallet, err := s.store.FetchWallet(ctx, username, currency.Code, pocket)
 	if err != nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
@@ -69,6 +120,33 @@ func (s *DepositService) DoDeposit(ctx context.Context, tx *sql.Tx, username str
 			Context:   nil,
 		}
 	}
+	if currentWallet != nil {
+		if appErr := checkWalletStatus(fnName, currentWallet, false); appErr != nil {
+			return nil, appErr
+		}
+		logger.Info(fmt.Sprintf("%s - Wallet status checked", fnName), zap.String("status", string(currentWallet.Status)))
+	}
+
+	limits := model.DefaultWalletLimits(currency)
+	if currentWallet != nil {
+		limits = currentWallet.Limits
+	}
+	if err := validation.ValidateWalletAmount(amount, limits, currency.Code); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("amount", amount),
+				zap.String("currency", currency.Code),
+				zap.Any("limits", limits),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Amount validated", fnName), zap.Int64("amount", amount))
+
 	if currentWallet == nil {
 		if isCounterparty {
 			return nil, &validation.WalletError{
@@ -79,15 +157,43 @@ func (s *DepositService) DoDeposit(ctx context.Context, tx *sql.Tx, username str
 				Err:       nil,
 				Context: []zap.Field{
 					zap.String("counterparty", username),
+					zap.String("currency", currency.Code),
+				},
+			}
+		}
+		if pocket != model.DefaultPocket {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_POCKET_NOT_FOUND,
+				Message:   "Pocket does not exist, create it first",
+				Timestamp: time.Now().UTC(),
+				Err:       nil,
+				Context: []zap.Field{
+					zap.String("username", username),
+					zap.String("currency", currency.Code),
+					zap.String("pocket", pocket),
+				},
+			}
+		}
+		if !s.config.AutoCreate {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+				Message:   "Wallet does not exist, open it first",
+				Timestamp: time.Now().UTC(),
+				Err:       nil,
+				Context: []zap.Field{
+					zap.String("username", username),
+					zap.String("currency", currency.Code),
 				},
 			}
 		}
-		logger.Warn(fmt.Sprintf("%s - No wallet found for user", fnName))
+		logger.Warn(fmt.Sprintf("%s - No wallet found for user, auto-creating", fnName))
 	}
 
 	if currentWallet != nil {
 		newBalance := currentWallet.Balance + amount
-		if err := validation.ValidateWalletBalance(newBalance); err != nil {
+		if err := validation.ValidateWalletBalance(newBalance, currentWallet); err != nil {
 			return nil, &validation.WalletError{
 				Name:      fnName,
 				Code:      validation.ERR_WALLET_BALANCE_VALIDATION_FAILED,
@@ -110,7 +216,7 @@ func (s *DepositService) DoDeposit(ctx context.Context, tx *sql.Tx, username str
 		)
 	}
 
-	updatedWallet, err := s.store.UpsertWallet(ctx, tx, username, amount)
+	updatedWallet, err := s.store.UpsertWallet(ctx, tx, username, currency.Code, pocket, amount)
 	if err != nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
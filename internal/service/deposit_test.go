@@ -18,29 +18,92 @@ func (m *mockDepositStore) initializeMockWallet() {
 	m.wallets = map[string]model.Wallet{
 		"JUAN": {
 			Username: "JUAN",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("USD"),
 			Balance:  2000,
 		},
 		"J_U_A_N": {
 			Username: "J_U_A_N",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("USD"),
 			Balance:  7000,
 		},
 		"J123": {
 			Username: "J123",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("USD"),
 			Balance:  5000,
 		},
 		"J_123": {
 			Username: "J_123",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("USD"),
 			Balance:  999999,
 		},
+		"J_KWD": {
+			Username: "J_KWD",
+			Currency: "KWD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("KWD"),
+			Balance:  200000,
+		},
+		"J_LIMIT": {
+			Username: "J_LIMIT",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   model.WalletLimits{MinAmount: 100, MaxAmount: 500, MaxBalance: 3000},
+			Balance:  2600,
+		},
+		"J_POCKET": {
+			Username: "J_POCKET",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Pocket:   "SAVINGS",
+			Limits:   defaultMockLimits("USD"),
+			Balance:  400,
+		},
+		"J_FROZEN": {
+			Username: "J_FROZEN",
+			Currency: "USD",
+			Status:   model.WalletFrozenDebit,
+			Limits:   defaultMockLimits("USD"),
+			Balance:  1000,
+		},
+		"J_LOCKED": {
+			Username: "J_LOCKED",
+			Currency: "USD",
+			Status:   model.WalletFrozenAll,
+			Limits:   defaultMockLimits("USD"),
+			Balance:  1000,
+		},
+		"J_CLOSED": {
+			Username: "J_CLOSED",
+			Currency: "USD",
+			Status:   model.WalletClosed,
+			Limits:   defaultMockLimits("USD"),
+		},
 	}
 }
 
-func (m *mockDepositStore) UpsertWallet(ctx context.Context, tx *sql.Tx, username string, amount int64) (*model.Wallet, error) {
+func mockPocket(w model.Wallet) string {
+	if w.Pocket == "" {
+		return model.DefaultPocket
+	}
+	return w.Pocket
+}
+
+func (m *mockDepositStore) UpsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
 	currentTimestamp := time.Now().UTC()
 	w, ok := m.wallets[username]
-	if !ok {
+	if !ok || w.Currency != currency || mockPocket(w) != pocket {
 		return &model.Wallet{
 			Username:           username,
+			Currency:           currency,
+			Pocket:             pocket,
 			Balance:            amount,
 			LastDepositAmount:  &amount,
 			LastDepositUpdated: &currentTimestamp,
@@ -48,16 +111,26 @@ func (m *mockDepositStore) UpsertWallet(ctx context.Context, tx *sql.Tx, usernam
 	}
 	return &model.Wallet{
 		Username:           w.Username,
+		Currency:           w.Currency,
+		Pocket:             mockPocket(w),
 		Balance:            w.Balance + amount,
 		LastDepositAmount:  &amount,
 		LastDepositUpdated: &currentTimestamp,
 	}, nil
 }
 
-func (m *mockDepositStore) FetchWallet(ctx context.Context, username string) (*model.Wallet, error) {
+func (m *mockDepositStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	w, ok := m.wallets[username]
+	if !ok || w.Currency != currency || mockPocket(w) != pocket {
+		return nil, nil
+	}
 	return &model.Wallet{
-		Username: m.wallets[username].Username,
-		Balance:  m.wallets[username].Balance,
+		Username: w.Username,
+		Currency: w.Currency,
+		Pocket:   mockPocket(w),
+		Status:   w.Status,
+		Balance:  w.Balance,
+		Limits:   w.Limits,
 	}, nil
 }
 
@@ -68,7 +141,10 @@ func TestDoDeposit(t *testing.T) {
 	type testCase struct {
 		name           string
 		username       string
+		currency       string
+		pocket         string
 		amount         int64
+		autoCreate     bool
 		expectedWallet *model.Wallet
 		expectErr      bool
 	}
@@ -80,6 +156,7 @@ func TestDoDeposit(t *testing.T) {
 			amount:   1000,
 			expectedWallet: &model.Wallet{
 				Username: "JUAN",
+				Currency: "USD",
 				Balance:  3000,
 			},
 			expectErr: false,
@@ -90,6 +167,7 @@ func TestDoDeposit(t *testing.T) {
 			amount:   750,
 			expectedWallet: &model.Wallet{
 				Username: "J_U_A_N",
+				Currency: "USD",
 				Balance:  7750,
 			},
 			expectErr: false,
@@ -100,50 +178,201 @@ func TestDoDeposit(t *testing.T) {
 			amount:   1000,
 			expectedWallet: &model.Wallet{
 				Username: "J123",
+				Currency: "USD",
 				Balance:  6000,
 			},
 			expectErr: false,
 		},
 		{
-			name:     "Successful Deposit - New wallet",
-			username: "JUAN123",
-			amount:   500,
+			name:       "Successful Deposit - New wallet",
+			username:   "JUAN123",
+			amount:     500,
+			autoCreate: true,
 			expectedWallet: &model.Wallet{
 				Username: "JUAN123",
+				Currency: "USD",
 				Balance:  500,
 			},
 			expectErr: false,
 		},
 		{
-			name:     "Successful Deposit - New wallet with lowercase and underscore username",
-			username: "__j__123",
-			amount:   500,
+			name:       "Successful Deposit - New wallet with lowercase and underscore username",
+			username:   "__j__123",
+			amount:     500,
+			autoCreate: true,
 			expectedWallet: &model.Wallet{
 				Username: "__J__123",
+				Currency: "USD",
 				Balance:  500,
 			},
 			expectErr: false,
 		},
 		{
-			name:     "Successful Deposit - New wallet with username padded with spaces",
-			username: " __juan__ ",
-			amount:   500,
+			name:       "Successful Deposit - New wallet with username padded with spaces",
+			username:   " __juan__ ",
+			amount:     500,
+			autoCreate: true,
 			expectedWallet: &model.Wallet{
 				Username: "__JUAN__",
+				Currency: "USD",
 				Balance:  500,
 			},
 			expectErr: false,
 		},
 		{
-			name:     "Successful Deposit - Amount at upper limit",
-			username: "_J_",
-			amount:   999999,
+			name:       "Successful Deposit - Amount at upper limit",
+			username:   "_J_",
+			amount:     999999,
+			autoCreate: true,
 			expectedWallet: &model.Wallet{
 				Username: "_J_",
+				Currency: "USD",
 				Balance:  999999,
 			},
 			expectErr: false,
 		},
+		{
+			name:       "Successful Deposit - New wallet in another currency",
+			username:   "juan",
+			currency:   "eur",
+			amount:     500,
+			autoCreate: true,
+			expectedWallet: &model.Wallet{
+				Username: "JUAN",
+				Currency: "EUR",
+				Balance:  500,
+			},
+			expectErr: false,
+		},
+		{
+			name:     "Successful Deposit - Existing wallet in non-default currency",
+			username: "j_kwd",
+			currency: "KWD",
+			amount:   99999,
+			expectedWallet: &model.Wallet{
+				Username: "J_KWD",
+				Currency: "KWD",
+				Balance:  299999,
+			},
+			expectErr: false,
+		},
+		{
+			name:     "Successful Deposit - Up to per-wallet limits",
+			username: "J_LIMIT",
+			amount:   400,
+			expectedWallet: &model.Wallet{
+				Username: "J_LIMIT",
+				Currency: "USD",
+				Balance:  3000,
+			},
+			expectErr: false,
+		},
+		{
+			name:     "Successful Deposit - Named pocket",
+			username: "j_pocket",
+			pocket:   "savings",
+			amount:   100,
+			expectedWallet: &model.Wallet{
+				Username: "J_POCKET",
+				Currency: "USD",
+				Pocket:   "SAVINGS",
+				Balance:  500,
+			},
+			expectErr: false,
+		},
+		{
+			name:           "Failed Deposit - New wallet without auto-create",
+			username:       "juan123",
+			amount:         500,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - New wallet in another currency without auto-create",
+			username:       "juan",
+			currency:       "eur",
+			amount:         500,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - Pocket does not exist",
+			username:       "juan",
+			pocket:         "travel",
+			amount:         100,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - Invalid pocket name",
+			username:       "juan",
+			pocket:         "rainy-day",
+			amount:         100,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:     "Successful Deposit - Debit frozen wallet still accepts credits",
+			username: "j_frozen",
+			amount:   100,
+			expectedWallet: &model.Wallet{
+				Username: "J_FROZEN",
+				Currency: "USD",
+				Balance:  1100,
+			},
+			expectErr: false,
+		},
+		{
+			name:           "Failed Deposit - Fully frozen wallet",
+			username:       "j_locked",
+			amount:         100,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - Closed wallet",
+			username:       "j_closed",
+			amount:         100,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - Below per-wallet minimum amount",
+			username:       "J_LIMIT",
+			amount:         99,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - Above per-wallet maximum amount",
+			username:       "J_LIMIT",
+			amount:         501,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - Breach per-wallet balance limit",
+			username:       "J_LIMIT",
+			amount:         401,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - Unsupported currency",
+			username:       "juan",
+			currency:       "XYZ",
+			amount:         500,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Deposit - Breach currency wallet limit",
+			username:       "J_KWD",
+			currency:       "KWD",
+			amount:         100000,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
 		{
 			name:           "Failed Deposit - Invalid username with special character",
 			username:       "J@123",
@@ -151,6 +380,13 @@ func TestDoDeposit(t *testing.T) {
 			expectedWallet: nil,
 			expectErr:      true,
 		},
+		{
+			name:           "Failed Deposit - Reserved system username",
+			username:       "sys_escrow",
+			amount:         200,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
 		{
 			name:           "Failed Deposit - Invalid amount with negative number",
 			username:       "juan",
@@ -176,6 +412,7 @@ func TestDoDeposit(t *testing.T) {
 			name:           "Failed Deposit - Breach wallet amount on ne wallets",
 			username:       "_J_test_",
 			amount:         1000000,
+			autoCreate:     true,
 			expectedWallet: nil,
 			expectErr:      true,
 		},
@@ -185,8 +422,8 @@ func TestDoDeposit(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mock := &mockDepositStore{}
 			mock.initializeMockWallet()
-			s := &DepositService{store: mock}
-			actual, err := s.DoDeposit(context.Background(), nil, test.username, test.amount, false)
+			s := &DepositService{store: mock, config: &model.WalletConfig{AutoCreate: test.autoCreate}}
+			actual, err := s.DoDeposit(context.Background(), nil, test.username, test.currency, test.pocket, test.amount, false)
 
 			if test.expectErr && err == nil {
 				t.Errorf("expected error but got nil")
@@ -200,6 +437,14 @@ func TestDoDeposit(t *testing.T) {
 				t.Errorf("expected username %s but got %s instead", test.expectedWallet.Username, actual.Username)
 			}
 
+			if !test.expectErr && actual != nil && test.expectedWallet.Currency != actual.Currency {
+				t.Errorf("expected currency %s but got %s instead", test.expectedWallet.Currency, actual.Currency)
+			}
+
+			if !test.expectErr && actual != nil && test.expectedWallet.Pocket != "" && test.expectedWallet.Pocket != actual.Pocket {
+				t.Errorf("expected pocket %s but got %s instead", test.expectedWallet.Pocket, actual.Pocket)
+			}
+
 			if !test.expectErr && actual != nil && test.expectedWallet.Balance != actual.Balance {
 				t.Errorf("expected balance %d but got %d instead", test.expectedWallet.Balance, actual.Balance)
 			}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type EscrowSettler func(ctx context.Context, tx *sql.Tx, escrow *model.Escrow, escrowWallet *model.Wallet) *validation.WalletError
+
+type EscrowStore interface {
+	BeginTransaction(ctx context.Context) (*sql.Tx, error)
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	UpsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error)
+	WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error)
+	InsertEscrow(ctx context.Context, tx *sql.Tx, escrow *model.Escrow) error
+	FetchEscrowForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Escrow, error)
+	ClaimExpiredEscrow(ctx context.Context, tx *sql.Tx, at time.Time) (*model.Escrow, error)
+	ResolveEscrow(ctx context.Context, tx *sql.Tx, escrow *model.Escrow) error
+	FetchEscrows(ctx context.Context, criteria *model.EscrowCriteria) ([]model.Escrow, error)
+}
+
+type EscrowService struct {
+	store  EscrowStore
+	config *model.EscrowConfig
+}
+
+func NewEscrowService(store EscrowStore, config *model.EscrowConfig) *EscrowService {
+	logger.Info("Initializing EscrowService")
+	return &EscrowService{store: store, config: config}
+}
+
+func (s *EscrowService) DoCreateEscrow(ctx context.Context, tx *sql.Tx, payload *request.EscrowPayload) (*model.Escrow, *model.Wallet, *validation.WalletError) {
+	fnName := "EscrowService.DoCreateEscrow"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	payer, err := validation.SanitizeAndValidateUsername(payload.Payer)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize payer",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("payer", payload.Payer),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Payer sanitized", fnName), zap.String("payer", payer))
+
+	payee, err := validation.SanitizeAndValidateUsername(payload.Payee)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize payee",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("payee", payload.Payee),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Payee sanitized", fnName), zap.String("payee", payee))
+
+	if payer == payee || validation.IsReservedUsername(payer) || validation.IsReservedUsername(payee) {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ESCROW_PARTIES_INVALID,
+			Message:   "Payer and payee must be different user wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("invalid escrow parties %s and %s", payer, payee),
+		}
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	if err := validation.ValidateAmount(payload.Amount, currency); err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("amount", payload.Amount),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Amount validated", fnName), zap.Int64("amount", payload.Amount))
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(s.config.DefaultTTL)
+	if payload.ExpiresAt != nil {
+		expiresAt = payload.ExpiresAt.UTC()
+	}
+	if !expiresAt.After(now) {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ESCROW_EXPIRY_INVALID,
+			Message:   "Escrow expiry must be in the future",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("expiry %s is not after %s", expiresAt.Format(time.RFC3339), now.Format(time.RFC3339)),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Expiry validated", fnName), zap.Time("expiresAt", expiresAt))
+
+	payeeWallet, err := s.store.FetchWallet(ctx, payee, currency.Code, model.DefaultPocket)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Failed to fetch payee wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	if payeeWallet == nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "Payee wallet does not exist",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("payee", payee),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+
+	escrow := &model.Escrow{
+		Payer:     payer,
+		Payee:     payee,
+		Currency:  currency.Code,
+		Amount:    payload.Amount,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.store.InsertEscrow(ctx, tx, escrow); err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_ESCROW_FAILED,
+			Message:   "Failed to create escrow",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("escrow", escrow),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Escrow created", fnName), zap.Any("escrow", escrow))
+
+	escrowWallet, err := s.store.UpsertWallet(ctx, tx, model.EscrowWallet, currency.Code, model.DefaultPocket, escrow.Amount)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_ESCROW_FAILED,
+			Message:   "Failed to credit escrow wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("escrow", escrow),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Escrow wallet credited", fnName), zap.Any("wallet", escrowWallet))
+	return escrow, escrowWallet, nil
+}
+
+func (s *EscrowService) DoReleaseEscrow(ctx context.Context, tx *sql.Tx, id int64, amount *int64) (*model.Escrow, *model.Wallet, *validation.WalletError) {
+	fnName := "EscrowService.DoReleaseEscrow"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id), zap.Any("amount", amount))
+
+	escrow, appErr := s.fetchFundedEscrow(ctx, tx, fnName, id)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	released := escrow.Amount
+	if amount != nil {
+		released = *amount
+	}
+	if released <= 0 || released > escrow.Amount {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ESCROW_AMOUNT_INVALID,
+			Message:   "Release amount must be positive and no more than the escrowed amount",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("release %d of escrow %d for %d", released, escrow.ID, escrow.Amount),
+		}
+	}
+
+	escrow.Status = model.EscrowReleased
+	escrow.ReleasedAmount = released
+	escrow.RefundedAmount = escrow.Amount - released
+	escrowWallet, appErr := s.resolveEscrow(ctx, tx, fnName, escrow)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Escrow released", fnName), zap.Any("escrow", escrow))
+	return escrow, escrowWallet, nil
+}
+
+func (s *EscrowService) DoRefundEscrow(ctx context.Context, tx *sql.Tx, id int64) (*model.Escrow, *model.Wallet, *validation.WalletError) {
+	fnName := "EscrowService.DoRefundEscrow"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id))
+
+	escrow, appErr := s.fetchFundedEscrow(ctx, tx, fnName, id)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	escrow.Status = model.EscrowRefunded
+	escrow.RefundedAmount = escrow.Amount
+	escrowWallet, appErr := s.resolveEscrow(ctx, tx, fnName, escrow)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Escrow refunded", fnName), zap.Any("escrow", escrow))
+	return escrow, escrowWallet, nil
+}
+
+func (s *EscrowService) DoFetchEscrows(ctx context.Context, username string, status string, limit string) ([]model.Escrow, *model.EscrowCriteria, *validation.WalletError) {
+	fnName := "EscrowService.DoFetchEscrows"
+	queryUsername := validation.SanitizeUsernameWithoutError(username)
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", queryUsername))
+
+	queryStatus := ""
+	if model.IsEscrowStatusValid(status) {
+		queryStatus = status
+	}
+	logger.Info(fmt.Sprintf("%s - Status valid", fnName), zap.String("status", queryStatus))
+
+	queryLimit, err := strconv.Atoi(limit)
+	if err != nil {
+		queryLimit = 0
+	}
+	logger.Info(fmt.Sprintf("%s - Limit converted to int", fnName), zap.Int("limit", queryLimit))
+
+	criteria := &model.EscrowCriteria{
+		Username: queryUsername,
+		Status:   model.EscrowStatus(queryStatus),
+		Limit:    queryLimit,
+	}
+
+	escrows, err := s.store.FetchEscrows(ctx, criteria)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_ESCROW_FAILED,
+			Message:   "Failed to fetch escrows",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("criteria", criteria),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Escrows fetched", fnName), zap.Int("count", len(escrows)))
+	return escrows, criteria, nil
+}
+
+func (s *EscrowService) DoExpireDue(ctx context.Context, settle EscrowSettler) (int, *validation.WalletError) {
+	fnName := "EscrowService.DoExpireDue"
+	processed := 0
+	for {
+		ok, appErr := s.expireNext(ctx, settle)
+		if appErr != nil {
+			return processed, appErr
+		}
+		if !ok {
+			break
+		}
+		processed++
+	}
+	logger.Info(fmt.Sprintf("%s - Expired escrows refunded", fnName), zap.Int("processed", processed))
+	return processed, nil
+}
+
+func (s *EscrowService) RunExpirySweeper(ctx context.Context, interval time.Duration, settle EscrowSettler) {
+	fnName := "EscrowService.RunExpirySweeper"
+	logger.Info(fmt.Sprintf("%s - Sweeper started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Sweeper stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoExpireDue(ctx, settle); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Escrow expiry sweep failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func (s *EscrowService) expireNext(ctx context.Context, settle EscrowSettler) (bool, *validation.WalletError) {
+	fnName := "EscrowService.expireNext"
+
+	tx, err := s.store.BeginTransaction(ctx)
+	if err != nil {
+		return false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SETTLE_ESCROW_FAILED,
+			Message:   "Failed to start transaction",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	escrow, err := s.store.ClaimExpiredEscrow(ctx, tx, time.Now().UTC())
+	if err != nil {
+		return false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_ESCROW_FAILED,
+			Message:   "Failed to claim expired escrow",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	if escrow == nil {
+		return false, nil
+	}
+	logger.Info(fmt.Sprintf("%s - Expired escrow claimed", fnName), zap.Any("escrow", escrow))
+
+	escrow.Status = model.EscrowExpired
+	escrow.RefundedAmount = escrow.Amount
+	escrowWallet, appErr := s.resolveEscrow(ctx, tx, fnName, escrow)
+	if appErr != nil {
+		return false, appErr
+	}
+
+	if appErr := settle(ctx, tx, escrow, escrowWallet); appErr != nil {
+		return false, appErr
+	}
+	if err := tx.Commit(); err != nil {
+		return false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SETTLE_ESCROW_FAILED,
+			Message:   "Failed to commit expired escrow",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Expired escrow refunded", fnName), zap.Any("escrow", escrow))
+	return true, nil
+}
+
+func (s *EscrowService) fetchFundedEscrow(ctx context.Context, tx *sql.Tx, fnName string, id int64) (*model.Escrow, *validation.WalletError) {
+	escrow, err := s.store.FetchEscrowForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_ESCROW_FAILED,
+			Message:   "Failed to fetch escrow",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if escrow == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ESCROW_NOT_FOUND,
+			Message:   "Escrow not found",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if escrow.Status != model.EscrowFunded {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ESCROW_NOT_FUNDED,
+			Message:   "Escrow is no longer funded",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("escrow %d is %s", escrow.ID, escrow.Status),
+		}
+	}
+	if !time.Now().UTC().Before(escrow.ExpiresAt) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ESCROW_EXPIRED,
+			Message:   "Escrow has expired",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("escrow %d expired at %s", escrow.ID, escrow.ExpiresAt.Format(time.RFC3339)),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Funded escrow fetched", fnName), zap.Any("escrow", escrow))
+	return escrow, nil
+}
+
+func (s *EscrowService) resolveEscrow(ctx context.Context, tx *sql.Tx, fnName string, escrow *model.Escrow) (*model.Wallet, *validation.WalletError) {
+	escrowWallet, err := s.store.WithdrawWallet(ctx, tx, model.EscrowWallet, escrow.Currency, model.DefaultPocket, escrow.Amount)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SETTLE_ESCROW_FAILED,
+			Message:   "Failed to debit escrow wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("escrow", escrow),
+			},
+		}
+	}
+
+	resolvedAt := time.Now().UTC()
+	escrow.ResolvedAt = &resolvedAt
+	if err := s.store.ResolveEscrow(ctx, tx, escrow); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SETTLE_ESCROW_FAILED,
+			Message:   "Failed to resolve escrow",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("escrow", escrow),
+			},
+		}
+	}
+	return escrowWallet, nil
+}
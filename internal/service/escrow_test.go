@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockEscrowStore struct {
+	wallets map[string]*model.Wallet
+	escrows map[int64]*model.Escrow
+	nextID  int64
+}
+
+func (m *mockEscrowStore) initializeMockData() {
+	m.wallets = map[string]*model.Wallet{
+		"JUAN":             {ID: 1, Username: "JUAN", Currency: "USD", Balance: 1000, AvailableBalance: 1000},
+		"MARY":             {ID: 2, Username: "MARY", Currency: "USD", Balance: 500, AvailableBalance: 500},
+		model.EscrowWallet: {ID: 3, Username: model.EscrowWallet, Currency: "USD", Balance: 700, AvailableBalance: 700},
+	}
+	future := time.Now().UTC().Add(time.Hour)
+	m.escrows = map[int64]*model.Escrow{
+		1: {ID: 1, Payer: "JUAN", Payee: "MARY", Currency: "USD", Amount: 400, Status: model.EscrowFunded, ExpiresAt: future},
+		2: {ID: 2, Payer: "JUAN", Payee: "MARY", Currency: "USD", Amount: 200, RefundedAmount: 200, Status: model.EscrowRefunded, ExpiresAt: future},
+		3: {ID: 3, Payer: "JUAN", Payee: "MARY", Currency: "USD", Amount: 300, Status: model.EscrowFunded, ExpiresAt: time.Now().UTC().Add(-time.Minute)},
+	}
+	m.nextID = 4
+}
+
+func (m *mockEscrowStore) BeginTransaction(ctx context.Context) (*sql.Tx, error) {
+	return nil, fmt.Errorf("not supported by mock")
+}
+
+func (m *mockEscrowStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username]
+	if !ok || wallet.Currency != currency {
+		return nil, nil
+	}
+	return wallet, nil
+}
+
+func (m *mockEscrowStore) UpsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username]
+	if !ok {
+		wallet = &model.Wallet{ID: int64(len(m.wallets) + 1), Username: username, Currency: currency}
+		m.wallets[username] = wallet
+	}
+	wallet.Balance += amount
+	wallet.AvailableBalance += amount
+	return wallet, nil
+}
+
+func (m *mockEscrowStore) WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username]
+	if !ok || wallet.AvailableBalance < amount {
+		return nil, fmt.Errorf("insufficient available balance")
+	}
+	wallet.Balance -= amount
+	wallet.AvailableBalance -= amount
+	return wallet, nil
+}
+
+func (m *mockEscrowStore) InsertEscrow(ctx context.Context, tx *sql.Tx, escrow *model.Escrow) error {
+	escrow.ID = m.nextID
+	escrow.Status = model.EscrowFunded
+	escrow.CreatedAt = time.Now().UTC()
+	m.escrows[escrow.ID] = escrow
+	m.nextID++
+	return nil
+}
+
+func (m *mockEscrowStore) FetchEscrowForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Escrow, error) {
+	escrow, ok := m.escrows[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *escrow
+	return &copied, nil
+}
+
+func (m *mockEscrowStore) ClaimExpiredEscrow(ctx context.Context, tx *sql.Tx, at time.Time) (*model.Escrow, error) {
+	return nil, nil
+}
+
+func (m *mockEscrowStore) ResolveEscrow(ctx context.Context, tx *sql.Tx, escrow *model.Escrow) error {
+	if m.escrows[escrow.ID].Status != model.EscrowFunded {
+		return fmt.Errorf("escrow %d is no longer funded", escrow.ID)
+	}
+	m.escrows[escrow.ID] = escrow
+	return nil
+}
+
+func (m *mockEscrowStore) FetchEscrows(ctx context.Context, criteria *model.EscrowCriteria) ([]model.Escrow, error) {
+	return []model.Escrow{}, nil
+}
+
+func TestDoCreateEscrow(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name                 string
+		payload              *request.EscrowPayload
+		expectedEscrowWallet int64
+		expectedCode         validation.WalletErrorCode
+		expectErr            bool
+	}
+
+	tests := []testCase{
+		{
+			name:                 "Successful Escrow - Funds escrow wallet",
+			payload:              &request.EscrowPayload{Payer: "juan", Payee: "mary", Amount: 250},
+			expectedEscrowWallet: 950,
+			expectErr:            false,
+		},
+		{
+			name:         "Failed Escrow - Payer and payee are the same",
+			payload:      &request.EscrowPayload{Payer: "juan", Payee: "JUAN", Amount: 250},
+			expectedCode: validation.ERR_ESCROW_PARTIES_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Escrow - Payee is a system wallet",
+			payload:      &request.EscrowPayload{Payer: "juan", Payee: "sys_escrow", Amount: 250},
+			expectedCode: validation.ERR_ESCROW_PARTIES_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Escrow - Payee wallet does not exist",
+			payload:      &request.EscrowPayload{Payer: "juan", Payee: "ghost", Amount: 250},
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Escrow - Expiry in the past",
+			payload:      &request.EscrowPayload{Payer: "juan", Payee: "mary", Amount: 250, ExpiresAt: utils.Ptr(time.Now().Add(-time.Hour))},
+			expectedCode: validation.ERR_ESCROW_EXPIRY_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Escrow - Negative amount",
+			payload:      &request.EscrowPayload{Payer: "juan", Payee: "mary", Amount: -250},
+			expectedCode: validation.ERR_AMOUNT_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockEscrowStore{}
+			mock.initializeMockData()
+			s := &EscrowService{store: mock, config: &model.EscrowConfig{DefaultTTL: time.Hour}}
+
+			actual, escrowWallet, err := s.DoCreateEscrow(context.Background(), nil, test.payload)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.EscrowFunded {
+				t.Errorf("expected status %s but got %s instead", model.EscrowFunded, actual.Status)
+			}
+
+			if escrowWallet.Balance != test.expectedEscrowWallet {
+				t.Errorf("expected escrow wallet balance %d but got %d instead", test.expectedEscrowWallet, escrowWallet.Balance)
+			}
+		})
+	}
+}
+
+func TestDoReleaseEscrow(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name             string
+		id               int64
+		amount           *int64
+		expectedReleased int64
+		expectedRefunded int64
+		expectedCode     validation.WalletErrorCode
+		expectErr        bool
+	}
+
+	tests := []testCase{
+		{
+			name:             "Successful Release - Full amount to payee",
+			id:               1,
+			expectedReleased: 400,
+			expectedRefunded: 0,
+			expectErr:        false,
+		},
+		{
+			name:             "Successful Release - Split remainder refunded to payer",
+			id:               1,
+			amount:           utils.Ptr(int64(150)),
+			expectedReleased: 150,
+			expectedRefunded: 250,
+			expectErr:        false,
+		},
+		{
+			name:         "Failed Release - Amount above escrowed amount",
+			id:           1,
+			amount:       utils.Ptr(int64(401)),
+			expectedCode: validation.ERR_ESCROW_AMOUNT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Release - Zero amount",
+			id:           1,
+			amount:       utils.Ptr(int64(0)),
+			expectedCode: validation.ERR_ESCROW_AMOUNT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Release - Escrow already refunded",
+			id:           2,
+			expectedCode: validation.ERR_ESCROW_NOT_FUNDED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Release - Escrow expired",
+			id:           3,
+			expectedCode: validation.ERR_ESCROW_EXPIRED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Release - Escrow not found",
+			id:           99,
+			expectedCode: validation.ERR_ESCROW_NOT_FOUND,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockEscrowStore{}
+			mock.initializeMockData()
+			s := &EscrowService{store: mock, config: &model.EscrowConfig{DefaultTTL: time.Hour}}
+
+			actual, escrowWallet, err := s.DoReleaseEscrow(context.Background(), nil, test.id, test.amount)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.EscrowReleased {
+				t.Errorf("expected status %s but got %s instead", model.EscrowReleased, actual.Status)
+			}
+
+			if actual.ReleasedAmount != test.expectedReleased || actual.RefundedAmount != test.expectedRefunded {
+				t.Errorf("expected released %d and refunded %d but got %d and %d instead", test.expectedReleased, test.expectedRefunded, actual.ReleasedAmount, actual.RefundedAmount)
+			}
+
+			if actual.ResolvedAt == nil {
+				t.Errorf("expected resolvedAt to be set")
+			}
+
+			if escrowWallet.Balance != 300 {
+				t.Errorf("expected escrow wallet balance 300 but got %d instead", escrowWallet.Balance)
+			}
+		})
+	}
+}
+
+func TestDoRefundEscrow(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name         string
+		id           int64
+		expectedCode validation.WalletErrorCode
+		expectErr    bool
+	}
+
+	tests := []testCase{
+		{
+			name:      "Successful Refund - Full amount to payer",
+			id:        1,
+			expectErr: false,
+		},
+		{
+			name:         "Failed Refund - Escrow already refunded",
+			id:           2,
+			expectedCode: validation.ERR_ESCROW_NOT_FUNDED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Refund - Escrow expired",
+			id:           3,
+			expectedCode: validation.ERR_ESCROW_EXPIRED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockEscrowStore{}
+			mock.initializeMockData()
+			s := &EscrowService{store: mock, config: &model.EscrowConfig{DefaultTTL: time.Hour}}
+
+			actual, _, err := s.DoRefundEscrow(context.Background(), nil, test.id)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.EscrowRefunded {
+				t.Errorf("expected status %s but got %s instead", model.EscrowRefunded, actual.Status)
+			}
+
+			if actual.RefundedAmount != actual.Amount || actual.ReleasedAmount != 0 {
+				t.Errorf("expected full refund of %d but got released %d and refunded %d instead", actual.Amount, actual.ReleasedAmount, actual.RefundedAmount)
+			}
+		})
+	}
+}
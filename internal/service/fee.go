@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const feeMaxRateBps = 10000
+
+type FeeStore interface {
+	UpsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error)
+	DebitWalletFee(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error)
+	RetireFeeRules(ctx context.Context, tx *sql.Tx, txnType model.TxnType) (int64, error)
+	InsertFeeRule(ctx context.Context, tx *sql.Tx, rule *model.FeeRule) error
+	FetchFeeRules(ctx context.Context, txnType string) ([]model.FeeRule, error)
+	FetchApplicableFeeRule(ctx context.Context, tx *sql.Tx, txnType model.TxnType, currency string, amount int64) (*model.FeeRule, error)
+}
+
+type FeeService struct {
+	store   FeeStore
+	journal *JournalService
+}
+
+func NewFeeService(store FeeStore, journal *JournalService) *FeeService {
+	logger.Info("Initializing FeeService")
+	return &FeeService{store: store, journal: journal}
+}
+
+func (s *FeeService) DoLoadFeeSchedule(ctx context.Context, tx *sql.Tx, payload *request.FeeScheduleLoadPayload) ([]model.FeeRule, *validation.WalletError) {
+	fnName := "FeeService.DoLoadFeeSchedule"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("txnType", payload.TxnType), zap.Int("count", len(payload.Rules)))
+
+	if !model.IsFeeTxnTypeValid(payload.TxnType) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FEE_RULE_VALIDATION_FAILED,
+			Message:   "Fee schedules are only supported for withdraw, transfer and overdraft",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("invalid fee transaction type %q", payload.TxnType),
+		}
+	}
+	txnType := model.TxnType(payload.TxnType)
+
+	rules := []model.FeeRule{}
+	for i, p := range payload.Rules {
+		rule, err := buildFeeRule(txnType, p)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_FEE_RULE_VALIDATION_FAILED,
+				Message:   "Fee rule validation failed",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Int("index", i),
+					zap.Any("rule", p),
+				},
+			}
+		}
+		for j := range rules {
+			if feeRulesOverlap(&rules[j], rule) {
+				return nil, &validation.WalletError{
+					Name:      fnName,
+					Code:      validation.ERR_FEE_RULE_VALIDATION_FAILED,
+					Message:   "Fee rule amount bands overlap",
+					Timestamp: time.Now().UTC(),
+					Err:       fmt.Errorf("rule %d overlaps rule %d", i, j),
+					Context: []zap.Field{
+						zap.Int("index", i),
+						zap.Any("rule", p),
+					},
+				}
+			}
+		}
+		rules = append(rules, *rule)
+	}
+
+	retired, err := s.store.RetireFeeRules(ctx, tx, txnType)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_INSERT_FEE_RULE_FAILED,
+			Message:   "Failed to retire active fee rules",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("txnType", string(txnType)),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Active fee rules retired", fnName), zap.Int64("count", retired))
+
+	for i := range rules {
+		if err := s.store.InsertFeeRule(ctx, tx, &rules[i]); err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_INSERT_FEE_RULE_FAILED,
+				Message:   "Failed to insert fee rule",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Any("rule", rules[i]),
+				},
+			}
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Fee schedule loaded", fnName), zap.Any("rules", rules))
+	return rules, nil
+}
+
+func (s *FeeService) DoFetchFeeRules(ctx context.Context, txnType string) ([]model.FeeRule, *validation.WalletError) {
+	fnName := "FeeService.DoFetchFeeRules"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("txnType", txnType))
+
+	if !model.IsFeeTxnTypeValid(txnType) {
+		txnType = ""
+	}
+
+	rules, err := s.store.FetchFeeRules(ctx, txnType)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_FEE_RULE_FAILED,
+			Message:   "Failed to fetch fee rules",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("txnType", txnType),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Fee rules fetched", fnName), zap.Int("count", len(rules)))
+	return rules, nil
+}
+
+func (s *FeeService) DoPostedCharge(ctx context.Context, tx *sql.Tx, txnType model.TxnType, wallet *model.Wallet, amount int64) (*model.FeeBreakdown, *model.Wallet, *model.JournalEntry, *validation.WalletError) {
+	fnName := "FeeService.DoPostedCharge"
+
+	fee, debited, feeWallet, appErr := s.DoChargeFee(ctx, tx, txnType, wallet, amount)
+	if appErr != nil {
+		return nil, nil, nil, appErr
+	}
+	if fee == nil || fee.Fee == 0 {
+		return fee, debited, nil, nil
+	}
+
+	entry, appErr := s.journal.PostFee(ctx, tx, debited, feeWallet, fee.Fee)
+	if appErr != nil {
+		return nil, nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+	return fee, debited, entry, nil
+}
+
+func (s *FeeService) DoChargeFee(ctx context.Context, tx *sql.Tx, txnType model.TxnType, wallet *model.Wallet, amount int64) (*model.FeeBreakdown, *model.Wallet, *model.Wallet, *validation.WalletError) {
+	fnName := "FeeService.DoChargeFee"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("txnType", string(txnType)), zap.Any("wallet", wallet), zap.Int64("amount", amount))
+
+	rule, err := s.store.FetchApplicableFeeRule(ctx, tx, txnType, wallet.Currency, amount)
+	if err != nil {
+		return nil, nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_FEE_RULE_FAILED,
+			Message:   "Failed to fetch applicable fee rule",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("txnType", string(txnType)),
+				zap.String("currency", wallet.Currency),
+				zap.Int64("amount", amount),
+			},
+		}
+	}
+	if rule == nil {
+		logger.Info(fmt.Sprintf("%s - No fee rule applies", fnName))
+		return nil, wallet, nil, nil
+	}
+
+	breakdown := calculateFee(rule, wallet.Currency, amount)
+	logger.Info(fmt.Sprintf("%s - Fee calculated", fnName), zap.Any("breakdown", breakdown))
+	if breakdown.Fee == 0 {
+		return breakdown, wallet, nil, nil
+	}
+
+	if appErr := checkWalletStatus(fnName, wallet, true); appErr != nil {
+		return nil, nil, nil, appErr
+	}
+
+	if wallet.AvailableBalance < breakdown.Fee {
+		return nil, nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+			Message:   "Insufficient balance to cover fee",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("available %d is less than fee %d", wallet.AvailableBalance, breakdown.Fee),
+			Context: []zap.Field{
+				zap.String("username", wallet.Username),
+				zap.Int64("available", wallet.AvailableBalance),
+				zap.Int64("fee", breakdown.Fee),
+			},
+		}
+	}
+
+	debited, err := s.store.DebitWalletFee(ctx, tx, wallet.Username, wallet.Currency, wallet.Pocket, breakdown.Fee)
+	if err != nil || debited == nil {
+		return nil, nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CHARGE_FEE_FAILED,
+			Message:   "Failed to debit fee from wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", wallet.Username),
+				zap.Int64("fee", breakdown.Fee),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Fee debited from wallet", fnName), zap.Any("wallet", debited))
+
+	feeWallet, err := s.store.UpsertWallet(ctx, tx, model.FeeWallet, wallet.Currency, model.DefaultPocket, breakdown.Fee)
+	if err != nil {
+		return nil, nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CHARGE_FEE_FAILED,
+			Message:   "Failed to credit fee wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", wallet.Currency),
+				zap.Int64("fee", breakdown.Fee),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Fee credited to fee wallet", fnName), zap.Any("wallet", feeWallet))
+	return breakdown, debited, feeWallet, nil
+}
+
+func calculateFee(rule *model.FeeRule, currency string, amount int64) *model.FeeBreakdown {
+	percentage := amount * int64(rule.RateBps) / feeMaxRateBps
+	fee := rule.FlatFee + percentage
+	if rule.MinFee != nil && fee < *rule.MinFee {
+		fee = *rule.MinFee
+	}
+	if rule.MaxFee != nil && fee > *rule.MaxFee {
+		fee = *rule.MaxFee
+	}
+
+	return &model.FeeBreakdown{
+		RuleID:        rule.ID,
+		TxnType:       rule.TxnType,
+		Currency:      currency,
+		Amount:        amount,
+		FlatFee:       rule.FlatFee,
+		RateBps:       rule.RateBps,
+		PercentageFee: percentage,
+		MinFee:        rule.MinFee,
+		MaxFee:        rule.MaxFee,
+		Fee:           fee,
+	}
+}
+
+func buildFeeRule(txnType model.TxnType, p request.FeeRulePayload) (*model.FeeRule, error) {
+	rule := &model.FeeRule{TxnType: txnType}
+
+	if p.Currency != nil {
+		currency, err := validation.SanitizeAndValidateCurrency(*p.Currency)
+		if err != nil {
+			return nil, err
+		}
+		rule.Currency = &currency.Code
+	}
+
+	if p.MinAmount != nil {
+		if *p.MinAmount < 0 {
+			return nil, fmt.Errorf("minAmount cannot be negative")
+		}
+		rule.MinAmount = *p.MinAmount
+	}
+	if p.MaxAmount != nil {
+		if *p.MaxAmount <= rule.MinAmount {
+			return nil, fmt.Errorf("maxAmount must be greater than minAmount")
+		}
+		rule.MaxAmount = p.MaxAmount
+	}
+
+	if p.FlatFee != nil {
+		if *p.FlatFee < 0 {
+			return nil, fmt.Errorf("flatFee cannot be negative")
+		}
+		rule.FlatFee = *p.FlatFee
+	}
+	if p.RateBps != nil {
+		if *p.RateBps < 0 || *p.RateBps > feeMaxRateBps {
+			return nil, fmt.Errorf("rateBps must be between 0 and %d", feeMaxRateBps)
+		}
+		rule.RateBps = *p.RateBps
+	}
+
+	if p.MinFee != nil {
+		if *p.MinFee < 0 {
+			return nil, fmt.Errorf("minFee cannot be negative")
+		}
+		rule.MinFee = p.MinFee
+	}
+	if p.MaxFee != nil {
+		if *p.MaxFee < 0 {
+			return nil, fmt.Errorf("maxFee cannot be negative")
+		}
+		if rule.MinFee != nil && *rule.MinFee > *p.MaxFee {
+			return nil, fmt.Errorf("minFee cannot exceed maxFee")
+		}
+		rule.MaxFee = p.MaxFee
+	}
+	return rule, nil
+}
+
+func feeRulesOverlap(a *model.FeeRule, b *model.FeeRule) bool {
+	if (a.Currency == nil) != (b.Currency == nil) {
+		return false
+	}
+	if a.Currency != nil && *a.Currency != *b.Currency {
+		return false
+	}
+	aBelowB := a.MaxAmount != nil && *a.MaxAmount <= b.MinAmount
+	bBelowA := b.MaxAmount != nil && *b.MaxAmount <= a.MinAmount
+	return !aBelowB && !bBelowA
+}
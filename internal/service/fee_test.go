@@ -0,0 +1,503 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockFeeStore struct {
+	wallets  map[string]*model.Wallet
+	rules    []model.FeeRule
+	retired  int64
+	postings map[int64]int64
+	entries  []model.JournalEntry
+}
+
+func (m *mockFeeStore) initializeMockData() {
+	m.wallets = map[string]*model.Wallet{
+		"JUAN|USD": {ID: 1, Username: "JUAN", Currency: "USD", Status: model.WalletActive, Balance: 1000, AvailableBalance: 1000},
+		"JUAN|EUR": {ID: 2, Username: "JUAN", Currency: "EUR", Status: model.WalletActive, Balance: 1000, AvailableBalance: 1000},
+		"POOR|USD": {ID: 3, Username: "POOR", Currency: "USD", Status: model.WalletActive, Balance: 5, AvailableBalance: 5},
+		"COLD|USD": {ID: 4, Username: "COLD", Currency: "USD", Status: model.WalletFrozenAll, Balance: 1000, AvailableBalance: 1000},
+		"GONE|USD": {ID: 5, Username: "GONE", Currency: "USD", Status: model.WalletClosed, Balance: 1000, AvailableBalance: 1000},
+	}
+	m.rules = []model.FeeRule{
+		{ID: 1, TxnType: model.TypeWithdraw, Currency: utils.Ptr("USD"), MinAmount: 0, MaxAmount: utils.Ptr(int64(1000)), FlatFee: 10},
+		{ID: 2, TxnType: model.TypeWithdraw, Currency: utils.Ptr("USD"), MinAmount: 1000, RateBps: 150, MinFee: utils.Ptr(int64(20)), MaxFee: utils.Ptr(int64(50))},
+		{ID: 3, TxnType: model.TypeWithdraw, RateBps: 100},
+		{ID: 4, TxnType: model.TypeTransfer, Currency: utils.Ptr("USD")},
+	}
+	m.postings = map[int64]int64{}
+	for _, wallet := range m.wallets {
+		m.postings[wallet.ID] = wallet.Balance
+	}
+}
+
+func (m *mockFeeStore) InsertJournalEntry(ctx context.Context, tx *sql.Tx, entry *model.JournalEntry) error {
+	entry.ID = int64(len(m.entries) + 1)
+	for _, posting := range entry.Postings {
+		if posting.WalletID == nil {
+			continue
+		}
+		switch posting.Direction {
+		case model.DirectionCredit:
+			m.postings[*posting.WalletID] += posting.Amount
+		case model.DirectionDebit:
+			m.postings[*posting.WalletID] -= posting.Amount
+		}
+	}
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *mockFeeStore) FetchWalletLedgerBalance(ctx context.Context, tx *sql.Tx, walletID int64) (int64, int64, error) {
+	for _, w := range m.wallets {
+		if w.ID == walletID {
+			return w.Balance, m.postings[walletID], nil
+		}
+	}
+	return 0, 0, fmt.Errorf("Test Ledger - No wallet %d", walletID)
+}
+
+func (m *mockFeeStore) UpsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
+	key := username + "|" + currency
+	wallet, ok := m.wallets[key]
+	if !ok {
+		wallet = &model.Wallet{ID: int64(len(m.wallets) + 1), Username: username, Currency: currency}
+		m.wallets[key] = wallet
+	}
+	wallet.Balance += amount
+	wallet.AvailableBalance += amount
+	return wallet, nil
+}
+
+func (m *mockFeeStore) DebitWalletFee(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username+"|"+currency]
+	if !ok || wallet.Status != model.WalletActive || wallet.AvailableBalance < amount {
+		return nil, nil
+	}
+	wallet.Balance -= amount
+	wallet.AvailableBalance -= amount
+	return wallet, nil
+}
+
+func (m *mockFeeStore) RetireFeeRules(ctx context.Context, tx *sql.Tx, txnType model.TxnType) (int64, error) {
+	active := []model.FeeRule{}
+	for _, rule := range m.rules {
+		if rule.TxnType == txnType {
+			m.retired++
+			continue
+		}
+		active = append(active, rule)
+	}
+	m.rules = active
+	return m.retired, nil
+}
+
+func (m *mockFeeStore) InsertFeeRule(ctx context.Context, tx *sql.Tx, rule *model.FeeRule) error {
+	rule.ID = int64(len(m.rules) + 1)
+	m.rules = append(m.rules, *rule)
+	return nil
+}
+
+func (m *mockFeeStore) FetchFeeRules(ctx context.Context, txnType string) ([]model.FeeRule, error) {
+	return m.rules, nil
+}
+
+func (m *mockFeeStore) FetchApplicableFeeRule(ctx context.Context, tx *sql.Tx, txnType model.TxnType, currency string, amount int64) (*model.FeeRule, error) {
+	var match *model.FeeRule
+	for i, rule := range m.rules {
+		if rule.TxnType != txnType || (rule.Currency != nil && *rule.Currency != currency) {
+			continue
+		}
+		if amount < rule.MinAmount || (rule.MaxAmount != nil && amount >= *rule.MaxAmount) {
+			continue
+		}
+		if match == nil || (match.Currency == nil && rule.Currency != nil) || ((match.Currency == nil) == (rule.Currency == nil) && rule.MinAmount > match.MinAmount) {
+			match = &m.rules[i]
+		}
+	}
+	if match == nil {
+		return nil, nil
+	}
+	copied := *match
+	return &copied, nil
+}
+
+func TestDoChargeFee(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name              string
+		walletKey         string
+		txnType           model.TxnType
+		amount            int64
+		expectedRuleID    int64
+		expectedFee       int64
+		expectedBalance   int64
+		expectedFeeWallet int64
+		expectNoRule      bool
+		expectedCode      validation.WalletErrorCode
+		expectErr         bool
+	}
+
+	tests := []testCase{
+		{
+			name:              "Successful Fee - Flat fee from lower tier",
+			walletKey:         "JUAN|USD",
+			txnType:           model.TypeWithdraw,
+			amount:            500,
+			expectedRuleID:    1,
+			expectedFee:       10,
+			expectedBalance:   990,
+			expectedFeeWallet: 10,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Fee - Percentage raised to minimum fee",
+			walletKey:         "JUAN|USD",
+			txnType:           model.TypeWithdraw,
+			amount:            1000,
+			expectedRuleID:    2,
+			expectedFee:       20,
+			expectedBalance:   980,
+			expectedFeeWallet: 20,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Fee - Percentage capped at maximum fee",
+			walletKey:         "JUAN|USD",
+			txnType:           model.TypeWithdraw,
+			amount:            5000,
+			expectedRuleID:    2,
+			expectedFee:       50,
+			expectedBalance:   950,
+			expectedFeeWallet: 50,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Fee - Falls back to any-currency rule",
+			walletKey:         "JUAN|EUR",
+			txnType:           model.TypeWithdraw,
+			amount:            550,
+			expectedRuleID:    3,
+			expectedFee:       5,
+			expectedBalance:   995,
+			expectedFeeWallet: 5,
+			expectErr:         false,
+		},
+		{
+			name:            "Successful Fee - Zero fee rule leaves wallet untouched",
+			walletKey:       "JUAN|USD",
+			txnType:         model.TypeTransfer,
+			amount:          500,
+			expectedRuleID:  4,
+			expectedFee:     0,
+			expectedBalance: 1000,
+			expectErr:       false,
+		},
+		{
+			name:            "Successful Fee - No applicable rule",
+			walletKey:       "JUAN|EUR",
+			txnType:         model.TypeTransfer,
+			amount:          500,
+			expectedBalance: 1000,
+			expectNoRule:    true,
+			expectErr:       false,
+		},
+		{
+			name:         "Failed Fee - Insufficient balance to cover fee",
+			walletKey:    "POOR|USD",
+			txnType:      model.TypeWithdraw,
+			amount:       500,
+			expectedCode: validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Fee - Frozen wallet is not charged",
+			walletKey:    "COLD|USD",
+			txnType:      model.TypeWithdraw,
+			amount:       500,
+			expectedCode: validation.ERR_WALLET_FROZEN,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Fee - Closed wallet is not charged",
+			walletKey:    "GONE|USD",
+			txnType:      model.TypeWithdraw,
+			amount:       500,
+			expectedCode: validation.ERR_WALLET_CLOSED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockFeeStore{}
+			mock.initializeMockData()
+			s := &FeeService{store: mock}
+
+			breakdown, wallet, feeWallet, err := s.DoChargeFee(context.Background(), nil, test.txnType, mock.wallets[test.walletKey], test.amount)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if wallet.Balance != test.expectedBalance {
+				t.Errorf("expected balance %d but got %d instead", test.expectedBalance, wallet.Balance)
+			}
+
+			if test.expectNoRule {
+				if breakdown != nil {
+					t.Errorf("expected no fee breakdown but got %+v", breakdown)
+				}
+				return
+			}
+
+			if breakdown.RuleID != test.expectedRuleID {
+				t.Errorf("expected rule %d but got %d instead", test.expectedRuleID, breakdown.RuleID)
+			}
+
+			if breakdown.Fee != test.expectedFee {
+				t.Errorf("expected fee %d but got %d instead", test.expectedFee, breakdown.Fee)
+			}
+
+			if test.expectedFee == 0 {
+				if feeWallet != nil {
+					t.Errorf("expected fee wallet to be untouched but got %+v", feeWallet)
+				}
+				return
+			}
+
+			if feeWallet.Balance != test.expectedFeeWallet {
+				t.Errorf("expected fee wallet balance %d but got %d instead", test.expectedFeeWallet, feeWallet.Balance)
+			}
+		})
+	}
+}
+
+func TestDoPostedCharge(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name            string
+		walletKey       string
+		txnType         model.TxnType
+		amount          int64
+		expectedBalance int64
+		expectedEntries int
+		expectedCode    validation.WalletErrorCode
+		expectErr       bool
+	}
+
+	tests := []testCase{
+		{
+			name:            "Successful Charge - Fee posted in its own entry",
+			walletKey:       "JUAN|USD",
+			txnType:         model.TypeWithdraw,
+			amount:          500,
+			expectedBalance: 990,
+			expectedEntries: 1,
+			expectErr:       false,
+		},
+		{
+			name:            "Successful Charge - Zero fee posts nothing",
+			walletKey:       "JUAN|USD",
+			txnType:         model.TypeTransfer,
+			amount:          500,
+			expectedBalance: 1000,
+			expectedEntries: 0,
+			expectErr:       false,
+		},
+		{
+			name:            "Successful Charge - No applicable rule posts nothing",
+			walletKey:       "JUAN|EUR",
+			txnType:         model.TypeTransfer,
+			amount:          500,
+			expectedBalance: 1000,
+			expectedEntries: 0,
+			expectErr:       false,
+		},
+		{
+			name:         "Failed Charge - Insufficient balance posts nothing",
+			walletKey:    "POOR|USD",
+			txnType:      model.TypeWithdraw,
+			amount:       500,
+			expectedCode: validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockFeeStore{}
+			mock.initializeMockData()
+			s := &FeeService{store: mock, journal: &JournalService{store: mock}}
+
+			_, wallet, entry, err := s.DoPostedCharge(context.Background(), nil, test.txnType, mock.wallets[test.walletKey], test.amount)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(mock.entries) != test.expectedEntries {
+				t.Errorf("expected %d journal entries but got %d instead", test.expectedEntries, len(mock.entries))
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if wallet.Balance != test.expectedBalance {
+				t.Errorf("expected balance %d but got %d instead", test.expectedBalance, wallet.Balance)
+			}
+
+			if (entry != nil) != (test.expectedEntries > 0) {
+				t.Errorf("expected entry only when a fee is posted but got %+v", entry)
+			}
+
+			if entry != nil && entry.EntryType != model.TypeFee {
+				t.Errorf("expected entry type %s but got %s instead", model.TypeFee, entry.EntryType)
+			}
+		})
+	}
+}
+
+func TestDoLoadFeeSchedule(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name            string
+		payload         *request.FeeScheduleLoadPayload
+		expectedCount   int
+		expectedRetired int64
+		expectedCode    validation.WalletErrorCode
+		expectErr       bool
+	}
+
+	tests := []testCase{
+		{
+			name: "Successful Load - Replaces withdraw schedule with tiers",
+			payload: &request.FeeScheduleLoadPayload{TxnType: "withdraw", Rules: []request.FeeRulePayload{
+				{Currency: utils.Ptr("usd"), MaxAmount: utils.Ptr(int64(1000)), FlatFee: utils.Ptr(int64(10))},
+				{Currency: utils.Ptr("usd"), MinAmount: utils.Ptr(int64(1000)), RateBps: utils.Ptr(100)},
+				{MaxAmount: utils.Ptr(int64(1000)), FlatFee: utils.Ptr(int64(15))},
+			}},
+			expectedCount:   3,
+			expectedRetired: 3,
+			expectErr:       false,
+		},
+		{
+			name:            "Successful Load - Empty schedule clears fees",
+			payload:         &request.FeeScheduleLoadPayload{TxnType: "transfer"},
+			expectedCount:   0,
+			expectedRetired: 1,
+			expectErr:       false,
+		},
+		{
+			name:         "Failed Load - Unsupported transaction type",
+			payload:      &request.FeeScheduleLoadPayload{TxnType: "deposit"},
+			expectedCode: validation.ERR_FEE_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Load - Overlapping amount bands",
+			payload: &request.FeeScheduleLoadPayload{TxnType: "withdraw", Rules: []request.FeeRulePayload{
+				{MaxAmount: utils.Ptr(int64(1000)), FlatFee: utils.Ptr(int64(10))},
+				{MinAmount: utils.Ptr(int64(500)), RateBps: utils.Ptr(100)},
+			}},
+			expectedCode: validation.ERR_FEE_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Load - Rate above 100 percent",
+			payload: &request.FeeScheduleLoadPayload{TxnType: "withdraw", Rules: []request.FeeRulePayload{
+				{RateBps: utils.Ptr(10001)},
+			}},
+			expectedCode: validation.ERR_FEE_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Load - Minimum fee above maximum fee",
+			payload: &request.FeeScheduleLoadPayload{TxnType: "transfer", Rules: []request.FeeRulePayload{
+				{RateBps: utils.Ptr(100), MinFee: utils.Ptr(int64(50)), MaxFee: utils.Ptr(int64(20))},
+			}},
+			expectedCode: validation.ERR_FEE_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockFeeStore{}
+			mock.initializeMockData()
+			s := &FeeService{store: mock}
+
+			actual, err := s.DoLoadFeeSchedule(context.Background(), nil, test.payload)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				if mock.retired != 0 {
+					t.Errorf("expected no rules to be retired but got %d", mock.retired)
+				}
+				return
+			}
+
+			if len(actual) != test.expectedCount {
+				t.Errorf("expected %d rules but got %d instead", test.expectedCount, len(actual))
+			}
+
+			if mock.retired != test.expectedRetired {
+				t.Errorf("expected %d retired rules but got %d instead", test.expectedRetired, mock.retired)
+			}
+
+			for _, rule := range actual {
+				if rule.TxnType != model.TxnType(test.payload.TxnType) {
+					t.Errorf("expected txn type %s but got %s instead", test.payload.TxnType, rule.TxnType)
+				}
+				if rule.Currency != nil && *rule.Currency != "USD" {
+					t.Errorf("expected sanitized currency USD but got %s instead", *rule.Currency)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,476 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	fxRateScale    = 10
+	fxMaxSpreadBps = 9999
+)
+
+type FXStore interface {
+	InsertFXRate(ctx context.Context, tx *sql.Tx, rate *model.FXRate) error
+	FetchFXRates(ctx context.Context, baseCurrency string, quoteCurrency string) ([]model.FXRate, error)
+	FetchActiveFXRate(ctx context.Context, baseCurrency string, quoteCurrency string, at time.Time) (*model.FXRate, error)
+	InsertFXQuote(ctx context.Context, quote *model.FXQuote) error
+	FetchFXQuoteForUpdate(ctx context.Context, tx *sql.Tx, id string) (*model.FXQuote, error)
+	MarkFXQuoteUsed(ctx context.Context, tx *sql.Tx, id string, usedAt time.Time) error
+}
+
+type FXService struct {
+	store  FXStore
+	config *model.FXConfig
+}
+
+func NewFXService(store FXStore, config *model.FXConfig) *FXService {
+	logger.Debug("Initializing FXService")
+	return &FXService{store: store, config: config}
+}
+
+func (s *FXService) DoLoadFXRates(ctx context.Context, tx *sql.Tx, payload *request.FXRateLoadPayload) ([]model.FXRate, *validation.WalletError) {
+	fnName := "FXService.DoLoadFXRates"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int("count", len(payload.Rates)))
+
+	if len(payload.Rates) == 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FX_RATE_VALIDATION_FAILED,
+			Message:   "No FX rates to load",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("rates cannot be empty"),
+		}
+	}
+
+	rates := []model.FXRate{}
+	for i, p := range payload.Rates {
+		rate, err := s.buildFXRate(p)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_FX_RATE_VALIDATION_FAILED,
+				Message:   "FX rate validation failed",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Int("index", i),
+					zap.Any("rate", p),
+				},
+			}
+		}
+
+		if err := s.store.InsertFXRate(ctx, tx, rate); err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_INSERT_FX_RATE_FAILED,
+				Message:   "Failed to insert FX rate",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Any("rate", rate),
+				},
+			}
+		}
+		rates = append(rates, *rate)
+	}
+	logger.Info(fmt.Sprintf("%s - FX rates loaded", fnName), zap.Any("rates", rates))
+	return rates, nil
+}
+
+func (s *FXService) DoFetchFXRates(ctx context.Context, baseCurrency string, quoteCurrency string) ([]model.FXRate, *validation.WalletError) {
+	fnName := "FXService.DoFetchFXRates"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("baseCurrency", baseCurrency), zap.String("quoteCurrency", quoteCurrency))
+
+	base := validation.SanitizeCurrencyWithoutError(baseCurrency)
+	quote := validation.SanitizeCurrencyWithoutError(quoteCurrency)
+
+	rates, err := s.store.FetchFXRates(ctx, base, quote)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_FX_RATE_FAILED,
+			Message:   "Failed to fetch FX rates",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("baseCurrency", base),
+				zap.String("quoteCurrency", quote),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - FX rates fetched", fnName), zap.Int("count", len(rates)))
+	return rates, nil
+}
+
+func (s *FXService) DoCreateQuote(ctx context.Context, payload *request.FXQuotePayload) (*model.FXQuote, *validation.WalletError) {
+	fnName := "FXService.DoCreateQuote"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, err := validation.SanitizeAndValidateUsername(payload.Username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize username",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", payload.Username),
+			},
+		}
+	}
+
+	base, quote, err := validateCurrencyPair(payload.BaseCurrency, payload.QuoteCurrency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("baseCurrency", payload.BaseCurrency),
+				zap.String("quoteCurrency", payload.QuoteCurrency),
+			},
+		}
+	}
+
+	if err := validation.ValidateAmount(payload.Amount, base); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("amount", payload.Amount),
+				zap.String("currency", base.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Quote request validated", fnName), zap.String("username", username))
+
+	now := time.Now().UTC()
+	rate, err := s.store.FetchActiveFXRate(ctx, base.Code, quote.Code, now)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_FX_RATE_FAILED,
+			Message:   "Failed to fetch FX rate",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("baseCurrency", base.Code),
+				zap.String("quoteCurrency", quote.Code),
+			},
+		}
+	}
+	if rate == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FX_RATE_NOT_FOUND,
+			Message:   "No active FX rate for currency pair",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("baseCurrency", base.Code),
+				zap.String("quoteCurrency", quote.Code),
+			},
+		}
+	}
+
+	quoteAmount, effectiveRate, err := convertAmount(payload.Amount, rate.Rate, rate.SpreadBps, base, quote)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FX_CONVERSION_FAILED,
+			Message:   "Failed to convert amount",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("rate", rate),
+				zap.Int64("amount", payload.Amount),
+			},
+		}
+	}
+
+	id, err := newQuoteID()
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_FX_QUOTE_FAILED,
+			Message:   "Failed to generate quote ID",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+
+	fxQuote := &model.FXQuote{
+		ID:            id,
+		RateID:        rate.ID,
+		Username:      username,
+		BaseCurrency:  base.Code,
+		QuoteCurrency: quote.Code,
+		BaseAmount:    payload.Amount,
+		QuoteAmount:   quoteAmount,
+		Rate:          effectiveRate,
+		ExpiresAt:     now.Add(s.config.QuoteTTL),
+		CreatedAt:     now,
+	}
+	if err := s.store.InsertFXQuote(ctx, fxQuote); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_FX_QUOTE_FAILED,
+			Message:   "Failed to store quote",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("quote", fxQuote),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Quote created", fnName), zap.Any("quote", fxQuote))
+	return fxQuote, nil
+}
+
+func (s *FXService) DoConsumeQuote(ctx context.Context, tx *sql.Tx, quoteID string, username string, baseCurrency string, quoteCurrency string, amount int64) (*model.FXQuote, *validation.WalletError) {
+	fnName := "FXService.DoConsumeQuote"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName),
+		zap.String("quoteID", quoteID),
+		zap.String("username", username),
+		zap.String("baseCurrency", baseCurrency),
+		zap.String("quoteCurrency", quoteCurrency),
+		zap.Int64("amount", amount),
+	)
+
+	quote, err := s.store.FetchFXQuoteForUpdate(ctx, tx, quoteID)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_FX_QUOTE_FAILED,
+			Message:   "Failed to fetch quote",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("quoteID", quoteID),
+			},
+		}
+	}
+	if quote == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FX_QUOTE_NOT_FOUND,
+			Message:   "Quote does not exist",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("quoteID", quoteID),
+			},
+		}
+	}
+
+	now := time.Now().UTC()
+	if appErr := checkQuoteUsable(fnName, quote, now, username, baseCurrency, quoteCurrency, amount); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.store.MarkFXQuoteUsed(ctx, tx, quote.ID, now); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FX_QUOTE_ALREADY_USED,
+			Message:   "Quote has already been used",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("quoteID", quote.ID),
+			},
+		}
+	}
+	quote.UsedAt = &now
+	logger.Info(fmt.Sprintf("%s - Quote consumed", fnName), zap.Any("quote", quote))
+	return quote, nil
+}
+
+func checkQuoteUsable(fnName string, quote *model.FXQuote, now time.Time, username string, baseCurrency string, quoteCurrency string, amount int64) *validation.WalletError {
+	if quote.UsedAt != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FX_QUOTE_ALREADY_USED,
+			Message:   "Quote has already been used",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("quoteID", quote.ID),
+				zap.Timep("usedAt", quote.UsedAt),
+			},
+		}
+	}
+
+	if !now.Before(quote.ExpiresAt) {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FX_QUOTE_EXPIRED,
+			Message:   "Quote has expired",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("quoteID", quote.ID),
+				zap.Time("expiresAt", quote.ExpiresAt),
+			},
+		}
+	}
+
+	sanitizedUser, _ := validation.SanitizeAndValidateUsername(username)
+	base, _ := validation.SanitizeAndValidateCurrency(baseCurrency)
+	target, _ := validation.SanitizeAndValidateCurrency(quoteCurrency)
+	if quoteCurrency == "" {
+		target.Code = quote.QuoteCurrency
+	}
+	if quote.Username != sanitizedUser || quote.BaseCurrency != base.Code || quote.QuoteCurrency != target.Code || quote.BaseAmount != amount {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FX_QUOTE_MISMATCH,
+			Message:   "Quote does not match transfer",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.Any("quote", quote),
+				zap.String("username", sanitizedUser),
+				zap.String("baseCurrency", base.Code),
+				zap.String("quoteCurrency", target.Code),
+				zap.Int64("amount", amount),
+			},
+		}
+	}
+	return nil
+}
+
+func (s *FXService) buildFXRate(p request.FXRatePayload) (*model.FXRate, error) {
+	base, quote, err := validateCurrencyPair(p.BaseCurrency, p.QuoteCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := parseFXRate(p.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	spread := s.config.DefaultSpreadBps
+	if p.SpreadBps != nil {
+		spread = *p.SpreadBps
+	}
+	if spread < 0 || spread > fxMaxSpreadBps {
+		return nil, fmt.Errorf("spread must be between 0 and %d bps", fxMaxSpreadBps)
+	}
+
+	validFrom := time.Now().UTC()
+	if p.ValidFrom != nil {
+		validFrom = p.ValidFrom.UTC()
+	}
+	var validTo *time.Time
+	if p.ValidTo != nil {
+		to := p.ValidTo.UTC()
+		if !to.After(validFrom) {
+			return nil, fmt.Errorf("validTo must be after validFrom")
+		}
+		validTo = &to
+	}
+
+	return &model.FXRate{
+		BaseCurrency:  base.Code,
+		QuoteCurrency: quote.Code,
+		Rate:          rate.FloatString(fxRateScale),
+		SpreadBps:     spread,
+		ValidFrom:     validFrom,
+		ValidTo:       validTo,
+	}, nil
+}
+
+func validateCurrencyPair(baseCurrency string, quoteCurrency string) (model.Currency, model.Currency, error) {
+	if strings.TrimSpace(baseCurrency) == "" || strings.TrimSpace(quoteCurrency) == "" {
+		return model.Currency{}, model.Currency{}, fmt.Errorf("base and quote currency are required")
+	}
+	base, err := validation.SanitizeAndValidateCurrency(baseCurrency)
+	if err != nil {
+		return model.Currency{}, model.Currency{}, err
+	}
+	quote, err := validation.SanitizeAndValidateCurrency(quoteCurrency)
+	if err != nil {
+		return model.Currency{}, model.Currency{}, err
+	}
+	if base.Code == quote.Code {
+		return model.Currency{}, model.Currency{}, fmt.Errorf("base and quote currency must differ")
+	}
+	return base, quote, nil
+}
+
+func parseFXRate(raw string) (*big.Rat, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("rate cannot be empty")
+	}
+	if i := strings.IndexByte(raw, '.'); i >= 0 && len(raw)-i-1 > fxRateScale {
+		return nil, fmt.Errorf("rate cannot have more than %d decimal places", fxRateScale)
+	}
+	rate, ok := new(big.Rat).SetString(raw)
+	if !ok || strings.ContainsAny(raw, "eE/") {
+		return nil, fmt.Errorf("rate %q is not a valid decimal", raw)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("rate must be greater than 0")
+	}
+	return rate, nil
+}
+
+func convertAmount(amount int64, rawRate string, spreadBps int, from model.Currency, to model.Currency) (int64, string, error) {
+	rate, err := parseFXRate(rawRate)
+	if err != nil {
+		return 0, "", err
+	}
+
+	effective := new(big.Rat).Mul(rate, big.NewRat(int64(10000-spreadBps), 10000))
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), effective)
+	exponentDiff := to.Exponent - from.Exponent
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(exponentDiff))), nil)
+	if exponentDiff >= 0 {
+		converted.Mul(converted, new(big.Rat).SetInt(scale))
+	} else {
+		converted.Quo(converted, new(big.Rat).SetInt(scale))
+	}
+
+	quoteAmount := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !quoteAmount.IsInt64() || quoteAmount.Int64() <= 0 {
+		return 0, "", fmt.Errorf("converted amount %s %s is out of range", quoteAmount.String(), to.Code)
+	}
+	return quoteAmount.Int64(), effective.FloatString(fxRateScale), nil
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func newQuoteID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockFXStore struct {
+	quotes map[string]model.FXQuote
+}
+
+func (m *mockFXStore) initializeMockQuotes() {
+	now := time.Now().UTC()
+	used := now.Add(-time.Minute)
+	m.quotes = map[string]model.FXQuote{
+		"valid": {
+			ID:            "valid",
+			Username:      "JUAN",
+			BaseCurrency:  "USD",
+			QuoteCurrency: "EUR",
+			BaseAmount:    1000,
+			QuoteAmount:   920,
+			Rate:          "0.9200000000",
+			ExpiresAt:     now.Add(time.Minute),
+		},
+		"expired": {
+			ID:            "expired",
+			Username:      "JUAN",
+			BaseCurrency:  "USD",
+			QuoteCurrency: "EUR",
+			BaseAmount:    1000,
+			QuoteAmount:   920,
+			Rate:          "0.9200000000",
+			ExpiresAt:     now.Add(-time.Second),
+		},
+		"used": {
+			ID:            "used",
+			Username:      "JUAN",
+			BaseCurrency:  "USD",
+			QuoteCurrency: "EUR",
+			BaseAmount:    1000,
+			QuoteAmount:   920,
+			Rate:          "0.9200000000",
+			ExpiresAt:     now.Add(time.Minute),
+			UsedAt:        &used,
+		},
+	}
+}
+
+func (m *mockFXStore) InsertFXRate(ctx context.Context, tx *sql.Tx, rate *model.FXRate) error {
+	return nil
+}
+
+func (m *mockFXStore) FetchFXRates(ctx context.Context, baseCurrency string, quoteCurrency string) ([]model.FXRate, error) {
+	return []model.FXRate{}, nil
+}
+
+func (m *mockFXStore) FetchActiveFXRate(ctx context.Context, baseCurrency string, quoteCurrency string, at time.Time) (*model.FXRate, error) {
+	return nil, nil
+}
+
+func (m *mockFXStore) InsertFXQuote(ctx context.Context, quote *model.FXQuote) error {
+	m.quotes[quote.ID] = *quote
+	return nil
+}
+
+func (m *mockFXStore) FetchFXQuoteForUpdate(ctx context.Context, tx *sql.Tx, id string) (*model.FXQuote, error) {
+	q, ok := m.quotes[id]
+	if !ok {
+		return nil, nil
+	}
+	return &q, nil
+}
+
+func (m *mockFXStore) MarkFXQuoteUsed(ctx context.Context, tx *sql.Tx, id string, usedAt time.Time) error {
+	q, ok := m.quotes[id]
+	if !ok || q.UsedAt != nil {
+		return fmt.Errorf("quote %s was already used", id)
+	}
+	q.UsedAt = &usedAt
+	m.quotes[id] = q
+	return nil
+}
+
+func TestConvertAmount(t *testing.T) {
+	type testCase struct {
+		name           string
+		amount         int64
+		rate           string
+		spreadBps      int
+		from           string
+		to             string
+		expectedAmount int64
+		expectedRate   string
+		expectErr      bool
+	}
+
+	tests := []testCase{
+		{
+			name:           "Successful Conversion - Same exponent without spread",
+			amount:         1000,
+			rate:           "0.92",
+			from:           "USD",
+			to:             "EUR",
+			expectedAmount: 920,
+			expectedRate:   "0.9200000000",
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Conversion - Spread applied to rate",
+			amount:         10000,
+			rate:           "0.92",
+			spreadBps:      50,
+			from:           "USD",
+			to:             "EUR",
+			expectedAmount: 9154,
+			expectedRate:   "0.9154000000",
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Conversion - Into zero exponent currency",
+			amount:         1050,
+			rate:           "157.25",
+			from:           "USD",
+			to:             "JPY",
+			expectedAmount: 1651,
+			expectedRate:   "157.2500000000",
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Conversion - Into three exponent currency",
+			amount:         1000,
+			rate:           "0.307",
+			from:           "USD",
+			to:             "KWD",
+			expectedAmount: 3070,
+			expectedRate:   "0.3070000000",
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Conversion - Rounds down fractional minor units",
+			amount:         333,
+			rate:           "0.3333333333",
+			from:           "USD",
+			to:             "EUR",
+			expectedAmount: 110,
+			expectedRate:   "0.3333333333",
+			expectErr:      false,
+		},
+		{
+			name:      "Failed Conversion - Converted amount rounds to zero",
+			amount:    1,
+			rate:      "0.005",
+			from:      "JPY",
+			to:        "USD",
+			expectErr: true,
+		},
+		{
+			name:      "Failed Conversion - Rate with too many decimal places",
+			amount:    1000,
+			rate:      "0.12345678901",
+			from:      "USD",
+			to:        "EUR",
+			expectErr: true,
+		},
+		{
+			name:      "Failed Conversion - Negative rate",
+			amount:    1000,
+			rate:      "-1.5",
+			from:      "USD",
+			to:        "EUR",
+			expectErr: true,
+		},
+		{
+			name:      "Failed Conversion - Fraction notation rate",
+			amount:    1000,
+			rate:      "1/3",
+			from:      "USD",
+			to:        "EUR",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			from, _ := model.LookupCurrency(test.from)
+			to, _ := model.LookupCurrency(test.to)
+			actual, rate, err := convertAmount(test.amount, test.rate, test.spreadBps, from, to)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !test.expectErr && test.expectedAmount != actual {
+				t.Errorf("expected amount %d but got %d instead", test.expectedAmount, actual)
+			}
+
+			if !test.expectErr && test.expectedRate != rate {
+				t.Errorf("expected rate %s but got %s instead", test.expectedRate, rate)
+			}
+		})
+	}
+}
+
+func TestDoConsumeQuote(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name          string
+		quoteID       string
+		username      string
+		baseCurrency  string
+		quoteCurrency string
+		amount        int64
+		expectedCode  validation.WalletErrorCode
+		expectErr     bool
+	}
+
+	tests := []testCase{
+		{
+			name:          "Successful Consume - Matching quote",
+			quoteID:       "valid",
+			username:      "juan",
+			baseCurrency:  "usd",
+			quoteCurrency: "eur",
+			amount:        1000,
+			expectErr:     false,
+		},
+		{
+			name:          "Successful Consume - Quote currency taken from quote",
+			quoteID:       "valid",
+			username:      "JUAN",
+			baseCurrency:  "USD",
+			quoteCurrency: "",
+			amount:        1000,
+			expectErr:     false,
+		},
+		{
+			name:          "Failed Consume - Quote not found",
+			quoteID:       "missing",
+			username:      "JUAN",
+			baseCurrency:  "USD",
+			quoteCurrency: "EUR",
+			amount:        1000,
+			expectedCode:  validation.ERR_FX_QUOTE_NOT_FOUND,
+			expectErr:     true,
+		},
+		{
+			name:          "Failed Consume - Quote expired",
+			quoteID:       "expired",
+			username:      "JUAN",
+			baseCurrency:  "USD",
+			quoteCurrency: "EUR",
+			amount:        1000,
+			expectedCode:  validation.ERR_FX_QUOTE_EXPIRED,
+			expectErr:     true,
+		},
+		{
+			name:          "Failed Consume - Quote already used",
+			quoteID:       "used",
+			username:      "JUAN",
+			baseCurrency:  "USD",
+			quoteCurrency: "EUR",
+			amount:        1000,
+			expectedCode:  validation.ERR_FX_QUOTE_ALREADY_USED,
+			expectErr:     true,
+		},
+		{
+			name:          "Failed Consume - Different user",
+			quoteID:       "valid",
+			username:      "MARY",
+			baseCurrency:  "USD",
+			quoteCurrency: "EUR",
+			amount:        1000,
+			expectedCode:  validation.ERR_FX_QUOTE_MISMATCH,
+			expectErr:     true,
+		},
+		{
+			name:          "Failed Consume - Different amount",
+			quoteID:       "valid",
+			username:      "JUAN",
+			baseCurrency:  "USD",
+			quoteCurrency: "EUR",
+			amount:        1001,
+			expectedCode:  validation.ERR_FX_QUOTE_MISMATCH,
+			expectErr:     true,
+		},
+		{
+			name:          "Failed Consume - Different target currency",
+			quoteID:       "valid",
+			username:      "JUAN",
+			baseCurrency:  "USD",
+			quoteCurrency: "GBP",
+			amount:        1000,
+			expectedCode:  validation.ERR_FX_QUOTE_MISMATCH,
+			expectErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockFXStore{}
+			mock.initializeMockQuotes()
+			s := &FXService{store: mock, config: &model.FXConfig{QuoteTTL: time.Minute}}
+			quote, err := s.DoConsumeQuote(context.Background(), nil, test.quoteID, test.username, test.baseCurrency, test.quoteCurrency, test.amount)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && test.expectedCode != err.Code {
+				t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && quote != nil && quote.UsedAt == nil {
+				t.Errorf("expected quote to be marked as used")
+			}
+
+			if !test.expectErr && mock.quotes[test.quoteID].UsedAt == nil {
+				t.Errorf("expected stored quote to be marked as used")
+			}
+		})
+	}
+}
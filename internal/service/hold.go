@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type HoldStore interface {
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	ReserveWalletFunds(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) error
+	ReleaseWalletFunds(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) error
+	InsertHold(ctx context.Context, tx *sql.Tx, hold *model.Hold) error
+	FetchHoldForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Hold, error)
+	ResolveHold(ctx context.Context, tx *sql.Tx, hold *model.Hold) error
+	ExpireHolds(ctx context.Context, at time.Time) ([]model.Hold, error)
+}
+
+type HoldService struct {
+	store  HoldStore
+	config *model.HoldConfig
+}
+
+func NewHoldService(store HoldStore, config *model.HoldConfig) *HoldService {
+	logger.Info("Initializing HoldService")
+	return &HoldService{store: store, config: config}
+}
+
+func (s *HoldService) DoPlaceHold(ctx context.Context, tx *sql.Tx, payload *request.HoldPayload) (*model.Hold, *validation.WalletError) {
+	fnName := "HoldService.DoPlaceHold"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, err := validation.SanitizeAndValidateUsername(payload.Username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize username",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", payload.Username),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	actor := username
+	if payload.Actor != "" {
+		actor, err = validation.SanitizeAndValidateUsername(payload.Actor)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+				Message:   "Failed to sanitize actor",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("actor", payload.Actor),
+				},
+			}
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Actor resolved", fnName), zap.String("actor", actor))
+
+	if validation.IsReservedUsername(username) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "Username is reserved for system wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s uses reserved prefix %s", username, model.SystemUsernamePrefix),
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	pocket, err := validation.SanitizeAndValidatePocket(payload.Pocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", payload.Pocket),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket validated", fnName), zap.String("pocket", pocket))
+
+	if err := validation.ValidateAmount(payload.Amount, currency); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("amount", payload.Amount),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Amount validated", fnName), zap.Int64("amount", payload.Amount))
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(s.config.DefaultTTL)
+	if payload.ExpiresAt != nil {
+		expiresAt = payload.ExpiresAt.UTC()
+	}
+	if !expiresAt.After(now) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_HOLD_EXPIRY_INVALID,
+			Message:   "Hold expiry must be in the future",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("expiry %s is not after %s", expiresAt.Format(time.RFC3339), now.Format(time.RFC3339)),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Expiry validated", fnName), zap.Time("expiresAt", expiresAt))
+
+	wallet, err := s.store.FetchWallet(ctx, username, currency.Code, pocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Failed to fetch wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	if wallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "No existing wallet found for user",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if appErr := checkWalletStatus(fnName, wallet, true); appErr != nil {
+		return nil, appErr
+	}
+	if wallet.AvailableBalance < payload.Amount {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+			Message:   "Insufficient available balance for hold",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("available %d is less than %d", wallet.AvailableBalance, payload.Amount),
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.Int64("available", wallet.AvailableBalance),
+				zap.Int64("amount", payload.Amount),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Available balance validated", fnName), zap.Int64("available", wallet.AvailableBalance))
+
+	if err := s.store.ReserveWalletFunds(ctx, tx, wallet.ID, payload.Amount); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_PLACE_HOLD_FAILED,
+			Message:   "Failed to reserve wallet funds",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+
+	hold := &model.Hold{
+		WalletID:  wallet.ID,
+		Username:  username,
+		Actor:     actor,
+		Currency:  currency.Code,
+		Pocket:    pocket,
+		Amount:    payload.Amount,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.store.InsertHold(ctx, tx, hold); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_PLACE_HOLD_FAILED,
+			Message:   "Failed to place hold",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("hold", hold),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Hold placed", fnName), zap.Any("hold", hold))
+	return hold, nil
+}
+
+func (s *HoldService) DoCaptureHold(ctx context.Context, tx *sql.Tx, id int64, amount *int64) (*model.Hold, *validation.WalletError) {
+	fnName := "HoldService.DoCaptureHold"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id), zap.Any("amount", amount))
+
+	hold, appErr := s.fetchActiveHold(ctx, tx, fnName, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	captured := hold.Amount
+	if amount != nil {
+		captured = *amount
+	}
+	if captured <= 0 || captured > hold.Amount {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_HOLD_AMOUNT_INVALID,
+			Message:   "Capture amount must be positive and no more than the held amount",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("capture %d of hold %d for %d", captured, hold.ID, hold.Amount),
+		}
+	}
+
+	hold.Status = model.HoldCaptured
+	hold.CapturedAmount = &captured
+	if appErr := s.releaseHold(ctx, tx, fnName, hold); appErr != nil {
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Hold captured", fnName), zap.Any("hold", hold))
+	return hold, nil
+}
+
+func (s *HoldService) DoVoidHold(ctx context.Context, tx *sql.Tx, id int64) (*model.Hold, *validation.WalletError) {
+	fnName := "HoldService.DoVoidHold"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id))
+
+	hold, appErr := s.fetchActiveHold(ctx, tx, fnName, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	hold.Status = model.HoldVoided
+	if appErr := s.releaseHold(ctx, tx, fnName, hold); appErr != nil {
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Hold voided", fnName), zap.Any("hold", hold))
+	return hold, nil
+}
+
+func (s *HoldService) DoExpireHolds(ctx context.Context) ([]model.Hold, *validation.WalletError) {
+	fnName := "HoldService.DoExpireHolds"
+	holds, err := s.store.ExpireHolds(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_EXPIRE_HOLDS_FAILED,
+			Message:   "Failed to expire holds",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Expired holds released", fnName), zap.Int("count", len(holds)))
+	return holds, nil
+}
+
+func (s *HoldService) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	fnName := "HoldService.RunExpirySweeper"
+	logger.Info(fmt.Sprintf("%s - Sweeper started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Sweeper stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoExpireHolds(ctx); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Hold expiry sweep failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func (s *HoldService) fetchActiveHold(ctx context.Context, tx *sql.Tx, fnName string, id int64) (*model.Hold, *validation.WalletError) {
+	hold, err := s.store.FetchHoldForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_HOLD_FAILED,
+			Message:   "Failed to fetch hold",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if hold == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_HOLD_NOT_FOUND,
+			Message:   "Hold not found",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if hold.Status != model.HoldActive {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_HOLD_NOT_ACTIVE,
+			Message:   "Hold is no longer active",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("hold %d is %s", hold.ID, hold.Status),
+		}
+	}
+	if !time.Now().UTC().Before(hold.ExpiresAt) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_HOLD_EXPIRED,
+			Message:   "Hold has expired",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("hold %d expired at %s", hold.ID, hold.ExpiresAt.Format(time.RFC3339)),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Active hold fetched", fnName), zap.Any("hold", hold))
+	return hold, nil
+}
+
+func (s *HoldService) releaseHold(ctx context.Context, tx *sql.Tx, fnName string, hold *model.Hold) *validation.WalletError {
+	if err := s.store.ReleaseWalletFunds(ctx, tx, hold.WalletID, hold.Amount); err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RELEASE_HOLD_FAILED,
+			Message:   "Failed to release held funds",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("hold", hold),
+			},
+		}
+	}
+
+	resolvedAt := time.Now().UTC()
+	hold.ResolvedAt = &resolvedAt
+	if err := s.store.ResolveHold(ctx, tx, hold); err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RELEASE_HOLD_FAILED,
+			Message:   "Failed to resolve hold",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("hold", hold),
+			},
+		}
+	}
+	return nil
+}
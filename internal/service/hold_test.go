@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockHoldStore struct {
+	wallets map[string]*model.Wallet
+	holds   map[int64]*model.Hold
+	nextID  int64
+}
+
+func (m *mockHoldStore) initializeMockData() {
+	m.wallets = map[string]*model.Wallet{
+		"JUAN":  {ID: 1, Username: "JUAN", Currency: "USD", Pocket: model.DefaultPocket, Status: model.WalletActive, Balance: 1000, HeldBalance: 400, AvailableBalance: 600},
+		"MARY":  {ID: 2, Username: "MARY", Currency: "USD", Pocket: model.DefaultPocket, Status: model.WalletActive, Balance: 500, HeldBalance: 0, AvailableBalance: 500},
+		"PEDRO": {ID: 3, Username: "PEDRO", Currency: "USD", Pocket: "SAVINGS", Status: model.WalletActive, Balance: 800, HeldBalance: 0, AvailableBalance: 800},
+	}
+	future := time.Now().UTC().Add(time.Hour)
+	m.holds = map[int64]*model.Hold{
+		1: {ID: 1, WalletID: 1, Username: "JUAN", Currency: "USD", Amount: 300, Status: model.HoldActive, ExpiresAt: future},
+		2: {ID: 2, WalletID: 1, Username: "JUAN", Currency: "USD", Amount: 200, Status: model.HoldVoided, ExpiresAt: future},
+		3: {ID: 3, WalletID: 1, Username: "JUAN", Currency: "USD", Amount: 100, Status: model.HoldActive, ExpiresAt: time.Now().UTC().Add(-time.Minute)},
+	}
+	m.nextID = 4
+}
+
+func (m *mockHoldStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username]
+	if !ok || wallet.Currency != currency || wallet.Pocket != pocket {
+		return nil, nil
+	}
+	return wallet, nil
+}
+
+func (m *mockHoldStore) walletByID(walletID int64) (*model.Wallet, error) {
+	for _, wallet := range m.wallets {
+		if wallet.ID == walletID {
+			return wallet, nil
+		}
+	}
+	return nil, fmt.Errorf("wallet %d not found", walletID)
+}
+
+func (m *mockHoldStore) ReserveWalletFunds(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) error {
+	wallet, err := m.walletByID(walletID)
+	if err != nil {
+		return err
+	}
+	if wallet.AvailableBalance < amount {
+		return fmt.Errorf("insufficient available balance")
+	}
+	wallet.HeldBalance += amount
+	wallet.AvailableBalance -= amount
+	return nil
+}
+
+func (m *mockHoldStore) ReleaseWalletFunds(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) error {
+	wallet, err := m.walletByID(walletID)
+	if err != nil {
+		return err
+	}
+	if wallet.HeldBalance < amount {
+		return fmt.Errorf("held balance below release amount")
+	}
+	wallet.HeldBalance -= amount
+	wallet.AvailableBalance += amount
+	return nil
+}
+
+func (m *mockHoldStore) InsertHold(ctx context.Context, tx *sql.Tx, hold *model.Hold) error {
+	hold.ID = m.nextID
+	hold.Status = model.HoldActive
+	hold.CreatedAt = time.Now().UTC()
+	m.holds[hold.ID] = hold
+	m.nextID++
+	return nil
+}
+
+func (m *mockHoldStore) FetchHoldForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Hold, error) {
+	hold, ok := m.holds[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *hold
+	return &copied, nil
+}
+
+func (m *mockHoldStore) ResolveHold(ctx context.Context, tx *sql.Tx, hold *model.Hold) error {
+	m.holds[hold.ID] = hold
+	return nil
+}
+
+func (m *mockHoldStore) ExpireHolds(ctx context.Context, at time.Time) ([]model.Hold, error) {
+	return nil, nil
+}
+
+func TestDoPlaceHold(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name                string
+		payload             *request.HoldPayload
+		expectedHeldBalance int64
+		expectedActor       string
+		expectedPocket      string
+		expectedCode        validation.WalletErrorCode
+		expectErr           bool
+	}
+
+	tests := []testCase{
+		{
+			name:                "Successful Hold - Within available balance",
+			payload:             &request.HoldPayload{Username: "juan", Currency: "USD", Amount: 500},
+			expectedHeldBalance: 900,
+			expectedActor:       "JUAN",
+			expectErr:           false,
+		},
+		{
+			name:                "Successful Hold - Full available balance",
+			payload:             &request.HoldPayload{Username: "juan", Currency: "USD", Amount: 600},
+			expectedHeldBalance: 1000,
+			expectedActor:       "JUAN",
+			expectErr:           false,
+		},
+		{
+			name:                "Successful Hold - Explicit expiry",
+			payload:             &request.HoldPayload{Username: "mary", Currency: "USD", Amount: 100, ExpiresAt: utils.Ptr(time.Now().Add(time.Hour))},
+			expectedHeldBalance: 100,
+			expectedActor:       "MARY",
+			expectErr:           false,
+		},
+		{
+			name:                "Successful Hold - Actor stored with the hold",
+			payload:             &request.HoldPayload{Username: "juan", Actor: "mary", Currency: "USD", Amount: 100},
+			expectedHeldBalance: 500,
+			expectedActor:       "MARY",
+			expectErr:           false,
+		},
+		{
+			name:                "Successful Hold - Named pocket",
+			payload:             &request.HoldPayload{Username: "pedro", Currency: "USD", Pocket: "savings", Amount: 300},
+			expectedHeldBalance: 300,
+			expectedActor:       "PEDRO",
+			expectedPocket:      "SAVINGS",
+			expectErr:           false,
+		},
+		{
+			name:         "Failed Hold - Pocket does not exist",
+			payload:      &request.HoldPayload{Username: "pedro", Currency: "USD", Amount: 300},
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Hold - Symbol in pocket",
+			payload:      &request.HoldPayload{Username: "juan", Currency: "USD", Pocket: "sav-ings", Amount: 100},
+			expectedCode: validation.ERR_POCKET_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Hold - Symbol in actor",
+			payload:      &request.HoldPayload{Username: "juan", Actor: "m@ry", Currency: "USD", Amount: 100},
+			expectedCode: validation.ERR_SANITIZE_USERNAME_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Hold - Amount covered by balance but not available balance",
+			payload:      &request.HoldPayload{Username: "juan", Currency: "USD", Amount: 700},
+			expectedCode: validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Hold - Wallet does not exist",
+			payload:      &request.HoldPayload{Username: "kyle", Currency: "USD", Amount: 100},
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Hold - Expiry in the past",
+			payload:      &request.HoldPayload{Username: "juan", Currency: "USD", Amount: 100, ExpiresAt: utils.Ptr(time.Now().Add(-time.Hour))},
+			expectedCode: validation.ERR_HOLD_EXPIRY_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Hold - Negative amount",
+			payload:      &request.HoldPayload{Username: "juan", Currency: "USD", Amount: -100},
+			expectedCode: validation.ERR_AMOUNT_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockHoldStore{}
+			mock.initializeMockData()
+			s := &HoldService{store: mock, config: &model.HoldConfig{DefaultTTL: time.Hour}}
+
+			actual, err := s.DoPlaceHold(context.Background(), nil, test.payload)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.HoldActive {
+				t.Errorf("expected status %s but got %s instead", model.HoldActive, actual.Status)
+			}
+
+			if actual.Amount != test.payload.Amount {
+				t.Errorf("expected amount %d but got %d instead", test.payload.Amount, actual.Amount)
+			}
+
+			expectedPocket := test.expectedPocket
+			if expectedPocket == "" {
+				expectedPocket = model.DefaultPocket
+			}
+			if actual.Pocket != expectedPocket {
+				t.Errorf("expected pocket %s but got %s instead", expectedPocket, actual.Pocket)
+			}
+
+			if actual.Actor != test.expectedActor {
+				t.Errorf("expected actor %s but got %s instead", test.expectedActor, actual.Actor)
+			}
+
+			wallet := mock.wallets[actual.Username]
+			if test.expectedHeldBalance != wallet.HeldBalance {
+				t.Errorf("expected held balance %d but got %d instead", test.expectedHeldBalance, wallet.HeldBalance)
+			}
+		})
+	}
+}
+
+func TestDoCaptureHold(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name                string
+		id                  int64
+		amount              *int64
+		expectedCaptured    int64
+		expectedHeldBalance int64
+		expectedCode        validation.WalletErrorCode
+		expectErr           bool
+	}
+
+	tests := []testCase{
+		{
+			name:                "Successful Capture - Full hold",
+			id:                  1,
+			expectedCaptured:    300,
+			expectedHeldBalance: 100,
+			expectErr:           false,
+		},
+		{
+			name:                "Successful Capture - Partial releases remainder",
+			id:                  1,
+			amount:              utils.Ptr(int64(120)),
+			expectedCaptured:    120,
+			expectedHeldBalance: 100,
+			expectErr:           false,
+		},
+		{
+			name:         "Failed Capture - Amount above held amount",
+			id:           1,
+			amount:       utils.Ptr(int64(301)),
+			expectedCode: validation.ERR_HOLD_AMOUNT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Capture - Zero amount",
+			id:           1,
+			amount:       utils.Ptr(int64(0)),
+			expectedCode: validation.ERR_HOLD_AMOUNT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Capture - Hold already voided",
+			id:           2,
+			expectedCode: validation.ERR_HOLD_NOT_ACTIVE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Capture - Hold expired",
+			id:           3,
+			expectedCode: validation.ERR_HOLD_EXPIRED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Capture - Hold not found",
+			id:           99,
+			expectedCode: validation.ERR_HOLD_NOT_FOUND,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockHoldStore{}
+			mock.initializeMockData()
+			s := &HoldService{store: mock, config: &model.HoldConfig{DefaultTTL: time.Hour}}
+
+			actual, err := s.DoCaptureHold(context.Background(), nil, test.id, test.amount)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.HoldCaptured {
+				t.Errorf("expected status %s but got %s instead", model.HoldCaptured, actual.Status)
+			}
+
+			if actual.CapturedAmount == nil || *actual.CapturedAmount != test.expectedCaptured {
+				t.Errorf("expected captured amount %d but got %v instead", test.expectedCaptured, actual.CapturedAmount)
+			}
+
+			if actual.ResolvedAt == nil {
+				t.Errorf("expected resolved timestamp but got nil")
+			}
+
+			if held := mock.wallets["JUAN"].HeldBalance; test.expectedHeldBalance != held {
+				t.Errorf("expected held balance %d but got %d instead", test.expectedHeldBalance, held)
+			}
+		})
+	}
+}
+
+func TestDoVoidHold(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name                string
+		id                  int64
+		expectedHeldBalance int64
+		expectedCode        validation.WalletErrorCode
+		expectErr           bool
+	}
+
+	tests := []testCase{
+		{
+			name:                "Successful Void - Active hold",
+			id:                  1,
+			expectedHeldBalance: 100,
+			expectErr:           false,
+		},
+		{
+			name:         "Failed Void - Hold already voided",
+			id:           2,
+			expectedCode: validation.ERR_HOLD_NOT_ACTIVE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Void - Hold not found",
+			id:           99,
+			expectedCode: validation.ERR_HOLD_NOT_FOUND,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockHoldStore{}
+			mock.initializeMockData()
+			s := &HoldService{store: mock, config: &model.HoldConfig{DefaultTTL: time.Hour}}
+
+			actual, err := s.DoVoidHold(context.Background(), nil, test.id)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.HoldVoided {
+				t.Errorf("expected status %s but got %s instead", model.HoldVoided, actual.Status)
+			}
+
+			if held := mock.wallets["JUAN"].HeldBalance; test.expectedHeldBalance != held {
+				t.Errorf("expected held balance %d but got %d instead", test.expectedHeldBalance, held)
+			}
+		})
+	}
+}
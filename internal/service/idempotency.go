@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type IdempotencyStore interface {
+	ClaimIdempotencyKey(ctx context.Context, tx *sql.Tx, username string, key string, fingerprint string, at time.Time, expiresAt time.Time) (bool, error)
+	FetchIdempotencyKey(ctx context.Context, tx *sql.Tx, username string, key string) (*model.IdempotencyRecord, error)
+	SaveIdempotentResponse(ctx context.Context, tx *sql.Tx, username string, key string, statusCode int, response []byte) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, at time.Time) (int64, error)
+}
+
+type IdempotencyService struct {
+	store  IdempotencyStore
+	config *model.IdempotencyConfig
+}
+
+func NewIdempotencyService(store IdempotencyStore, config *model.IdempotencyConfig) *IdempotencyService {
+	logger.Debug("Initializing IdempotencyService")
+	return &IdempotencyService{store: store, config: config}
+}
+
+// requestFingerprint hashes the endpoint with the decoded payload rather than
+// the raw body, so whitespace and key order do not make a retry look like a
+// different request.
+func requestFingerprint(endpoint string, payload any) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(endpoint + "\n" + string(body)))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// DoClaimKey reserves the user's key for this request inside tx. Keys are
+// scoped to the username, so two users picking the same key never see each
+// other's requests. It returns the stored record when the key was already
+// used for the same request, in which case the caller replays it instead of
+// running the request again. An empty key opts out and returns nil.
+func (s *IdempotencyService) DoClaimKey(ctx context.Context, tx *sql.Tx, username string, key string, endpoint string, payload any) (*model.IdempotencyRecord, *validation.WalletError) {
+	fnName := "IdempotencyService.DoClaimKey"
+	if key == "" {
+		return nil, nil
+	}
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("key", key), zap.String("endpoint", endpoint))
+
+	sanitized, err := validation.SanitizeAndValidateUsername(username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize username",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+	username = sanitized
+
+	if err := validation.ValidateIdempotencyKey(key); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_IDEMPOTENCY_KEY_INVALID,
+			Message:   "Idempotency key is invalid",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("key", key),
+			},
+		}
+	}
+
+	fingerprint, err := requestFingerprint(endpoint, payload)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CLAIM_IDEMPOTENCY_KEY_FAILED,
+			Message:   "Failed to fingerprint request",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("key", key),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Request fingerprinted", fnName), zap.String("fingerprint", fingerprint))
+
+	now := time.Now().UTC()
+	claimed, err := s.store.ClaimIdempotencyKey(ctx, tx, username, key, fingerprint, now, now.Add(s.config.Retention))
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CLAIM_IDEMPOTENCY_KEY_FAILED,
+			Message:   "Failed to claim idempotency key",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("key", key),
+			},
+		}
+	}
+	if claimed {
+		logger.Info(fmt.Sprintf("%s - Idempotency key claimed", fnName), zap.String("key", key))
+		return nil, nil
+	}
+
+	record, err := s.store.FetchIdempotencyKey(ctx, tx, username, key)
+	if err == nil && record == nil {
+		err = fmt.Errorf("idempotency key %q missing after conflict", key)
+	}
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CLAIM_IDEMPOTENCY_KEY_FAILED,
+			Message:   "Failed to fetch idempotency key",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("key", key),
+			},
+		}
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_IDEMPOTENCY_KEY_REUSED,
+			Message:   "Idempotency key was already used for a different request",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("fingerprint %s does not match stored %s", fingerprint, record.Fingerprint),
+			Context: []zap.Field{
+				zap.String("key", key),
+				zap.String("endpoint", endpoint),
+			},
+		}
+	}
+
+	if record.StatusCode == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_IDEMPOTENCY_KEY_IN_PROGRESS,
+			Message:   "Request with this idempotency key has not finished",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("idempotency key %q has no stored response", key),
+			Context: []zap.Field{
+				zap.String("key", key),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Replaying stored response", fnName), zap.String("key", key), zap.Int("statusCode", *record.StatusCode))
+	return record, nil
+}
+
+// DoSaveResponse stores the response for the user's claimed key in the same
+// DB transaction as the request's own writes, so a committed request always
+// has a response to replay. An empty key is a no-op.
+func (s *IdempotencyService) DoSaveResponse(ctx context.Context, tx *sql.Tx, username string, key string, statusCode int, resp any) *validation.WalletError {
+	fnName := "IdempotencyService.DoSaveResponse"
+	if key == "" {
+		return nil
+	}
+
+	username, err := validation.SanitizeAndValidateUsername(username)
+	var body []byte
+	if err == nil {
+		body, err = json.Marshal(resp)
+	}
+	if err == nil {
+		err = s.store.SaveIdempotentResponse(ctx, tx, username, key, statusCode, body)
+	}
+	if err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SAVE_IDEMPOTENT_RESPONSE_FAILED,
+			Message:   "Failed to save idempotent response",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("key", key),
+				zap.Int("statusCode", statusCode),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Response saved", fnName), zap.String("key", key), zap.Int("statusCode", statusCode))
+	return nil
+}
+
+func (s *IdempotencyService) DoPurgeExpiredKeys(ctx context.Context) (int64, *validation.WalletError) {
+	fnName := "IdempotencyService.DoPurgeExpiredKeys"
+	count, err := s.store.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_PURGE_IDEMPOTENCY_KEYS_FAILED,
+			Message:   "Failed to purge expired idempotency keys",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Expired idempotency keys purged", fnName), zap.Int64("count", count))
+	return count, nil
+}
+
+func (s *IdempotencyService) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	fnName := "IdempotencyService.RunExpirySweeper"
+	logger.Info(fmt.Sprintf("%s - Sweeper started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Sweeper stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoPurgeExpiredKeys(ctx); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Idempotency key sweep failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockIdempotencyStore struct {
+	records map[string]model.IdempotencyRecord
+}
+
+func (m *mockIdempotencyStore) initializeMockKeys() {
+	depositFingerprint, _ := requestFingerprint("/deposit", mockDepositPayload())
+	m.records = map[string]model.IdempotencyRecord{
+		"JUAN:done-key": {
+			Username:    "JUAN",
+			Key:         "done-key",
+			Fingerprint: depositFingerprint,
+			StatusCode:  utils.Ptr(200),
+			Response:    json.RawMessage(`{"status":200,"action":"deposit"}`),
+			ExpiresAt:   time.Now().UTC().Add(time.Hour),
+		},
+		"JUAN:pending-key": {
+			Username:    "JUAN",
+			Key:         "pending-key",
+			Fingerprint: depositFingerprint,
+			ExpiresAt:   time.Now().UTC().Add(time.Hour),
+		},
+		"JUAN:expired-key": {
+			Username:    "JUAN",
+			Key:         "expired-key",
+			Fingerprint: "stale",
+			StatusCode:  utils.Ptr(200),
+			Response:    json.RawMessage(`{"status":200}`),
+			ExpiresAt:   time.Now().UTC().Add(-time.Minute),
+		},
+	}
+}
+
+func mockDepositPayload() *request.RequestPayload {
+	return &request.RequestPayload{Username: "juan", Amount: 500, Currency: "USD"}
+}
+
+func (m *mockIdempotencyStore) ClaimIdempotencyKey(ctx context.Context, tx *sql.Tx, username string, key string, fingerprint string, at time.Time, expiresAt time.Time) (bool, error) {
+	if record, ok := m.records[username+":"+key]; ok && record.ExpiresAt.After(at) {
+		return false, nil
+	}
+	m.records[username+":"+key] = model.IdempotencyRecord{Username: username, Key: key, Fingerprint: fingerprint, CreatedAt: at, ExpiresAt: expiresAt}
+	return true, nil
+}
+
+func (m *mockIdempotencyStore) FetchIdempotencyKey(ctx context.Context, tx *sql.Tx, username string, key string) (*model.IdempotencyRecord, error) {
+	record, ok := m.records[username+":"+key]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (m *mockIdempotencyStore) SaveIdempotentResponse(ctx context.Context, tx *sql.Tx, username string, key string, statusCode int, response []byte) error {
+	record, ok := m.records[username+":"+key]
+	if !ok {
+		return fmt.Errorf("idempotency key %q is not claimed for %s", key, username)
+	}
+	record.StatusCode = &statusCode
+	record.Response = response
+	m.records[username+":"+key] = record
+	return nil
+}
+
+func (m *mockIdempotencyStore) DeleteExpiredIdempotencyKeys(ctx context.Context, at time.Time) (int64, error) {
+	var count int64
+	for key, record := range m.records {
+		if !record.ExpiresAt.After(at) {
+			delete(m.records, key)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestDoClaimKey(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name            string
+		key             string
+		endpoint        string
+		payload         *request.RequestPayload
+		expectReplay    bool
+		expectClaimed   bool
+		expectedErrCode validation.WalletErrorCode
+	}
+
+	changedAmount := mockDepositPayload()
+	changedAmount.Amount = 600
+	otherUser := mockDepositPayload()
+	otherUser.Username = "mary"
+
+	tests := []testCase{
+		{
+			name:     "Claim Key - No key given",
+			key:      "",
+			endpoint: "/deposit",
+			payload:  mockDepositPayload(),
+		},
+		{
+			name:          "Claim Key - New key",
+			key:           "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d",
+			endpoint:      "/deposit",
+			payload:       mockDepositPayload(),
+			expectClaimed: true,
+		},
+		{
+			name:          "Claim Key - Expired key taken over",
+			key:           "expired-key",
+			endpoint:      "/deposit",
+			payload:       mockDepositPayload(),
+			expectClaimed: true,
+		},
+		{
+			name:         "Claim Key - Same request replayed",
+			key:          "done-key",
+			endpoint:     "/deposit",
+			payload:      mockDepositPayload(),
+			expectReplay: true,
+		},
+		{
+			name:          "Claim Key - Same key from another user",
+			key:           "done-key",
+			endpoint:      "/deposit",
+			payload:       otherUser,
+			expectClaimed: true,
+		},
+		{
+			name:            "Claim Key - Different body",
+			key:             "done-key",
+			endpoint:        "/deposit",
+			payload:         changedAmount,
+			expectedErrCode: validation.ERR_IDEMPOTENCY_KEY_REUSED,
+		},
+		{
+			name:            "Claim Key - Same body on another endpoint",
+			key:             "done-key",
+			endpoint:        "/withdraw",
+			payload:         mockDepositPayload(),
+			expectedErrCode: validation.ERR_IDEMPOTENCY_KEY_REUSED,
+		},
+		{
+			name:            "Claim Key - No stored response",
+			key:             "pending-key",
+			endpoint:        "/deposit",
+			payload:         mockDepositPayload(),
+			expectedErrCode: validation.ERR_IDEMPOTENCY_KEY_IN_PROGRESS,
+		},
+		{
+			name:            "Claim Key - Key with space",
+			key:             "my key",
+			endpoint:        "/deposit",
+			payload:         mockDepositPayload(),
+			expectedErrCode: validation.ERR_IDEMPOTENCY_KEY_INVALID,
+		},
+		{
+			name:            "Claim Key - Invalid username",
+			key:             "new-key",
+			endpoint:        "/deposit",
+			payload:         &request.RequestPayload{Username: "j@an", Amount: 500, Currency: "USD"},
+			expectedErrCode: validation.ERR_SANITIZE_USERNAME_FAILED,
+		},
+		{
+			name:            "Claim Key - Key too long",
+			key:             strings.Repeat("k", model.MaxIdempotencyKeyLength+1),
+			endpoint:        "/deposit",
+			payload:         mockDepositPayload(),
+			expectedErrCode: validation.ERR_IDEMPOTENCY_KEY_INVALID,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockIdempotencyStore{}
+			mock.initializeMockKeys()
+			s := NewIdempotencyService(mock, &model.IdempotencyConfig{Retention: time.Hour})
+
+			record, appErr := s.DoClaimKey(context.Background(), nil, test.payload.Username, test.key, test.endpoint, test.payload)
+			if test.expectedErrCode != "" {
+				if appErr == nil {
+					t.Fatalf("expected error %s but got nil", test.expectedErrCode)
+				}
+				if appErr.Code != test.expectedErrCode {
+					t.Fatalf("expected error %s but got %s", test.expectedErrCode, appErr.Code)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %s: %v", appErr.Code, appErr.Err)
+			}
+
+			if test.expectReplay {
+				if record == nil {
+					t.Fatalf("expected stored response but got nil")
+				}
+				if *record.StatusCode != 200 || string(record.Response) != `{"status":200,"action":"deposit"}` {
+					t.Errorf("expected stored response but got %d %s", *record.StatusCode, record.Response)
+				}
+				return
+			}
+			if record != nil {
+				t.Fatalf("expected no stored response but got %+v", record)
+			}
+
+			claimed, ok := mock.records[strings.ToUpper(test.payload.Username)+":"+test.key]
+			if ok != test.expectClaimed {
+				t.Fatalf("Claimed - expected %t but got %t", test.expectClaimed, ok)
+			}
+			if test.expectClaimed && claimed.StatusCode != nil {
+				t.Errorf("expected claimed key to have no response but got %d", *claimed.StatusCode)
+			}
+		})
+	}
+}
+
+func TestDoSaveResponse(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	mock := &mockIdempotencyStore{}
+	mock.initializeMockKeys()
+	s := NewIdempotencyService(mock, &model.IdempotencyConfig{Retention: time.Hour})
+
+	if appErr := s.DoSaveResponse(context.Background(), nil, "juan", "", 200, map[string]int{"status": 200}); appErr != nil {
+		t.Fatalf("expected no-op without key but got %s", appErr.Code)
+	}
+
+	key := "4f8c1e2a"
+	if _, appErr := s.DoClaimKey(context.Background(), nil, "juan", key, "/deposit", mockDepositPayload()); appErr != nil {
+		t.Fatalf("expected claim but got %s", appErr.Code)
+	}
+	if appErr := s.DoSaveResponse(context.Background(), nil, "juan", key, 200, map[string]int{"status": 200}); appErr != nil {
+		t.Fatalf("expected saved response but got %s: %v", appErr.Code, appErr.Err)
+	}
+
+	record, appErr := s.DoClaimKey(context.Background(), nil, "juan", key, "/deposit", mockDepositPayload())
+	if appErr != nil {
+		t.Fatalf("expected replay but got %s", appErr.Code)
+	}
+	if record == nil || *record.StatusCode != 200 || string(record.Response) != `{"status":200}` {
+		t.Fatalf("expected stored response but got %+v", record)
+	}
+
+	if appErr := s.DoSaveResponse(context.Background(), nil, "mary", key, 200, map[string]int{"status": 200}); appErr == nil || appErr.Code != validation.ERR_SAVE_IDEMPOTENT_RESPONSE_FAILED {
+		t.Fatalf("expected %s for another user's key but got %v", validation.ERR_SAVE_IDEMPOTENT_RESPONSE_FAILED, appErr)
+	}
+
+	if appErr := s.DoSaveResponse(context.Background(), nil, "juan", "never-claimed", 200, map[string]int{"status": 200}); appErr == nil || appErr.Code != validation.ERR_SAVE_IDEMPOTENT_RESPONSE_FAILED {
+		t.Fatalf("expected %s for unclaimed key but got %v", validation.ERR_SAVE_IDEMPOTENT_RESPONSE_FAILED, appErr)
+	}
+
+	count, appErr := s.DoPurgeExpiredKeys(context.Background())
+	if appErr != nil {
+		t.Fatalf("expected purge but got %s", appErr.Code)
+	}
+	if count != 1 {
+		t.Errorf("Purged - expected 1 but got %d", count)
+	}
+	if _, ok := mock.records["JUAN:expired-key"]; ok {
+		t.Errorf("expected expired-key to be purged")
+	}
+}
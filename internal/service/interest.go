@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	interestAccrualScale = 10
+	interestDateLayout   = "2006-01-02"
+	interestPeriodLayout = "2006-01"
+)
+
+type InterestBooker func(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, amount int64) (*model.Transaction, *validation.WalletError)
+
+type InterestStore interface {
+	BeginTransaction(ctx context.Context) (*sql.Tx, error)
+	FetchWalletForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Wallet, error)
+	FetchLastInterestAccrualDate(ctx context.Context) (*time.Time, error)
+	FetchEndOfDayBalances(ctx context.Context, tx *sql.Tx, cutoff time.Time) ([]model.EndOfDayBalance, error)
+	InsertInterestAccrual(ctx context.Context, tx *sql.Tx, accrual *model.InterestAccrual) (bool, error)
+	FetchUnbookedInterestPeriods(ctx context.Context, before time.Time) ([]model.InterestPeriod, error)
+	ClaimUnbookedInterestAccruals(ctx context.Context, tx *sql.Tx, walletID int64, period time.Time) ([]model.InterestAccrual, error)
+	CreditWalletInterest(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) (*model.Wallet, error)
+	InsertInterestPosting(ctx context.Context, tx *sql.Tx, posting *model.InterestPosting) error
+	MarkInterestAccrualsBooked(ctx context.Context, tx *sql.Tx, ids []int64, postingID int64) error
+	FetchInterestPostings(ctx context.Context, criteria *model.InterestCriteria) ([]model.InterestPosting, error)
+}
+
+type InterestService struct {
+	store  InterestStore
+	config *model.InterestConfig
+}
+
+func NewInterestService(store InterestStore, config *model.InterestConfig) *InterestService {
+	logger.Info("Initializing InterestService")
+	return &InterestService{store: store, config: config}
+}
+
+func (s *InterestService) DoRunAccrual(ctx context.Context, through *string, book InterestBooker) (*model.InterestRun, *validation.WalletError) {
+	fnName := "InterestService.DoRunAccrual"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("through", through))
+
+	today := truncateToDay(time.Now().UTC())
+	throughDate := today.AddDate(0, 0, -1)
+	if through != nil {
+		parsed, err := time.Parse(interestDateLayout, *through)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_INTEREST_DATE_INVALID,
+				Message:   "Through date must be formatted as YYYY-MM-DD",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			}
+		}
+		throughDate = parsed
+	}
+	if !throughDate.Before(today) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_INTEREST_DATE_INVALID,
+			Message:   "Interest can only be accrued for completed days",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("through date %s is not before %s", throughDate.Format(interestDateLayout), today.Format(interestDateLayout)),
+		}
+	}
+
+	run := &model.InterestRun{Through: throughDate, Postings: []model.InterestPosting{}}
+
+	if s.config.AnnualRateBps > 0 {
+		last, err := s.store.FetchLastInterestAccrualDate(ctx)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_ACCRUE_INTEREST_FAILED,
+				Message:   "Failed to fetch last accrual date",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			}
+		}
+
+		start := throughDate
+		if last != nil {
+			start = truncateToDay(last.UTC()).AddDate(0, 0, 1)
+		}
+		for day := start; !day.After(throughDate); day = day.AddDate(0, 0, 1) {
+			accrued, appErr := s.accrueDay(ctx, day)
+			if appErr != nil {
+				return run, appErr
+			}
+			run.Days++
+			run.Accruals += accrued
+		}
+		logger.Info(fmt.Sprintf("%s - Interest accrued", fnName), zap.Int("days", run.Days), zap.Int("accruals", run.Accruals))
+	} else {
+		logger.Info(fmt.Sprintf("%s - Annual rate is zero, skipping accrual", fnName))
+	}
+
+	nextDay := throughDate.AddDate(0, 0, 1)
+	before := time.Date(nextDay.Year(), nextDay.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periods, err := s.store.FetchUnbookedInterestPeriods(ctx, before)
+	if err != nil {
+		return run, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BOOK_INTEREST_FAILED,
+			Message:   "Failed to fetch unbooked interest periods",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	for _, period := range periods {
+		posting, appErr := s.bookPeriod(ctx, period, book)
+		if appErr != nil {
+			return run, appErr
+		}
+		if posting != nil {
+			run.Postings = append(run.Postings, *posting)
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Interest booked", fnName), zap.Int("postings", len(run.Postings)))
+	return run, nil
+}
+
+func (s *InterestService) RunAccrualJob(ctx context.Context, interval time.Duration, book InterestBooker) {
+	fnName := "InterestService.RunAccrualJob"
+	logger.Info(fmt.Sprintf("%s - Job started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Job stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoRunAccrual(ctx, nil, book); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Interest accrual failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func (s *InterestService) DoFetchInterestPostings(ctx context.Context, username string, period string, limit string) ([]model.InterestPosting, *model.InterestCriteria, *validation.WalletError) {
+	fnName := "InterestService.DoFetchInterestPostings"
+	queryUsername := validation.SanitizeUsernameWithoutError(username)
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", queryUsername))
+
+	var queryPeriod *time.Time
+	if parsed, err := time.Parse(interestPeriodLayout, period); err == nil {
+		queryPeriod = &parsed
+	}
+	logger.Info(fmt.Sprintf("%s - Period parsed", fnName), zap.Any("period", queryPeriod))
+
+	queryLimit, err := strconv.Atoi(limit)
+	if err != nil {
+		queryLimit = 0
+	}
+	logger.Info(fmt.Sprintf("%s - Limit converted to int", fnName), zap.Int("limit", queryLimit))
+
+	criteria := &model.InterestCriteria{
+		Username: queryUsername,
+		Period:   queryPeriod,
+		Limit:    queryLimit,
+	}
+
+	postings, err := s.store.FetchInterestPostings(ctx, criteria)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_INTEREST_FAILED,
+			Message:   "Failed to fetch interest postings",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("criteria", criteria),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Interest postings fetched", fnName), zap.Int("count", len(postings)))
+	return postings, criteria, nil
+}
+
+func (s *InterestService) accrueDay(ctx context.Context, day time.Time) (int, *validation.WalletError) {
+	fnName := "InterestService.accrueDay"
+
+	tx, err := s.store.BeginTransaction(ctx)
+	if err != nil {
+		return 0, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ACCRUE_INTEREST_FAILED,
+			Message:   "Failed to start transaction",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	balances, err := s.store.FetchEndOfDayBalances(ctx, tx, day.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ACCRUE_INTEREST_FAILED,
+			Message:   "Failed to fetch end-of-day balances",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Time("day", day),
+			},
+		}
+	}
+
+	accrued := 0
+	for _, balance := range balances {
+		accrual := &model.InterestAccrual{
+			WalletID:    balance.WalletID,
+			AccrualDate: day,
+			Balance:     balance.Balance,
+			RateBps:     s.config.AnnualRateBps,
+			Amount:      dailyInterest(balance.Balance, s.config.AnnualRateBps, s.config.DayCount).FloatString(interestAccrualScale),
+		}
+		inserted, err := s.store.InsertInterestAccrual(ctx, tx, accrual)
+		if err != nil {
+			return 0, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_ACCRUE_INTEREST_FAILED,
+				Message:   "Failed to insert interest accrual",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Any("accrual", accrual),
+				},
+			}
+		}
+		if inserted {
+			accrued++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_ACCRUE_INTEREST_FAILED,
+			Message:   "Failed to commit interest accruals",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Day accrued", fnName), zap.Time("day", day), zap.Int("wallets", len(balances)), zap.Int("accrued", accrued))
+	return accrued, nil
+}
+
+func (s *InterestService) bookPeriod(ctx context.Context, period model.InterestPeriod, book InterestBooker) (*model.InterestPosting, *validation.WalletError) {
+	fnName := "InterestService.bookPeriod"
+
+	tx, err := s.store.BeginTransaction(ctx)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BOOK_INTEREST_FAILED,
+			Message:   "Failed to start transaction",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	posting, appErr := s.bookPeriodTx(ctx, tx, period, book)
+	if appErr != nil || posting == nil {
+		return nil, appErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BOOK_INTEREST_FAILED,
+			Message:   "Failed to commit interest posting",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Interest booked", fnName), zap.Any("posting", posting))
+	return posting, nil
+}
+
+func (s *InterestService) bookPeriodTx(ctx context.Context, tx *sql.Tx, period model.InterestPeriod, book InterestBooker) (*model.InterestPosting, *validation.WalletError) {
+	fnName := "InterestService.bookPeriodTx"
+
+	wallet, err := s.store.FetchWalletForUpdate(ctx, tx, period.WalletID)
+	if err != nil || wallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Failed to fetch wallet for interest posting",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("walletID", period.WalletID),
+			},
+		}
+	}
+
+	if wallet.Status == model.WalletFrozenAll {
+		logger.Info(fmt.Sprintf("%s - Wallet frozen, deferring interest booking", fnName), zap.Any("period", period))
+		return nil, nil
+	}
+
+	accruals, err := s.store.ClaimUnbookedInterestAccruals(ctx, tx, period.WalletID, period.Period)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BOOK_INTEREST_FAILED,
+			Message:   "Failed to claim interest accruals",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("period", period),
+			},
+		}
+	}
+	if len(accruals) == 0 {
+		logger.Info(fmt.Sprintf("%s - Period already booked", fnName), zap.Any("period", period))
+		return nil, nil
+	}
+
+	accrued := new(big.Rat)
+	ids := make([]int64, 0, len(accruals))
+	for _, accrual := range accruals {
+		amount, ok := new(big.Rat).SetString(accrual.Amount)
+		if !ok {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_BOOK_INTEREST_FAILED,
+				Message:   "Invalid interest accrual amount",
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("cannot parse accrual %d amount %q", accrual.ID, accrual.Amount),
+			}
+		}
+		accrued.Add(accrued, amount)
+		ids = append(ids, accrual.ID)
+	}
+
+	amount := roundInterest(accrued, s.config.Rounding)
+	credit := amount
+	if wallet.Status == model.WalletClosed {
+		credit = 0
+		logger.Warn(fmt.Sprintf("%s - Wallet closed, interest forfeited", fnName), zap.Int64("amount", amount))
+	} else if err := validation.ValidateWalletBalance(wallet.Balance+amount, wallet); err != nil {
+		credit = max(wallet.Limits.MaxBalance-wallet.Balance, 0)
+		logger.Warn(fmt.Sprintf("%s - Interest capped by wallet balance limit", fnName), zap.Int64("amount", amount), zap.Int64("credit", credit), zap.Error(err))
+	}
+
+	posting := &model.InterestPosting{
+		WalletID:  wallet.ID,
+		Username:  wallet.Username,
+		Currency:  wallet.Currency,
+		Period:    period.Period,
+		Days:      len(accruals),
+		Accrued:   accrued.FloatString(interestAccrualScale),
+		Amount:    credit,
+		Forfeited: amount - credit,
+	}
+
+	if credit > 0 {
+		credited, err := s.store.CreditWalletInterest(ctx, tx, wallet.ID, credit)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_BOOK_INTEREST_FAILED,
+				Message:   "Failed to credit interest to wallet",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Int64("walletID", wallet.ID),
+					zap.Int64("amount", credit),
+				},
+			}
+		}
+
+		transaction, appErr := book(ctx, tx, credited, credit)
+		if appErr != nil {
+			return nil, appErr
+		}
+		posting.TransactionID = &transaction.ID
+	}
+
+	if err := s.store.InsertInterestPosting(ctx, tx, posting); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BOOK_INTEREST_FAILED,
+			Message:   "Failed to insert interest posting",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("posting", posting),
+			},
+		}
+	}
+
+	if err := s.store.MarkInterestAccrualsBooked(ctx, tx, ids, posting.ID); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BOOK_INTEREST_FAILED,
+			Message:   "Failed to mark interest accruals as booked",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("postingID", posting.ID),
+			},
+		}
+	}
+	return posting, nil
+}
+
+func dailyInterest(balance int64, annualRateBps int, dayCount int) *big.Rat {
+	numerator := new(big.Int).Mul(big.NewInt(balance), big.NewInt(int64(annualRateBps)))
+	denominator := big.NewInt(int64(10000 * dayCount))
+	return new(big.Rat).SetFrac(numerator, denominator)
+}
+
+func roundInterest(amount *big.Rat, rounding model.InterestRounding) int64 {
+	quotient, remainder := new(big.Int).QuoRem(amount.Num(), amount.Denom(), new(big.Int))
+	twice := new(big.Int).Lsh(remainder, 1)
+
+	switch rounding {
+	case model.RoundHalfUp:
+		if twice.Cmp(amount.Denom()) >= 0 {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	case model.RoundHalfEven:
+		cmp := twice.Cmp(amount.Denom())
+		if cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1) {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
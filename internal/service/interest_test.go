@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockInterestStore struct {
+	wallets  map[int64]*model.Wallet
+	accruals []model.InterestAccrual
+	postings []model.InterestPosting
+}
+
+func (m *mockInterestStore) initializeMockData() {
+	m.wallets = map[int64]*model.Wallet{
+		1: {ID: 1, Username: "JUAN", Currency: "USD", Limits: defaultMockLimits("USD"), Balance: 100000, AvailableBalance: 100000},
+		2: {ID: 2, Username: "MARY", Currency: "USD", Limits: defaultMockLimits("USD"), Balance: 999990, AvailableBalance: 999990},
+		3: {ID: 3, Username: "PAUL", Currency: "USD", Limits: defaultMockLimits("USD"), Balance: 500, AvailableBalance: 500},
+		4: {ID: 4, Username: "GONE", Currency: "USD", Status: model.WalletClosed, Limits: defaultMockLimits("USD"), Balance: 0, AvailableBalance: 0},
+		5: {ID: 5, Username: "COLD", Currency: "USD", Status: model.WalletFrozenAll, Limits: defaultMockLimits("USD"), Balance: 100000, AvailableBalance: 100000},
+	}
+	june := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	m.accruals = []model.InterestAccrual{
+		{ID: 1, WalletID: 1, AccrualDate: june, Amount: "10.2500000000"},
+		{ID: 2, WalletID: 1, AccrualDate: june.AddDate(0, 0, 1), Amount: "10.2500000000"},
+		{ID: 3, WalletID: 1, AccrualDate: june.AddDate(0, 0, 2), Amount: "10.2500000000"},
+		{ID: 4, WalletID: 2, AccrualDate: june, Amount: "25.0000000000"},
+		{ID: 5, WalletID: 3, AccrualDate: june, Amount: "0.2500000000"},
+		{ID: 6, WalletID: 3, AccrualDate: june.AddDate(0, 0, 1), Amount: "0.2500000000"},
+		{ID: 7, WalletID: 4, AccrualDate: june, Amount: "10.2500000000"},
+		{ID: 8, WalletID: 5, AccrualDate: june, Amount: "10.2500000000"},
+	}
+}
+
+func (m *mockInterestStore) BeginTransaction(ctx context.Context) (*sql.Tx, error) {
+	return nil, fmt.Errorf("not supported by mock")
+}
+
+func (m *mockInterestStore) FetchWalletForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Wallet, error) {
+	wallet, ok := m.wallets[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *wallet
+	return &copied, nil
+}
+
+func (m *mockInterestStore) FetchLastInterestAccrualDate(ctx context.Context) (*time.Time, error) {
+	return nil, nil
+}
+
+func (m *mockInterestStore) FetchEndOfDayBalances(ctx context.Context, tx *sql.Tx, cutoff time.Time) ([]model.EndOfDayBalance, error) {
+	return []model.EndOfDayBalance{}, nil
+}
+
+func (m *mockInterestStore) InsertInterestAccrual(ctx context.Context, tx *sql.Tx, accrual *model.InterestAccrual) (bool, error) {
+	return false, nil
+}
+
+func (m *mockInterestStore) FetchUnbookedInterestPeriods(ctx context.Context, before time.Time) ([]model.InterestPeriod, error) {
+	return []model.InterestPeriod{}, nil
+}
+
+func (m *mockInterestStore) ClaimUnbookedInterestAccruals(ctx context.Context, tx *sql.Tx, walletID int64, period time.Time) ([]model.InterestAccrual, error) {
+	accruals := []model.InterestAccrual{}
+	for _, accrual := range m.accruals {
+		if accrual.WalletID == walletID && accrual.PostingID == nil && !accrual.AccrualDate.Before(period) && accrual.AccrualDate.Before(period.AddDate(0, 1, 0)) {
+			accruals = append(accruals, accrual)
+		}
+	}
+	return accruals, nil
+}
+
+func (m *mockInterestStore) CreditWalletInterest(ctx context.Context, tx *sql.Tx, walletID int64, amount int64) (*model.Wallet, error) {
+	wallet := m.wallets[walletID]
+	wallet.Balance += amount
+	wallet.AvailableBalance += amount
+	return wallet, nil
+}
+
+func (m *mockInterestStore) InsertInterestPosting(ctx context.Context, tx *sql.Tx, posting *model.InterestPosting) error {
+	for _, existing := range m.postings {
+		if existing.WalletID == posting.WalletID && existing.Period.Equal(posting.Period) {
+			return fmt.Errorf("duplicate interest posting")
+		}
+	}
+	posting.ID = int64(len(m.postings) + 1)
+	m.postings = append(m.postings, *posting)
+	return nil
+}
+
+func (m *mockInterestStore) MarkInterestAccrualsBooked(ctx context.Context, tx *sql.Tx, ids []int64, postingID int64) error {
+	for _, id := range ids {
+		for i := range m.accruals {
+			if m.accruals[i].ID == id {
+				m.accruals[i].PostingID = &postingID
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mockInterestStore) FetchInterestPostings(ctx context.Context, criteria *model.InterestCriteria) ([]model.InterestPosting, error) {
+	return m.postings, nil
+}
+
+func mockInterestBooker(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, amount int64) (*model.Transaction, *validation.WalletError) {
+	return &model.Transaction{ID: 99, Username: wallet.Username, TxnType: model.TypeInterest, Currency: wallet.Currency, Amount: amount}, nil
+}
+
+func TestRoundInterest(t *testing.T) {
+	type testCase struct {
+		name     string
+		amount   string
+		rounding model.InterestRounding
+		expected int64
+	}
+
+	tests := []testCase{
+		{name: "Round Down - Drops fraction", amount: "30.75", rounding: model.RoundDown, expected: 30},
+		{name: "Round Half Up - Rounds half away", amount: "30.5", rounding: model.RoundHalfUp, expected: 31},
+		{name: "Round Half Up - Below half", amount: "30.4999999999", rounding: model.RoundHalfUp, expected: 30},
+		{name: "Round Half Even - Half to even below", amount: "30.5", rounding: model.RoundHalfEven, expected: 30},
+		{name: "Round Half Even - Half to even above", amount: "31.5", rounding: model.RoundHalfEven, expected: 32},
+		{name: "Round Half Even - Above half", amount: "30.51", rounding: model.RoundHalfEven, expected: 31},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			amount, _ := new(big.Rat).SetString(test.amount)
+			actual := roundInterest(amount, test.rounding)
+			if actual != test.expected {
+				t.Errorf("expected %d but got %d instead", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDailyInterest(t *testing.T) {
+	actual := dailyInterest(100000, 365, 365).FloatString(interestAccrualScale)
+	if actual != "10.0000000000" {
+		t.Errorf("expected 10.0000000000 but got %s instead", actual)
+	}
+
+	actual = dailyInterest(1000, 500, 365).FloatString(interestAccrualScale)
+	if actual != "0.1369863014" {
+		t.Errorf("expected 0.1369863014 but got %s instead", actual)
+	}
+}
+
+func TestBookInterestPeriod(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	june := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		name              string
+		walletID          int64
+		rounding          model.InterestRounding
+		rebook            bool
+		expectedAmount    int64
+		expectedForfeited int64
+		expectedBalance   int64
+		expectedDays      int
+		expectNoPosting   bool
+		expectedPostings  int
+		expectedCode      validation.WalletErrorCode
+		expectErr         bool
+	}
+
+	tests := []testCase{
+		{
+			name:            "Successful Booking - Monthly sum rounded down",
+			walletID:        1,
+			rounding:        model.RoundDown,
+			expectedAmount:  30,
+			expectedBalance: 100030,
+			expectedDays:    3,
+			expectErr:       false,
+		},
+		{
+			name:            "Successful Booking - Monthly sum rounded half up",
+			walletID:        1,
+			rounding:        model.RoundHalfUp,
+			expectedAmount:  31,
+			expectedBalance: 100031,
+			expectedDays:    3,
+			expectErr:       false,
+		},
+		{
+			name:              "Successful Booking - Capped at maximum balance",
+			walletID:          2,
+			rounding:          model.RoundDown,
+			expectedAmount:    9,
+			expectedForfeited: 16,
+			expectedBalance:   999999,
+			expectedDays:      1,
+			expectErr:         false,
+		},
+		{
+			name:            "Successful Booking - Rounds to zero without transaction",
+			walletID:        3,
+			rounding:        model.RoundDown,
+			expectedAmount:  0,
+			expectedBalance: 500,
+			expectedDays:    2,
+			expectErr:       false,
+		},
+		{
+			name:             "Successful Booking - Re-running a booked period is a no-op",
+			walletID:         1,
+			rounding:         model.RoundDown,
+			rebook:           true,
+			expectedBalance:  100030,
+			expectNoPosting:  true,
+			expectedPostings: 1,
+			expectErr:        false,
+		},
+		{
+			name:              "Successful Booking - Closed wallet forfeits interest",
+			walletID:          4,
+			rounding:          model.RoundDown,
+			expectedAmount:    0,
+			expectedForfeited: 10,
+			expectedBalance:   0,
+			expectedDays:      1,
+			expectErr:         false,
+		},
+		{
+			name:             "Successful Booking - Frozen wallet is deferred",
+			walletID:         5,
+			rounding:         model.RoundDown,
+			expectedBalance:  100000,
+			expectNoPosting:  true,
+			expectedPostings: 0,
+			expectErr:        false,
+		},
+		{
+			name:         "Failed Booking - Wallet not found",
+			walletID:     9,
+			rounding:     model.RoundDown,
+			expectedCode: validation.ERR_FETCH_WALLET_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockInterestStore{}
+			mock.initializeMockData()
+			s := &InterestService{store: mock, config: &model.InterestConfig{AnnualRateBps: 500, DayCount: 365, Rounding: test.rounding}}
+			period := model.InterestPeriod{WalletID: test.walletID, Period: june}
+
+			if test.rebook {
+				if _, err := s.bookPeriodTx(context.Background(), nil, period, mockInterestBooker); err != nil {
+					t.Fatalf("unexpected error on first booking: %v", err)
+				}
+			}
+
+			actual, err := s.bookPeriodTx(context.Background(), nil, period, mockInterestBooker)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if balance := mock.wallets[test.walletID].Balance; balance != test.expectedBalance {
+				t.Errorf("expected balance %d but got %d instead", test.expectedBalance, balance)
+			}
+
+			if test.expectNoPosting {
+				if actual != nil {
+					t.Errorf("expected no posting but got %+v", actual)
+				}
+				if len(mock.postings) != test.expectedPostings {
+					t.Errorf("expected %d postings but got %d instead", test.expectedPostings, len(mock.postings))
+				}
+				return
+			}
+
+			if actual.Amount != test.expectedAmount {
+				t.Errorf("expected amount %d but got %d instead", test.expectedAmount, actual.Amount)
+			}
+
+			if actual.Forfeited != test.expectedForfeited {
+				t.Errorf("expected forfeited %d but got %d instead", test.expectedForfeited, actual.Forfeited)
+			}
+
+			if actual.Days != test.expectedDays {
+				t.Errorf("expected %d days but got %d instead", test.expectedDays, actual.Days)
+			}
+
+			if (actual.TransactionID != nil) != (test.expectedAmount > 0) {
+				t.Errorf("expected transaction only when amount is positive but got %v", actual.TransactionID)
+			}
+		})
+	}
+}
+
+func TestDoRunAccrual(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	today := time.Now().UTC().Format(interestDateLayout)
+
+	type testCase struct {
+		name         string
+		through      *string
+		expectedCode validation.WalletErrorCode
+	}
+
+	tests := []testCase{
+		{
+			name:         "Failed Run - Through date is today",
+			through:      utils.Ptr(today),
+			expectedCode: validation.ERR_INTEREST_DATE_INVALID,
+		},
+		{
+			name:         "Failed Run - Through date is malformed",
+			through:      utils.Ptr("06/30/2025"),
+			expectedCode: validation.ERR_INTEREST_DATE_INVALID,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockInterestStore{}
+			mock.initializeMockData()
+			s := &InterestService{store: mock, config: &model.InterestConfig{AnnualRateBps: 500, DayCount: 365, Rounding: model.RoundDown}}
+
+			_, err := s.DoRunAccrual(context.Background(), test.through, mockInterestBooker)
+			if err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+			if test.expectedCode != err.Code {
+				t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+			}
+		})
+	}
+}
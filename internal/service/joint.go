@@ -0,0 +1,973 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const minJointMembers = 2
+
+type JointWalletStore interface {
+	CountWallets(ctx context.Context, username string) (int64, error)
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	InsertPocket(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, limits model.WalletLimits) (*model.Wallet, error)
+	FetchWalletMembers(ctx context.Context, walletUsername string) ([]model.WalletMember, error)
+	UpsertWalletMember(ctx context.Context, tx *sql.Tx, member *model.WalletMember) error
+	DeleteWalletMember(ctx context.Context, tx *sql.Tx, walletUsername string, member string) (bool, error)
+	FetchSigningRules(ctx context.Context, walletUsername string) ([]model.SigningRule, error)
+	UpsertSigningRule(ctx context.Context, tx *sql.Tx, rule *model.SigningRule) error
+	DeleteSigningRule(ctx context.Context, tx *sql.Tx, walletUsername string, currency string) (bool, error)
+	InsertApprovalRequest(ctx context.Context, tx *sql.Tx, approval *model.ApprovalRequest) error
+	InsertApprovalVote(ctx context.Context, tx *sql.Tx, approvalID int64, member string) (bool, error)
+	FetchApprovalRequestForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.ApprovalRequest, error)
+	FetchApprovalRequests(ctx context.Context, walletUsername string) ([]model.ApprovalRequest, error)
+	ResolveApprovalRequest(ctx context.Context, tx *sql.Tx, approval *model.ApprovalRequest) error
+}
+
+type JointWalletService struct {
+	store  JointWalletStore
+	config *model.ApprovalConfig
+}
+
+func NewJointWalletService(store JointWalletStore, config *model.ApprovalConfig) *JointWalletService {
+	logger.Info("Initializing JointWalletService")
+	return &JointWalletService{store: store, config: config}
+}
+
+func (s *JointWalletService) DoCreateJointWallet(ctx context.Context, tx *sql.Tx, payload *request.JointWalletPayload) (*model.JointWallet, *validation.WalletError) {
+	fnName := "JointWalletService.DoCreateJointWallet"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, appErr := validateJointUsername(fnName, payload.Username)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	actor, appErr := validateJointMember(fnName, username, payload.Actor)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	members := []model.WalletMember{{WalletUsername: username, Member: actor, Role: model.RoleOwner}}
+	seen := map[string]struct{}{actor: {}}
+	for _, m := range payload.Members {
+		member, appErr := validateJointMember(fnName, username, m.Username)
+		if appErr != nil {
+			return nil, appErr
+		}
+		if _, ok := seen[member]; ok {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_JOINT_MEMBER_INVALID,
+				Message:   fmt.Sprintf("Member %s is listed more than once", member),
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("duplicate member %s", member),
+			}
+		}
+		seen[member] = struct{}{}
+
+		if !model.IsWalletRoleValid(m.Role) {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_JOINT_MEMBER_INVALID,
+				Message:   fmt.Sprintf("Role %q is not supported", m.Role),
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("invalid role %q for %s", m.Role, member),
+			}
+		}
+		members = append(members, model.WalletMember{WalletUsername: username, Member: member, Role: model.WalletRole(m.Role)})
+	}
+	if len(members) < minJointMembers {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_JOINT_MEMBER_INVALID,
+			Message:   fmt.Sprintf("A joint wallet needs at least %d members", minJointMembers),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("got %d members", len(members)),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Members validated", fnName), zap.Any("members", members))
+
+	var rule *model.SigningRule
+	if payload.ApprovalThreshold != nil {
+		rule = &model.SigningRule{WalletUsername: username, Currency: currency.Code, ApprovalThreshold: *payload.ApprovalThreshold, RequiredApprovals: 2}
+		if payload.RequiredApprovals != nil {
+			rule.RequiredApprovals = *payload.RequiredApprovals
+		}
+		if appErr := validateSigningRule(fnName, rule, members); appErr != nil {
+			return nil, appErr
+		}
+		logger.Info(fmt.Sprintf("%s - Signing rule validated", fnName), zap.Any("rule", rule))
+	}
+
+	count, err := s.store.CountWallets(ctx, username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Error while fetching wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+	existing, appErr := s.fetchMembers(ctx, fnName, username)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if count > 0 || len(existing) > 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_JOINT_WALLET_EXISTS,
+			Message:   "Username is already in use by another wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("%s already has %d wallets and %d members", username, count, len(existing)),
+		}
+	}
+
+	wallet, err := s.store.InsertPocket(ctx, tx, username, currency.Code, model.DefaultPocket, model.DefaultWalletLimits(currency))
+	if err != nil || wallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_JOINT_WALLET_FAILED,
+			Message:   "Failed to create joint wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Joint wallet created", fnName), zap.Any("wallet", wallet))
+
+	for i := range members {
+		if err := s.store.UpsertWalletMember(ctx, tx, &members[i]); err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_CREATE_JOINT_WALLET_FAILED,
+				Message:   "Failed to add joint wallet member",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Any("member", members[i]),
+				},
+			}
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Members added", fnName), zap.Int("count", len(members)))
+
+	rules := []model.SigningRule{}
+	if rule != nil {
+		if err := s.store.UpsertSigningRule(ctx, tx, rule); err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_CREATE_JOINT_WALLET_FAILED,
+				Message:   "Failed to save signing rule",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Any("rule", rule),
+				},
+			}
+		}
+		rules = append(rules, *rule)
+		logger.Info(fmt.Sprintf("%s - Signing rule saved", fnName), zap.Any("rule", rule))
+	}
+
+	return &model.JointWallet{
+		Username: username,
+		Members:  members,
+		Rules:    rules,
+		Wallet:   wallet,
+	}, nil
+}
+
+func (s *JointWalletService) DoFetchJointWallet(ctx context.Context, username string) (*model.JointWallet, *validation.WalletError) {
+	fnName := "JointWalletService.DoFetchJointWallet"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username))
+
+	username, appErr := validateJointUsername(fnName, username)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	joint, appErr := s.fetchJointWallet(ctx, fnName, username)
+	if appErr != nil {
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Joint wallet fetched", fnName), zap.Any("joint", joint))
+	return joint, nil
+}
+
+func (s *JointWalletService) DoSetMember(ctx context.Context, tx *sql.Tx, walletUsername string, payload *request.WalletMemberPayload) (*model.JointWallet, *validation.WalletError) {
+	fnName := "JointWalletService.DoSetMember"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("walletUsername", walletUsername), zap.Any("payload", payload))
+
+	username, appErr := validateJointUsername(fnName, walletUsername)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	joint, appErr := s.fetchJointWallet(ctx, fnName, username)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if _, appErr := requireJointOwner(fnName, joint, payload.Actor); appErr != nil {
+		return nil, appErr
+	}
+
+	member, appErr := validateJointMember(fnName, username, payload.Username)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	members := []model.WalletMember{}
+	found := false
+	for _, m := range joint.Members {
+		if m.Member == member {
+			found = true
+			continue
+		}
+		members = append(members, m)
+	}
+
+	var updated *model.WalletMember
+	if payload.Remove {
+		if !found {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_JOINT_MEMBER_INVALID,
+				Message:   fmt.Sprintf("%s is not a member of the joint wallet", member),
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("%s is not a member of %s", member, username),
+			}
+		}
+	} else {
+		if !model.IsWalletRoleValid(payload.Role) {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_JOINT_MEMBER_INVALID,
+				Message:   fmt.Sprintf("Role %q is not supported", payload.Role),
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("invalid role %q for %s", payload.Role, member),
+			}
+		}
+		updated = &model.WalletMember{WalletUsername: username, Member: member, Role: model.WalletRole(payload.Role)}
+		members = append(members, *updated)
+	}
+
+	if appErr := validateJointMembers(fnName, members, joint.Rules); appErr != nil {
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Membership change validated", fnName), zap.String("member", member), zap.Bool("remove", payload.Remove))
+
+	if updated == nil {
+		if _, err := s.store.DeleteWalletMember(ctx, tx, username, member); err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_UPDATE_WALLET_MEMBER_FAILED,
+				Message:   "Failed to remove joint wallet member",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("username", username),
+					zap.String("member", member),
+				},
+			}
+		}
+		logger.Info(fmt.Sprintf("%s - Member removed", fnName), zap.String("member", member))
+	} else {
+		if err := s.store.UpsertWalletMember(ctx, tx, updated); err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_UPDATE_WALLET_MEMBER_FAILED,
+				Message:   "Failed to save joint wallet member",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Any("member", updated),
+				},
+			}
+		}
+		members[len(members)-1] = *updated
+		logger.Info(fmt.Sprintf("%s - Member saved", fnName), zap.Any("member", updated))
+	}
+
+	joint.Members = members
+	return joint, nil
+}
+
+func (s *JointWalletService) DoSetSigningRule(ctx context.Context, tx *sql.Tx, walletUsername string, payload *request.SigningRulePayload) (*model.JointWallet, *validation.WalletError) {
+	fnName := "JointWalletService.DoSetSigningRule"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("walletUsername", walletUsername), zap.Any("payload", payload))
+
+	username, appErr := validateJointUsername(fnName, walletUsername)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	joint, appErr := s.fetchJointWallet(ctx, fnName, username)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if _, appErr := requireJointOwner(fnName, joint, payload.Actor); appErr != nil {
+		return nil, appErr
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	rules := []model.SigningRule{}
+	for _, r := range joint.Rules {
+		if r.Currency != currency.Code {
+			rules = append(rules, r)
+		}
+	}
+
+	if payload.ApprovalThreshold == nil {
+		if _, err := s.store.DeleteSigningRule(ctx, tx, username, currency.Code); err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_UPDATE_SIGNING_RULE_FAILED,
+				Message:   "Failed to remove signing rule",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("username", username),
+					zap.String("currency", currency.Code),
+				},
+			}
+		}
+		logger.Info(fmt.Sprintf("%s - Signing rule removed", fnName), zap.String("currency", currency.Code))
+		joint.Rules = rules
+		return joint, nil
+	}
+
+	rule := &model.SigningRule{
+		WalletUsername:    username,
+		Currency:          currency.Code,
+		ApprovalThreshold: *payload.ApprovalThreshold,
+		RequiredApprovals: payload.RequiredApprovals,
+	}
+	if appErr := validateSigningRule(fnName, rule, joint.Members); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.store.UpsertSigningRule(ctx, tx, rule); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_SIGNING_RULE_FAILED,
+			Message:   "Failed to save signing rule",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("rule", rule),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Signing rule saved", fnName), zap.Any("rule", rule))
+
+	joint.Rules = append(rules, *rule)
+	return joint, nil
+}
+
+func (s *JointWalletService) DoRequestApproval(ctx context.Context, tx *sql.Tx, auth *model.SpendAuthorization, txnType model.TxnType, payload *request.RequestPayload) (*model.ApprovalRequest, *validation.WalletError) {
+	fnName := "JointWalletService.DoRequestApproval"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("authorization", auth), zap.String("txnType", string(txnType)), zap.Any("payload", payload))
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+
+	pocket, err := validation.SanitizeAndValidatePocket(payload.Pocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", payload.Pocket),
+			},
+		}
+	}
+
+	if payload.Amount <= 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("amount must be greater than 0"),
+			Context: []zap.Field{
+				zap.Int64("amount", payload.Amount),
+			},
+		}
+	}
+
+	var counterparty *string
+	if txnType == model.TypeTransfer {
+		if payload.Counterparty == nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+				Message:   "Counterparty is required for transfers",
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("missing counterparty"),
+			}
+		}
+		sanitized, err := validation.SanitizeAndValidateUsername(*payload.Counterparty)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+				Message:   "Counterparty validation failed",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("counterparty", *payload.Counterparty),
+				},
+			}
+		}
+		counterparty = &sanitized
+
+		// A quote expires and is consumed once, long before the last approval
+		// usually comes in, so a cross-currency transfer cannot wait for one.
+		crossCurrency := false
+		if payload.CounterpartyCurrency != nil {
+			counterpartyCurrency, err := validation.SanitizeAndValidateCurrency(*payload.CounterpartyCurrency)
+			crossCurrency = err != nil || counterpartyCurrency.Code != currency.Code
+		}
+		if payload.QuoteID != nil || crossCurrency {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_CROSS_CURRENCY_TRANSFER,
+				Message:   "Cross-currency transfers cannot wait for approval",
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("%s needs %d approvals for a quoted transfer", auth.Username, auth.RequiredApprovals),
+			}
+		}
+	}
+
+	memo, reference, metadata, appErr := sanitizeTransactionDetails(fnName, payload.Memo, payload.Reference, payload.Metadata)
+	if appErr != nil {
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Request validated", fnName), zap.String("currency", currency.Code), zap.String("pocket", pocket))
+
+	approval := &model.ApprovalRequest{
+		WalletUsername:       auth.Username,
+		Currency:             currency.Code,
+		Pocket:               pocket,
+		TxnType:              txnType,
+		Amount:               payload.Amount,
+		Counterparty:         counterparty,
+		CounterpartyCurrency: payload.CounterpartyCurrency,
+		QuoteID:              payload.QuoteID,
+		Memo:                 memo,
+		Reference:            reference,
+		Metadata:             metadata,
+		Initiator:            auth.Actor,
+		RequiredApprovals:    auth.RequiredApprovals,
+		ExpiresAt:            time.Now().UTC().Add(s.config.TTL),
+	}
+	if err := s.store.InsertApprovalRequest(ctx, tx, approval); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_APPROVAL_FAILED,
+			Message:   "Failed to create approval request",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("approval", approval),
+			},
+		}
+	}
+
+	if _, err := s.store.InsertApprovalVote(ctx, tx, approval.ID, auth.Actor); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_APPROVAL_FAILED,
+			Message:   "Failed to record initiator approval",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("approvalID", approval.ID),
+				zap.String("actor", auth.Actor),
+			},
+		}
+	}
+	approval.Approvals = []string{auth.Actor}
+	logger.Info(fmt.Sprintf("%s - Approval request created", fnName), zap.Any("approval", approval))
+	return approval, nil
+}
+
+func (s *JointWalletService) DoApprove(ctx context.Context, tx *sql.Tx, id int64, payload *request.ApprovalDecisionPayload) (*model.ApprovalRequest, bool, *validation.WalletError) {
+	fnName := "JointWalletService.DoApprove"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id), zap.Any("payload", payload))
+
+	approval, actor, appErr := s.fetchPendingApproval(ctx, tx, fnName, id, payload.Actor)
+	if appErr != nil {
+		return nil, false, appErr
+	}
+
+	inserted, err := s.store.InsertApprovalVote(ctx, tx, approval.ID, actor)
+	if err != nil {
+		return nil, false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_APPROVAL_FAILED,
+			Message:   "Failed to record approval",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("approvalID", approval.ID),
+				zap.String("actor", actor),
+			},
+		}
+	}
+	if !inserted {
+		return nil, false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_APPROVAL_ALREADY_GIVEN,
+			Message:   fmt.Sprintf("%s has already approved this request", actor),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("duplicate approval by %s on request %d", actor, approval.ID),
+		}
+	}
+	approval.Approvals = append(approval.Approvals, actor)
+	logger.Info(fmt.Sprintf("%s - Approval recorded", fnName), zap.Int64("approvalID", approval.ID), zap.Strings("approvals", approval.Approvals))
+	return approval, approval.Approved(), nil
+}
+
+func (s *JointWalletService) DoReject(ctx context.Context, tx *sql.Tx, id int64, payload *request.ApprovalDecisionPayload) (*model.ApprovalRequest, *validation.WalletError) {
+	fnName := "JointWalletService.DoReject"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id), zap.Any("payload", payload))
+
+	approval, actor, appErr := s.fetchPendingApproval(ctx, tx, fnName, id, payload.Actor)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	resolvedAt := time.Now().UTC()
+	approval.Status = model.ApprovalRejected
+	approval.ResolvedAt = &resolvedAt
+	if err := s.store.ResolveApprovalRequest(ctx, tx, approval); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_APPROVAL_FAILED,
+			Message:   "Failed to reject approval request",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("approvalID", approval.ID),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Approval request rejected", fnName), zap.Int64("approvalID", approval.ID), zap.String("actor", actor))
+	return approval, nil
+}
+
+func (s *JointWalletService) DoMarkExecuted(ctx context.Context, tx *sql.Tx, approval *model.ApprovalRequest, transactionID int64) *validation.WalletError {
+	fnName := "JointWalletService.DoMarkExecuted"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("approvalID", approval.ID), zap.Int64("transactionID", transactionID))
+
+	resolvedAt := time.Now().UTC()
+	approval.Status = model.ApprovalExecuted
+	approval.TransactionID = &transactionID
+	approval.ResolvedAt = &resolvedAt
+	if err := s.store.ResolveApprovalRequest(ctx, tx, approval); err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_APPROVAL_FAILED,
+			Message:   "Failed to mark approval request executed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("approvalID", approval.ID),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Approval request executed", fnName), zap.Any("approval", approval))
+	return nil
+}
+
+func (s *JointWalletService) DoFetchApprovals(ctx context.Context, username string, status string) ([]model.ApprovalRequest, *validation.WalletError) {
+	fnName := "JointWalletService.DoFetchApprovals"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("status", status))
+
+	username, appErr := validateJointUsername(fnName, username)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	switch model.ApprovalStatus(status) {
+	case "", model.ApprovalPending, model.ApprovalExecuted, model.ApprovalRejected, model.ApprovalExpired:
+	default:
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_APPROVAL_FAILED,
+			Message:   fmt.Sprintf("Approval status %q is not supported", status),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("invalid approval status %q", status),
+		}
+	}
+
+	approvals, err := s.store.FetchApprovalRequests(ctx, username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_APPROVAL_FAILED,
+			Message:   "Failed to fetch approval requests",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	now := time.Now().UTC()
+	filtered := []model.ApprovalRequest{}
+	for _, approval := range approvals {
+		if approval.Status == model.ApprovalPending && !now.Before(approval.ExpiresAt) {
+			approval.Status = model.ApprovalExpired
+		}
+		if status == "" || approval.Status == model.ApprovalStatus(status) {
+			filtered = append(filtered, approval)
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Approval requests fetched", fnName), zap.Int("count", len(filtered)))
+	return filtered, nil
+}
+
+func (s *JointWalletService) fetchPendingApproval(ctx context.Context, tx *sql.Tx, fnName string, id int64, rawActor string) (*model.ApprovalRequest, string, *validation.WalletError) {
+	actor, err := validation.SanitizeAndValidateUsername(rawActor)
+	if err != nil {
+		return nil, "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Actor validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("actor", rawActor),
+			},
+		}
+	}
+
+	approval, err := s.store.FetchApprovalRequestForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_APPROVAL_FAILED,
+			Message:   "Failed to fetch approval request",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if approval == nil {
+		return nil, "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_APPROVAL_NOT_FOUND,
+			Message:   "Approval request not found",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("approval request %d does not exist", id),
+		}
+	}
+	if approval.Status != model.ApprovalPending {
+		return nil, "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_APPROVAL_NOT_PENDING,
+			Message:   fmt.Sprintf("Approval request is already %s", approval.Status),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("approval request %d is %s", id, approval.Status),
+		}
+	}
+	if !time.Now().UTC().Before(approval.ExpiresAt) {
+		return nil, "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_APPROVAL_NOT_PENDING,
+			Message:   "Approval request has expired",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("approval request %d expired at %s", id, approval.ExpiresAt.Format(time.RFC3339)),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pending approval request fetched", fnName), zap.Any("approval", approval))
+
+	members, appErr := s.fetchMembers(ctx, fnName, approval.WalletUsername)
+	if appErr != nil {
+		return nil, "", appErr
+	}
+	role := memberRole(members, actor)
+	if !role.CanSpend() {
+		return nil, "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_ACCESS_DENIED,
+			Message:   "Only owners and spenders can decide on approval requests",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("%s has role %q on %s", actor, role, approval.WalletUsername),
+		}
+	}
+	return approval, actor, nil
+}
+
+func (s *JointWalletService) fetchJointWallet(ctx context.Context, fnName string, username string) (*model.JointWallet, *validation.WalletError) {
+	members, appErr := s.fetchMembers(ctx, fnName, username)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if len(members) == 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_JOINT_WALLET_NOT_FOUND,
+			Message:   "Joint wallet not found",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("%s has no members", username),
+		}
+	}
+
+	rules, err := s.store.FetchSigningRules(ctx, username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_ACCESS_FAILED,
+			Message:   "Failed to fetch signing rules",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	return &model.JointWallet{
+		Username: username,
+		Members:  members,
+		Rules:    rules,
+	}, nil
+}
+
+func (s *JointWalletService) fetchMembers(ctx context.Context, fnName string, username string) ([]model.WalletMember, *validation.WalletError) {
+	members, err := s.store.FetchWalletMembers(ctx, username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_ACCESS_FAILED,
+			Message:   "Failed to fetch wallet members",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+	return members, nil
+}
+
+func requireJointOwner(fnName string, joint *model.JointWallet, rawActor string) (string, *validation.WalletError) {
+	actor, err := validation.SanitizeAndValidateUsername(rawActor)
+	if err != nil {
+		return "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Actor validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("actor", rawActor),
+			},
+		}
+	}
+	if memberRole(joint.Members, actor) != model.RoleOwner {
+		return "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_ACCESS_DENIED,
+			Message:   "Only owners can manage a joint wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("%s is not an owner of %s", actor, joint.Username),
+		}
+	}
+	return actor, nil
+}
+
+func validateJointUsername(fnName string, rawUsername string) (string, *validation.WalletError) {
+	username, err := validation.SanitizeAndValidateUsername(rawUsername)
+	if err != nil {
+		return "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", rawUsername),
+			},
+		}
+	}
+	if validation.IsReservedUsername(username) {
+		return "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "System wallets cannot be joint wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s is reserved", username),
+		}
+	}
+	return username, nil
+}
+
+func validateJointMember(fnName string, walletUsername string, rawMember string) (string, *validation.WalletError) {
+	member, err := validation.SanitizeAndValidateUsername(rawMember)
+	if err != nil {
+		return "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Member validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("member", rawMember),
+			},
+		}
+	}
+	if validation.IsReservedUsername(member) || member == walletUsername {
+		return "", &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_JOINT_MEMBER_INVALID,
+			Message:   fmt.Sprintf("%s cannot be a member of the joint wallet", member),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("invalid member %s for %s", member, walletUsername),
+		}
+	}
+	return member, nil
+}
+
+func validateJointMembers(fnName string, members []model.WalletMember, rules []model.SigningRule) *validation.WalletError {
+	if len(members) < minJointMembers {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_JOINT_MEMBER_INVALID,
+			Message:   fmt.Sprintf("A joint wallet needs at least %d members", minJointMembers),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("change would leave %d members", len(members)),
+		}
+	}
+
+	owners := 0
+	for _, m := range members {
+		if m.Role == model.RoleOwner {
+			owners++
+		}
+	}
+	if owners == 0 {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_JOINT_MEMBER_INVALID,
+			Message:   "A joint wallet needs at least one owner",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("change would leave no owners"),
+		}
+	}
+
+	for i := range rules {
+		if appErr := validateSigningRule(fnName, &rules[i], members); appErr != nil {
+			return appErr
+		}
+	}
+	return nil
+}
+
+func validateSigningRule(fnName string, rule *model.SigningRule, members []model.WalletMember) *validation.WalletError {
+	spenders := 0
+	for _, m := range members {
+		if m.Role.CanSpend() {
+			spenders++
+		}
+	}
+
+	var err error
+	switch {
+	case rule.ApprovalThreshold < 0:
+		err = fmt.Errorf("approval threshold must not be negative")
+	case rule.RequiredApprovals < 2:
+		err = fmt.Errorf("required approvals must be at least 2")
+	case rule.RequiredApprovals > spenders:
+		err = fmt.Errorf("required approvals %d exceeds the %d members who can spend", rule.RequiredApprovals, spenders)
+	}
+	if err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SIGNING_RULE_INVALID,
+			Message:   "Signing rule validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("rule", rule),
+			},
+		}
+	}
+	return nil
+}
+
+func memberRole(members []model.WalletMember, member string) model.WalletRole {
+	for _, m := range members {
+		if m.Member == member {
+			return m.Role
+		}
+	}
+	return ""
+}
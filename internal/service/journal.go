@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type JournalStore interface {
+	InsertJournalEntry(ctx context.Context, tx *sql.Tx, entry *model.JournalEntry) error
+	FetchWalletLedgerBalance(ctx context.Context, tx *sql.Tx, walletID int64) (int64, int64, error)
+}
+
+type JournalService struct {
+	store JournalStore
+}
+
+func NewJournalService(store JournalStore) *JournalService {
+	logger.Debug("Initializing JournalService")
+	return &JournalService{store: store}
+}
+
+func (s *JournalService) PostDeposit(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, amount int64) (*model.JournalEntry, *validation.WalletError) {
+	return s.PostEntry(ctx, tx, model.TypeDeposit, []model.Posting{
+		model.SystemPosting(model.AccountCash, wallet.Currency, model.DirectionDebit, amount),
+		model.WalletPosting(wallet.ID, wallet.Currency, model.DirectionCredit, amount),
+	})
+}
+
+func (s *JournalService) PostWithdraw(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, amount int64) (*model.JournalEntry, *validation.WalletError) {
+	return s.PostEntry(ctx, tx, model.TypeWithdraw, []model.Posting{
+		model.WalletPosting(wallet.ID, wallet.Currency, model.DirectionDebit, amount),
+		model.SystemPosting(model.AccountCash, wallet.Currency, model.DirectionCredit, amount),
+	})
+}
+
+func (s *JournalService) PostFee(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, feeWallet *model.Wallet, amount int64) (*model.JournalEntry, *validation.WalletError) {
+	return s.PostEntry(ctx, tx, model.TypeFee, []model.Posting{
+		model.WalletPosting(wallet.ID, wallet.Currency, model.DirectionDebit, amount),
+		model.WalletPosting(feeWallet.ID, feeWallet.Currency, model.DirectionCredit, amount),
+	})
+}
+
+func (s *JournalService) PostTransfer(ctx context.Context, tx *sql.Tx, from *model.Wallet, to *model.Wallet, amount int64) (*model.JournalEntry, *validation.WalletError) {
+	return s.PostEntry(ctx, tx, model.TypeTransfer, []model.Posting{
+		model.WalletPosting(from.ID, from.Currency, model.DirectionDebit, amount),
+		model.WalletPosting(to.ID, to.Currency, model.DirectionCredit, amount),
+	})
+}
+
+func (s *JournalService) PostPocketMove(ctx context.Context, tx *sql.Tx, from *model.Wallet, to *model.Wallet, amount int64) (*model.JournalEntry, *validation.WalletError) {
+	return s.PostEntry(ctx, tx, model.TypePocketMove, []model.Posting{
+		model.WalletPosting(from.ID, from.Currency, model.DirectionDebit, amount),
+		model.WalletPosting(to.ID, to.Currency, model.DirectionCredit, amount),
+	})
+}
+
+func (s *JournalService) PostFXTransfer(ctx context.Context, tx *sql.Tx, from *model.Wallet, to *model.Wallet, debitAmount int64, creditAmount int64) (*model.JournalEntry, *validation.WalletError) {
+	return s.PostEntry(ctx, tx, model.TypeTransfer, []model.Posting{
+		model.WalletPosting(from.ID, from.Currency, model.DirectionDebit, debitAmount),
+		model.SystemPosting(model.AccountFX, from.Currency, model.DirectionCredit, debitAmount),
+		model.SystemPosting(model.AccountFX, to.Currency, model.DirectionDebit, creditAmount),
+		model.WalletPosting(to.ID, to.Currency, model.DirectionCredit, creditAmount),
+	})
+}
+
+func (s *JournalService) PostReversal(ctx context.Context, tx *sql.Tx, legs []model.ReversalLeg) (*model.JournalEntry, *validation.WalletError) {
+	postings := []model.Posting{}
+	net := map[string]int64{}
+	currencies := []string{}
+	account := model.AccountCash
+	for _, leg := range legs {
+		postings = append(postings, model.WalletPosting(leg.Wallet.ID, leg.Wallet.Currency, leg.Direction, leg.Amount))
+		if _, ok := net[leg.Wallet.Currency]; !ok {
+			currencies = append(currencies, leg.Wallet.Currency)
+		}
+		if leg.Direction == model.DirectionDebit {
+			net[leg.Wallet.Currency] += leg.Amount
+		} else {
+			net[leg.Wallet.Currency] -= leg.Amount
+		}
+		if leg.Original.TxnType == model.TypeTransferIn || leg.Original.TxnType == model.TypeTransferOut {
+			account = model.AccountFX
+		}
+	}
+	for _, currency := range currencies {
+		switch {
+		case net[currency] > 0:
+			postings = append(postings, model.SystemPosting(account, currency, model.DirectionCredit, net[currency]))
+		case net[currency] < 0:
+			postings = append(postings, model.SystemPosting(account, currency, model.DirectionDebit, -net[currency]))
+		}
+	}
+	return s.PostEntry(ctx, tx, model.TypeReversal, postings)
+}
+
+func (s *JournalService) PostEntry(ctx context.Context, tx *sql.Tx, entryType model.TxnType, postings []model.Posting) (*model.JournalEntry, *validation.WalletError) {
+	fnName := "JournalService.PostEntry"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("entryType", string(entryType)), zap.Any("postings", postings))
+
+	if err := validateJournalPostings(postings); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_JOURNAL_ENTRY_UNBALANCED,
+			Message:   "Journal entry is not balanced",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("entryType", string(entryType)),
+				zap.Any("postings", postings),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Postings balanced", fnName))
+
+	entry := &model.JournalEntry{
+		EntryType: entryType,
+		Timestamp: time.Now().UTC(),
+		Postings:  postings,
+	}
+	if err := s.store.InsertJournalEntry(ctx, tx, entry); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POST_JOURNAL_FAILED,
+			Message:   "Failed to post journal entry",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("entry", entry),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+
+	checked := map[int64]struct{}{}
+	for _, posting := range entry.Postings {
+		if posting.WalletID == nil {
+			continue
+		}
+		if _, ok := checked[*posting.WalletID]; ok {
+			continue
+		}
+		checked[*posting.WalletID] = struct{}{}
+
+		if appErr := s.verifyWalletBalance(ctx, tx, *posting.WalletID); appErr != nil {
+			return nil, appErr
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet balances match ledger", fnName), zap.Int("wallets", len(checked)))
+	return entry, nil
+}
+
+func (s *JournalService) verifyWalletBalance(ctx context.Context, tx *sql.Tx, walletID int64) *validation.WalletError {
+	fnName := "JournalService.verifyWalletBalance"
+	walletBalance, ledgerBalance, err := s.store.FetchWalletLedgerBalance(ctx, tx, walletID)
+	if err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_LEDGER_BALANCE_FAILED,
+			Message:   "Failed to fetch ledger balance",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("walletID", walletID),
+			},
+		}
+	}
+
+	if walletBalance != ledgerBalance {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_LEDGER_BALANCE_MISMATCH,
+			Message:   "Wallet balance does not match ledger",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("wallet balance %d does not match ledger balance %d", walletBalance, ledgerBalance),
+			Context: []zap.Field{
+				zap.Int64("walletID", walletID),
+				zap.Int64("walletBalance", walletBalance),
+				zap.Int64("ledgerBalance", ledgerBalance),
+			},
+		}
+	}
+	return nil
+}
+
+func validateJournalPostings(postings []model.Posting) error {
+	if len(postings) < 2 {
+		return fmt.Errorf("journal entry requires at least 2 postings, got %d", len(postings))
+	}
+
+	imbalances := map[string]int64{}
+	for _, posting := range postings {
+		if posting.Amount <= 0 {
+			return fmt.Errorf("posting amount must be greater than 0")
+		}
+		if (posting.WalletID == nil) == (posting.SystemAccount == nil) {
+			return fmt.Errorf("posting must reference exactly one wallet or system account")
+		}
+		if posting.Currency == "" {
+			return fmt.Errorf("posting currency cannot be empty")
+		}
+		switch posting.Direction {
+		case model.DirectionDebit:
+			imbalances[posting.Currency] += posting.Amount
+		case model.DirectionCredit:
+			imbalances[posting.Currency] -= posting.Amount
+		default:
+			return fmt.Errorf("invalid posting direction %q", posting.Direction)
+		}
+	}
+
+	for currency, imbalance := range imbalances {
+		if imbalance != 0 {
+			return fmt.Errorf("debits do not equal credits for %s, off by %d", currency, imbalance)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockJournalStore struct {
+	walletBalances map[int64]int64
+	ledgerBalances map[int64]int64
+	entries        []model.JournalEntry
+}
+
+func (m *mockJournalStore) initializeMockLedger() {
+	m.walletBalances = map[int64]int64{
+		1: 2500,
+		2: 1500,
+		3: 700,
+	}
+	m.ledgerBalances = map[int64]int64{
+		1: 2000,
+		2: 2000,
+		3: 500,
+	}
+}
+
+func (m *mockJournalStore) InsertJournalEntry(ctx context.Context, tx *sql.Tx, entry *model.JournalEntry) error {
+	entry.ID = int64(len(m.entries) + 1)
+	for _, posting := range entry.Postings {
+		if posting.WalletID == nil {
+			continue
+		}
+		switch posting.Direction {
+		case model.DirectionCredit:
+			m.ledgerBalances[*posting.WalletID] += posting.Amount
+		case model.DirectionDebit:
+			m.ledgerBalances[*posting.WalletID] -= posting.Amount
+		}
+	}
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *mockJournalStore) FetchWalletLedgerBalance(ctx context.Context, tx *sql.Tx, walletID int64) (int64, int64, error) {
+	return m.walletBalances[walletID], m.ledgerBalances[walletID], nil
+}
+
+func TestPostEntry(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name         string
+		postings     []model.Posting
+		expectedCode validation.WalletErrorCode
+		expectErr    bool
+	}
+
+	tests := []testCase{
+		{
+			name: "Successful Post - Deposit from cash",
+			postings: []model.Posting{
+				model.SystemPosting(model.AccountCash, "USD", model.DirectionDebit, 500),
+				model.WalletPosting(1, "USD", model.DirectionCredit, 500),
+			},
+			expectErr: false,
+		},
+		{
+			name: "Successful Post - Withdraw to cash",
+			postings: []model.Posting{
+				model.WalletPosting(2, "USD", model.DirectionDebit, 500),
+				model.SystemPosting(model.AccountCash, "USD", model.DirectionCredit, 500),
+			},
+			expectErr: false,
+		},
+		{
+			name: "Successful Post - Transfer between wallets",
+			postings: []model.Posting{
+				model.WalletPosting(2, "USD", model.DirectionDebit, 500),
+				model.WalletPosting(1, "USD", model.DirectionCredit, 500),
+			},
+			expectErr: false,
+		},
+		{
+			name: "Failed Post - Debits do not equal credits",
+			postings: []model.Posting{
+				model.SystemPosting(model.AccountCash, "USD", model.DirectionDebit, 400),
+				model.WalletPosting(1, "USD", model.DirectionCredit, 500),
+			},
+			expectedCode: validation.ERR_JOURNAL_ENTRY_UNBALANCED,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Post - Balanced total across different currencies",
+			postings: []model.Posting{
+				model.SystemPosting(model.AccountCash, "EUR", model.DirectionDebit, 500),
+				model.WalletPosting(1, "USD", model.DirectionCredit, 500),
+			},
+			expectedCode: validation.ERR_JOURNAL_ENTRY_UNBALANCED,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Post - Single posting",
+			postings: []model.Posting{
+				model.WalletPosting(1, "USD", model.DirectionCredit, 500),
+			},
+			expectedCode: validation.ERR_JOURNAL_ENTRY_UNBALANCED,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Post - Zero amount posting",
+			postings: []model.Posting{
+				model.SystemPosting(model.AccountCash, "USD", model.DirectionDebit, 0),
+				model.WalletPosting(1, "USD", model.DirectionCredit, 0),
+			},
+			expectedCode: validation.ERR_JOURNAL_ENTRY_UNBALANCED,
+			expectErr:    true,
+		},
+		{
+			name: "Failed Post - Wallet balance drifted from ledger",
+			postings: []model.Posting{
+				model.SystemPosting(model.AccountCash, "USD", model.DirectionDebit, 100),
+				model.WalletPosting(3, "USD", model.DirectionCredit, 100),
+			},
+			expectedCode: validation.ERR_LEDGER_BALANCE_MISMATCH,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockJournalStore{}
+			mock.initializeMockLedger()
+			s := &JournalService{store: mock}
+			entry, err := s.PostEntry(context.Background(), nil, model.TypeDeposit, test.postings)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && test.expectedCode != err.Code {
+				t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && entry != nil && len(entry.Postings) != len(test.postings) {
+				t.Errorf("expected %d postings but got %d instead", len(test.postings), len(entry.Postings))
+			}
+		})
+	}
+}
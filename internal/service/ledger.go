@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const chainBatchSize = 1000
+
+type LedgerStore interface {
+	FetchTransactionChain(ctx context.Context, afterID int64, limit int) ([]model.Transaction, error)
+}
+
+type LedgerService struct {
+	store LedgerStore
+}
+
+func NewLedgerService(store LedgerStore) *LedgerService {
+	logger.Info("Initializing LedgerService")
+	return &LedgerService{store: store}
+}
+
+func (ls *LedgerService) DoVerifyChain(ctx context.Context) (*model.ChainVerification, *validation.WalletError) {
+	fnName := "LedgerService.DoVerifyChain"
+	result := &model.ChainVerification{
+		Verified: true,
+		LastHash: model.GenesisHash,
+	}
+
+	var afterID int64
+	var minVersion int
+	for {
+		batch, err := ls.store.FetchTransactionChain(ctx, afterID, chainBatchSize)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_FETCH_TRANSACTION_CHAIN_FAILED,
+				Message:   "Failed to fetch transaction chain",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Int64("afterID", afterID),
+				},
+			}
+		}
+
+		for i := range batch {
+			txn := &batch[i]
+			if chainBreak := checkChainLink(result.LastHash, minVersion, txn); chainBreak != nil {
+				result.Verified = false
+				result.FirstBreak = chainBreak
+				logger.Warn(fmt.Sprintf("%s - Transaction chain broken", fnName), zap.Any("break", chainBreak), zap.Int64("checked", result.Checked))
+				return result, nil
+			}
+			result.LastHash = txn.Hash
+			minVersion = txn.HashVersion
+			result.Checked++
+			afterID = txn.ID
+		}
+
+		if len(batch) < chainBatchSize {
+			break
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Transaction chain verified", fnName), zap.Int64("checked", result.Checked), zap.String("lastHash", result.LastHash))
+	return result, nil
+}
+
+// checkChainLink verifies txn against the row before it. hash_version is not
+// part of the hash, so a row may not use an older version than the row before
+// it; otherwise an edited row could be rehashed in a format that leaves the
+// edited field out.
+func checkChainLink(expectedPrevHash string, minVersion int, txn *model.Transaction) *model.ChainBreak {
+	expectedHash := utils.GenerateTransactionHash(expectedPrevHash, txn)
+	chainBreak := &model.ChainBreak{
+		TransactionID:    txn.ID,
+		ExpectedPrevHash: expectedPrevHash,
+		ActualPrevHash:   txn.PrevHash,
+		ExpectedHash:     expectedHash,
+		ActualHash:       txn.Hash,
+		MinHashVersion:   minVersion,
+		HashVersion:      txn.HashVersion,
+	}
+	if txn.PrevHash != expectedPrevHash {
+		chainBreak.Reason = model.BreakPrevHashMismatch
+		return chainBreak
+	}
+	if txn.HashVersion < minVersion {
+		chainBreak.Reason = model.BreakVersionDowngrade
+		return chainBreak
+	}
+	if txn.Hash != expectedHash {
+		chainBreak.Reason = model.BreakHashMismatch
+		return chainBreak
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/utils"
+)
+
+type mockLedgerStore struct {
+	transactions []model.Transaction
+}
+
+func (m *mockLedgerStore) initializeMockChain(count int) {
+	m.transactions = []model.Transaction{}
+	prevHash := model.GenesisHash
+	timestamp := time.Date(2025, 6, 22, 13, 44, 27, 260471000, time.UTC)
+	for i := 1; i <= count; i++ {
+		txn := model.Transaction{
+			ID:          int64(i),
+			Username:    "JUAN",
+			TxnType:     model.TypeDeposit,
+			Currency:    "USD",
+			Amount:      int64(i * 100),
+			Timestamp:   timestamp.Add(time.Duration(i) * time.Second),
+			PrevHash:    prevHash,
+			HashVersion: model.CurrentHashVersion,
+		}
+		if i%2 == 0 {
+			txn.TxnType = model.TypeTransferOut
+			txn.Counterparty = utils.Ptr("MARY")
+		}
+		if i%3 == 0 {
+			txn.Memo = utils.Ptr("Invoice 1042")
+			txn.Reference = utils.Ptr("INV-1042")
+			txn.Metadata = map[string]string{"order": "1042", "channel": "web"}
+		}
+		txn.Hash = utils.GenerateTransactionHash(prevHash, &txn)
+		prevHash = txn.Hash
+		m.transactions = append(m.transactions, txn)
+	}
+}
+
+func (m *mockLedgerStore) rehashMockChain(versions ...int) {
+	prevHash := model.GenesisHash
+	for i := range m.transactions {
+		txn := &m.transactions[i]
+		if i < len(versions) {
+			txn.HashVersion = versions[i]
+		}
+		txn.PrevHash = prevHash
+		txn.Hash = utils.GenerateTransactionHash(prevHash, txn)
+		prevHash = txn.Hash
+	}
+}
+
+func (m *mockLedgerStore) FetchTransactionChain(ctx context.Context, afterID int64, limit int) ([]model.Transaction, error) {
+	batch := []model.Transaction{}
+	for _, txn := range m.transactions {
+		if txn.ID > afterID && len(batch) < limit {
+			batch = append(batch, txn)
+		}
+	}
+	return batch, nil
+}
+
+func TestDoVerifyChain(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name            string
+		count           int
+		tamper          func(m *mockLedgerStore)
+		expectVerified  bool
+		expectedChecked int64
+		expectedBreakID int64
+		expectedReason  model.ChainBreakReason
+	}
+
+	tests := []testCase{
+		{
+			name:            "Verified Chain - Empty log",
+			count:           0,
+			expectVerified:  true,
+			expectedChecked: 0,
+		},
+		{
+			name:            "Verified Chain - Untouched log",
+			count:           5,
+			expectVerified:  true,
+			expectedChecked: 5,
+		},
+		{
+			name:            "Verified Chain - Spans multiple batches",
+			count:           chainBatchSize + 3,
+			expectVerified:  true,
+			expectedChecked: int64(chainBatchSize + 3),
+		},
+		{
+			name:  "Broken Chain - Edited amount",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions[2].Amount = 1
+			},
+			expectVerified:  false,
+			expectedChecked: 2,
+			expectedBreakID: 3,
+			expectedReason:  model.BreakHashMismatch,
+		},
+		{
+			name:  "Broken Chain - Edited counterparty",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions[3].Counterparty = utils.Ptr("JOHN")
+			},
+			expectVerified:  false,
+			expectedChecked: 3,
+			expectedBreakID: 4,
+			expectedReason:  model.BreakHashMismatch,
+		},
+		{
+			name:  "Broken Chain - Edited memo",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions[2].Memo = utils.Ptr("Refund")
+			},
+			expectVerified:  false,
+			expectedChecked: 2,
+			expectedBreakID: 3,
+			expectedReason:  model.BreakHashMismatch,
+		},
+		{
+			name:  "Broken Chain - Edited metadata",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions[2].Metadata["order"] = "9999"
+			},
+			expectVerified:  false,
+			expectedChecked: 2,
+			expectedBreakID: 3,
+			expectedReason:  model.BreakHashMismatch,
+		},
+		{
+			name:  "Broken Chain - Reference added afterwards",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions[0].Reference = utils.Ptr("INV-1042")
+			},
+			expectVerified:  false,
+			expectedChecked: 0,
+			expectedBreakID: 1,
+			expectedReason:  model.BreakHashMismatch,
+		},
+		{
+			name:  "Verified Chain - Rows hashed under every version",
+			count: 6,
+			tamper: func(m *mockLedgerStore) {
+				m.rehashMockChain(model.HashVersionOriginal, model.HashVersionCurrency, model.HashVersionChained, model.HashVersionJournal, model.HashVersionPocket, model.HashVersionDetails)
+			},
+			expectVerified:  true,
+			expectedChecked: 6,
+		},
+		{
+			name:  "Broken Chain - Edited row hashed before the chain",
+			count: 6,
+			tamper: func(m *mockLedgerStore) {
+				m.rehashMockChain(model.HashVersionOriginal, model.HashVersionCurrency, model.HashVersionChained)
+				m.transactions[1].Amount = 1
+			},
+			expectVerified:  false,
+			expectedChecked: 1,
+			expectedBreakID: 2,
+			expectedReason:  model.BreakHashMismatch,
+		},
+		{
+			name:  "Broken Chain - Hash version downgraded to drop details",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions[2].HashVersion = model.HashVersionPocket
+			},
+			expectVerified:  false,
+			expectedChecked: 2,
+			expectedBreakID: 3,
+			expectedReason:  model.BreakVersionDowngrade,
+		},
+		{
+			name:  "Broken Chain - Edited row rehashed and relinked under an older version",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions[2].Memo = utils.Ptr("Refund")
+				m.rehashMockChain(model.CurrentHashVersion, model.CurrentHashVersion, model.HashVersionOriginal)
+			},
+			expectVerified:  false,
+			expectedChecked: 2,
+			expectedBreakID: 3,
+			expectedReason:  model.BreakVersionDowngrade,
+		},
+		{
+			name:  "Broken Chain - Unknown hash version",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions[0].HashVersion = 0
+			},
+			expectVerified:  false,
+			expectedChecked: 0,
+			expectedBreakID: 1,
+			expectedReason:  model.BreakHashMismatch,
+		},
+		{
+			name:  "Broken Chain - Deleted row",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				m.transactions = append(m.transactions[:1], m.transactions[2:]...)
+			},
+			expectVerified:  false,
+			expectedChecked: 1,
+			expectedBreakID: 3,
+			expectedReason:  model.BreakPrevHashMismatch,
+		},
+		{
+			name:  "Broken Chain - Rehashed row without relinking",
+			count: 5,
+			tamper: func(m *mockLedgerStore) {
+				txn := &m.transactions[1]
+				txn.Amount = 1
+				txn.Hash = utils.GenerateTransactionHash(txn.PrevHash, txn)
+			},
+			expectVerified:  false,
+			expectedChecked: 2,
+			expectedBreakID: 3,
+			expectedReason:  model.BreakPrevHashMismatch,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockLedgerStore{}
+			mock.initializeMockChain(test.count)
+			if test.tamper != nil {
+				test.tamper(mock)
+			}
+			s := &LedgerService{store: mock}
+
+			actual, err := s.DoVerifyChain(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectVerified != actual.Verified {
+				t.Errorf("expected verified %t but got %t instead", test.expectVerified, actual.Verified)
+			}
+
+			if test.expectedChecked != actual.Checked {
+				t.Errorf("expected checked %d but got %d instead", test.expectedChecked, actual.Checked)
+			}
+
+			if test.expectVerified && actual.FirstBreak != nil {
+				t.Errorf("expected no break but got transaction %d", actual.FirstBreak.TransactionID)
+			}
+
+			if !test.expectVerified && actual.FirstBreak == nil {
+				t.Fatalf("expected break but got nil")
+			}
+
+			if !test.expectVerified && test.expectedBreakID != actual.FirstBreak.TransactionID {
+				t.Errorf("expected break at transaction %d but got %d instead", test.expectedBreakID, actual.FirstBreak.TransactionID)
+			}
+
+			if !test.expectVerified && test.expectedReason != actual.FirstBreak.Reason {
+				t.Errorf("expected break reason %s but got %s instead", test.expectedReason, actual.FirstBreak.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const maxStatusNoteLength = 500
+
+type LifecycleStore interface {
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	FetchPocketsForUpdate(ctx context.Context, tx *sql.Tx, username string, currency string) ([]model.Wallet, error)
+	FetchEscrows(ctx context.Context, criteria *model.EscrowCriteria) ([]model.Escrow, error)
+	UpdateWalletStatus(ctx context.Context, tx *sql.Tx, username string, currency string, status model.WalletStatus) ([]model.Wallet, error)
+	InsertWalletStatusChange(ctx context.Context, tx *sql.Tx, change *model.WalletStatusChange) error
+	FetchWalletStatusChanges(ctx context.Context, username string, currency string) ([]model.WalletStatusChange, error)
+}
+
+type LifecycleService struct {
+	store LifecycleStore
+}
+
+func NewLifecycleService(store LifecycleStore) *LifecycleService {
+	logger.Info("Initializing LifecycleService")
+	return &LifecycleService{store: store}
+}
+
+func (s *LifecycleService) DoFreezeWallet(ctx context.Context, tx *sql.Tx, payload *request.WalletStatusPayload) (*model.WalletStatusChange, *validation.WalletError) {
+	fnName := "LifecycleService.DoFreezeWallet"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	target, ok := model.FreezeStatus(payload.Mode)
+	if !ok {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_STATUS_TRANSITION_INVALID,
+			Message:   "Freeze mode must be debit or all",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("invalid freeze mode %q", payload.Mode),
+		}
+	}
+
+	return s.changeStatus(ctx, tx, fnName, payload, func(current model.WalletStatus, _ []model.Wallet) (model.WalletStatus, *validation.WalletError) {
+		if current == target {
+			return "", &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_WALLET_STATUS_TRANSITION_INVALID,
+				Message:   fmt.Sprintf("Wallet is already %s", current),
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("wallet is already %s", current),
+			}
+		}
+		return target, nil
+	})
+}
+
+func (s *LifecycleService) DoUnfreezeWallet(ctx context.Context, tx *sql.Tx, payload *request.WalletStatusPayload) (*model.WalletStatusChange, *validation.WalletError) {
+	fnName := "LifecycleService.DoUnfreezeWallet"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	return s.changeStatus(ctx, tx, fnName, payload, func(current model.WalletStatus, _ []model.Wallet) (model.WalletStatus, *validation.WalletError) {
+		if !current.IsFrozen() {
+			return "", &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_WALLET_STATUS_TRANSITION_INVALID,
+				Message:   "Only a frozen wallet can be unfrozen",
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("wallet is %s", current),
+			}
+		}
+		return model.WalletActive, nil
+	})
+}
+
+func (s *LifecycleService) DoCloseWallet(ctx context.Context, tx *sql.Tx, payload *request.WalletStatusPayload) (*model.WalletStatusChange, *validation.WalletError) {
+	fnName := "LifecycleService.DoCloseWallet"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	return s.changeStatus(ctx, tx, fnName, payload, func(_ model.WalletStatus, pockets []model.Wallet) (model.WalletStatus, *validation.WalletError) {
+		for _, pocket := range pockets {
+			if pocket.Balance != 0 || pocket.HeldBalance != 0 {
+				return "", &validation.WalletError{
+					Name:      fnName,
+					Code:      validation.ERR_WALLET_NOT_EMPTY,
+					Message:   fmt.Sprintf("Pocket %s must be empty before the wallet is closed", pocket.Pocket),
+					Timestamp: time.Now().UTC(),
+					Err:       fmt.Errorf("pocket %s has balance %d and held %d", pocket.Pocket, pocket.Balance, pocket.HeldBalance),
+				}
+			}
+		}
+
+		escrows, err := s.store.FetchEscrows(ctx, &model.EscrowCriteria{Username: pockets[0].Username, Status: model.EscrowFunded})
+		if err != nil {
+			return "", &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_FETCH_ESCROW_FAILED,
+				Message:   "Failed to fetch funded escrows",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			}
+		}
+		for _, escrow := range escrows {
+			if escrow.Currency == pockets[0].Currency {
+				return "", &validation.WalletError{
+					Name:      fnName,
+					Code:      validation.ERR_WALLET_NOT_EMPTY,
+					Message:   "Funded escrows must be settled before the wallet is closed",
+					Timestamp: time.Now().UTC(),
+					Err:       fmt.Errorf("escrow %d is still funded", escrow.ID),
+				}
+			}
+		}
+		return model.WalletClosed, nil
+	})
+}
+
+func (s *LifecycleService) DoFetchWalletStatus(ctx context.Context, username string, currencyCode string) (*model.Wallet, []model.WalletStatusChange, *validation.WalletError) {
+	fnName := "LifecycleService.DoFetchWalletStatus"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode))
+
+	username, currency, appErr := validateStatusWallet(fnName, username, currencyCode)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	wallet, err := s.store.FetchWallet(ctx, username, currency.Code, model.DefaultPocket)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Error while fetching wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if wallet == nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "User does not have an existing wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+
+	changes, err := s.store.FetchWalletStatusChanges(ctx, username, currency.Code)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_STATUS_FAILED,
+			Message:   "Failed to fetch wallet status history",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet status fetched", fnName), zap.String("status", string(wallet.Status)), zap.Int("changes", len(changes)))
+	return wallet, changes, nil
+}
+
+func (s *LifecycleService) changeStatus(ctx context.Context, tx *sql.Tx, fnName string, payload *request.WalletStatusPayload, transition func(current model.WalletStatus, pockets []model.Wallet) (model.WalletStatus, *validation.WalletError)) (*model.WalletStatusChange, *validation.WalletError) {
+	username, currency, appErr := validateStatusWallet(fnName, payload.Username, payload.Currency)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	actor, err := validation.SanitizeAndValidateUsername(payload.Actor)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Actor validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("actor", payload.Actor),
+			},
+		}
+	}
+
+	if !model.IsStatusReasonValid(payload.Reason) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_STATUS_REASON_INVALID,
+			Message:   fmt.Sprintf("Reason %q is not supported", payload.Reason),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("invalid status reason %q", payload.Reason),
+		}
+	}
+	var note *string
+	if payload.Note != "" {
+		if len(payload.Note) > maxStatusNoteLength {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_WALLET_STATUS_REASON_INVALID,
+				Message:   fmt.Sprintf("Note must be at most %d characters", maxStatusNoteLength),
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("note is %d characters", len(payload.Note)),
+			}
+		}
+		note = &payload.Note
+	}
+	logger.Info(fmt.Sprintf("%s - Status change validated", fnName), zap.String("actor", actor), zap.String("reason", payload.Reason))
+
+	pockets, err := s.store.FetchPocketsForUpdate(ctx, tx, username, currency.Code)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Error while fetching wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if len(pockets) == 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "User does not have an existing wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+
+	current := pockets[0].Status
+	if current == model.WalletClosed {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_CLOSED,
+			Message:   "Wallet is closed and can no longer change status",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("wallet %s %s is closed", username, currency.Code),
+		}
+	}
+	target, appErr := transition(current, pockets)
+	if appErr != nil {
+		return nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Status transition validated", fnName), zap.String("from", string(current)), zap.String("to", string(target)))
+
+	updated, err := s.store.UpdateWalletStatus(ctx, tx, username, currency.Code, target)
+	if err != nil || len(updated) != len(pockets) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_WALLET_STATUS_FAILED,
+			Message:   "Failed to update wallet status",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("status", string(target)),
+			},
+		}
+	}
+
+	change := &model.WalletStatusChange{
+		Username:   username,
+		Currency:   currency.Code,
+		FromStatus: current,
+		ToStatus:   target,
+		Reason:     model.StatusReason(payload.Reason),
+		Note:       note,
+		Actor:      actor,
+	}
+	if err := s.store.InsertWalletStatusChange(ctx, tx, change); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_WALLET_STATUS_FAILED,
+			Message:   "Failed to record wallet status change",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("change", change),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet status changed", fnName), zap.Any("change", change))
+	return change, nil
+}
+
+func validateStatusWallet(fnName string, rawUsername string, currencyCode string) (string, model.Currency, *validation.WalletError) {
+	username, err := validation.SanitizeAndValidateUsername(rawUsername)
+	if err != nil {
+		return "", model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", rawUsername),
+			},
+		}
+	}
+	if validation.IsReservedUsername(username) {
+		return "", model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "System wallets cannot change status",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s is reserved", username),
+		}
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return "", model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", currencyCode),
+			},
+		}
+	}
+	return username, currency, nil
+}
+
+func checkWalletStatus(fnName string, wallet *model.Wallet, debit bool) *validation.WalletError {
+	allowed, movement := wallet.Status.AllowsCredit(), "credited"
+	if debit {
+		allowed, movement = wallet.Status.AllowsDebit(), "debited"
+	}
+	if allowed {
+		return nil
+	}
+
+	var code validation.WalletErrorCode
+	switch wallet.Status {
+	case model.WalletClosed:
+		code = validation.ERR_WALLET_CLOSED
+	case model.WalletFrozenAll:
+		code = validation.ERR_WALLET_FROZEN
+	default:
+		code = validation.ERR_WALLET_DEBIT_FROZEN
+	}
+	return &validation.WalletError{
+		Name:      fnName,
+		Code:      code,
+		Message:   fmt.Sprintf("Wallet is %s and cannot be %s", wallet.Status, movement),
+		Timestamp: time.Now().UTC(),
+		Err:       fmt.Errorf("wallet %s %s is %s", wallet.Username, wallet.Currency, wallet.Status),
+		Context: []zap.Field{
+			zap.String("username", wallet.Username),
+			zap.String("currency", wallet.Currency),
+			zap.String("pocket", wallet.Pocket),
+		},
+	}
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockLifecycleStore struct {
+	wallets []*model.Wallet
+	escrows []model.Escrow
+	changes []model.WalletStatusChange
+}
+
+func (m *mockLifecycleStore) initializeMockData() {
+	m.wallets = []*model.Wallet{
+		{ID: 1, Username: "JUAN", Currency: "USD", Pocket: model.DefaultPocket, Status: model.WalletActive, Balance: 1000},
+		{ID: 2, Username: "JUAN", Currency: "USD", Pocket: "SAVINGS", Status: model.WalletActive},
+		{ID: 3, Username: "MARY", Currency: "USD", Pocket: model.DefaultPocket, Status: model.WalletFrozenAll},
+		{ID: 4, Username: "PAUL", Currency: "USD", Pocket: model.DefaultPocket, Status: model.WalletActive},
+		{ID: 5, Username: "PAUL", Currency: "EUR", Pocket: model.DefaultPocket, Status: model.WalletActive},
+		{ID: 6, Username: "ANNA", Currency: "USD", Pocket: model.DefaultPocket, Status: model.WalletClosed},
+		{ID: 7, Username: "LENA", Currency: "USD", Pocket: model.DefaultPocket, Status: model.WalletActive},
+		{ID: 8, Username: "LENA", Currency: "USD", Pocket: "TRAVEL", Status: model.WalletActive, Balance: 50, HeldBalance: 50},
+	}
+	m.escrows = []model.Escrow{
+		{ID: 1, Payer: "PAUL", Payee: "JUAN", Currency: "EUR", Amount: 100, Status: model.EscrowFunded},
+	}
+}
+
+func (m *mockLifecycleStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	for _, wallet := range m.wallets {
+		if wallet.Username == username && wallet.Currency == currency && wallet.Pocket == pocket {
+			copied := *wallet
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockLifecycleStore) FetchPocketsForUpdate(ctx context.Context, tx *sql.Tx, username string, currency string) ([]model.Wallet, error) {
+	pockets := []model.Wallet{}
+	for _, wallet := range m.wallets {
+		if wallet.Username == username && wallet.Currency == currency {
+			pockets = append(pockets, *wallet)
+		}
+	}
+	return pockets, nil
+}
+
+func (m *mockLifecycleStore) FetchEscrows(ctx context.Context, criteria *model.EscrowCriteria) ([]model.Escrow, error) {
+	escrows := []model.Escrow{}
+	for _, escrow := range m.escrows {
+		if (escrow.Payer == criteria.Username || escrow.Payee == criteria.Username) && escrow.Status == criteria.Status {
+			escrows = append(escrows, escrow)
+		}
+	}
+	return escrows, nil
+}
+
+func (m *mockLifecycleStore) UpdateWalletStatus(ctx context.Context, tx *sql.Tx, username string, currency string, status model.WalletStatus) ([]model.Wallet, error) {
+	updated := []model.Wallet{}
+	for _, wallet := range m.wallets {
+		if wallet.Username == username && wallet.Currency == currency {
+			wallet.Status = status
+			updated = append(updated, *wallet)
+		}
+	}
+	return updated, nil
+}
+
+func (m *mockLifecycleStore) InsertWalletStatusChange(ctx context.Context, tx *sql.Tx, change *model.WalletStatusChange) error {
+	change.ID = int64(len(m.changes) + 1)
+	m.changes = append(m.changes, *change)
+	return nil
+}
+
+func (m *mockLifecycleStore) FetchWalletStatusChanges(ctx context.Context, username string, currency string) ([]model.WalletStatusChange, error) {
+	return m.changes, nil
+}
+
+func TestDoChangeWalletStatus(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name           string
+		action         string
+		payload        request.WalletStatusPayload
+		expectedFrom   model.WalletStatus
+		expectedStatus model.WalletStatus
+		expectedCode   validation.WalletErrorCode
+		expectErr      bool
+	}
+
+	tests := []testCase{
+		{
+			name:           "Successful Freeze - Debits only",
+			action:         "freeze",
+			payload:        request.WalletStatusPayload{Username: "juan", Mode: "debit", Reason: "fraud_suspected", Actor: "officer"},
+			expectedFrom:   model.WalletActive,
+			expectedStatus: model.WalletFrozenDebit,
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Freeze - Escalate to all movements",
+			action:         "freeze",
+			payload:        request.WalletStatusPayload{Username: "juan", Currency: "usd", Mode: "all", Reason: "legal_order", Note: "Case 42", Actor: "officer"},
+			expectedFrom:   model.WalletActive,
+			expectedStatus: model.WalletFrozenAll,
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Unfreeze - Frozen wallet",
+			action:         "unfreeze",
+			payload:        request.WalletStatusPayload{Username: "mary", Reason: "review_cleared", Actor: "officer"},
+			expectedFrom:   model.WalletFrozenAll,
+			expectedStatus: model.WalletActive,
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Close - Empty wallet",
+			action:         "close",
+			payload:        request.WalletStatusPayload{Username: "paul", Reason: "customer_request", Actor: "officer"},
+			expectedFrom:   model.WalletActive,
+			expectedStatus: model.WalletClosed,
+			expectErr:      false,
+		},
+		{
+			name:         "Failed Freeze - Unknown mode",
+			action:       "freeze",
+			payload:      request.WalletStatusPayload{Username: "juan", Mode: "credit", Reason: "fraud_suspected", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_STATUS_TRANSITION_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Freeze - Already in that state",
+			action:       "freeze",
+			payload:      request.WalletStatusPayload{Username: "mary", Mode: "all", Reason: "fraud_suspected", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_STATUS_TRANSITION_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Freeze - Unknown reason",
+			action:       "freeze",
+			payload:      request.WalletStatusPayload{Username: "juan", Mode: "all", Reason: "because", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_STATUS_REASON_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Freeze - Note too long",
+			action:       "freeze",
+			payload:      request.WalletStatusPayload{Username: "juan", Mode: "all", Reason: "other", Note: strings.Repeat("x", maxStatusNoteLength+1), Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_STATUS_REASON_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Freeze - Missing actor",
+			action:       "freeze",
+			payload:      request.WalletStatusPayload{Username: "juan", Mode: "all", Reason: "fraud_suspected"},
+			expectedCode: validation.ERR_SANITIZE_USERNAME_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Freeze - Closed wallet",
+			action:       "freeze",
+			payload:      request.WalletStatusPayload{Username: "anna", Mode: "all", Reason: "fraud_suspected", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_CLOSED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Freeze - Wallet not found",
+			action:       "freeze",
+			payload:      request.WalletStatusPayload{Username: "juan", Currency: "EUR", Mode: "all", Reason: "fraud_suspected", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Freeze - Reserved system username",
+			action:       "freeze",
+			payload:      request.WalletStatusPayload{Username: model.EscrowWallet, Mode: "all", Reason: "fraud_suspected", Actor: "officer"},
+			expectedCode: validation.ERR_RESERVED_USERNAME,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Unfreeze - Active wallet",
+			action:       "unfreeze",
+			payload:      request.WalletStatusPayload{Username: "juan", Reason: "review_cleared", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_STATUS_TRANSITION_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Close - Balance remaining",
+			action:       "close",
+			payload:      request.WalletStatusPayload{Username: "juan", Reason: "customer_request", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_NOT_EMPTY,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Close - Held funds in a pocket",
+			action:       "close",
+			payload:      request.WalletStatusPayload{Username: "lena", Reason: "customer_request", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_NOT_EMPTY,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Close - Funded escrow",
+			action:       "close",
+			payload:      request.WalletStatusPayload{Username: "paul", Currency: "EUR", Reason: "customer_request", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_NOT_EMPTY,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Close - Already closed",
+			action:       "close",
+			payload:      request.WalletStatusPayload{Username: "anna", Reason: "customer_request", Actor: "officer"},
+			expectedCode: validation.ERR_WALLET_CLOSED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockLifecycleStore{}
+			mock.initializeMockData()
+			s := &LifecycleService{store: mock}
+
+			var change *model.WalletStatusChange
+			var err *validation.WalletError
+			switch test.action {
+			case "freeze":
+				change, err = s.DoFreezeWallet(context.Background(), nil, &test.payload)
+			case "unfreeze":
+				change, err = s.DoUnfreezeWallet(context.Background(), nil, &test.payload)
+			case "close":
+				change, err = s.DoCloseWallet(context.Background(), nil, &test.payload)
+			}
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if test.expectErr && len(mock.changes) != 0 {
+				t.Errorf("expected no recorded change but got %d", len(mock.changes))
+			}
+
+			if !test.expectErr && change != nil {
+				if change.FromStatus != test.expectedFrom || change.ToStatus != test.expectedStatus {
+					t.Errorf("expected %s -> %s but got %s -> %s instead", test.expectedFrom, test.expectedStatus, change.FromStatus, change.ToStatus)
+				}
+				if len(mock.changes) != 1 {
+					t.Errorf("expected 1 recorded change but got %d", len(mock.changes))
+				}
+				for _, wallet := range mock.wallets {
+					if wallet.Username == change.Username && wallet.Currency == change.Currency && wallet.Status != test.expectedStatus {
+						t.Errorf("expected pocket %s to be %s but got %s", wallet.Pocket, test.expectedStatus, wallet.Status)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCheckWalletStatus(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name         string
+		status       model.WalletStatus
+		debit        bool
+		expectedCode validation.WalletErrorCode
+		expectErr    bool
+	}
+
+	tests := []testCase{
+		{name: "Active - Debit allowed", status: model.WalletActive, debit: true, expectErr: false},
+		{name: "Active - Credit allowed", status: model.WalletActive, debit: false, expectErr: false},
+		{name: "Frozen debit - Credit allowed", status: model.WalletFrozenDebit, debit: false, expectErr: false},
+		{name: "Frozen debit - Debit refused", status: model.WalletFrozenDebit, debit: true, expectedCode: validation.ERR_WALLET_DEBIT_FROZEN, expectErr: true},
+		{name: "Frozen all - Credit refused", status: model.WalletFrozenAll, debit: false, expectedCode: validation.ERR_WALLET_FROZEN, expectErr: true},
+		{name: "Frozen all - Debit refused", status: model.WalletFrozenAll, debit: true, expectedCode: validation.ERR_WALLET_FROZEN, expectErr: true},
+		{name: "Closed - Credit refused", status: model.WalletClosed, debit: false, expectedCode: validation.ERR_WALLET_CLOSED, expectErr: true},
+		{name: "Closed - Debit refused", status: model.WalletClosed, debit: true, expectedCode: validation.ERR_WALLET_CLOSED, expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkWalletStatus("test", &model.Wallet{Username: "JUAN", Currency: "USD", Status: test.status}, test.debit)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type LimitStore interface {
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	UpdateWalletLimits(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, limits model.WalletLimits) (*model.Wallet, error)
+}
+
+type LimitService struct {
+	store LimitStore
+}
+
+func NewLimitService(store LimitStore) *LimitService {
+	logger.Info("Initializing LimitService")
+	return &LimitService{store: store}
+}
+
+func (s *LimitService) DoSetWalletLimits(ctx context.Context, tx *sql.Tx, payload *request.WalletLimitsPayload) (*model.Wallet, *validation.WalletError) {
+	fnName := "LimitService.DoSetWalletLimits"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	wallet, currency, appErr := s.fetchUserWallet(ctx, fnName, payload.Username, payload.Currency, payload.Pocket)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	limits := wallet.Limits
+	if payload.Reset {
+		limits = model.DefaultWalletLimits(currency)
+	}
+	if payload.MinAmount != nil {
+		limits.MinAmount = *payload.MinAmount
+	}
+	if payload.MaxAmount != nil {
+		limits.MaxAmount = *payload.MaxAmount
+	}
+	if payload.MaxBalance != nil {
+		limits.MaxBalance = *payload.MaxBalance
+	}
+
+	if err := validateWalletLimits(limits, wallet.Balance); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_LIMITS_INVALID,
+			Message:   "Wallet limits validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", wallet.Username),
+				zap.String("currency", wallet.Currency),
+				zap.Int64("balance", wallet.Balance),
+				zap.Any("limits", limits),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet limits validated", fnName), zap.Any("limits", limits))
+
+	updated, err := s.store.UpdateWalletLimits(ctx, tx, wallet.Username, wallet.Currency, wallet.Pocket, limits)
+	if err != nil || updated == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_WALLET_LIMITS_FAILED,
+			Message:   "Failed to update wallet limits",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", wallet.Username),
+				zap.String("currency", wallet.Currency),
+				zap.Any("limits", limits),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet limits updated", fnName), zap.Any("wallet", updated))
+	return updated, nil
+}
+
+func (s *LimitService) DoFetchWalletLimits(ctx context.Context, username string, currencyCode string, pocketName string) (*model.Wallet, model.WalletLimits, *validation.WalletError) {
+	fnName := "LimitService.DoFetchWalletLimits"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode), zap.String("pocket", pocketName))
+
+	wallet, currency, appErr := s.fetchUserWallet(ctx, fnName, username, currencyCode, pocketName)
+	if appErr != nil {
+		return nil, model.WalletLimits{}, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet limits fetched", fnName), zap.Any("limits", wallet.Limits))
+	return wallet, model.DefaultWalletLimits(currency), nil
+}
+
+func (s *LimitService) fetchUserWallet(ctx context.Context, fnName string, username string, currencyCode string, pocketName string) (*model.Wallet, model.Currency, *validation.WalletError) {
+	username, err := validation.SanitizeAndValidateUsername(username)
+	if err != nil {
+		return nil, model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+	if validation.IsReservedUsername(username) {
+		return nil, model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "System wallets do not have limits",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s is reserved", username),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return nil, model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", currencyCode),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	pocket, err := validation.SanitizeAndValidatePocket(pocketName)
+	if err != nil {
+		return nil, model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", pocketName),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket validated", fnName), zap.String("pocket", pocket))
+
+	wallet, err := s.store.FetchWallet(ctx, username, currency.Code, pocket)
+	if err != nil {
+		return nil, model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Error while fetching wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("pocket", pocket),
+			},
+		}
+	}
+	if wallet == nil {
+		return nil, model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "User does not have an existing wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("pocket", pocket),
+			},
+		}
+	}
+	return wallet, currency, nil
+}
+
+func validateWalletLimits(limits model.WalletLimits, balance int64) error {
+	switch {
+	case limits.MinAmount <= 0:
+		return fmt.Errorf("minAmount must be greater than 0")
+	case limits.MaxAmount < limits.MinAmount:
+		return fmt.Errorf("maxAmount %d must not be less than minAmount %d", limits.MaxAmount, limits.MinAmount)
+	case limits.MaxBalance < 0:
+		return fmt.Errorf("maxBalance must not be negative")
+	case limits.MaxBalance < balance:
+		return fmt.Errorf("maxBalance %d is below current balance %d", limits.MaxBalance, balance)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+func defaultMockLimits(code string) model.WalletLimits {
+	currency, _ := model.LookupCurrency(code)
+	return model.DefaultWalletLimits(currency)
+}
+
+type mockLimitStore struct {
+	wallets map[string]*model.Wallet
+}
+
+func (m *mockLimitStore) initializeMockWallets() {
+	m.wallets = map[string]*model.Wallet{
+		"JUAN": {Username: "JUAN", Currency: "USD", Balance: 5000, Limits: defaultMockLimits("USD")},
+		"MARY": {Username: "MARY", Currency: "USD", Balance: 200, Limits: model.WalletLimits{MinAmount: 100, MaxAmount: 500, MaxBalance: 1000}},
+	}
+}
+
+func (m *mockLimitStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username]
+	if !ok || wallet.Currency != currency {
+		return nil, nil
+	}
+	copied := *wallet
+	return &copied, nil
+}
+
+func (m *mockLimitStore) UpdateWalletLimits(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, limits model.WalletLimits) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username]
+	if !ok || wallet.Currency != currency || wallet.Balance > limits.MaxBalance {
+		return nil, nil
+	}
+	wallet.Limits = limits
+	copied := *wallet
+	return &copied, nil
+}
+
+func TestDoSetWalletLimits(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name           string
+		payload        request.WalletLimitsPayload
+		expectedLimits model.WalletLimits
+		expectedCode   validation.WalletErrorCode
+		expectErr      bool
+	}
+
+	tests := []testCase{
+		{
+			name:           "Successful Limits - Raise all limits",
+			payload:        request.WalletLimitsPayload{Username: "juan", MinAmount: utils.Ptr(int64(10)), MaxAmount: utils.Ptr(int64(2000000)), MaxBalance: utils.Ptr(int64(5000000))},
+			expectedLimits: model.WalletLimits{MinAmount: 10, MaxAmount: 2000000, MaxBalance: 5000000},
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Limits - Partial update keeps other limits",
+			payload:        request.WalletLimitsPayload{Username: "MARY", MaxAmount: utils.Ptr(int64(800))},
+			expectedLimits: model.WalletLimits{MinAmount: 100, MaxAmount: 800, MaxBalance: 1000},
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Limits - Reset to currency defaults",
+			payload:        request.WalletLimitsPayload{Username: "MARY", Currency: "usd", Reset: true},
+			expectedLimits: defaultMockLimits("USD"),
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Limits - Reset with override",
+			payload:        request.WalletLimitsPayload{Username: "MARY", Reset: true, MaxBalance: utils.Ptr(int64(200))},
+			expectedLimits: model.WalletLimits{MinAmount: 1, MaxAmount: 999999, MaxBalance: 200},
+			expectErr:      false,
+		},
+		{
+			name:         "Failed Limits - Max balance below current balance",
+			payload:      request.WalletLimitsPayload{Username: "JUAN", MaxBalance: utils.Ptr(int64(4999))},
+			expectedCode: validation.ERR_WALLET_LIMITS_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Limits - Max amount below min amount",
+			payload:      request.WalletLimitsPayload{Username: "MARY", MaxAmount: utils.Ptr(int64(99))},
+			expectedCode: validation.ERR_WALLET_LIMITS_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Limits - Zero min amount",
+			payload:      request.WalletLimitsPayload{Username: "JUAN", MinAmount: utils.Ptr(int64(0))},
+			expectedCode: validation.ERR_WALLET_LIMITS_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Limits - Reserved system username",
+			payload:      request.WalletLimitsPayload{Username: model.EscrowWallet, MaxBalance: utils.Ptr(int64(100))},
+			expectedCode: validation.ERR_RESERVED_USERNAME,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Limits - Wallet not found",
+			payload:      request.WalletLimitsPayload{Username: "PAUL", MaxBalance: utils.Ptr(int64(100))},
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockLimitStore{}
+			mock.initializeMockWallets()
+			s := &LimitService{store: mock}
+
+			wallet, err := s.DoSetWalletLimits(context.Background(), nil, &test.payload)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && wallet != nil && wallet.Limits != test.expectedLimits {
+				t.Errorf("expected limits %+v but got %+v instead", test.expectedLimits, wallet.Limits)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type OverdraftCharger func(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, usage int64) (*model.FeeBreakdown, *validation.WalletError)
+
+type OverdraftStore interface {
+	BeginTransaction(ctx context.Context) (*sql.Tx, error)
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	FetchWalletForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Wallet, error)
+	UpdateWalletCreditLimit(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, creditLimit int64) (*model.Wallet, error)
+	FetchOverdrawnWallets(ctx context.Context) ([]model.Wallet, error)
+	InsertOverdraftCharge(ctx context.Context, tx *sql.Tx, charge *model.OverdraftCharge) (bool, error)
+	UpdateOverdraftCharge(ctx context.Context, tx *sql.Tx, id int64, fee int64, transactionID *int64) error
+}
+
+type OverdraftService struct {
+	store OverdraftStore
+}
+
+func NewOverdraftService(store OverdraftStore) *OverdraftService {
+	logger.Info("Initializing OverdraftService")
+	return &OverdraftService{store: store}
+}
+
+func (s *OverdraftService) DoSetCreditLimit(ctx context.Context, tx *sql.Tx, payload *request.CreditLimitPayload) (*model.Wallet, *validation.WalletError) {
+	fnName := "OverdraftService.DoSetCreditLimit"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, err := validation.SanitizeAndValidateUsername(payload.Username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", payload.Username),
+			},
+		}
+	}
+	if validation.IsReservedUsername(username) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "Credit limits cannot be set on system wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s is reserved", username),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	if payload.CreditLimit == nil || *payload.CreditLimit < 0 || *payload.CreditLimit > currency.MaxBalance {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREDIT_LIMIT_INVALID,
+			Message:   fmt.Sprintf("Credit limit must be between 0 and %d %s", currency.MaxBalance, currency.Code),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("invalid credit limit %v", payload.CreditLimit),
+		}
+	}
+	creditLimit := *payload.CreditLimit
+
+	wallet, err := s.store.FetchWallet(ctx, username, currency.Code, model.DefaultPocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Error while fetching wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if wallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "User does not have an existing wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+
+	if wallet.Balance-wallet.HeldBalance+creditLimit < 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREDIT_LIMIT_INVALID,
+			Message:   "Credit limit is below current overdraft usage",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("credit limit %d does not cover balance %d with %d held", creditLimit, wallet.Balance, wallet.HeldBalance),
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+
+	updated, err := s.store.UpdateWalletCreditLimit(ctx, tx, username, currency.Code, model.DefaultPocket, creditLimit)
+	if err != nil || updated == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_CREDIT_LIMIT_FAILED,
+			Message:   "Failed to update credit limit",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.Int64("creditLimit", creditLimit),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Credit limit updated", fnName), zap.Any("wallet", updated))
+	return updated, nil
+}
+
+func (s *OverdraftService) DoFetchOverdrafts(ctx context.Context) ([]model.OverdraftWallet, *validation.WalletError) {
+	fnName := "OverdraftService.DoFetchOverdrafts"
+	logger.Info(fmt.Sprintf("%s - No params to receive", fnName))
+
+	wallets, err := s.store.FetchOverdrawnWallets(ctx)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_OVERDRAFT_FAILED,
+			Message:   "Failed to fetch overdrawn wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+
+	overdrafts := []model.OverdraftWallet{}
+	for _, wallet := range wallets {
+		overdrafts = append(overdrafts, buildOverdraftWallet(wallet))
+	}
+	logger.Info(fmt.Sprintf("%s - Overdrawn wallets fetched", fnName), zap.Int("count", len(overdrafts)))
+	return overdrafts, nil
+}
+
+func (s *OverdraftService) DoChargeOverdrafts(ctx context.Context, charge OverdraftCharger) (*model.OverdraftRun, *validation.WalletError) {
+	fnName := "OverdraftService.DoChargeOverdrafts"
+
+	run := &model.OverdraftRun{ChargeDate: truncateToDay(time.Now().UTC()), Charges: []model.OverdraftCharge{}}
+	logger.Info(fmt.Sprintf("%s - Charging overdrafts", fnName), zap.Time("chargeDate", run.ChargeDate))
+
+	wallets, err := s.store.FetchOverdrawnWallets(ctx)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_OVERDRAFT_FAILED,
+			Message:   "Failed to fetch overdrawn wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+
+	for _, wallet := range wallets {
+		charged, appErr := s.chargeWallet(ctx, wallet.ID, run.ChargeDate, charge)
+		if appErr != nil {
+			logger.Warn(fmt.Sprintf("%s - Overdraft charge skipped", fnName), zap.String("username", wallet.Username), zap.String("currency", wallet.Currency), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			run.Skipped++
+			continue
+		}
+		if charged != nil {
+			run.Charges = append(run.Charges, *charged)
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Overdrafts charged", fnName), zap.Int("charges", len(run.Charges)), zap.Int("skipped", run.Skipped))
+	return run, nil
+}
+
+func (s *OverdraftService) RunChargeJob(ctx context.Context, interval time.Duration, charge OverdraftCharger) {
+	fnName := "OverdraftService.RunChargeJob"
+	logger.Info(fmt.Sprintf("%s - Job started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Job stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoChargeOverdrafts(ctx, charge); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Overdraft charging failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func (s *OverdraftService) chargeWallet(ctx context.Context, walletID int64, day time.Time, charge OverdraftCharger) (*model.OverdraftCharge, *validation.WalletError) {
+	fnName := "OverdraftService.chargeWallet"
+
+	tx, err := s.store.BeginTransaction(ctx)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CHARGE_OVERDRAFT_FAILED,
+			Message:   "Failed to start transaction",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	charged, appErr := s.chargeWalletTx(ctx, tx, walletID, day, charge)
+	if appErr != nil || charged == nil {
+		return nil, appErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CHARGE_OVERDRAFT_FAILED,
+			Message:   "Failed to commit overdraft charge",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Overdraft charged", fnName), zap.Any("charge", charged))
+	return charged, nil
+}
+
+func (s *OverdraftService) chargeWalletTx(ctx context.Context, tx *sql.Tx, walletID int64, day time.Time, charge OverdraftCharger) (*model.OverdraftCharge, *validation.WalletError) {
+	fnName := "OverdraftService.chargeWalletTx"
+
+	wallet, err := s.store.FetchWalletForUpdate(ctx, tx, walletID)
+	if err != nil || wallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Failed to fetch wallet for overdraft charge",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("walletID", walletID),
+			},
+		}
+	}
+	if wallet.Balance >= 0 {
+		logger.Info(fmt.Sprintf("%s - Wallet no longer overdrawn", fnName), zap.String("username", wallet.Username), zap.String("currency", wallet.Currency))
+		return nil, nil
+	}
+
+	charged := &model.OverdraftCharge{
+		WalletID:   wallet.ID,
+		Username:   wallet.Username,
+		Currency:   wallet.Currency,
+		ChargeDate: day,
+		Usage:      -wallet.Balance,
+	}
+	inserted, err := s.store.InsertOverdraftCharge(ctx, tx, charged)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CHARGE_OVERDRAFT_FAILED,
+			Message:   "Failed to record overdraft charge",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("charge", charged),
+			},
+		}
+	}
+	if !inserted {
+		logger.Info(fmt.Sprintf("%s - Overdraft already charged", fnName), zap.String("username", wallet.Username), zap.Time("chargeDate", day))
+		return nil, nil
+	}
+
+	fee, appErr := charge(ctx, tx, wallet, charged.Usage)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if fee == nil || fee.Fee == 0 {
+		logger.Info(fmt.Sprintf("%s - No overdraft fee applicable", fnName), zap.Any("charge", charged))
+		return charged, nil
+	}
+
+	charged.Fee = fee.Fee
+	charged.TransactionID = fee.TransactionID
+	if err := s.store.UpdateOverdraftCharge(ctx, tx, charged.ID, charged.Fee, charged.TransactionID); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CHARGE_OVERDRAFT_FAILED,
+			Message:   "Failed to update overdraft charge",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("charge", charged),
+			},
+		}
+	}
+	return charged, nil
+}
+
+func buildOverdraftWallet(wallet model.Wallet) model.OverdraftWallet {
+	used := max(-wallet.Balance, 0)
+	return model.OverdraftWallet{
+		Username:           wallet.Username,
+		Currency:           wallet.Currency,
+		Balance:            wallet.Balance,
+		CreditLimit:        wallet.CreditLimit,
+		OverdraftUsed:      used,
+		OverdraftAvailable: max(wallet.AvailableBalance, 0),
+		OverdrawnSince:     wallet.OverdrawnSince,
+	}
+}
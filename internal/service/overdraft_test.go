@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockOverdraftStore struct {
+	wallets map[int64]*model.Wallet
+	charges []model.OverdraftCharge
+}
+
+func (m *mockOverdraftStore) initializeMockData() {
+	m.wallets = map[int64]*model.Wallet{
+		1: {ID: 1, Username: "JUAN", Currency: "USD", Balance: 1000, AvailableBalance: 1000},
+		2: {ID: 2, Username: "MARY", Currency: "USD", Balance: -300, HeldBalance: 100, CreditLimit: 500, AvailableBalance: 100},
+		3: {ID: 3, Username: "PAUL", Currency: "USD", Balance: -50, CreditLimit: 50, AvailableBalance: 0},
+	}
+	m.charges = []model.OverdraftCharge{
+		{ID: 1, WalletID: 3, ChargeDate: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), Usage: 50},
+	}
+}
+
+func (m *mockOverdraftStore) BeginTransaction(ctx context.Context) (*sql.Tx, error) {
+	return nil, fmt.Errorf("not supported by mock")
+}
+
+func (m *mockOverdraftStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	for _, wallet := range m.wallets {
+		if wallet.Username == username && wallet.Currency == currency {
+			copied := *wallet
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockOverdraftStore) FetchWalletForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Wallet, error) {
+	wallet, ok := m.wallets[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *wallet
+	return &copied, nil
+}
+
+func (m *mockOverdraftStore) UpdateWalletCreditLimit(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, creditLimit int64) (*model.Wallet, error) {
+	for _, wallet := range m.wallets {
+		if wallet.Username == username && wallet.Currency == currency {
+			if wallet.Balance-wallet.HeldBalance+creditLimit < 0 {
+				return nil, nil
+			}
+			wallet.CreditLimit = creditLimit
+			wallet.AvailableBalance = wallet.Balance - wallet.HeldBalance + creditLimit
+			copied := *wallet
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockOverdraftStore) FetchOverdrawnWallets(ctx context.Context) ([]model.Wallet, error) {
+	wallets := []model.Wallet{}
+	for _, wallet := range m.wallets {
+		if wallet.Balance < 0 {
+			wallets = append(wallets, *wallet)
+		}
+	}
+	return wallets, nil
+}
+
+func (m *mockOverdraftStore) InsertOverdraftCharge(ctx context.Context, tx *sql.Tx, charge *model.OverdraftCharge) (bool, error) {
+	for _, existing := range m.charges {
+		if existing.WalletID == charge.WalletID && existing.ChargeDate.Equal(charge.ChargeDate) {
+			return false, nil
+		}
+	}
+	charge.ID = int64(len(m.charges) + 1)
+	m.charges = append(m.charges, *charge)
+	return true, nil
+}
+
+func (m *mockOverdraftStore) UpdateOverdraftCharge(ctx context.Context, tx *sql.Tx, id int64, fee int64, transactionID *int64) error {
+	for i := range m.charges {
+		if m.charges[i].ID == id {
+			m.charges[i].Fee = fee
+			m.charges[i].TransactionID = transactionID
+			return nil
+		}
+	}
+	return fmt.Errorf("overdraft charge %d not found", id)
+}
+
+func TestDoSetCreditLimit(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name              string
+		payload           request.CreditLimitPayload
+		expectedAvailable int64
+		expectedCode      validation.WalletErrorCode
+		expectErr         bool
+	}
+
+	tests := []testCase{
+		{
+			name:              "Successful Credit Limit - Granted on positive balance",
+			payload:           request.CreditLimitPayload{Username: "juan", Currency: "usd", CreditLimit: utils.Ptr(int64(500))},
+			expectedAvailable: 1500,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Credit Limit - Lowered to current usage",
+			payload:           request.CreditLimitPayload{Username: "MARY", CreditLimit: utils.Ptr(int64(400))},
+			expectedAvailable: 0,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Credit Limit - Removed on positive balance",
+			payload:           request.CreditLimitPayload{Username: "JUAN", CreditLimit: utils.Ptr(int64(0))},
+			expectedAvailable: 1000,
+			expectErr:         false,
+		},
+		{
+			name:         "Failed Credit Limit - Below current overdraft usage",
+			payload:      request.CreditLimitPayload{Username: "MARY", CreditLimit: utils.Ptr(int64(399))},
+			expectedCode: validation.ERR_CREDIT_LIMIT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Credit Limit - Negative limit",
+			payload:      request.CreditLimitPayload{Username: "JUAN", CreditLimit: utils.Ptr(int64(-1))},
+			expectedCode: validation.ERR_CREDIT_LIMIT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Credit Limit - Missing limit",
+			payload:      request.CreditLimitPayload{Username: "JUAN"},
+			expectedCode: validation.ERR_CREDIT_LIMIT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Credit Limit - Limit exceeding currency maximum balance",
+			payload:      request.CreditLimitPayload{Username: "JUAN", CreditLimit: utils.Ptr(int64(1000000))},
+			expectedCode: validation.ERR_CREDIT_LIMIT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Credit Limit - Reserved system username",
+			payload:      request.CreditLimitPayload{Username: model.FeeWallet, CreditLimit: utils.Ptr(int64(500))},
+			expectedCode: validation.ERR_RESERVED_USERNAME,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Credit Limit - Wallet not found",
+			payload:      request.CreditLimitPayload{Username: "JUAN", Currency: "EUR", CreditLimit: utils.Ptr(int64(500))},
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockOverdraftStore{}
+			mock.initializeMockData()
+			s := &OverdraftService{store: mock}
+
+			wallet, err := s.DoSetCreditLimit(context.Background(), nil, &test.payload)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && wallet != nil && wallet.CreditLimit != *test.payload.CreditLimit {
+				t.Errorf("expected credit limit %d but got %d instead", *test.payload.CreditLimit, wallet.CreditLimit)
+			}
+
+			if !test.expectErr && wallet != nil && wallet.AvailableBalance != test.expectedAvailable {
+				t.Errorf("expected available balance %d but got %d instead", test.expectedAvailable, wallet.AvailableBalance)
+			}
+		})
+	}
+}
+
+func TestChargeOverdraftWallet(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	june := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		name          string
+		walletID      int64
+		day           time.Time
+		fee           *model.FeeBreakdown
+		expectCharge  bool
+		expectedUsage int64
+		expectedFee   int64
+		expectErr     bool
+	}
+
+	tests := []testCase{
+		{
+			name:          "Successful Charge - Fee booked on overdraft usage",
+			walletID:      2,
+			day:           june,
+			fee:           &model.FeeBreakdown{Fee: 3, TransactionID: utils.Ptr(int64(42))},
+			expectCharge:  true,
+			expectedUsage: 300,
+			expectedFee:   3,
+			expectErr:     false,
+		},
+		{
+			name:          "Successful Charge - No applicable fee rule still recorded",
+			walletID:      2,
+			day:           june,
+			fee:           nil,
+			expectCharge:  true,
+			expectedUsage: 300,
+			expectedFee:   0,
+			expectErr:     false,
+		},
+		{
+			name:         "Successful Charge - Already charged for the day",
+			walletID:     3,
+			day:          june,
+			fee:          &model.FeeBreakdown{Fee: 1, TransactionID: utils.Ptr(int64(42))},
+			expectCharge: false,
+			expectErr:    false,
+		},
+		{
+			name:          "Successful Charge - Next day charged again",
+			walletID:      3,
+			day:           june.AddDate(0, 0, 1),
+			fee:           &model.FeeBreakdown{Fee: 1, TransactionID: utils.Ptr(int64(42))},
+			expectCharge:  true,
+			expectedUsage: 50,
+			expectedFee:   1,
+			expectErr:     false,
+		},
+		{
+			name:         "Successful Charge - Wallet no longer overdrawn",
+			walletID:     1,
+			day:          june,
+			fee:          &model.FeeBreakdown{Fee: 1, TransactionID: utils.Ptr(int64(42))},
+			expectCharge: false,
+			expectErr:    false,
+		},
+		{
+			name:      "Failed Charge - Wallet not found",
+			walletID:  9,
+			day:       june,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockOverdraftStore{}
+			mock.initializeMockData()
+			s := &OverdraftService{store: mock}
+
+			calls := 0
+			charger := func(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, usage int64) (*model.FeeBreakdown, *validation.WalletError) {
+				calls++
+				return test.fee, nil
+			}
+
+			charged, err := s.chargeWalletTx(context.Background(), nil, test.walletID, test.day, charger)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !test.expectErr && test.expectCharge != (charged != nil) {
+				t.Errorf("expected charge %t but got %+v instead", test.expectCharge, charged)
+			}
+
+			if !test.expectCharge && calls != 0 {
+				t.Errorf("expected charger not to be called but got %d calls", calls)
+			}
+
+			if test.expectCharge && charged != nil && charged.Usage != test.expectedUsage {
+				t.Errorf("expected usage %d but got %d instead", test.expectedUsage, charged.Usage)
+			}
+
+			if test.expectCharge && charged != nil && charged.Fee != test.expectedFee {
+				t.Errorf("expected fee %d but got %d instead", test.expectedFee, charged.Fee)
+			}
+
+			if test.expectCharge && charged != nil && (charged.Fee > 0) != (charged.TransactionID != nil) {
+				t.Errorf("expected transaction ID only when a fee is booked but got %v", charged.TransactionID)
+			}
+		})
+	}
+}
+
+func TestBuildOverdraftWallet(t *testing.T) {
+	wallet := model.Wallet{Username: "MARY", Currency: "USD", Balance: -300, HeldBalance: 100, CreditLimit: 500, AvailableBalance: 100}
+
+	overdraft := buildOverdraftWallet(wallet)
+
+	if overdraft.OverdraftUsed != 300 {
+		t.Errorf("expected overdraft used 300 but got %d instead", overdraft.OverdraftUsed)
+	}
+	if overdraft.OverdraftAvailable != 100 {
+		t.Errorf("expected overdraft available 100 but got %d instead", overdraft.OverdraftAvailable)
+	}
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type PocketStore interface {
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	FetchPockets(ctx context.Context, username string, currency string) ([]model.Wallet, error)
+	InsertPocket(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, limits model.WalletLimits) (*model.Wallet, error)
+	MovePocketFunds(ctx context.Context, tx *sql.Tx, username string, currency string, from string, to string, amount int64) (*model.Wallet, *model.Wallet, error)
+}
+
+type PocketService struct {
+	store PocketStore
+}
+
+func NewPocketService(store PocketStore) *PocketService {
+	logger.Info("Initializing PocketService")
+	return &PocketService{store: store}
+}
+
+func (s *PocketService) DoCreatePocket(ctx context.Context, tx *sql.Tx, payload *request.PocketPayload) (*model.Wallet, *validation.WalletError) {
+	fnName := "PocketService.DoCreatePocket"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, currency, appErr := validatePocketOwner(fnName, payload.Username, payload.Currency)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	pocket, err := validation.SanitizeAndValidatePocket(payload.Pocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", payload.Pocket),
+			},
+		}
+	}
+	if pocket == model.DefaultPocket {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_ALREADY_EXISTS,
+			Message:   fmt.Sprintf("%s pocket is created with the wallet", model.DefaultPocket),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("pocket %s is reserved", pocket),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket validated", fnName), zap.String("pocket", pocket))
+
+	mainWallet, err := s.store.FetchWallet(ctx, username, currency.Code, model.DefaultPocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Error while fetching wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if mainWallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "User does not have an existing wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if appErr := checkWalletStatus(fnName, mainWallet, true); appErr != nil {
+		return nil, appErr
+	}
+
+	limits := model.DefaultWalletLimits(currency)
+	if payload.MinAmount != nil {
+		limits.MinAmount = *payload.MinAmount
+	}
+	if payload.MaxAmount != nil {
+		limits.MaxAmount = *payload.MaxAmount
+	}
+	if payload.MaxBalance != nil {
+		limits.MaxBalance = *payload.MaxBalance
+	}
+	if err := validateWalletLimits(limits, 0); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_LIMITS_INVALID,
+			Message:   "Wallet limits validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", pocket),
+				zap.Any("limits", limits),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket limits validated", fnName), zap.Any("limits", limits))
+
+	created, err := s.store.InsertPocket(ctx, tx, username, currency.Code, pocket, limits)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_POCKET_FAILED,
+			Message:   "Failed to create pocket",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("pocket", pocket),
+			},
+		}
+	}
+	if created == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_ALREADY_EXISTS,
+			Message:   "Pocket already exists",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("pocket %s already exists", pocket),
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("pocket", pocket),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket created", fnName), zap.Any("pocket", created))
+	return created, nil
+}
+
+func (s *PocketService) DoFetchPockets(ctx context.Context, username string, currencyCode string) ([]model.Wallet, *validation.WalletError) {
+	fnName := "PocketService.DoFetchPockets"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode))
+
+	username, currency, appErr := validatePocketOwner(fnName, username, currencyCode)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	pockets, err := s.store.FetchPockets(ctx, username, currency.Code)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_POCKET_FAILED,
+			Message:   "Failed to fetch pockets",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if len(pockets) == 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "User does not have an existing wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pockets fetched", fnName), zap.Int("count", len(pockets)))
+	return pockets, nil
+}
+
+func (s *PocketService) DoMovePocketFunds(ctx context.Context, tx *sql.Tx, payload *request.PocketMovePayload) (*model.Wallet, *model.Wallet, *validation.WalletError) {
+	fnName := "PocketService.DoMovePocketFunds"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, currency, appErr := validatePocketOwner(fnName, payload.Username, payload.Currency)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	from, err := validation.SanitizeAndValidatePocket(payload.From)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Source pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("from", payload.From),
+			},
+		}
+	}
+	to, err := validation.SanitizeAndValidatePocket(payload.To)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Destination pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("to", payload.To),
+			},
+		}
+	}
+	if from == to {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_MOVE_INVALID,
+			Message:   "Source and destination pockets must differ",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("cannot move funds from %s to itself", from),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pockets validated", fnName), zap.String("from", from), zap.String("to", to))
+
+	if payload.Amount <= 0 {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("amount must be greater than 0"),
+			Context: []zap.Field{
+				zap.Int64("amount", payload.Amount),
+			},
+		}
+	}
+
+	source, appErr := s.fetchPocket(ctx, fnName, username, currency.Code, from)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	destination, appErr := s.fetchPocket(ctx, fnName, username, currency.Code, to)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	if source.Balance-source.HeldBalance < payload.Amount {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_BALANCE_VALIDATION_FAILED,
+			Message:   "Insufficient funds in source pocket, credit cannot be moved",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("available funds %d below amount %d", source.Balance-source.HeldBalance, payload.Amount),
+			Context: []zap.Field{
+				zap.String("pocket", from),
+				zap.Int64("balance", source.Balance),
+				zap.Int64("held", source.HeldBalance),
+				zap.Int64("amount", payload.Amount),
+			},
+		}
+	}
+	if err := validation.ValidateWalletBalance(destination.Balance+payload.Amount, destination); err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_BALANCE_VALIDATION_FAILED,
+			Message:   "Destination pocket balance would exceed limit",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", to),
+				zap.Int64("balance", destination.Balance),
+				zap.Int64("amount", payload.Amount),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket balances validated", fnName), zap.Int64("amount", payload.Amount))
+
+	if appErr := checkWalletStatus(fnName, source, true); appErr != nil {
+		return nil, nil, appErr
+	}
+	if appErr := checkWalletStatus(fnName, destination, false); appErr != nil {
+		return nil, nil, appErr
+	}
+
+	debited, credited, err := s.store.MovePocketFunds(ctx, tx, username, currency.Code, from, to, payload.Amount)
+	if err != nil || debited == nil || credited == nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_MOVE_POCKET_FUNDS_FAILED,
+			Message:   "Failed to move funds between pockets",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("from", from),
+				zap.String("to", to),
+				zap.Int64("amount", payload.Amount),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Funds moved", fnName), zap.Any("from", debited), zap.Any("to", credited))
+	return debited, credited, nil
+}
+
+func (s *PocketService) fetchPocket(ctx context.Context, fnName string, username string, currency string, pocket string) (*model.Wallet, *validation.WalletError) {
+	wallet, err := s.store.FetchWallet(ctx, username, currency, pocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_POCKET_FAILED,
+			Message:   "Error while fetching pocket",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency),
+				zap.String("pocket", pocket),
+			},
+		}
+	}
+	if wallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_NOT_FOUND,
+			Message:   fmt.Sprintf("Pocket %s does not exist", pocket),
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency),
+				zap.String("pocket", pocket),
+			},
+		}
+	}
+	return wallet, nil
+}
+
+func validatePocketOwner(fnName string, rawUsername string, currencyCode string) (string, model.Currency, *validation.WalletError) {
+	username, err := validation.SanitizeAndValidateUsername(rawUsername)
+	if err != nil {
+		return "", model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", rawUsername),
+			},
+		}
+	}
+	if validation.IsReservedUsername(username) {
+		return "", model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "System wallets do not have pockets",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s is reserved", username),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return "", model.Currency{}, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", currencyCode),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+	return username, currency, nil
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockPocketStore struct {
+	wallets map[string]model.Wallet
+}
+
+func (m *mockPocketStore) initializeMockWallet() {
+	m.wallets = map[string]model.Wallet{
+		"JUAN:USD:MAIN": {
+			Username:    "JUAN",
+			Currency:    "USD",
+			Status:      model.WalletActive,
+			Pocket:      model.DefaultPocket,
+			Limits:      defaultMockLimits("USD"),
+			Balance:     2000,
+			HeldBalance: 500,
+			CreditLimit: 1000,
+		},
+		"JUAN:USD:SAVINGS": {
+			Username: "JUAN",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Pocket:   "SAVINGS",
+			Limits:   model.WalletLimits{MinAmount: 1, MaxAmount: 10000, MaxBalance: 1000},
+			Balance:  800,
+		},
+		"JUAN:EUR:MAIN": {
+			Username: "JUAN",
+			Currency: "EUR",
+			Status:   model.WalletActive,
+			Pocket:   model.DefaultPocket,
+			Limits:   defaultMockLimits("EUR"),
+			Balance:  300,
+		},
+	}
+}
+
+func (m *mockPocketStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	w, ok := m.wallets[username+":"+currency+":"+pocket]
+	if !ok {
+		return nil, nil
+	}
+	return &w, nil
+}
+
+func (m *mockPocketStore) FetchPockets(ctx context.Context, username string, currency string) ([]model.Wallet, error) {
+	pockets := []model.Wallet{}
+	for _, w := range m.wallets {
+		if w.Username == username && w.Currency == currency {
+			pockets = append(pockets, w)
+		}
+	}
+	return pockets, nil
+}
+
+func (m *mockPocketStore) InsertPocket(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, limits model.WalletLimits) (*model.Wallet, error) {
+	key := username + ":" + currency + ":" + pocket
+	if _, ok := m.wallets[key]; ok {
+		return nil, nil
+	}
+	w := model.Wallet{Username: username, Currency: currency, Pocket: pocket, Limits: limits}
+	m.wallets[key] = w
+	return &w, nil
+}
+
+func (m *mockPocketStore) MovePocketFunds(ctx context.Context, tx *sql.Tx, username string, currency string, from string, to string, amount int64) (*model.Wallet, *model.Wallet, error) {
+	source := m.wallets[username+":"+currency+":"+from]
+	destination := m.wallets[username+":"+currency+":"+to]
+	source.Balance -= amount
+	destination.Balance += amount
+	return &source, &destination, nil
+}
+
+func TestDoCreatePocket(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name           string
+		payload        request.PocketPayload
+		expectedPocket string
+		expectedLimits *model.WalletLimits
+		expectedCode   validation.WalletErrorCode
+		expectErr      bool
+	}
+
+	tests := []testCase{
+		{
+			name:           "Successful Create - Default limits",
+			payload:        request.PocketPayload{Username: "juan", Currency: "usd", Pocket: " travel "},
+			expectedPocket: "TRAVEL",
+			expectErr:      false,
+		},
+		{
+			name:           "Successful Create - Custom limits",
+			payload:        request.PocketPayload{Username: "JUAN", Currency: "USD", Pocket: "BILLS", MaxBalance: utils.Ptr(int64(5000))},
+			expectedPocket: "BILLS",
+			expectedLimits: &model.WalletLimits{MinAmount: defaultMockLimits("USD").MinAmount, MaxAmount: defaultMockLimits("USD").MaxAmount, MaxBalance: 5000},
+			expectErr:      false,
+		},
+		{
+			name:         "Failed Create - Pocket already exists",
+			payload:      request.PocketPayload{Username: "JUAN", Currency: "USD", Pocket: "savings"},
+			expectedCode: validation.ERR_POCKET_ALREADY_EXISTS,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Create - Main pocket",
+			payload:      request.PocketPayload{Username: "JUAN", Currency: "USD", Pocket: "main"},
+			expectedCode: validation.ERR_POCKET_ALREADY_EXISTS,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Create - Empty pocket name",
+			payload:      request.PocketPayload{Username: "JUAN", Currency: "USD"},
+			expectedCode: validation.ERR_POCKET_ALREADY_EXISTS,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Create - Invalid pocket name",
+			payload:      request.PocketPayload{Username: "JUAN", Currency: "USD", Pocket: "rainy day"},
+			expectedCode: validation.ERR_POCKET_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Create - No main wallet",
+			payload:      request.PocketPayload{Username: "MARY", Currency: "USD", Pocket: "TRAVEL"},
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Create - Invalid limits",
+			payload:      request.PocketPayload{Username: "JUAN", Currency: "USD", Pocket: "TRAVEL", MinAmount: utils.Ptr(int64(0))},
+			expectedCode: validation.ERR_WALLET_LIMITS_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Create - Reserved system username",
+			payload:      request.PocketPayload{Username: "sys_escrow", Currency: "USD", Pocket: "TRAVEL"},
+			expectedCode: validation.ERR_RESERVED_USERNAME,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockPocketStore{}
+			mock.initializeMockWallet()
+			s := &PocketService{store: mock}
+
+			actual, err := s.DoCreatePocket(context.Background(), nil, &test.payload)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && actual != nil && actual.Pocket != test.expectedPocket {
+				t.Errorf("expected pocket %s but got %s instead", test.expectedPocket, actual.Pocket)
+			}
+
+			if !test.expectErr && actual != nil && test.expectedLimits != nil && actual.Limits != *test.expectedLimits {
+				t.Errorf("expected limits %+v but got %+v instead", *test.expectedLimits, actual.Limits)
+			}
+		})
+	}
+}
+
+func TestDoMovePocketFunds(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name                string
+		payload             request.PocketMovePayload
+		expectedFromBalance int64
+		expectedToBalance   int64
+		expectedCode        validation.WalletErrorCode
+		expectErr           bool
+	}
+
+	tests := []testCase{
+		{
+			name:                "Successful Move - Main to named pocket",
+			payload:             request.PocketMovePayload{Username: "juan", Currency: "usd", To: "savings", Amount: 200},
+			expectedFromBalance: 1800,
+			expectedToBalance:   1000,
+			expectErr:           false,
+		},
+		{
+			name:                "Successful Move - Named pocket to main",
+			payload:             request.PocketMovePayload{Username: "JUAN", Currency: "USD", From: "SAVINGS", To: "MAIN", Amount: 800},
+			expectedFromBalance: 0,
+			expectedToBalance:   2800,
+			expectErr:           false,
+		},
+		{
+			name:         "Failed Move - Same pocket",
+			payload:      request.PocketMovePayload{Username: "JUAN", Currency: "USD", From: "main", Amount: 100},
+			expectedCode: validation.ERR_POCKET_MOVE_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Move - Destination pocket not found",
+			payload:      request.PocketMovePayload{Username: "JUAN", Currency: "USD", To: "TRAVEL", Amount: 100},
+			expectedCode: validation.ERR_POCKET_NOT_FOUND,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Move - Pocket in another currency",
+			payload:      request.PocketMovePayload{Username: "JUAN", Currency: "EUR", To: "SAVINGS", Amount: 100},
+			expectedCode: validation.ERR_POCKET_NOT_FOUND,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Move - Held funds and credit cannot be moved",
+			payload:      request.PocketMovePayload{Username: "JUAN", Currency: "USD", To: "SAVINGS", Amount: 1600},
+			expectedCode: validation.ERR_WALLET_BALANCE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Move - Destination balance limit",
+			payload:      request.PocketMovePayload{Username: "JUAN", Currency: "USD", To: "SAVINGS", Amount: 201},
+			expectedCode: validation.ERR_WALLET_BALANCE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Move - Zero amount",
+			payload:      request.PocketMovePayload{Username: "JUAN", Currency: "USD", To: "SAVINGS", Amount: 0},
+			expectedCode: validation.ERR_AMOUNT_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Move - Invalid pocket name",
+			payload:      request.PocketMovePayload{Username: "JUAN", Currency: "USD", To: "SAVINGS!", Amount: 100},
+			expectedCode: validation.ERR_POCKET_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockPocketStore{}
+			mock.initializeMockWallet()
+			s := &PocketService{store: mock}
+
+			from, to, err := s.DoMovePocketFunds(context.Background(), nil, &test.payload)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && from != nil && from.Balance != test.expectedFromBalance {
+				t.Errorf("expected source balance %d but got %d instead", test.expectedFromBalance, from.Balance)
+			}
+
+			if !test.expectErr && to != nil && to.Balance != test.expectedToBalance {
+				t.Errorf("expected destination balance %d but got %d instead", test.expectedToBalance, to.Balance)
+			}
+		})
+	}
+}
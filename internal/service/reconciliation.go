@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type ReconciliationStore interface {
+	FetchReconciliationSnapshot(ctx context.Context) ([]model.Wallet, []model.TransactionTotal, []model.PostingTotal, error)
+}
+
+type ReconciliationService struct {
+	store  ReconciliationStore
+	mu     sync.RWMutex
+	latest *model.ReconciliationReport
+}
+
+func NewReconciliationService(store ReconciliationStore) *ReconciliationService {
+	logger.Info("Initializing ReconciliationService")
+	return &ReconciliationService{store: store}
+}
+
+func (rs *ReconciliationService) DoReconcile(ctx context.Context) (*model.ReconciliationReport, *validation.WalletError) {
+	fnName := "ReconciliationService.DoReconcile"
+	wallets, totals, postings, err := rs.store.FetchReconciliationSnapshot(ctx)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_RECONCILIATION_FAILED,
+			Message:   "Failed to fetch reconciliation snapshot",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Snapshot fetched", fnName), zap.Int("wallets", len(wallets)), zap.Int("totals", len(totals)), zap.Int("postings", len(postings)))
+
+	report := buildReconciliationReport(wallets, totals, postings)
+
+	rs.mu.Lock()
+	rs.latest = report
+	rs.mu.Unlock()
+
+	if !report.Reconciled {
+		logger.Warn(fmt.Sprintf("%s - Balance drift detected", fnName), zap.Int("mismatched", report.Mismatched), zap.Any("drifts", report.Drifts))
+		return report, nil
+	}
+	logger.Info(fmt.Sprintf("%s - All wallets reconciled", fnName), zap.Int("checked", report.Checked))
+	return report, nil
+}
+
+func (rs *ReconciliationService) DoFetchLatestReport(ctx context.Context) (*model.ReconciliationReport, *validation.WalletError) {
+	rs.mu.RLock()
+	latest := rs.latest
+	rs.mu.RUnlock()
+
+	if latest != nil {
+		return latest, nil
+	}
+	return rs.DoReconcile(ctx)
+}
+
+func (rs *ReconciliationService) RunScheduler(ctx context.Context, interval time.Duration) {
+	fnName := "ReconciliationService.RunScheduler"
+	logger.Info(fmt.Sprintf("%s - Scheduler started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Scheduler stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := rs.DoReconcile(ctx); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Scheduled reconciliation failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func buildReconciliationReport(wallets []model.Wallet, totals []model.TransactionTotal, postings []model.PostingTotal) *model.ReconciliationReport {
+	type walletKey struct {
+		username string
+		currency string
+		pocket   string
+	}
+
+	drifts := map[walletKey]*model.WalletDrift{}
+	keys := []walletKey{}
+	lookup := func(username string, currency string, pocket string) *model.WalletDrift {
+		key := walletKey{username: username, currency: currency, pocket: pocket}
+		drift, ok := drifts[key]
+		if !ok {
+			drift = &model.WalletDrift{Username: username, Currency: currency, Pocket: pocket}
+			drifts[key] = drift
+			keys = append(keys, key)
+		}
+		return drift
+	}
+
+	for _, wallet := range wallets {
+		drift := lookup(wallet.Username, wallet.Currency, wallet.Pocket)
+		drift.WalletExists = true
+		drift.WalletBalance = wallet.Balance
+	}
+
+	for _, total := range totals {
+		drift := lookup(total.Username, total.Currency, total.Pocket)
+		if total.Direction == model.DirectionCredit {
+			drift.TransactionBalance += total.Amount
+		} else {
+			drift.TransactionBalance -= total.Amount
+		}
+	}
+
+	for _, posting := range postings {
+		drift := lookup(posting.Username, posting.Currency, posting.Pocket)
+		if posting.Direction == model.DirectionCredit {
+			drift.LedgerBalance += posting.Amount
+		} else {
+			drift.LedgerBalance -= posting.Amount
+		}
+	}
+
+	report := &model.ReconciliationReport{
+		RunAt:      time.Now().UTC(),
+		Reconciled: true,
+		Checked:    len(keys),
+		Drifts:     []model.WalletDrift{},
+	}
+	for _, key := range keys {
+		drift := drifts[key]
+		drift.Drift = drift.WalletBalance - drift.TransactionBalance
+		if drift.Drift != 0 || drift.WalletBalance != drift.LedgerBalance || !drift.WalletExists {
+			report.Reconciled = false
+			report.Mismatched++
+			report.Drifts = append(report.Drifts, *drift)
+		}
+	}
+	return report
+}
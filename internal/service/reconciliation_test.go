@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockReconciliationStore struct {
+	wallets  []model.Wallet
+	totals   []model.TransactionTotal
+	postings []model.PostingTotal
+}
+
+func (m *mockReconciliationStore) FetchReconciliationSnapshot(ctx context.Context) ([]model.Wallet, []model.TransactionTotal, []model.PostingTotal, error) {
+	return m.wallets, m.totals, m.postings, nil
+}
+
+func TestDoReconcile(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name              string
+		wallets           []model.Wallet
+		totals            []model.TransactionTotal
+		postings          []model.PostingTotal
+		expectReconciled  bool
+		expectedChecked   int
+		expectedDrifts    []model.WalletDrift
+		expectedErrorCode validation.WalletErrorCode
+		expectErr         bool
+	}
+
+	tests := []testCase{
+		{
+			name:             "Reconciled - No wallets",
+			expectReconciled: true,
+			expectedChecked:  0,
+			expectErr:        false,
+		},
+		{
+			name: "Reconciled - Deposits, withdrawals and transfers",
+			wallets: []model.Wallet{
+				{Username: "JUAN", Currency: "USD", Balance: 700},
+				{Username: "MARY", Currency: "USD", Balance: 300},
+			},
+			totals: []model.TransactionTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 1500},
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionDebit, Amount: 500},
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionDebit, Amount: 300},
+				{Username: "MARY", Currency: "USD", Direction: model.DirectionCredit, Amount: 300},
+			},
+			postings: []model.PostingTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 1500},
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionDebit, Amount: 800},
+				{Username: "MARY", Currency: "USD", Direction: model.DirectionCredit, Amount: 300},
+			},
+			expectReconciled: true,
+			expectedChecked:  2,
+			expectErr:        false,
+		},
+		{
+			name: "Reconciled - Empty wallet without transactions",
+			wallets: []model.Wallet{
+				{Username: "JUAN", Currency: "USD", Balance: 0},
+			},
+			expectReconciled: true,
+			expectedChecked:  1,
+			expectErr:        false,
+		},
+		{
+			name: "Drift - Wallet balance ahead of transaction log",
+			wallets: []model.Wallet{
+				{Username: "JUAN", Currency: "USD", Balance: 1200},
+				{Username: "JUAN", Currency: "EUR", Balance: 500},
+			},
+			totals: []model.TransactionTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 1000},
+				{Username: "JUAN", Currency: "EUR", Direction: model.DirectionCredit, Amount: 500},
+			},
+			postings: []model.PostingTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 1200},
+				{Username: "JUAN", Currency: "EUR", Direction: model.DirectionCredit, Amount: 500},
+			},
+			expectReconciled: false,
+			expectedChecked:  2,
+			expectedDrifts: []model.WalletDrift{
+				{Username: "JUAN", Currency: "USD", WalletExists: true, WalletBalance: 1200, LedgerBalance: 1200, TransactionBalance: 1000, Drift: 200},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Drift - Wallet without transactions",
+			wallets: []model.Wallet{
+				{Username: "JUAN", Currency: "USD", Balance: 1000},
+			},
+			postings: []model.PostingTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 1000},
+			},
+			expectReconciled: false,
+			expectedChecked:  1,
+			expectedDrifts: []model.WalletDrift{
+				{Username: "JUAN", Currency: "USD", WalletExists: true, WalletBalance: 1000, LedgerBalance: 1000, TransactionBalance: 0, Drift: 1000},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Drift - Transactions without wallet",
+			totals: []model.TransactionTotal{
+				{Username: "MARY", Currency: "EUR", Direction: model.DirectionCredit, Amount: 400},
+			},
+			expectReconciled: false,
+			expectedChecked:  1,
+			expectedDrifts: []model.WalletDrift{
+				{Username: "MARY", Currency: "EUR", WalletExists: false, WalletBalance: 0, TransactionBalance: 400, Drift: -400},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Reconciled - Partially reversed deposit",
+			wallets: []model.Wallet{
+				{Username: "JUAN", Currency: "USD", Balance: 600},
+			},
+			totals: []model.TransactionTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 1000},
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionDebit, Amount: 400},
+			},
+			postings: []model.PostingTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 1000},
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionDebit, Amount: 400},
+			},
+			expectReconciled: true,
+			expectedChecked:  1,
+			expectErr:        false,
+		},
+		{
+			name: "Drift - Wallet balance ahead of postings",
+			wallets: []model.Wallet{
+				{Username: "JUAN", Currency: "USD", Balance: 1000},
+			},
+			totals: []model.TransactionTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 1000},
+			},
+			postings: []model.PostingTotal{
+				{Username: "JUAN", Currency: "USD", Direction: model.DirectionCredit, Amount: 900},
+			},
+			expectReconciled: false,
+			expectedChecked:  1,
+			expectedDrifts: []model.WalletDrift{
+				{Username: "JUAN", Currency: "USD", WalletExists: true, WalletBalance: 1000, LedgerBalance: 900, TransactionBalance: 1000, Drift: 0},
+			},
+			expectErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockReconciliationStore{wallets: test.wallets, totals: test.totals, postings: test.postings}
+			s := &ReconciliationService{store: mock}
+
+			actual, err := s.DoReconcile(context.Background())
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedErrorCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedErrorCode, err.Code)
+				}
+				return
+			}
+
+			if test.expectReconciled != actual.Reconciled {
+				t.Errorf("expected reconciled %t but got %t instead", test.expectReconciled, actual.Reconciled)
+			}
+
+			if test.expectedChecked != actual.Checked {
+				t.Errorf("expected checked %d but got %d instead", test.expectedChecked, actual.Checked)
+			}
+
+			if len(test.expectedDrifts) != actual.Mismatched || len(test.expectedDrifts) != len(actual.Drifts) {
+				t.Fatalf("expected %d drifts but got %d instead", len(test.expectedDrifts), len(actual.Drifts))
+			}
+
+			for i, expected := range test.expectedDrifts {
+				if expected != actual.Drifts[i] {
+					t.Errorf("expected drift %+v but got %+v instead", expected, actual.Drifts[i])
+				}
+			}
+
+			latest, _ := s.DoFetchLatestReport(context.Background())
+			if latest != actual {
+				t.Errorf("expected latest report to be the last run")
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type ReversalStore interface {
+	FetchTransactionForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Transaction, error)
+	FetchJournalEntryTransactionsForUpdate(ctx context.Context, tx *sql.Tx, entryID int64) ([]model.Transaction, error)
+	FetchReversedAmount(ctx context.Context, tx *sql.Tx, id int64) (int64, error)
+}
+
+type ReversalService struct {
+	store ReversalStore
+}
+
+func NewReversalService(store ReversalStore) *ReversalService {
+	logger.Info("Initializing ReversalService")
+	return &ReversalService{store: store}
+}
+
+func (s *ReversalService) DoPrepareReversal(ctx context.Context, tx *sql.Tx, id int64, amount *int64) ([]model.ReversalLeg, *validation.WalletError) {
+	fnName := "ReversalService.DoPrepareReversal"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id), zap.Any("amount", amount))
+
+	original, err := s.store.FetchTransactionForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_TRANSACTION_FAILED,
+			Message:   "Failed to fetch transaction",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if original == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_TRANSACTION_NOT_FOUND,
+			Message:   "Transaction not found",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Original transaction fetched", fnName), zap.Any("original", original))
+
+	if original.TxnType == model.TypeReversal {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_REVERSAL_NOT_ALLOWED,
+			Message:   "Reversal transactions cannot be reversed",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("transaction %d is a reversal of %d", original.ID, *original.ReversalOf),
+		}
+	}
+	if original.TxnType == model.TypeEscrowFund || original.TxnType == model.TypeEscrowRelease || original.TxnType == model.TypeEscrowRefund || original.TxnType == model.TypeFee || original.TxnType == model.TypeInterest {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_REVERSAL_NOT_ALLOWED,
+			Message:   "Escrow, fee and interest transactions cannot be reversed",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("transaction %d is of type %s", original.ID, original.TxnType),
+		}
+	}
+	if original.JournalEntryID == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_REVERSAL_NOT_ALLOWED,
+			Message:   "Transaction is not linked to a journal entry",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("transaction %d has no journal entry", original.ID),
+		}
+	}
+
+	related, err := s.store.FetchJournalEntryTransactionsForUpdate(ctx, tx, *original.JournalEntryID)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_TRANSACTION_FAILED,
+			Message:   "Failed to fetch related transactions",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("entryID", *original.JournalEntryID),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Related transactions fetched", fnName), zap.Int("count", len(related)))
+
+	legs := []model.ReversalLeg{}
+	var originalLeg *model.ReversalLeg
+	for _, txn := range related {
+		reversed, err := s.store.FetchReversedAmount(ctx, tx, txn.ID)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_FETCH_TRANSACTION_FAILED,
+				Message:   "Failed to fetch reversed amount",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Int64("id", txn.ID),
+				},
+			}
+		}
+		legs = append(legs, model.ReversalLeg{
+			Original:  txn,
+			Direction: txn.Direction.Opposite(),
+			Remaining: txn.Amount - reversed,
+		})
+	}
+	for i := range legs {
+		if legs[i].Original.ID == original.ID {
+			originalLeg = &legs[i]
+		}
+	}
+	if originalLeg == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_REVERSAL_NOT_ALLOWED,
+			Message:   "Transaction is not part of its journal entry",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("transaction %d missing from entry %d", original.ID, *original.JournalEntryID),
+		}
+	}
+
+	if originalLeg.Remaining <= 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_TRANSACTION_ALREADY_REVERSED,
+			Message:   "Transaction has already been fully reversed",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.Int64("id", original.ID),
+			},
+		}
+	}
+
+	requested := originalLeg.Remaining
+	if amount != nil {
+		requested = *amount
+	}
+	if requested <= 0 || requested > originalLeg.Remaining {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_REVERSAL_AMOUNT_INVALID,
+			Message:   "Reversal amount must be positive and no more than the unreversed amount",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("requested %d but %d remains", requested, originalLeg.Remaining),
+			Context: []zap.Field{
+				zap.Int64("requested", requested),
+				zap.Int64("remaining", originalLeg.Remaining),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Reversal amount valid", fnName), zap.Int64("requested", requested), zap.Int64("remaining", originalLeg.Remaining))
+
+	for i := range legs {
+		leg := &legs[i]
+		switch {
+		case leg.Original.ID == original.ID:
+			leg.Amount = requested
+		case requested == originalLeg.Remaining:
+			leg.Amount = leg.Remaining
+		default:
+			leg.Amount = min(leg.Original.Amount*requested/original.Amount, leg.Remaining)
+		}
+		if leg.Amount <= 0 {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_REVERSAL_AMOUNT_INVALID,
+				Message:   "Reversal amount is too small to apply to every leg",
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("leg %d would reverse %d", leg.Original.ID, leg.Amount),
+				Context: []zap.Field{
+					zap.Any("leg", leg),
+				},
+			}
+		}
+	}
+
+	sort.SliceStable(legs, func(i, j int) bool {
+		return legs[i].Direction == model.DirectionDebit && legs[j].Direction != model.DirectionDebit
+	})
+	logger.Info(fmt.Sprintf("%s - Reversal legs prepared", fnName), zap.Any("legs", legs))
+	return legs, nil
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockReversalStore struct {
+	transactions map[int64]model.Transaction
+	reversed     map[int64]int64
+}
+
+func (m *mockReversalStore) initializeMockTransactions() {
+	m.transactions = map[int64]model.Transaction{
+		1:  {ID: 1, Username: "JUAN", TxnType: model.TypeDeposit, Direction: model.DirectionCredit, Currency: "USD", Amount: 1000, JournalEntryID: utils.Ptr(int64(1))},
+		2:  {ID: 2, Username: "JUAN", TxnType: model.TypeWithdraw, Direction: model.DirectionDebit, Currency: "USD", Amount: 400, JournalEntryID: utils.Ptr(int64(2))},
+		3:  {ID: 3, Username: "JUAN", TxnType: model.TypeTransferOut, Direction: model.DirectionDebit, Currency: "USD", Amount: 500, Counterparty: utils.Ptr("MARY"), JournalEntryID: utils.Ptr(int64(3))},
+		4:  {ID: 4, Username: "MARY", TxnType: model.TypeTransferIn, Direction: model.DirectionCredit, Currency: "USD", Amount: 500, Counterparty: utils.Ptr("JUAN"), JournalEntryID: utils.Ptr(int64(3))},
+		5:  {ID: 5, Username: "JUAN", TxnType: model.TypeTransferOut, Direction: model.DirectionDebit, Currency: "USD", Amount: 1000, Counterparty: utils.Ptr("MARY"), FXRate: utils.Ptr("0.9150000000"), JournalEntryID: utils.Ptr(int64(4))},
+		6:  {ID: 6, Username: "MARY", TxnType: model.TypeTransferIn, Direction: model.DirectionCredit, Currency: "EUR", Amount: 915, Counterparty: utils.Ptr("JUAN"), FXRate: utils.Ptr("0.9150000000"), JournalEntryID: utils.Ptr(int64(4))},
+		7:  {ID: 7, Username: "JUAN", TxnType: model.TypeReversal, Direction: model.DirectionDebit, Currency: "USD", Amount: 100, JournalEntryID: utils.Ptr(int64(5)), ReversalOf: utils.Ptr(int64(8))},
+		8:  {ID: 8, Username: "JUAN", TxnType: model.TypeDeposit, Direction: model.DirectionCredit, Currency: "USD", Amount: 100, JournalEntryID: utils.Ptr(int64(6))},
+		9:  {ID: 9, Username: "JUAN", TxnType: model.TypeDeposit, Direction: model.DirectionCredit, Currency: "USD", Amount: 300},
+		10: {ID: 10, Username: "JUAN", TxnType: model.TypeEscrowFund, Direction: model.DirectionDebit, Currency: "USD", Amount: 200, Counterparty: utils.Ptr(model.EscrowWallet), JournalEntryID: utils.Ptr(int64(7))},
+		11: {ID: 11, Username: "JUAN", TxnType: model.TypeFee, Direction: model.DirectionDebit, Currency: "USD", Amount: 10, Counterparty: utils.Ptr(model.FeeWallet), JournalEntryID: utils.Ptr(int64(8))},
+		12: {ID: 12, Username: "JUAN", TxnType: model.TypeInterest, Direction: model.DirectionCredit, Currency: "USD", Amount: 30, JournalEntryID: utils.Ptr(int64(9))},
+	}
+	m.reversed = map[int64]int64{
+		8: 100,
+	}
+}
+
+func (m *mockReversalStore) FetchTransactionForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.Transaction, error) {
+	txn, ok := m.transactions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &txn, nil
+}
+
+func (m *mockReversalStore) FetchJournalEntryTransactionsForUpdate(ctx context.Context, tx *sql.Tx, entryID int64) ([]model.Transaction, error) {
+	transactions := []model.Transaction{}
+	for id := int64(1); id <= int64(len(m.transactions)); id++ {
+		txn := m.transactions[id]
+		if txn.JournalEntryID != nil && *txn.JournalEntryID == entryID {
+			transactions = append(transactions, txn)
+		}
+	}
+	return transactions, nil
+}
+
+func (m *mockReversalStore) FetchReversedAmount(ctx context.Context, tx *sql.Tx, id int64) (int64, error) {
+	return m.reversed[id], nil
+}
+
+func TestDoPrepareReversal(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type expectedLeg struct {
+		originalID int64
+		direction  model.PostingDirection
+		amount     int64
+	}
+
+	type testCase struct {
+		name         string
+		id           int64
+		amount       *int64
+		reversed     map[int64]int64
+		expectedLegs []expectedLeg
+		expectedCode validation.WalletErrorCode
+		expectErr    bool
+	}
+
+	tests := []testCase{
+		{
+			name: "Successful Reversal - Full deposit",
+			id:   1,
+			expectedLegs: []expectedLeg{
+				{originalID: 1, direction: model.DirectionDebit, amount: 1000},
+			},
+			expectErr: false,
+		},
+		{
+			name:   "Successful Reversal - Partial withdraw refund",
+			id:     2,
+			amount: utils.Ptr(int64(150)),
+			expectedLegs: []expectedLeg{
+				{originalID: 2, direction: model.DirectionCredit, amount: 150},
+			},
+			expectErr: false,
+		},
+		{
+			name:     "Successful Reversal - Remainder after partial refund",
+			id:       1,
+			reversed: map[int64]int64{1: 600},
+			expectedLegs: []expectedLeg{
+				{originalID: 1, direction: model.DirectionDebit, amount: 400},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Successful Reversal - Transfer reverses both legs",
+			id:   3,
+			expectedLegs: []expectedLeg{
+				{originalID: 4, direction: model.DirectionDebit, amount: 500},
+				{originalID: 3, direction: model.DirectionCredit, amount: 500},
+			},
+			expectErr: false,
+		},
+		{
+			name:   "Successful Reversal - Transfer reversed through incoming leg",
+			id:     4,
+			amount: utils.Ptr(int64(200)),
+			expectedLegs: []expectedLeg{
+				{originalID: 4, direction: model.DirectionDebit, amount: 200},
+				{originalID: 3, direction: model.DirectionCredit, amount: 200},
+			},
+			expectErr: false,
+		},
+		{
+			name:   "Successful Reversal - Partial FX transfer rounds down converted leg",
+			id:     5,
+			amount: utils.Ptr(int64(500)),
+			expectedLegs: []expectedLeg{
+				{originalID: 6, direction: model.DirectionDebit, amount: 457},
+				{originalID: 5, direction: model.DirectionCredit, amount: 500},
+			},
+			expectErr: false,
+		},
+		{
+			name:     "Successful Reversal - Final FX partial takes converted remainder",
+			id:       5,
+			reversed: map[int64]int64{5: 500, 6: 457},
+			expectedLegs: []expectedLeg{
+				{originalID: 6, direction: model.DirectionDebit, amount: 458},
+				{originalID: 5, direction: model.DirectionCredit, amount: 500},
+			},
+			expectErr: false,
+		},
+		{
+			name:         "Failed Reversal - Transaction not found",
+			id:           99,
+			expectedCode: validation.ERR_TRANSACTION_NOT_FOUND,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - Reversal of a reversal",
+			id:           7,
+			expectedCode: validation.ERR_REVERSAL_NOT_ALLOWED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - Escrow transaction",
+			id:           10,
+			expectedCode: validation.ERR_REVERSAL_NOT_ALLOWED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - Fee transaction",
+			id:           11,
+			expectedCode: validation.ERR_REVERSAL_NOT_ALLOWED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - Interest transaction",
+			id:           12,
+			expectedCode: validation.ERR_REVERSAL_NOT_ALLOWED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - Transaction without journal entry",
+			id:           9,
+			expectedCode: validation.ERR_REVERSAL_NOT_ALLOWED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - Already fully reversed",
+			id:           8,
+			expectedCode: validation.ERR_TRANSACTION_ALREADY_REVERSED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - Amount above unreversed remainder",
+			id:           1,
+			amount:       utils.Ptr(int64(500)),
+			reversed:     map[int64]int64{1: 600},
+			expectedCode: validation.ERR_REVERSAL_AMOUNT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - Zero amount",
+			id:           1,
+			amount:       utils.Ptr(int64(0)),
+			expectedCode: validation.ERR_REVERSAL_AMOUNT_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Reversal - FX partial too small for converted leg",
+			id:           5,
+			amount:       utils.Ptr(int64(1)),
+			expectedCode: validation.ERR_REVERSAL_AMOUNT_INVALID,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockReversalStore{}
+			mock.initializeMockTransactions()
+			for id, amount := range test.reversed {
+				mock.reversed[id] = amount
+			}
+			s := &ReversalService{store: mock}
+
+			actual, err := s.DoPrepareReversal(context.Background(), nil, test.id, test.amount)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if len(test.expectedLegs) != len(actual) {
+				t.Fatalf("expected %d legs but got %d instead", len(test.expectedLegs), len(actual))
+			}
+
+			for i, expected := range test.expectedLegs {
+				if expected.originalID != actual[i].Original.ID {
+					t.Errorf("leg %d: expected original %d but got %d instead", i, expected.originalID, actual[i].Original.ID)
+				}
+				if expected.direction != actual[i].Direction {
+					t.Errorf("leg %d: expected direction %s but got %s instead", i, expected.direction, actual[i].Direction)
+				}
+				if expected.amount != actual[i].Amount {
+					t.Errorf("leg %d: expected amount %d but got %d instead", i, expected.amount, actual[i].Amount)
+				}
+			}
+		})
+	}
+}
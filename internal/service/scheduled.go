@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const scheduledSavepoint = "scheduled_transfer"
+
+type TransferExecutor func(ctx context.Context, tx *sql.Tx, payload *request.RequestPayload) (*model.Transaction, *validation.WalletError)
+
+type ScheduledTransferStore interface {
+	BeginTransaction(ctx context.Context) (*sql.Tx, error)
+	Savepoint(ctx context.Context, tx *sql.Tx, name string) error
+	RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error
+	InsertScheduledTransfer(ctx context.Context, tx *sql.Tx, st *model.ScheduledTransfer) error
+	FetchScheduledTransferForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.ScheduledTransfer, error)
+	ClaimDueScheduledTransfer(ctx context.Context, tx *sql.Tx, at time.Time) (*model.ScheduledTransfer, error)
+	UpdateScheduledTransfer(ctx context.Context, tx *sql.Tx, st *model.ScheduledTransfer) error
+	InsertScheduledTransferAttempt(ctx context.Context, tx *sql.Tx, attempt *model.ScheduledTransferAttempt) error
+	FetchScheduledTransfers(ctx context.Context, criteria *model.ScheduledCriteria) ([]model.ScheduledTransfer, error)
+	FetchScheduledTransferAttempts(ctx context.Context, ids []int64) ([]model.ScheduledTransferAttempt, error)
+}
+
+type ScheduledTransferService struct {
+	store  ScheduledTransferStore
+	config *model.ScheduledTransferConfig
+}
+
+func NewScheduledTransferService(store ScheduledTransferStore, config *model.ScheduledTransferConfig) *ScheduledTransferService {
+	logger.Info("Initializing ScheduledTransferService")
+	return &ScheduledTransferService{store: store, config: config}
+}
+
+func (s *ScheduledTransferService) DoCreateScheduledTransfer(ctx context.Context, tx *sql.Tx, payload *request.ScheduledTransferPayload) (*model.ScheduledTransfer, *validation.WalletError) {
+	fnName := "ScheduledTransferService.DoCreateScheduledTransfer"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, err := validation.SanitizeAndValidateUsername(payload.Username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize username",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", payload.Username),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	actor := username
+	if payload.Actor != "" {
+		actor, err = validation.SanitizeAndValidateUsername(payload.Actor)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+				Message:   "Failed to sanitize actor",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("actor", payload.Actor),
+				},
+			}
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Actor resolved", fnName), zap.String("actor", actor))
+
+	counterparty, err := validation.SanitizeAndValidateUsername(payload.Counterparty)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize counterparty",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("counterparty", payload.Counterparty),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Counterparty sanitized", fnName), zap.String("counterparty", counterparty))
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	if err := validation.ValidateAmount(payload.Amount, currency); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("amount", payload.Amount),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Amount validated", fnName), zap.Int64("amount", payload.Amount))
+
+	now := time.Now().UTC()
+	executeAt := payload.ExecuteAt.UTC()
+	if !executeAt.After(now) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SCHEDULE_TIME_INVALID,
+			Message:   "Scheduled time must be in the future",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("executeAt %s is not after %s", executeAt.Format(time.RFC3339), now.Format(time.RFC3339)),
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled time validated", fnName), zap.Time("executeAt", executeAt))
+
+	st := &model.ScheduledTransfer{
+		Username:     username,
+		Actor:        actor,
+		Currency:     currency.Code,
+		Amount:       payload.Amount,
+		Counterparty: counterparty,
+		ExecuteAt:    executeAt,
+	}
+	if err := s.store.InsertScheduledTransfer(ctx, tx, st); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_SCHEDULED_TRANSFER_FAILED,
+			Message:   "Failed to create scheduled transfer",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("scheduledTransfer", st),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled transfer created", fnName), zap.Any("scheduledTransfer", st))
+	return st, nil
+}
+
+func (s *ScheduledTransferService) DoFetchScheduledTransfers(ctx context.Context, username string, standingOrderID string, status string, limit string) ([]model.ScheduledTransfer, *model.ScheduledCriteria, *validation.WalletError) {
+	fnName := "ScheduledTransferService.DoFetchScheduledTransfers"
+	queryUsername := validation.SanitizeUsernameWithoutError(username)
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", queryUsername))
+
+	queryStandingOrderID, err := strconv.ParseInt(standingOrderID, 10, 64)
+	if err != nil {
+		queryStandingOrderID = 0
+	}
+	logger.Info(fmt.Sprintf("%s - Standing order ID converted to int", fnName), zap.Int64("standingOrderID", queryStandingOrderID))
+
+	queryStatus := ""
+	if model.IsScheduledStatusValid(status) {
+		queryStatus = status
+	}
+	logger.Info(fmt.Sprintf("%s - Status valid", fnName), zap.String("status", queryStatus))
+
+	queryLimit, err := strconv.Atoi(limit)
+	if err != nil {
+		queryLimit = 0
+	}
+	logger.Info(fmt.Sprintf("%s - Limit converted to int", fnName), zap.Int("limit", queryLimit))
+
+	criteria := &model.ScheduledCriteria{
+		Username:        queryUsername,
+		StandingOrderID: queryStandingOrderID,
+		Status:          model.ScheduledStatus(queryStatus),
+		Limit:           queryLimit,
+	}
+
+	scheduled, err := s.store.FetchScheduledTransfers(ctx, criteria)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_SCHEDULED_TRANSFER_FAILED,
+			Message:   "Failed to fetch scheduled transfers",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("criteria", criteria),
+			},
+		}
+	}
+	if len(scheduled) == 0 {
+		return scheduled, criteria, nil
+	}
+
+	ids := make([]int64, 0, len(scheduled))
+	index := make(map[int64]int, len(scheduled))
+	for i, st := range scheduled {
+		ids = append(ids, st.ID)
+		index[st.ID] = i
+	}
+
+	attempts, err := s.store.FetchScheduledTransferAttempts(ctx, ids)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_SCHEDULED_TRANSFER_FAILED,
+			Message:   "Failed to fetch scheduled transfer attempts",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	for _, attempt := range attempts {
+		i := index[attempt.ScheduledTransferID]
+		scheduled[i].Attempts = append(scheduled[i].Attempts, attempt)
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled transfers fetched", fnName), zap.Int("count", len(scheduled)), zap.Int("attempts", len(attempts)))
+	return scheduled, criteria, nil
+}
+
+func (s *ScheduledTransferService) DoCancelScheduledTransfer(ctx context.Context, tx *sql.Tx, id int64) (*model.ScheduledTransfer, *validation.WalletError) {
+	fnName := "ScheduledTransferService.DoCancelScheduledTransfer"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id))
+
+	st, err := s.store.FetchScheduledTransferForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_SCHEDULED_TRANSFER_FAILED,
+			Message:   "Failed to fetch scheduled transfer",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if st == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SCHEDULED_TRANSFER_NOT_FOUND,
+			Message:   "Scheduled transfer not found",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if st.Status != model.ScheduledPending {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SCHEDULED_TRANSFER_NOT_PENDING,
+			Message:   "Only pending scheduled transfers can be cancelled",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("scheduled transfer %d is %s", st.ID, st.Status),
+		}
+	}
+
+	cancelledAt := time.Now().UTC()
+	st.Status = model.ScheduledCancelled
+	st.CancelledAt = &cancelledAt
+	if err := s.store.UpdateScheduledTransfer(ctx, tx, st); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_SCHEDULED_TRANSFER_FAILED,
+			Message:   "Failed to cancel scheduled transfer",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("scheduledTransfer", st),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled transfer cancelled", fnName), zap.Any("scheduledTransfer", st))
+	return st, nil
+}
+
+func (s *ScheduledTransferService) DoExecuteDue(ctx context.Context, execute TransferExecutor) (int, *validation.WalletError) {
+	fnName := "ScheduledTransferService.DoExecuteDue"
+	processed := 0
+	for {
+		ok, appErr := s.executeNext(ctx, execute)
+		if appErr != nil {
+			return processed, appErr
+		}
+		if !ok {
+			break
+		}
+		processed++
+	}
+	logger.Info(fmt.Sprintf("%s - Due scheduled transfers processed", fnName), zap.Int("processed", processed))
+	return processed, nil
+}
+
+func (s *ScheduledTransferService) RunScheduler(ctx context.Context, interval time.Duration, execute TransferExecutor) {
+	fnName := "ScheduledTransferService.RunScheduler"
+	logger.Info(fmt.Sprintf("%s - Scheduler started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Scheduler stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoExecuteDue(ctx, execute); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Scheduled transfer run failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func (s *ScheduledTransferService) executeNext(ctx context.Context, execute TransferExecutor) (bool, *validation.WalletError) {
+	fnName := "ScheduledTransferService.executeNext"
+	failed := func(message string, err error) *validation.WalletError {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_EXECUTE_SCHEDULED_TRANSFER_FAILED,
+			Message:   message,
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+
+	tx, err := s.store.BeginTransaction(ctx)
+	if err != nil {
+		return false, failed("Failed to start transaction", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	st, err := s.store.ClaimDueScheduledTransfer(ctx, tx, now)
+	if err != nil {
+		return false, failed("Failed to claim due scheduled transfer", err)
+	}
+	if st == nil {
+		return false, nil
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled transfer claimed", fnName), zap.Any("scheduledTransfer", st))
+
+	if err := s.store.Savepoint(ctx, tx, scheduledSavepoint); err != nil {
+		return false, failed("Failed to create savepoint", err)
+	}
+
+	txn, appErr := execute(ctx, tx, &request.RequestPayload{
+		Username:     st.Username,
+		Actor:        st.Actor,
+		Amount:       st.Amount,
+		Currency:     st.Currency,
+		Counterparty: &st.Counterparty,
+	})
+	if appErr != nil {
+		logger.Warn(fmt.Sprintf("%s - Scheduled transfer attempt failed", fnName), zap.Int64("id", st.ID), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+		if err := s.store.RollbackToSavepoint(ctx, tx, scheduledSavepoint); err != nil {
+			return false, failed("Failed to roll back failed attempt", err)
+		}
+	}
+
+	attempt := s.recordAttempt(st, txn, appErr, now)
+	if err := s.store.UpdateScheduledTransfer(ctx, tx, st); err != nil {
+		return false, failed("Failed to update scheduled transfer", err)
+	}
+	if err := s.store.InsertScheduledTransferAttempt(ctx, tx, &attempt); err != nil {
+		return false, failed("Failed to record scheduled transfer attempt", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, failed("Failed to commit scheduled transfer attempt", err)
+	}
+	logger.Info(fmt.Sprintf("%s - Scheduled transfer attempt recorded", fnName), zap.Any("scheduledTransfer", st), zap.Any("attempt", attempt))
+	return true, nil
+}
+
+func (s *ScheduledTransferService) recordAttempt(st *model.ScheduledTransfer, txn *model.Transaction, appErr *validation.WalletError, at time.Time) model.ScheduledTransferAttempt {
+	st.AttemptCount++
+	attempt := model.ScheduledTransferAttempt{
+		ScheduledTransferID: st.ID,
+		AttemptedAt:         at,
+	}
+
+	if appErr == nil {
+		attempt.Succeeded = true
+		attempt.TransactionID = &txn.ID
+		st.Status = model.ScheduledSucceeded
+		st.ExecutedAt = &at
+		st.LastError = nil
+		return attempt
+	}
+
+	code := string(appErr.Code)
+	message := appErr.Message
+	if appErr.Err != nil {
+		message = fmt.Sprintf("%s: %v", appErr.Message, appErr.Err)
+	}
+	attempt.ErrorCode = &code
+	attempt.ErrorMessage = &message
+	st.LastError = &message
+
+	retryable := st.StandingOrderID == nil || appErr.Code == validation.ERR_INSUFFICIENT_WALLET_BALANCE
+	if !retryable || st.AttemptCount >= s.config.MaxAttempts {
+		st.Status = model.ScheduledFailed
+		return attempt
+	}
+	st.NextAttemptAt = at.Add(s.config.RetryDelay)
+	return attempt
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockScheduledTransferStore struct {
+	scheduled map[int64]*model.ScheduledTransfer
+	nextID    int64
+}
+
+func (m *mockScheduledTransferStore) initializeMockScheduled() {
+	executeAt := time.Now().UTC().Add(time.Hour)
+	m.scheduled = map[int64]*model.ScheduledTransfer{
+		1: {ID: 1, Username: "JUAN", Currency: "USD", Amount: 500, Counterparty: "MARY", ExecuteAt: executeAt, NextAttemptAt: executeAt, Status: model.ScheduledPending},
+		2: {ID: 2, Username: "JUAN", Currency: "USD", Amount: 500, Counterparty: "MARY", ExecuteAt: executeAt, NextAttemptAt: executeAt, Status: model.ScheduledSucceeded},
+		3: {ID: 3, Username: "JUAN", Currency: "USD", Amount: 500, Counterparty: "MARY", ExecuteAt: executeAt, NextAttemptAt: executeAt, Status: model.ScheduledCancelled},
+	}
+	m.nextID = 4
+}
+
+func (m *mockScheduledTransferStore) BeginTransaction(ctx context.Context) (*sql.Tx, error) {
+	return nil, fmt.Errorf("not supported by mock")
+}
+
+func (m *mockScheduledTransferStore) Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	return nil
+}
+
+func (m *mockScheduledTransferStore) RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	return nil
+}
+
+func (m *mockScheduledTransferStore) InsertScheduledTransfer(ctx context.Context, tx *sql.Tx, st *model.ScheduledTransfer) error {
+	st.ID = m.nextID
+	st.NextAttemptAt = st.ExecuteAt
+	st.Status = model.ScheduledPending
+	st.CreatedAt = time.Now().UTC()
+	m.scheduled[st.ID] = st
+	m.nextID++
+	return nil
+}
+
+func (m *mockScheduledTransferStore) FetchScheduledTransferForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.ScheduledTransfer, error) {
+	st, ok := m.scheduled[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *st
+	return &copied, nil
+}
+
+func (m *mockScheduledTransferStore) ClaimDueScheduledTransfer(ctx context.Context, tx *sql.Tx, at time.Time) (*model.ScheduledTransfer, error) {
+	return nil, nil
+}
+
+func (m *mockScheduledTransferStore) UpdateScheduledTransfer(ctx context.Context, tx *sql.Tx, st *model.ScheduledTransfer) error {
+	if m.scheduled[st.ID].Status != model.ScheduledPending {
+		return fmt.Errorf("scheduled transfer %d is no longer pending", st.ID)
+	}
+	m.scheduled[st.ID] = st
+	return nil
+}
+
+func (m *mockScheduledTransferStore) InsertScheduledTransferAttempt(ctx context.Context, tx *sql.Tx, attempt *model.ScheduledTransferAttempt) error {
+	return nil
+}
+
+func (m *mockScheduledTransferStore) FetchScheduledTransfers(ctx context.Context, criteria *model.ScheduledCriteria) ([]model.ScheduledTransfer, error) {
+	return []model.ScheduledTransfer{}, nil
+}
+
+func (m *mockScheduledTransferStore) FetchScheduledTransferAttempts(ctx context.Context, ids []int64) ([]model.ScheduledTransferAttempt, error) {
+	return []model.ScheduledTransferAttempt{}, nil
+}
+
+func TestDoCreateScheduledTransfer(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name          string
+		payload       *request.ScheduledTransferPayload
+		expectedActor string
+		expectedCode  validation.WalletErrorCode
+		expectErr     bool
+	}
+
+	future := time.Now().Add(24 * time.Hour)
+
+	tests := []testCase{
+		{
+			name:          "Successful Schedule - Future transfer",
+			payload:       &request.ScheduledTransferPayload{Username: "juan", Amount: 500, Counterparty: "mary", ExecuteAt: future},
+			expectedActor: "JUAN",
+			expectErr:     false,
+		},
+		{
+			name:          "Successful Schedule - Joint wallet keeps acting member",
+			payload:       &request.ScheduledTransferPayload{Username: "j_family", Actor: "pedro", Amount: 500, Counterparty: "mary", ExecuteAt: future},
+			expectedActor: "PEDRO",
+			expectErr:     false,
+		},
+		{
+			name:      "Successful Schedule - Non-default currency",
+			payload:   &request.ScheduledTransferPayload{Username: "juan", Currency: "eur", Amount: 500, Counterparty: "mary", ExecuteAt: future},
+			expectErr: false,
+		},
+		{
+			name:         "Failed Schedule - Time in the past",
+			payload:      &request.ScheduledTransferPayload{Username: "juan", Amount: 500, Counterparty: "mary", ExecuteAt: time.Now().Add(-time.Minute)},
+			expectedCode: validation.ERR_SCHEDULE_TIME_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Schedule - Missing time",
+			payload:      &request.ScheduledTransferPayload{Username: "juan", Amount: 500, Counterparty: "mary"},
+			expectedCode: validation.ERR_SCHEDULE_TIME_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Schedule - Negative amount",
+			payload:      &request.ScheduledTransferPayload{Username: "juan", Amount: -500, Counterparty: "mary", ExecuteAt: future},
+			expectedCode: validation.ERR_AMOUNT_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Schedule - Symbol in counterparty",
+			payload:      &request.ScheduledTransferPayload{Username: "juan", Amount: 500, Counterparty: "m@ry", ExecuteAt: future},
+			expectedCode: validation.ERR_SANITIZE_USERNAME_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Schedule - Symbol in actor",
+			payload:      &request.ScheduledTransferPayload{Username: "juan", Actor: "p@dro", Amount: 500, Counterparty: "mary", ExecuteAt: future},
+			expectedCode: validation.ERR_SANITIZE_USERNAME_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Schedule - Invalid currency",
+			payload:      &request.ScheduledTransferPayload{Username: "juan", Currency: "XYZ", Amount: 500, Counterparty: "mary", ExecuteAt: future},
+			expectedCode: validation.ERR_CURRENCY_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockScheduledTransferStore{}
+			mock.initializeMockScheduled()
+			s := &ScheduledTransferService{store: mock, config: &model.ScheduledTransferConfig{RetryDelay: time.Minute, MaxAttempts: 3}}
+
+			actual, err := s.DoCreateScheduledTransfer(context.Background(), nil, test.payload)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.ScheduledPending {
+				t.Errorf("expected status %s but got %s instead", model.ScheduledPending, actual.Status)
+			}
+
+			if actual.Counterparty != "MARY" {
+				t.Errorf("expected counterparty MARY but got %s instead", actual.Counterparty)
+			}
+
+			if test.expectedActor != "" && actual.Actor != test.expectedActor {
+				t.Errorf("expected actor %s but got %s instead", test.expectedActor, actual.Actor)
+			}
+
+			if !actual.NextAttemptAt.Equal(actual.ExecuteAt) {
+				t.Errorf("expected first attempt at %s but got %s instead", actual.ExecuteAt, actual.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestDoCancelScheduledTransfer(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name         string
+		id           int64
+		expectedCode validation.WalletErrorCode
+		expectErr    bool
+	}
+
+	tests := []testCase{
+		{
+			name:      "Successful Cancel - Pending transfer",
+			id:        1,
+			expectErr: false,
+		},
+		{
+			name:         "Failed Cancel - Already executed",
+			id:           2,
+			expectedCode: validation.ERR_SCHEDULED_TRANSFER_NOT_PENDING,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Cancel - Already cancelled",
+			id:           3,
+			expectedCode: validation.ERR_SCHEDULED_TRANSFER_NOT_PENDING,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Cancel - Not found",
+			id:           99,
+			expectedCode: validation.ERR_SCHEDULED_TRANSFER_NOT_FOUND,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockScheduledTransferStore{}
+			mock.initializeMockScheduled()
+			s := &ScheduledTransferService{store: mock, config: &model.ScheduledTransferConfig{RetryDelay: time.Minute, MaxAttempts: 3}}
+
+			actual, err := s.DoCancelScheduledTransfer(context.Background(), nil, test.id)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.ScheduledCancelled {
+				t.Errorf("expected status %s but got %s instead", model.ScheduledCancelled, actual.Status)
+			}
+
+			if actual.CancelledAt == nil {
+				t.Errorf("expected cancelled timestamp but got nil")
+			}
+		})
+	}
+}
+
+func TestRecordAttempt(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name                string
+		attemptCount        int
+		standingOrderID     *int64
+		appErr              *validation.WalletError
+		expectedStatus      model.ScheduledStatus
+		expectedCount       int
+		expectRetry         bool
+		expectedErrorCode   string
+		expectedLastMessage string
+	}
+
+	at := time.Now().UTC()
+	insufficient := &validation.WalletError{
+		Code:    validation.ERR_INSUFFICIENT_WALLET_BALANCE,
+		Message: "Insufficient balance",
+		Err:     fmt.Errorf("balance 100 is less than 500"),
+	}
+
+	tests := []testCase{
+		{
+			name:           "Successful Attempt - Marks transfer succeeded",
+			expectedStatus: model.ScheduledSucceeded,
+			expectedCount:  1,
+		},
+		{
+			name:                "Failed Attempt - Retries while attempts remain",
+			attemptCount:        1,
+			appErr:              insufficient,
+			expectedStatus:      model.ScheduledPending,
+			expectedCount:       2,
+			expectRetry:         true,
+			expectedErrorCode:   string(validation.ERR_INSUFFICIENT_WALLET_BALANCE),
+			expectedLastMessage: "Insufficient balance: balance 100 is less than 500",
+		},
+		{
+			name:                "Failed Attempt - Last attempt marks transfer failed",
+			attemptCount:        2,
+			appErr:              insufficient,
+			expectedStatus:      model.ScheduledFailed,
+			expectedCount:       3,
+			expectedErrorCode:   string(validation.ERR_INSUFFICIENT_WALLET_BALANCE),
+			expectedLastMessage: "Insufficient balance: balance 100 is less than 500",
+		},
+		{
+			name:                "Failed Attempt - Message without underlying error",
+			appErr:              &validation.WalletError{Code: validation.ERR_WALLET_DOES_NOT_EXIST, Message: "No existing wallet found for user"},
+			expectedStatus:      model.ScheduledPending,
+			expectedCount:       1,
+			expectRetry:         true,
+			expectedErrorCode:   string(validation.ERR_WALLET_DOES_NOT_EXIST),
+			expectedLastMessage: "No existing wallet found for user",
+		},
+		{
+			name:                "Failed Attempt - Standing order retries on insufficient balance",
+			standingOrderID:     utils.Ptr(int64(1)),
+			appErr:              insufficient,
+			expectedStatus:      model.ScheduledPending,
+			expectedCount:       1,
+			expectRetry:         true,
+			expectedErrorCode:   string(validation.ERR_INSUFFICIENT_WALLET_BALANCE),
+			expectedLastMessage: "Insufficient balance: balance 100 is less than 500",
+		},
+		{
+			name:                "Failed Attempt - Standing order fails on other errors",
+			standingOrderID:     utils.Ptr(int64(1)),
+			appErr:              &validation.WalletError{Code: validation.ERR_WALLET_DOES_NOT_EXIST, Message: "No existing wallet found for user"},
+			expectedStatus:      model.ScheduledFailed,
+			expectedCount:       1,
+			expectedErrorCode:   string(validation.ERR_WALLET_DOES_NOT_EXIST),
+			expectedLastMessage: "No existing wallet found for user",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &ScheduledTransferService{config: &model.ScheduledTransferConfig{RetryDelay: time.Minute, MaxAttempts: 3}}
+			st := &model.ScheduledTransfer{ID: 1, NextAttemptAt: at, Status: model.ScheduledPending, AttemptCount: test.attemptCount, StandingOrderID: test.standingOrderID}
+
+			var txn *model.Transaction
+			if test.appErr == nil {
+				txn = &model.Transaction{ID: 42}
+			}
+
+			attempt := s.recordAttempt(st, txn, test.appErr, at)
+
+			if test.expectedStatus != st.Status {
+				t.Errorf("expected status %s but got %s instead", test.expectedStatus, st.Status)
+			}
+
+			if test.expectedCount != st.AttemptCount {
+				t.Errorf("expected attempt count %d but got %d instead", test.expectedCount, st.AttemptCount)
+			}
+
+			if test.expectRetry && !st.NextAttemptAt.Equal(at.Add(time.Minute)) {
+				t.Errorf("expected retry at %s but got %s instead", at.Add(time.Minute), st.NextAttemptAt)
+			}
+
+			if test.appErr == nil {
+				if !attempt.Succeeded || attempt.TransactionID == nil || *attempt.TransactionID != 42 {
+					t.Errorf("expected successful attempt with transaction 42 but got %+v", attempt)
+				}
+				if st.ExecutedAt == nil {
+					t.Errorf("expected executed timestamp but got nil")
+				}
+				return
+			}
+
+			if attempt.Succeeded {
+				t.Errorf("expected failed attempt but got success")
+			}
+
+			if attempt.ErrorCode == nil || *attempt.ErrorCode != test.expectedErrorCode {
+				t.Errorf("expected error code %s but got %v instead", test.expectedErrorCode, attempt.ErrorCode)
+			}
+
+			if st.LastError == nil || *st.LastError != test.expectedLastMessage {
+				t.Errorf("expected last error %q but got %v instead", test.expectedLastMessage, st.LastError)
+			}
+		})
+	}
+}
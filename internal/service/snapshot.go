@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	asOfDateLayout        = "2006-01-02"
+	balanceSnapshotSettle = 5 * time.Minute
+)
+
+type SnapshotStore interface {
+	FetchBalancesAsOf(ctx context.Context, username string, currency string, pocket string, asOf time.Time) ([]model.HistoricalBalance, error)
+	FetchTransactionLogStart(ctx context.Context) (*time.Time, error)
+	InsertBalanceSnapshots(ctx context.Context, snapshotAt time.Time) (int64, error)
+}
+
+type SnapshotService struct {
+	store SnapshotStore
+}
+
+func NewSnapshotService(store SnapshotStore) *SnapshotService {
+	logger.Info("Initializing SnapshotService")
+	return &SnapshotService{store: store}
+}
+
+func (s *SnapshotService) DoFetchBalanceAsOf(ctx context.Context, username string, currencyCode string, pocketName string, asOf string) (*model.HistoricalBalance, *validation.WalletError) {
+	fnName := "SnapshotService.DoFetchBalanceAsOf"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode), zap.String("pocket", pocketName), zap.String("asOf", asOf))
+
+	username, err := validation.SanitizeAndValidateUsername(username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", currencyCode),
+			},
+		}
+	}
+
+	pocket, err := validation.SanitizeAndValidatePocket(pocketName)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", pocketName),
+			},
+		}
+	}
+
+	_, balances, appErr := s.fetchBalancesAsOf(ctx, username, currency.Code, pocket, asOf)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if len(balances) == 0 {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "User does not have an existing wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+
+	balance := balances[0]
+	if !balance.Existed() {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   fmt.Sprintf("Wallet has no transactions at or before %s", balance.AsOf.Format(time.RFC3339)),
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.Time("asOf", balance.AsOf),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Balance rebuilt", fnName), zap.Any("balance", balance))
+	return &balance, nil
+}
+
+func (s *SnapshotService) DoFetchAllBalancesAsOf(ctx context.Context, asOf string) (time.Time, []model.HistoricalBalance, *validation.WalletError) {
+	fnName := "SnapshotService.DoFetchAllBalancesAsOf"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("asOf", asOf))
+
+	parsed, balances, appErr := s.fetchBalancesAsOf(ctx, "", "", "", asOf)
+	if appErr != nil {
+		return time.Time{}, nil, appErr
+	}
+
+	existed := []model.HistoricalBalance{}
+	for _, balance := range balances {
+		if balance.Existed() {
+			existed = append(existed, balance)
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Balances rebuilt", fnName), zap.Int("count", len(existed)))
+	return parsed, existed, nil
+}
+
+func (s *SnapshotService) DoTakeSnapshots(ctx context.Context) (*model.BalanceSnapshotRun, *validation.WalletError) {
+	fnName := "SnapshotService.DoTakeSnapshots"
+
+	snapshotAt := truncateToDay(time.Now().UTC().Add(-balanceSnapshotSettle))
+	logger.Info(fmt.Sprintf("%s - Taking balance snapshots", fnName), zap.Time("snapshotAt", snapshotAt))
+
+	count, err := s.store.InsertBalanceSnapshots(ctx, snapshotAt)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BALANCE_SNAPSHOT_FAILED,
+			Message:   "Failed to take balance snapshots",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Time("snapshotAt", snapshotAt),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Balance snapshots taken", fnName), zap.Int64("count", count))
+	return &model.BalanceSnapshotRun{SnapshotAt: snapshotAt, Snapshots: count}, nil
+}
+
+func (s *SnapshotService) RunSnapshotJob(ctx context.Context, interval time.Duration) {
+	fnName := "SnapshotService.RunSnapshotJob"
+	logger.Info(fmt.Sprintf("%s - Job started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Job stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoTakeSnapshots(ctx); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Balance snapshot failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func (s *SnapshotService) fetchBalancesAsOf(ctx context.Context, username string, currency string, pocket string, asOf string) (time.Time, []model.HistoricalBalance, *validation.WalletError) {
+	fnName := "SnapshotService.fetchBalancesAsOf"
+
+	now := time.Now().UTC()
+	parsed, err := parseAsOf(asOf, now)
+	if err != nil {
+		return time.Time{}, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AS_OF_INVALID,
+			Message:   "as_of must be an RFC 3339 timestamp or YYYY-MM-DD date that is not in the future",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("asOf", asOf),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - as_of parsed", fnName), zap.Time("asOf", parsed))
+
+	logStart, err := s.store.FetchTransactionLogStart(ctx)
+	if err != nil {
+		return time.Time{}, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_BALANCE_HISTORY_FAILED,
+			Message:   "Failed to fetch start of transaction log",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	if logStart == nil || parsed.Before(*logStart) {
+		return time.Time{}, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_BALANCE_HISTORY_UNAVAILABLE,
+			Message:   transactionLogStartMessage(logStart),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("as_of %s is outside the transaction log", parsed.Format(time.RFC3339)),
+			Context: []zap.Field{
+				zap.Time("asOf", parsed),
+				zap.Timep("logStart", logStart),
+			},
+		}
+	}
+
+	balances, err := s.store.FetchBalancesAsOf(ctx, username, currency, pocket, parsed)
+	if err != nil {
+		return time.Time{}, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_BALANCE_HISTORY_FAILED,
+			Message:   "Failed to rebuild balances from transaction log",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency),
+				zap.String("pocket", pocket),
+				zap.Time("asOf", parsed),
+			},
+		}
+	}
+
+	for _, balance := range balances {
+		if !balance.Reconciled() {
+			return time.Time{}, nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_BALANCE_HISTORY_UNAVAILABLE,
+				Message:   fmt.Sprintf("Transaction log for %s %s %s does not match its current balance, history cannot be rebuilt", balance.Username, balance.Currency, balance.Pocket),
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("wallet balance %d differs from log balance %d", balance.WalletBalance, balance.LogBalance),
+				Context: []zap.Field{
+					zap.String("username", balance.Username),
+					zap.String("currency", balance.Currency),
+					zap.String("pocket", balance.Pocket),
+					zap.Int64("walletBalance", balance.WalletBalance),
+					zap.Int64("logBalance", balance.LogBalance),
+				},
+			}
+		}
+	}
+	return parsed, balances, nil
+}
+
+func parseAsOf(raw string, now time.Time) (time.Time, error) {
+	asOf, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		asOf, err = time.Parse(asOfDateLayout, raw)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	asOf = asOf.UTC()
+
+	if asOf.After(now) {
+		return time.Time{}, fmt.Errorf("as_of %s is in the future", asOf.Format(time.RFC3339))
+	}
+	return asOf, nil
+}
+
+func transactionLogStartMessage(logStart *time.Time) string {
+	if logStart == nil {
+		return "Transaction log is empty, no balance history is available"
+	}
+	return fmt.Sprintf("as_of is before the transaction log starts at %s", logStart.Format(time.RFC3339))
+}
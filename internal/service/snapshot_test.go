@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockSnapshotStore struct {
+	logStart *time.Time
+	balances []model.HistoricalBalance
+}
+
+func (m *mockSnapshotStore) initializeMockData() {
+	snapshotAt := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+	m.logStart = utils.Ptr(time.Date(2025, time.April, 1, 9, 0, 0, 0, time.UTC))
+	m.balances = []model.HistoricalBalance{
+		{Username: "JUAN", Currency: "USD", Pocket: model.DefaultPocket, Balance: 1200, SnapshotAt: &snapshotAt, Replayed: 2, WalletBalance: 1500, LogBalance: 1500},
+		{Username: "MARY", Currency: "USD", Pocket: model.DefaultPocket, Balance: 0, Replayed: 0, WalletBalance: 300, LogBalance: 300},
+		{Username: "PEDRO", Currency: "EUR", Pocket: model.DefaultPocket, Balance: 50, Replayed: 1, WalletBalance: 900, LogBalance: 900},
+		{Username: "PEDRO", Currency: "EUR", Pocket: "TRAVEL", Balance: 20, Replayed: 1, WalletBalance: 20, LogBalance: 20},
+	}
+}
+
+func (m *mockSnapshotStore) FetchBalancesAsOf(ctx context.Context, username string, currency string, pocket string, asOf time.Time) ([]model.HistoricalBalance, error) {
+	balances := []model.HistoricalBalance{}
+	for _, balance := range m.balances {
+		if (username == "" || balance.Username == username) && (currency == "" || balance.Currency == currency) && (pocket == "" || balance.Pocket == pocket) {
+			balance.AsOf = asOf
+			balances = append(balances, balance)
+		}
+	}
+	return balances, nil
+}
+
+func (m *mockSnapshotStore) FetchTransactionLogStart(ctx context.Context) (*time.Time, error) {
+	return m.logStart, nil
+}
+
+func (m *mockSnapshotStore) InsertBalanceSnapshots(ctx context.Context, snapshotAt time.Time) (int64, error) {
+	return int64(len(m.balances)), nil
+}
+
+func TestParseAsOf(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 12, 30, 0, 0, time.UTC)
+
+	type testCase struct {
+		name         string
+		asOf         string
+		expectedAsOf time.Time
+		expectErr    bool
+	}
+
+	tests := []testCase{
+		{
+			name:         "Successful As Of - RFC 3339 timestamp",
+			asOf:         "2025-05-10T08:15:00Z",
+			expectedAsOf: time.Date(2025, time.May, 10, 8, 15, 0, 0, time.UTC),
+			expectErr:    false,
+		},
+		{
+			name:         "Successful As Of - Offset converted to UTC",
+			asOf:         "2025-05-10T16:15:00+08:00",
+			expectedAsOf: time.Date(2025, time.May, 10, 8, 15, 0, 0, time.UTC),
+			expectErr:    false,
+		},
+		{
+			name:         "Successful As Of - Date is start of day",
+			asOf:         "2025-05-10",
+			expectedAsOf: time.Date(2025, time.May, 10, 0, 0, 0, 0, time.UTC),
+			expectErr:    false,
+		},
+		{
+			name:      "Failed As Of - In the future",
+			asOf:      "2025-06-16",
+			expectErr: true,
+		},
+		{
+			name:      "Failed As Of - Invalid format",
+			asOf:      "10/05/2025",
+			expectErr: true,
+		},
+		{
+			name:      "Failed As Of - Empty",
+			asOf:      "",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			asOf, err := parseAsOf(test.asOf, now)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !test.expectErr && !asOf.Equal(test.expectedAsOf) {
+				t.Errorf("expected as of %s but got %s instead", test.expectedAsOf, asOf)
+			}
+		})
+	}
+}
+
+func TestDoFetchBalanceAsOf(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name            string
+		username        string
+		currency        string
+		pocket          string
+		asOf            string
+		emptyLog        bool
+		unreconciled    bool
+		expectedBalance int64
+		expectedCode    validation.WalletErrorCode
+		expectErr       bool
+	}
+
+	tests := []testCase{
+		{
+			name:            "Successful As Of - Snapshot plus replay",
+			username:        "juan",
+			currency:        "usd",
+			asOf:            "2025-05-10",
+			expectedBalance: 1200,
+			expectErr:       false,
+		},
+		{
+			name:            "Successful As Of - Replay without snapshot",
+			username:        "PEDRO",
+			currency:        "EUR",
+			asOf:            "2025-05-10T08:00:00Z",
+			expectedBalance: 50,
+			expectErr:       false,
+		},
+		{
+			name:            "Successful As Of - Named pocket",
+			username:        "PEDRO",
+			currency:        "EUR",
+			pocket:          "travel",
+			asOf:            "2025-05-10",
+			expectedBalance: 20,
+			expectErr:       false,
+		},
+		{
+			name:         "Failed As Of - Pocket not found",
+			username:     "PEDRO",
+			currency:     "EUR",
+			pocket:       "BILLS",
+			asOf:         "2025-05-10",
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed As Of - Wallet did not exist yet",
+			username:     "MARY",
+			currency:     "USD",
+			asOf:         "2025-05-10",
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed As Of - Wallet not found",
+			username:     "JUAN",
+			currency:     "EUR",
+			asOf:         "2025-05-10",
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed As Of - Before transaction log starts",
+			username:     "JUAN",
+			currency:     "USD",
+			asOf:         "2025-03-31",
+			expectedCode: validation.ERR_BALANCE_HISTORY_UNAVAILABLE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed As Of - Transaction log empty",
+			username:     "JUAN",
+			currency:     "USD",
+			asOf:         "2025-05-10",
+			emptyLog:     true,
+			expectedCode: validation.ERR_BALANCE_HISTORY_UNAVAILABLE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed As Of - Log does not match wallet balance",
+			username:     "JUAN",
+			currency:     "USD",
+			asOf:         "2025-05-10",
+			unreconciled: true,
+			expectedCode: validation.ERR_BALANCE_HISTORY_UNAVAILABLE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed As Of - Invalid timestamp",
+			username:     "JUAN",
+			currency:     "USD",
+			asOf:         "yesterday",
+			expectedCode: validation.ERR_AS_OF_INVALID,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockSnapshotStore{}
+			mock.initializeMockData()
+			if test.emptyLog {
+				mock.logStart = nil
+			}
+			if test.unreconciled {
+				mock.balances[0].LogBalance = 1400
+			}
+			s := &SnapshotService{store: mock}
+
+			balance, err := s.DoFetchBalanceAsOf(context.Background(), test.username, test.currency, test.pocket, test.asOf)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && balance != nil && balance.Balance != test.expectedBalance {
+				t.Errorf("expected balance %d but got %d instead", test.expectedBalance, balance.Balance)
+			}
+		})
+	}
+}
+
+func TestDoFetchAllBalancesAsOf(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	mock := &mockSnapshotStore{}
+	mock.initializeMockData()
+	s := &SnapshotService{store: mock}
+
+	asOf, balances, err := s.DoFetchAllBalancesAsOf(context.Background(), "2025-05-10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedAsOf := time.Date(2025, time.May, 10, 0, 0, 0, 0, time.UTC)
+	if !asOf.Equal(expectedAsOf) {
+		t.Errorf("expected as of %s but got %s instead", expectedAsOf, asOf)
+	}
+
+	if len(balances) != 3 {
+		t.Fatalf("expected 3 balances but got %d instead", len(balances))
+	}
+
+	for _, balance := range balances {
+		if balance.Username == "MARY" {
+			t.Errorf("expected wallet without history to be omitted")
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type StandingOrderStore interface {
+	BeginTransaction(ctx context.Context) (*sql.Tx, error)
+	InsertStandingOrder(ctx context.Context, tx *sql.Tx, order *model.StandingOrder) error
+	FetchStandingOrderForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.StandingOrder, error)
+	ClaimDueStandingOrder(ctx context.Context, tx *sql.Tx, at time.Time) (*model.StandingOrder, error)
+	UpdateStandingOrder(ctx context.Context, tx *sql.Tx, order *model.StandingOrder) error
+	FetchStandingOrders(ctx context.Context, criteria *model.StandingOrderCriteria) ([]model.StandingOrder, error)
+	InsertScheduledTransfer(ctx context.Context, tx *sql.Tx, st *model.ScheduledTransfer) error
+	CancelStandingOrderTransfers(ctx context.Context, tx *sql.Tx, standingOrderID int64, at time.Time) (int64, error)
+}
+
+type StandingOrderService struct {
+	store StandingOrderStore
+}
+
+func NewStandingOrderService(store StandingOrderStore) *StandingOrderService {
+	logger.Info("Initializing StandingOrderService")
+	return &StandingOrderService{store: store}
+}
+
+func (s *StandingOrderService) DoCreateStandingOrder(ctx context.Context, tx *sql.Tx, payload *request.StandingOrderPayload) (*model.StandingOrder, *validation.WalletError) {
+	fnName := "StandingOrderService.DoCreateStandingOrder"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, err := validation.SanitizeAndValidateUsername(payload.Username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize username",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", payload.Username),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	actor := username
+	if payload.Actor != "" {
+		actor, err = validation.SanitizeAndValidateUsername(payload.Actor)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+				Message:   "Failed to sanitize actor",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("actor", payload.Actor),
+				},
+			}
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Actor resolved", fnName), zap.String("actor", actor))
+
+	counterparty, err := validation.SanitizeAndValidateUsername(payload.Counterparty)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize counterparty",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("counterparty", payload.Counterparty),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Counterparty sanitized", fnName), zap.String("counterparty", counterparty))
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	if err := validation.ValidateAmount(payload.Amount, currency); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("amount", payload.Amount),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Amount validated", fnName), zap.Int64("amount", payload.Amount))
+
+	now := time.Now().UTC()
+	startAt := payload.StartAt.UTC()
+	if !startAt.After(now) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SCHEDULE_TIME_INVALID,
+			Message:   "Start time must be in the future",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("startAt %s is not after %s", startAt.Format(time.RFC3339), now.Format(time.RFC3339)),
+		}
+	}
+
+	order := &model.StandingOrder{
+		Username:       username,
+		Actor:          actor,
+		Currency:       currency.Code,
+		Amount:         payload.Amount,
+		Counterparty:   counterparty,
+		Frequency:      model.Frequency(payload.Frequency),
+		DayOfMonth:     payload.DayOfMonth,
+		StartAt:        startAt,
+		MaxOccurrences: payload.MaxOccurrences,
+	}
+	if payload.EndAt != nil {
+		endAt := payload.EndAt.UTC()
+		order.EndAt = &endAt
+	}
+	if order.Frequency == model.FrequencyMonthly && order.DayOfMonth == nil {
+		day := startAt.Day()
+		order.DayOfMonth = &day
+	}
+
+	if err := validateRecurrence(order); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RECURRENCE_INVALID,
+			Message:   "Recurrence rule is invalid",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("standingOrder", order),
+			},
+		}
+	}
+
+	first := occurrenceAt(order, 0)
+	order.NextOccurrenceAt = &first
+	logger.Info(fmt.Sprintf("%s - Recurrence validated", fnName), zap.Time("firstOccurrence", first))
+
+	if err := s.store.InsertStandingOrder(ctx, tx, order); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CREATE_STANDING_ORDER_FAILED,
+			Message:   "Failed to create standing order",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("standingOrder", order),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Standing order created", fnName), zap.Any("standingOrder", order))
+	return order, nil
+}
+
+func (s *StandingOrderService) DoFetchStandingOrders(ctx context.Context, username string, status string, limit string) ([]model.StandingOrder, *model.StandingOrderCriteria, *validation.WalletError) {
+	fnName := "StandingOrderService.DoFetchStandingOrders"
+	queryUsername := validation.SanitizeUsernameWithoutError(username)
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", queryUsername))
+
+	queryStatus := ""
+	if model.IsStandingOrderStatusValid(status) {
+		queryStatus = status
+	}
+	logger.Info(fmt.Sprintf("%s - Status valid", fnName), zap.String("status", queryStatus))
+
+	queryLimit, err := strconv.Atoi(limit)
+	if err != nil {
+		queryLimit = 0
+	}
+	logger.Info(fmt.Sprintf("%s - Limit converted to int", fnName), zap.Int("limit", queryLimit))
+
+	criteria := &model.StandingOrderCriteria{
+		Username: queryUsername,
+		Status:   model.StandingOrderStatus(queryStatus),
+		Limit:    queryLimit,
+	}
+
+	orders, err := s.store.FetchStandingOrders(ctx, criteria)
+	if err != nil {
+		return nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_STANDING_ORDER_FAILED,
+			Message:   "Failed to fetch standing orders",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("criteria", criteria),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Standing orders fetched", fnName), zap.Int("count", len(orders)))
+	return orders, criteria, nil
+}
+
+func (s *StandingOrderService) DoCancelStandingOrder(ctx context.Context, tx *sql.Tx, id int64) (*model.StandingOrder, *validation.WalletError) {
+	fnName := "StandingOrderService.DoCancelStandingOrder"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Int64("id", id))
+
+	order, err := s.store.FetchStandingOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_STANDING_ORDER_FAILED,
+			Message:   "Failed to fetch standing order",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if order == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_STANDING_ORDER_NOT_FOUND,
+			Message:   "Standing order not found",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.Int64("id", id),
+			},
+		}
+	}
+	if order.Status != model.StandingOrderActive {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_STANDING_ORDER_NOT_ACTIVE,
+			Message:   "Only active standing orders can be cancelled",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("standing order %d is %s", order.ID, order.Status),
+		}
+	}
+
+	cancelledAt := time.Now().UTC()
+	order.Status = model.StandingOrderCancelled
+	order.NextOccurrenceAt = nil
+	order.CancelledAt = &cancelledAt
+	if err := s.store.UpdateStandingOrder(ctx, tx, order); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_STANDING_ORDER_FAILED,
+			Message:   "Failed to cancel standing order",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("standingOrder", order),
+			},
+		}
+	}
+
+	cancelled, err := s.store.CancelStandingOrderTransfers(ctx, tx, order.ID, cancelledAt)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_UPDATE_SCHEDULED_TRANSFER_FAILED,
+			Message:   "Failed to cancel pending occurrences",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("standingOrderID", order.ID),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Standing order cancelled", fnName), zap.Any("standingOrder", order), zap.Int64("cancelledOccurrences", cancelled))
+	return order, nil
+}
+
+func (s *StandingOrderService) DoMaterializeDue(ctx context.Context) (int, *validation.WalletError) {
+	fnName := "StandingOrderService.DoMaterializeDue"
+	materialized := 0
+	for {
+		ok, appErr := s.materializeNext(ctx)
+		if appErr != nil {
+			return materialized, appErr
+		}
+		if !ok {
+			break
+		}
+		materialized++
+	}
+	logger.Info(fmt.Sprintf("%s - Due occurrences materialized", fnName), zap.Int("materialized", materialized))
+	return materialized, nil
+}
+
+func (s *StandingOrderService) RunMaterializer(ctx context.Context, interval time.Duration) {
+	fnName := "StandingOrderService.RunMaterializer"
+	logger.Info(fmt.Sprintf("%s - Materializer started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Materializer stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoMaterializeDue(ctx); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Standing order run failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func (s *StandingOrderService) materializeNext(ctx context.Context) (bool, *validation.WalletError) {
+	fnName := "StandingOrderService.materializeNext"
+	failed := func(message string, err error) *validation.WalletError {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_MATERIALIZE_STANDING_ORDER_FAILED,
+			Message:   message,
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+
+	tx, err := s.store.BeginTransaction(ctx)
+	if err != nil {
+		return false, failed("Failed to start transaction", err)
+	}
+	defer tx.Rollback()
+
+	order, err := s.store.ClaimDueStandingOrder(ctx, tx, time.Now().UTC())
+	if err != nil {
+		return false, failed("Failed to claim due standing order", err)
+	}
+	if order == nil {
+		return false, nil
+	}
+	logger.Info(fmt.Sprintf("%s - Standing order claimed", fnName), zap.Any("standingOrder", order))
+
+	occurrence := order.OccurrenceCount
+	st := &model.ScheduledTransfer{
+		Username:        order.Username,
+		Actor:           order.Actor,
+		Currency:        order.Currency,
+		Amount:          order.Amount,
+		Counterparty:    order.Counterparty,
+		ExecuteAt:       *order.NextOccurrenceAt,
+		StandingOrderID: &order.ID,
+		Occurrence:      &occurrence,
+	}
+	if err := s.store.InsertScheduledTransfer(ctx, tx, st); err != nil {
+		return false, failed("Failed to insert occurrence", err)
+	}
+
+	advanceStandingOrder(order)
+	if err := s.store.UpdateStandingOrder(ctx, tx, order); err != nil {
+		return false, failed("Failed to advance standing order", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, failed("Failed to commit occurrence", err)
+	}
+	logger.Info(fmt.Sprintf("%s - Occurrence materialized", fnName), zap.Any("scheduledTransfer", st), zap.Any("standingOrder", order))
+	return true, nil
+}
+
+func validateRecurrence(order *model.StandingOrder) error {
+	if !model.IsFrequencyValid(string(order.Frequency)) {
+		return fmt.Errorf("unknown frequency %q", order.Frequency)
+	}
+	if order.Frequency != model.FrequencyMonthly && order.DayOfMonth != nil {
+		return fmt.Errorf("dayOfMonth only applies to monthly standing orders")
+	}
+	if order.DayOfMonth != nil && (*order.DayOfMonth < 1 || *order.DayOfMonth > 31) {
+		return fmt.Errorf("dayOfMonth %d is not between 1 and 31", *order.DayOfMonth)
+	}
+	if order.MaxOccurrences != nil && *order.MaxOccurrences < 1 {
+		return fmt.Errorf("maxOccurrences %d must be at least 1", *order.MaxOccurrences)
+	}
+	if !occurrenceWithinEnd(order, 0) {
+		return fmt.Errorf("first occurrence %s is after endAt", occurrenceAt(order, 0).Format(time.RFC3339))
+	}
+	return nil
+}
+
+func advanceStandingOrder(order *model.StandingOrder) {
+	order.OccurrenceCount++
+	if !occurrenceWithinEnd(order, order.OccurrenceCount) {
+		order.Status = model.StandingOrderCompleted
+		order.NextOccurrenceAt = nil
+		return
+	}
+	next := occurrenceAt(order, order.OccurrenceCount)
+	order.NextOccurrenceAt = &next
+}
+
+func occurrenceWithinEnd(order *model.StandingOrder, n int) bool {
+	if order.MaxOccurrences != nil && n >= *order.MaxOccurrences {
+		return false
+	}
+	if order.EndAt != nil && occurrenceAt(order, n).After(*order.EndAt) {
+		return false
+	}
+	return true
+}
+
+func occurrenceAt(order *model.StandingOrder, n int) time.Time {
+	switch order.Frequency {
+	case model.FrequencyDaily:
+		return order.StartAt.AddDate(0, 0, n)
+	case model.FrequencyWeekly:
+		return order.StartAt.AddDate(0, 0, 7*n)
+	default:
+		offset := 0
+		if monthlyOccurrence(order.StartAt, *order.DayOfMonth, 0).Before(order.StartAt) {
+			offset = 1
+		}
+		return monthlyOccurrence(order.StartAt, *order.DayOfMonth, n+offset)
+	}
+}
+
+func monthlyOccurrence(start time.Time, day int, months int) time.Time {
+	year, month, _ := start.Date()
+	first := time.Date(year, month+time.Month(months), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
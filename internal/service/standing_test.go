@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockStandingOrderStore struct {
+	orders    map[int64]*model.StandingOrder
+	cancelled map[int64]bool
+	nextID    int64
+}
+
+func (m *mockStandingOrderStore) initializeMockOrders() {
+	next := time.Now().UTC().Add(time.Hour)
+	m.orders = map[int64]*model.StandingOrder{
+		1: {ID: 1, Username: "JUAN", Currency: "USD", Amount: 500, Counterparty: "MARY", Frequency: model.FrequencyDaily, StartAt: next, NextOccurrenceAt: &next, Status: model.StandingOrderActive},
+		2: {ID: 2, Username: "JUAN", Currency: "USD", Amount: 500, Counterparty: "MARY", Frequency: model.FrequencyDaily, StartAt: next, Status: model.StandingOrderCompleted},
+	}
+	m.cancelled = map[int64]bool{}
+	m.nextID = 3
+}
+
+func (m *mockStandingOrderStore) BeginTransaction(ctx context.Context) (*sql.Tx, error) {
+	return nil, fmt.Errorf("not supported by mock")
+}
+
+func (m *mockStandingOrderStore) InsertStandingOrder(ctx context.Context, tx *sql.Tx, order *model.StandingOrder) error {
+	order.ID = m.nextID
+	order.Status = model.StandingOrderActive
+	order.CreatedAt = time.Now().UTC()
+	m.orders[order.ID] = order
+	m.nextID++
+	return nil
+}
+
+func (m *mockStandingOrderStore) FetchStandingOrderForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*model.StandingOrder, error) {
+	order, ok := m.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (m *mockStandingOrderStore) ClaimDueStandingOrder(ctx context.Context, tx *sql.Tx, at time.Time) (*model.StandingOrder, error) {
+	return nil, nil
+}
+
+func (m *mockStandingOrderStore) UpdateStandingOrder(ctx context.Context, tx *sql.Tx, order *model.StandingOrder) error {
+	if m.orders[order.ID].Status != model.StandingOrderActive {
+		return fmt.Errorf("standing order %d is no longer active", order.ID)
+	}
+	m.orders[order.ID] = order
+	return nil
+}
+
+func (m *mockStandingOrderStore) FetchStandingOrders(ctx context.Context, criteria *model.StandingOrderCriteria) ([]model.StandingOrder, error) {
+	return []model.StandingOrder{}, nil
+}
+
+func (m *mockStandingOrderStore) InsertScheduledTransfer(ctx context.Context, tx *sql.Tx, st *model.ScheduledTransfer) error {
+	return nil
+}
+
+func (m *mockStandingOrderStore) CancelStandingOrderTransfers(ctx context.Context, tx *sql.Tx, standingOrderID int64, at time.Time) (int64, error) {
+	m.cancelled[standingOrderID] = true
+	return 1, nil
+}
+
+func TestOccurrenceAt(t *testing.T) {
+	type testCase struct {
+		name     string
+		order    *model.StandingOrder
+		expected []string
+	}
+
+	at := func(value string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return parsed
+	}
+
+	tests := []testCase{
+		{
+			name:     "Daily - Every day from start",
+			order:    &model.StandingOrder{Frequency: model.FrequencyDaily, StartAt: at("2025-02-27T09:00:00Z")},
+			expected: []string{"2025-02-27T09:00:00Z", "2025-02-28T09:00:00Z", "2025-03-01T09:00:00Z"},
+		},
+		{
+			name:     "Weekly - Same weekday from start",
+			order:    &model.StandingOrder{Frequency: model.FrequencyWeekly, StartAt: at("2025-06-02T09:00:00Z")},
+			expected: []string{"2025-06-02T09:00:00Z", "2025-06-09T09:00:00Z", "2025-06-16T09:00:00Z"},
+		},
+		{
+			name:     "Monthly - First of the month",
+			order:    &model.StandingOrder{Frequency: model.FrequencyMonthly, DayOfMonth: utils.Ptr(1), StartAt: at("2025-07-01T00:00:00Z")},
+			expected: []string{"2025-07-01T00:00:00Z", "2025-08-01T00:00:00Z", "2025-09-01T00:00:00Z"},
+		},
+		{
+			name:     "Monthly - Day 31 clamps to month end without drifting",
+			order:    &model.StandingOrder{Frequency: model.FrequencyMonthly, DayOfMonth: utils.Ptr(31), StartAt: at("2025-01-31T12:00:00Z")},
+			expected: []string{"2025-01-31T12:00:00Z", "2025-02-28T12:00:00Z", "2025-03-31T12:00:00Z", "2025-04-30T12:00:00Z"},
+		},
+		{
+			name:     "Monthly - Day 29 in a leap year",
+			order:    &model.StandingOrder{Frequency: model.FrequencyMonthly, DayOfMonth: utils.Ptr(29), StartAt: at("2024-01-29T08:00:00Z")},
+			expected: []string{"2024-01-29T08:00:00Z", "2024-02-29T08:00:00Z", "2024-03-29T08:00:00Z"},
+		},
+		{
+			name:     "Monthly - Day already passed in start month begins next month",
+			order:    &model.StandingOrder{Frequency: model.FrequencyMonthly, DayOfMonth: utils.Ptr(1), StartAt: at("2025-06-15T09:00:00Z")},
+			expected: []string{"2025-07-01T09:00:00Z", "2025-08-01T09:00:00Z"},
+		},
+		{
+			name:     "Monthly - Day later in start month",
+			order:    &model.StandingOrder{Frequency: model.FrequencyMonthly, DayOfMonth: utils.Ptr(20), StartAt: at("2025-12-15T09:00:00Z")},
+			expected: []string{"2025-12-20T09:00:00Z", "2026-01-20T09:00:00Z"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for n, expected := range test.expected {
+				actual := occurrenceAt(test.order, n)
+				if !actual.Equal(at(expected)) {
+					t.Errorf("occurrence %d: expected %s but got %s instead", n, expected, actual.Format(time.RFC3339))
+				}
+			}
+		})
+	}
+}
+
+func TestAdvanceStandingOrder(t *testing.T) {
+	type testCase struct {
+		name           string
+		order          *model.StandingOrder
+		expectedStatus model.StandingOrderStatus
+		expectedNext   *time.Time
+	}
+
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []testCase{
+		{
+			name:           "Open ended - Moves to next occurrence",
+			order:          &model.StandingOrder{Frequency: model.FrequencyDaily, StartAt: start, OccurrenceCount: 0, Status: model.StandingOrderActive},
+			expectedStatus: model.StandingOrderActive,
+			expectedNext:   utils.Ptr(start.AddDate(0, 0, 1)),
+		},
+		{
+			name:           "Count - Completes after last occurrence",
+			order:          &model.StandingOrder{Frequency: model.FrequencyDaily, StartAt: start, OccurrenceCount: 2, MaxOccurrences: utils.Ptr(3), Status: model.StandingOrderActive},
+			expectedStatus: model.StandingOrderCompleted,
+		},
+		{
+			name:           "End date - Occurrence on end date still runs",
+			order:          &model.StandingOrder{Frequency: model.FrequencyWeekly, StartAt: start, OccurrenceCount: 0, EndAt: utils.Ptr(start.AddDate(0, 0, 7)), Status: model.StandingOrderActive},
+			expectedStatus: model.StandingOrderActive,
+			expectedNext:   utils.Ptr(start.AddDate(0, 0, 7)),
+		},
+		{
+			name:           "End date - Completes when next occurrence is after end",
+			order:          &model.StandingOrder{Frequency: model.FrequencyWeekly, StartAt: start, OccurrenceCount: 1, EndAt: utils.Ptr(start.AddDate(0, 0, 10)), Status: model.StandingOrderActive},
+			expectedStatus: model.StandingOrderCompleted,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			count := test.order.OccurrenceCount
+			advanceStandingOrder(test.order)
+
+			if test.order.OccurrenceCount != count+1 {
+				t.Errorf("expected occurrence count %d but got %d instead", count+1, test.order.OccurrenceCount)
+			}
+
+			if test.expectedStatus != test.order.Status {
+				t.Errorf("expected status %s but got %s instead", test.expectedStatus, test.order.Status)
+			}
+
+			if test.expectedNext == nil {
+				if test.order.NextOccurrenceAt != nil {
+					t.Errorf("expected no next occurrence but got %s", test.order.NextOccurrenceAt)
+				}
+				return
+			}
+
+			if test.order.NextOccurrenceAt == nil || !test.order.NextOccurrenceAt.Equal(*test.expectedNext) {
+				t.Errorf("expected next occurrence %s but got %v instead", test.expectedNext, test.order.NextOccurrenceAt)
+			}
+		})
+	}
+}
+
+func TestDoCreateStandingOrder(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name               string
+		payload            *request.StandingOrderPayload
+		expectedActor      string
+		expectedDayOfMonth *int
+		expectedCode       validation.WalletErrorCode
+		expectErr          bool
+	}
+
+	start := time.Now().UTC().Add(24 * time.Hour)
+
+	tests := []testCase{
+		{
+			name:          "Successful Standing Order - Daily open ended",
+			payload:       &request.StandingOrderPayload{Username: "juan", Amount: 500, Counterparty: "mary", Frequency: "daily", StartAt: start},
+			expectedActor: "JUAN",
+			expectErr:     false,
+		},
+		{
+			name:          "Successful Standing Order - Joint wallet keeps acting member",
+			payload:       &request.StandingOrderPayload{Username: "j_family", Actor: "pedro", Amount: 500, Counterparty: "mary", Frequency: "weekly", StartAt: start},
+			expectedActor: "PEDRO",
+			expectErr:     false,
+		},
+		{
+			name:               "Successful Standing Order - Monthly defaults day of month to start",
+			payload:            &request.StandingOrderPayload{Username: "juan", Amount: 500, Counterparty: "mary", Frequency: "monthly", StartAt: start, MaxOccurrences: utils.Ptr(12)},
+			expectedDayOfMonth: utils.Ptr(start.Day()),
+			expectErr:          false,
+		},
+		{
+			name:         "Failed Standing Order - Symbol in actor",
+			payload:      &request.StandingOrderPayload{Username: "juan", Actor: "p@dro", Amount: 500, Counterparty: "mary", Frequency: "daily", StartAt: start},
+			expectedCode: validation.ERR_SANITIZE_USERNAME_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Standing Order - Unknown frequency",
+			payload:      &request.StandingOrderPayload{Username: "juan", Amount: 500, Counterparty: "mary", Frequency: "yearly", StartAt: start},
+			expectedCode: validation.ERR_RECURRENCE_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Standing Order - Day of month on weekly order",
+			payload:      &request.StandingOrderPayload{Username: "juan", Amount: 500, Counterparty: "mary", Frequency: "weekly", DayOfMonth: utils.Ptr(1), StartAt: start},
+			expectedCode: validation.ERR_RECURRENCE_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Standing Order - Day of month out of range",
+			payload:      &request.StandingOrderPayload{Username: "juan", Amount: 500, Counterparty: "mary", Frequency: "monthly", DayOfMonth: utils.Ptr(32), StartAt: start},
+			expectedCode: validation.ERR_RECURRENCE_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Standing Order - Zero occurrences",
+			payload:      &request.StandingOrderPayload{Username: "juan", Amount: 500, Counterparty: "mary", Frequency: "daily", StartAt: start, MaxOccurrences: utils.Ptr(0)},
+			expectedCode: validation.ERR_RECURRENCE_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Standing Order - End before first occurrence",
+			payload:      &request.StandingOrderPayload{Username: "juan", Amount: 500, Counterparty: "mary", Frequency: "daily", StartAt: start, EndAt: utils.Ptr(start.Add(-time.Hour))},
+			expectedCode: validation.ERR_RECURRENCE_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Standing Order - Start in the past",
+			payload:      &request.StandingOrderPayload{Username: "juan", Amount: 500, Counterparty: "mary", Frequency: "daily", StartAt: time.Now().Add(-time.Hour)},
+			expectedCode: validation.ERR_SCHEDULE_TIME_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Standing Order - Negative amount",
+			payload:      &request.StandingOrderPayload{Username: "juan", Amount: -500, Counterparty: "mary", Frequency: "daily", StartAt: start},
+			expectedCode: validation.ERR_AMOUNT_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockStandingOrderStore{}
+			mock.initializeMockOrders()
+			s := &StandingOrderService{store: mock}
+
+			actual, err := s.DoCreateStandingOrder(context.Background(), nil, test.payload)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.StandingOrderActive {
+				t.Errorf("expected status %s but got %s instead", model.StandingOrderActive, actual.Status)
+			}
+
+			if actual.NextOccurrenceAt == nil || actual.NextOccurrenceAt.Before(actual.StartAt) {
+				t.Errorf("expected first occurrence on or after %s but got %v instead", actual.StartAt, actual.NextOccurrenceAt)
+			}
+
+			if test.expectedActor != "" && actual.Actor != test.expectedActor {
+				t.Errorf("expected actor %s but got %s instead", test.expectedActor, actual.Actor)
+			}
+
+			if test.expectedDayOfMonth != nil && (actual.DayOfMonth == nil || *actual.DayOfMonth != *test.expectedDayOfMonth) {
+				t.Errorf("expected day of month %d but got %v instead", *test.expectedDayOfMonth, actual.DayOfMonth)
+			}
+		})
+	}
+}
+
+func TestDoCancelStandingOrder(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name         string
+		id           int64
+		expectedCode validation.WalletErrorCode
+		expectErr    bool
+	}
+
+	tests := []testCase{
+		{
+			name:      "Successful Cancel - Active standing order",
+			id:        1,
+			expectErr: false,
+		},
+		{
+			name:         "Failed Cancel - Already completed",
+			id:           2,
+			expectedCode: validation.ERR_STANDING_ORDER_NOT_ACTIVE,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Cancel - Not found",
+			id:           99,
+			expectedCode: validation.ERR_STANDING_ORDER_NOT_FOUND,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockStandingOrderStore{}
+			mock.initializeMockOrders()
+			s := &StandingOrderService{store: mock}
+
+			actual, err := s.DoCancelStandingOrder(context.Background(), nil, test.id)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectErr {
+				if test.expectedCode != err.Code {
+					t.Errorf("expected error code %s but got %s instead", test.expectedCode, err.Code)
+				}
+				return
+			}
+
+			if actual.Status != model.StandingOrderCancelled || actual.NextOccurrenceAt != nil {
+				t.Errorf("expected cancelled order without next occurrence but got %s / %v", actual.Status, actual.NextOccurrenceAt)
+			}
+
+			if !mock.cancelled[test.id] {
+				t.Errorf("expected pending occurrences to be cancelled")
+			}
+		})
+	}
+}
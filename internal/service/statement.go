@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	statementDateLayout   = "2006-01-02"
+	statementPeriodLayout = "2006-01"
+)
+
+type StatementStore interface {
+	BeginTransaction(ctx context.Context) (*sql.Tx, error)
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	FetchStatementActivity(ctx context.Context, username string, currency string, from time.Time, to time.Time) (int64, []model.StatementLine, error)
+	FetchStatementAccounts(ctx context.Context, before time.Time) ([]model.StatementAccount, error)
+	InsertStatement(ctx context.Context, tx *sql.Tx, statement *model.Statement) (bool, error)
+	InsertStatementLine(ctx context.Context, tx *sql.Tx, statementID int64, line *model.StatementLine) error
+	FetchStatement(ctx context.Context, username string, currency string, period time.Time) (*model.Statement, error)
+}
+
+type StatementService struct {
+	store StatementStore
+}
+
+func NewStatementService(store StatementStore) *StatementService {
+	logger.Info("Initializing StatementService")
+	return &StatementService{store: store}
+}
+
+func (s *StatementService) DoFetchStatement(ctx context.Context, username string, currencyCode string, from string, to string, period string) (*model.Statement, *validation.WalletError) {
+	fnName := "StatementService.DoFetchStatement"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode), zap.String("from", from), zap.String("to", to), zap.String("period", period))
+
+	username, err := validation.SanitizeAndValidateUsername(username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", currencyCode),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	wallet, err := s.store.FetchWallet(ctx, username, currency.Code, model.DefaultPocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_FAILED,
+			Message:   "Error while fetching wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if wallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_DOES_NOT_EXIST,
+			Message:   "User does not have an existing wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+
+	if period != "" {
+		return s.fetchStoredStatement(ctx, username, currency.Code, period)
+	}
+
+	start, end, err := parseStatementRange(from, to, time.Now().UTC())
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_STATEMENT_PERIOD_INVALID,
+			Message:   "Statement dates must be formatted as YYYY-MM-DD with from not after to",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("from", from),
+				zap.String("to", to),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Statement range parsed", fnName), zap.Time("from", start), zap.Time("to", end))
+
+	opening, lines, err := s.store.FetchStatementActivity(ctx, username, currency.Code, start, end)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_STATEMENT_FAILED,
+			Message:   "Failed to fetch statement activity",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.Time("from", start),
+				zap.Time("to", end),
+			},
+		}
+	}
+
+	statement := buildStatement(username, currency.Code, start, end, opening, lines)
+	logger.Info(fmt.Sprintf("%s - Statement built", fnName), zap.Int64("opening", statement.OpeningBalance), zap.Int64("closing", statement.ClosingBalance), zap.Int("lines", len(statement.Lines)))
+	return statement, nil
+}
+
+func (s *StatementService) DoGenerateStatements(ctx context.Context, period *string) (*model.StatementRun, *validation.WalletError) {
+	fnName := "StatementService.DoGenerateStatements"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("period", period))
+
+	now := time.Now().UTC()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := currentMonth.AddDate(0, -1, 0)
+	if period != nil {
+		parsed, err := time.Parse(statementPeriodLayout, *period)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_STATEMENT_PERIOD_INVALID,
+				Message:   "Statement period must be formatted as YYYY-MM",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+			}
+		}
+		start = parsed
+	}
+	if !start.Before(currentMonth) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_STATEMENT_PERIOD_INVALID,
+			Message:   "Statements can only be generated for completed months",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("period %s is not before %s", start.Format(statementPeriodLayout), currentMonth.Format(statementPeriodLayout)),
+		}
+	}
+	end := start.AddDate(0, 1, 0)
+
+	accounts, err := s.store.FetchStatementAccounts(ctx, end)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_GENERATE_STATEMENT_FAILED,
+			Message:   "Failed to fetch statement accounts",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Time("before", end),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Statement accounts fetched", fnName), zap.Int("count", len(accounts)))
+
+	run := &model.StatementRun{Period: start}
+	for _, account := range accounts {
+		generated, appErr := s.generateStatement(ctx, account, start, end)
+		if appErr != nil {
+			return run, appErr
+		}
+		if generated {
+			run.Generated++
+		} else {
+			run.Existing++
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Statements generated", fnName), zap.Any("run", run))
+	return run, nil
+}
+
+func (s *StatementService) RunGenerateJob(ctx context.Context, interval time.Duration) {
+	fnName := "StatementService.RunGenerateJob"
+	logger.Info(fmt.Sprintf("%s - Job started", fnName), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("%s - Job stopped", fnName))
+			return
+		case <-ticker.C:
+			if _, appErr := s.DoGenerateStatements(ctx, nil); appErr != nil {
+				logger.Error(fmt.Sprintf("%s - Statement generation failed", fnName), zap.String("code", string(appErr.Code)), zap.Error(appErr.Err))
+			}
+		}
+	}
+}
+
+func (s *StatementService) fetchStoredStatement(ctx context.Context, username string, currency string, period string) (*model.Statement, *validation.WalletError) {
+	fnName := "StatementService.fetchStoredStatement"
+
+	parsed, err := time.Parse(statementPeriodLayout, period)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_STATEMENT_PERIOD_INVALID,
+			Message:   "Statement period must be formatted as YYYY-MM",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+
+	statement, err := s.store.FetchStatement(ctx, username, currency, parsed)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_STATEMENT_FAILED,
+			Message:   "Failed to fetch statement",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency),
+				zap.String("period", period),
+			},
+		}
+	}
+	if statement == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_STATEMENT_NOT_FOUND,
+			Message:   "No statement has been generated for this period",
+			Timestamp: time.Now().UTC(),
+			Err:       nil,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency),
+				zap.String("period", period),
+			},
+		}
+	}
+	statement.Totals = buildStatementTotals(statement.Lines)
+	logger.Info(fmt.Sprintf("%s - Stored statement fetched", fnName), zap.Int64p("id", statement.ID), zap.Int("lines", len(statement.Lines)))
+	return statement, nil
+}
+
+func (s *StatementService) generateStatement(ctx context.Context, account model.StatementAccount, start time.Time, end time.Time) (bool, *validation.WalletError) {
+	fnName := "StatementService.generateStatement"
+
+	opening, lines, err := s.store.FetchStatementActivity(ctx, account.Username, account.Currency, start, end)
+	if err != nil {
+		return false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_GENERATE_STATEMENT_FAILED,
+			Message:   "Failed to fetch statement activity",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("account", account),
+				zap.Time("period", start),
+			},
+		}
+	}
+	statement := buildStatement(account.Username, account.Currency, start, end, opening, lines)
+	statement.Period = &start
+
+	tx, err := s.store.BeginTransaction(ctx)
+	if err != nil {
+		return false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_TRANSACTION_START_FAILED,
+			Message:   "Failed to start transaction",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	generated, appErr := s.generateStatementTx(ctx, tx, statement)
+	if appErr != nil {
+		return false, appErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_TRANSACTION_COMMIT_FAILED,
+			Message:   "Failed to commit statement",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("account", account),
+				zap.Time("period", start),
+			},
+		}
+	}
+	return generated, nil
+}
+
+func (s *StatementService) generateStatementTx(ctx context.Context, tx *sql.Tx, statement *model.Statement) (bool, *validation.WalletError) {
+	fnName := "StatementService.generateStatementTx"
+
+	inserted, err := s.store.InsertStatement(ctx, tx, statement)
+	if err != nil {
+		return false, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_GENERATE_STATEMENT_FAILED,
+			Message:   "Failed to insert statement",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", statement.Username),
+				zap.String("currency", statement.Currency),
+				zap.Timep("period", statement.Period),
+			},
+		}
+	}
+	if !inserted {
+		logger.Info(fmt.Sprintf("%s - Statement already generated", fnName), zap.String("username", statement.Username), zap.String("currency", statement.Currency))
+		return false, nil
+	}
+
+	for i := range statement.Lines {
+		if err := s.store.InsertStatementLine(ctx, tx, *statement.ID, &statement.Lines[i]); err != nil {
+			return false, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_GENERATE_STATEMENT_FAILED,
+				Message:   "Failed to insert statement line",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.Int64p("statementID", statement.ID),
+					zap.Any("line", statement.Lines[i]),
+				},
+			}
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Statement generated", fnName), zap.Int64p("id", statement.ID), zap.Int("lines", len(statement.Lines)))
+	return true, nil
+}
+
+func parseStatementRange(from string, to string, now time.Time) (time.Time, time.Time, error) {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if from != "" {
+		parsed, err := time.Parse(statementDateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = parsed
+	}
+
+	last := truncateToDay(now)
+	if to != "" {
+		parsed, err := time.Parse(statementDateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		last = parsed
+	}
+
+	if last.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to %s is before from %s", last.Format(statementDateLayout), start.Format(statementDateLayout))
+	}
+	return start, last.AddDate(0, 0, 1), nil
+}
+
+func buildStatement(username string, currency string, from time.Time, to time.Time, opening int64, lines []model.StatementLine) *model.Statement {
+	balance := opening
+	for i := range lines {
+		if lines[i].Direction == model.DirectionCredit {
+			balance += lines[i].Amount
+		} else {
+			balance -= lines[i].Amount
+		}
+		lines[i].Balance = balance
+	}
+
+	return &model.Statement{
+		Username:       username,
+		Currency:       currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		ClosingBalance: balance,
+		Totals:         buildStatementTotals(lines),
+		Lines:          lines,
+	}
+}
+
+func buildStatementTotals(lines []model.StatementLine) map[model.TxnType]model.StatementTotal {
+	totals := map[model.TxnType]model.StatementTotal{}
+	for _, line := range lines {
+		total := totals[line.TxnType]
+		total.Count++
+		if line.Direction == model.DirectionCredit {
+			total.Credits += line.Amount
+			total.Net += line.Amount
+		} else {
+			total.Debits += line.Amount
+			total.Net -= line.Amount
+		}
+		totals[line.TxnType] = total
+	}
+	return totals
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockStatementStore struct {
+	wallets    map[string]*model.Wallet
+	statements []model.Statement
+}
+
+func (m *mockStatementStore) initializeMockData() {
+	m.wallets = map[string]*model.Wallet{
+		"JUAN:USD": {Username: "JUAN", Currency: "USD", Balance: 1500},
+	}
+	period := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+	m.statements = []model.Statement{
+		{
+			ID:             utils.Ptr(int64(1)),
+			Username:       "JUAN",
+			Currency:       "USD",
+			Period:         &period,
+			From:           period,
+			To:             period.AddDate(0, 1, 0),
+			OpeningBalance: 0,
+			ClosingBalance: 800,
+			Lines: []model.StatementLine{
+				{TransactionID: 1, TxnType: model.TypeDeposit, Direction: model.DirectionCredit, Amount: 1000, Balance: 1000},
+				{TransactionID: 2, TxnType: model.TypeWithdraw, Direction: model.DirectionDebit, Amount: 200, Balance: 800},
+			},
+		},
+	}
+}
+
+func (m *mockStatementStore) BeginTransaction(ctx context.Context) (*sql.Tx, error) {
+	return nil, fmt.Errorf("not supported by mock")
+}
+
+func (m *mockStatementStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	wallet, ok := m.wallets[username+":"+currency]
+	if !ok {
+		return nil, nil
+	}
+	copied := *wallet
+	return &copied, nil
+}
+
+func (m *mockStatementStore) FetchStatementActivity(ctx context.Context, username string, currency string, from time.Time, to time.Time) (int64, []model.StatementLine, error) {
+	return 800, []model.StatementLine{
+		{TransactionID: 3, TxnType: model.TypeDeposit, Direction: model.DirectionCredit, Amount: 700},
+	}, nil
+}
+
+func (m *mockStatementStore) FetchStatementAccounts(ctx context.Context, before time.Time) ([]model.StatementAccount, error) {
+	return []model.StatementAccount{{Username: "JUAN", Currency: "USD"}}, nil
+}
+
+func (m *mockStatementStore) InsertStatement(ctx context.Context, tx *sql.Tx, statement *model.Statement) (bool, error) {
+	for _, existing := range m.statements {
+		if existing.Username == statement.Username && existing.Currency == statement.Currency && existing.Period.Equal(*statement.Period) {
+			return false, nil
+		}
+	}
+	statement.ID = utils.Ptr(int64(len(m.statements) + 1))
+	stored := *statement
+	stored.Lines = []model.StatementLine{}
+	m.statements = append(m.statements, stored)
+	return true, nil
+}
+
+func (m *mockStatementStore) InsertStatementLine(ctx context.Context, tx *sql.Tx, statementID int64, line *model.StatementLine) error {
+	for i := range m.statements {
+		if *m.statements[i].ID == statementID {
+			m.statements[i].Lines = append(m.statements[i].Lines, *line)
+			return nil
+		}
+	}
+	return fmt.Errorf("statement %d not found", statementID)
+}
+
+func (m *mockStatementStore) FetchStatement(ctx context.Context, username string, currency string, period time.Time) (*model.Statement, error) {
+	for _, statement := range m.statements {
+		if statement.Username == username && statement.Currency == currency && statement.Period.Equal(period) {
+			copied := statement
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestBuildStatement(t *testing.T) {
+	from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	lines := []model.StatementLine{
+		{TransactionID: 10, TxnType: model.TypeDeposit, Direction: model.DirectionCredit, Amount: 1000},
+		{TransactionID: 11, TxnType: model.TypeTransferOut, Direction: model.DirectionDebit, Amount: 300},
+		{TransactionID: 12, TxnType: model.TypeFee, Direction: model.DirectionDebit, Amount: 5},
+		{TransactionID: 13, TxnType: model.TypeDeposit, Direction: model.DirectionCredit, Amount: 200},
+		{TransactionID: 14, TxnType: model.TypeReversal, Direction: model.DirectionCredit, Amount: 300, ReversalOf: utils.Ptr(int64(11))},
+	}
+
+	statement := buildStatement("JUAN", "USD", from, from.AddDate(0, 1, 0), 500, lines)
+
+	expectedBalances := []int64{1500, 1200, 1195, 1395, 1695}
+	for i, expected := range expectedBalances {
+		if statement.Lines[i].Balance != expected {
+			t.Errorf("expected running balance %d on line %d but got %d instead", expected, i, statement.Lines[i].Balance)
+		}
+	}
+
+	if statement.OpeningBalance != 500 {
+		t.Errorf("expected opening balance 500 but got %d instead", statement.OpeningBalance)
+	}
+
+	if statement.ClosingBalance != 1695 {
+		t.Errorf("expected closing balance 1695 but got %d instead", statement.ClosingBalance)
+	}
+
+	expectedTotals := map[model.TxnType]model.StatementTotal{
+		model.TypeDeposit:     {Count: 2, Credits: 1200, Net: 1200},
+		model.TypeTransferOut: {Count: 1, Debits: 300, Net: -300},
+		model.TypeFee:         {Count: 1, Debits: 5, Net: -5},
+		model.TypeReversal:    {Count: 1, Credits: 300, Net: 300},
+	}
+	if len(statement.Totals) != len(expectedTotals) {
+		t.Errorf("expected %d totals but got %d instead", len(expectedTotals), len(statement.Totals))
+	}
+	for txnType, expected := range expectedTotals {
+		if statement.Totals[txnType] != expected {
+			t.Errorf("expected %s totals %+v but got %+v instead", txnType, expected, statement.Totals[txnType])
+		}
+	}
+}
+
+func TestBuildStatementNoActivity(t *testing.T) {
+	from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	statement := buildStatement("JUAN", "USD", from, from.AddDate(0, 1, 0), 750, []model.StatementLine{})
+
+	if statement.OpeningBalance != 750 || statement.ClosingBalance != 750 {
+		t.Errorf("expected opening and closing balance 750 but got %d and %d instead", statement.OpeningBalance, statement.ClosingBalance)
+	}
+
+	if len(statement.Totals) != 0 {
+		t.Errorf("expected no totals but got %+v instead", statement.Totals)
+	}
+}
+
+func TestParseStatementRange(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 12, 30, 0, 0, time.UTC)
+
+	type testCase struct {
+		name          string
+		from          string
+		to            string
+		expectedStart time.Time
+		expectedEnd   time.Time
+		expectErr     bool
+	}
+
+	tests := []testCase{
+		{
+			name:          "Successful Range - Defaults to month to date",
+			expectedStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2025, time.June, 16, 0, 0, 0, 0, time.UTC),
+			expectErr:     false,
+		},
+		{
+			name:          "Successful Range - To date is inclusive",
+			from:          "2025-05-01",
+			to:            "2025-05-31",
+			expectedStart: time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			expectErr:     false,
+		},
+		{
+			name:          "Successful Range - Single day",
+			from:          "2025-05-10",
+			to:            "2025-05-10",
+			expectedStart: time.Date(2025, time.May, 10, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2025, time.May, 11, 0, 0, 0, 0, time.UTC),
+			expectErr:     false,
+		},
+		{
+			name:      "Failed Range - To before from",
+			from:      "2025-05-10",
+			to:        "2025-05-09",
+			expectErr: true,
+		},
+		{
+			name:      "Failed Range - Invalid date format",
+			from:      "2025/05/10",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, err := parseStatementRange(test.from, test.to, now)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !test.expectErr && (!start.Equal(test.expectedStart) || !end.Equal(test.expectedEnd)) {
+				t.Errorf("expected range %s to %s but got %s to %s instead", test.expectedStart, test.expectedEnd, start, end)
+			}
+		})
+	}
+}
+
+func TestDoFetchStatement(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name            string
+		username        string
+		currency        string
+		period          string
+		expectedOpening int64
+		expectedClosing int64
+		expectedCode    validation.WalletErrorCode
+		expectErr       bool
+	}
+
+	tests := []testCase{
+		{
+			name:            "Successful Statement - Live range",
+			username:        "juan",
+			currency:        "usd",
+			expectedOpening: 800,
+			expectedClosing: 1500,
+			expectErr:       false,
+		},
+		{
+			name:            "Successful Statement - Stored monthly statement",
+			username:        "JUAN",
+			currency:        "USD",
+			period:          "2025-05",
+			expectedOpening: 0,
+			expectedClosing: 800,
+			expectErr:       false,
+		},
+		{
+			name:         "Failed Statement - Period not generated",
+			username:     "JUAN",
+			currency:     "USD",
+			period:       "2025-04",
+			expectedCode: validation.ERR_STATEMENT_NOT_FOUND,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Statement - Invalid period",
+			username:     "JUAN",
+			currency:     "USD",
+			period:       "May 2025",
+			expectedCode: validation.ERR_STATEMENT_PERIOD_INVALID,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Statement - Wallet not found",
+			username:     "MARY",
+			currency:     "USD",
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockStatementStore{}
+			mock.initializeMockData()
+			s := &StatementService{store: mock}
+
+			statement, err := s.DoFetchStatement(context.Background(), test.username, test.currency, "", "", test.period)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && statement != nil && statement.OpeningBalance != test.expectedOpening {
+				t.Errorf("expected opening balance %d but got %d instead", test.expectedOpening, statement.OpeningBalance)
+			}
+
+			if !test.expectErr && statement != nil && statement.ClosingBalance != test.expectedClosing {
+				t.Errorf("expected closing balance %d but got %d instead", test.expectedClosing, statement.ClosingBalance)
+			}
+
+			if !test.expectErr && statement != nil && len(statement.Totals) == 0 {
+				t.Errorf("expected totals but got none")
+			}
+		})
+	}
+}
+
+func TestGenerateStatementTx(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	june := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	may := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		name           string
+		period         time.Time
+		expectInserted bool
+		expectedLines  int
+	}
+
+	tests := []testCase{
+		{
+			name:           "Successful Generation - New period stored with lines",
+			period:         june,
+			expectInserted: true,
+			expectedLines:  1,
+		},
+		{
+			name:           "Successful Generation - Existing period left unchanged",
+			period:         may,
+			expectInserted: false,
+			expectedLines:  2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockStatementStore{}
+			mock.initializeMockData()
+			s := &StatementService{store: mock}
+
+			lines := []model.StatementLine{
+				{TransactionID: 3, TxnType: model.TypeDeposit, Direction: model.DirectionCredit, Amount: 700},
+			}
+			statement := buildStatement("JUAN", "USD", test.period, test.period.AddDate(0, 1, 0), 800, lines)
+			statement.Period = &test.period
+
+			inserted, err := s.generateStatementTx(context.Background(), nil, statement)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if inserted != test.expectInserted {
+				t.Errorf("expected inserted %t but got %t instead", test.expectInserted, inserted)
+			}
+
+			stored, _ := mock.FetchStatement(context.Background(), "JUAN", "USD", test.period)
+			if stored == nil {
+				t.Fatalf("expected stored statement but got nil")
+			}
+
+			if len(stored.Lines) != test.expectedLines {
+				t.Errorf("expected %d stored lines but got %d instead", test.expectedLines, len(stored.Lines))
+			}
+		})
+	}
+}
@@ -24,9 +24,9 @@ func NewTransactionService(store *db.Store) *TransactionService {
 	return &TransactionService{store: store}
 }
 
-func (ts *TransactionService) LogTransaction(ctx context.Context, tx *sql.Tx, txnUsername string, txnType model.TxnType, txnAmount int64, txnCounterparty *string) (*model.Transaction, *validation.WalletError) {
+func (ts *TransactionService) LogTransaction(ctx context.Context, tx *sql.Tx, txn model.Transaction) (*model.Transaction, *validation.WalletError) {
 	fnName := "TransactionService.LogTransaction"
-	if txnAmount <= 0 {
+	if txn.Amount <= 0 {
 		return nil, &validation.WalletError{
 			Name:      fnName,
 			Code:      validation.ERR_ZERO_AMOUNT,
@@ -34,13 +34,13 @@ func (ts *TransactionService) LogTransaction(ctx context.Context, tx *sql.Tx, tx
 			Timestamp: time.Now().UTC(),
 			Err:       nil,
 			Context: []zap.Field{
-				zap.Int64("amount", txnAmount),
+				zap.Int64("amount", txn.Amount),
 			},
 		}
 	}
-	logger.Info(fmt.Sprintf("%s - Amount valid", fnName), zap.Int64("amount", txnAmount))
+	logger.Info(fmt.Sprintf("%s - Amount valid", fnName), zap.Int64("amount", txn.Amount))
 
-	txUser, err := validation.SanitizeAndValidateUsername(txnUsername)
+	txUser, err := validation.SanitizeAndValidateUsername(txn.Username)
 	if err != nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
@@ -49,31 +49,99 @@ func (ts *TransactionService) LogTransaction(ctx context.Context, tx *sql.Tx, tx
 			Timestamp: time.Now().UTC(),
 			Err:       err,
 			Context: []zap.Field{
-				zap.String("username", txUser),
+				zap.String("username", txn.Username),
 			},
 		}
 	}
-	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", txUser))
+	txn.Username = txUser
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", txn.Username))
 
-	timestamp := time.Now().UTC()
-	hash := utils.GenerateTransactionHash(txUser, txnType, txnAmount, txnCounterparty, timestamp.Format(time.RFC3339))
-	logger.Info(fmt.Sprintf("%s - Generated hash", fnName), zap.String("hash", hash))
+	currency, err := validation.SanitizeAndValidateCurrency(txn.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", txn.Currency),
+			},
+		}
+	}
+	txn.Currency = currency.Code
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", txn.Currency))
+
+	pocket, err := validation.SanitizeAndValidatePocket(txn.Pocket)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", txn.Pocket),
+			},
+		}
+	}
+	txn.Pocket = pocket
+	logger.Info(fmt.Sprintf("%s - Pocket validated", fnName), zap.String("pocket", txn.Pocket))
+
+	memo, reference, metadata, appErr := sanitizeTransactionDetails(fnName, txn.Memo, txn.Reference, txn.Metadata)
+	if appErr != nil {
+		return nil, appErr
+	}
+	txn.Memo, txn.Reference, txn.Metadata = memo, reference, metadata
+	logger.Info(fmt.Sprintf("%s - Details validated", fnName), zap.Stringp("memo", txn.Memo), zap.Stringp("reference", txn.Reference), zap.Any("metadata", txn.Metadata))
 
-	txn := model.Transaction{
-		Username:     txUser,
-		TxnType:      txnType,
-		Amount:       txnAmount,
-		Counterparty: txnCounterparty,
-		Timestamp:    timestamp,
-		Hash:         hash,
+	if txn.Direction == "" {
+		direction, ok := model.TxnDirection(txn.TxnType)
+		if !ok {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_UNKNOWN_TRANSACTION_TYPE,
+				Message:   "Transaction type has no direction",
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("no direction for transaction type %s", txn.TxnType),
+				Context: []zap.Field{
+					zap.String("txnType", string(txn.TxnType)),
+				},
+			}
+		}
+		txn.Direction = direction
 	}
-	err = ts.store.InsertTransaction(ctx, tx, txn)
+	logger.Info(fmt.Sprintf("%s - Direction resolved", fnName), zap.String("direction", string(txn.Direction)))
+
+	prevHash, err := ts.store.FetchLastTransactionHash(ctx, tx)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_CHAIN_HEAD_FAILED,
+			Message:   "Failed to fetch previous transaction hash",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+		}
+	}
+	txn.PrevHash = model.GenesisHash
+	if prevHash != nil {
+		txn.PrevHash = *prevHash
+	}
+	logger.Info(fmt.Sprintf("%s - Fetched previous hash", fnName), zap.String("prevHash", txn.PrevHash))
+
+	txn.Timestamp = time.Now().UTC().Truncate(time.Microsecond)
+	txn.HashVersion = model.CurrentHashVersion
+	txn.Hash = utils.GenerateTransactionHash(txn.PrevHash, &txn)
+	logger.Info(fmt.Sprintf("%s - Generated hash", fnName), zap.String("hash", txn.Hash))
+
+	err = ts.store.InsertTransaction(ctx, tx, &txn)
 	if err != nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
 			Code:      validation.ERR_LOG_TRANSACTION_FAILED,
 			Message:   "Failed to log transaction",
 			Timestamp: time.Now().UTC(),
+			Err:       err,
 			Context: []zap.Field{
 				zap.Any("transaction", txn),
 			},
@@ -83,7 +151,7 @@ func (ts *TransactionService) LogTransaction(ctx context.Context, tx *sql.Tx, tx
 	return &txn, nil
 }
 
-func (ts *TransactionService) DoFetchTransaction(ctx context.Context, txnUsername string, txnCounterparty string, txnType string, txnLimit string) ([]model.Transaction, *model.Criteria, *validation.WalletError) {
+func (ts *TransactionService) DoFetchTransaction(ctx context.Context, txnUsername string, txnCounterparty string, txnType string, txnCurrency string, txnPocket string, txnReference string, txnLimit string) ([]model.Transaction, *model.Criteria, *validation.WalletError) {
 	fnName := "TransactionService.DoFetchTransaction"
 	queryUsername := validation.SanitizeUsernameWithoutError(txnUsername)
 	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", queryUsername))
@@ -97,6 +165,15 @@ func (ts *TransactionService) DoFetchTransaction(ctx context.Context, txnUsernam
 	queryCounterparty := validation.SanitizeUsernameWithoutError(txnCounterparty)
 	logger.Info(fmt.Sprintf("%s - Counterparty sanitized", fnName), zap.String("counterparty", queryCounterparty))
 
+	queryCurrency := validation.SanitizeCurrencyWithoutError(txnCurrency)
+	logger.Info(fmt.Sprintf("%s - Currency sanitized", fnName), zap.String("currency", queryCurrency))
+
+	queryPocket := validation.SanitizePocketWithoutError(txnPocket)
+	logger.Info(fmt.Sprintf("%s - Pocket sanitized", fnName), zap.String("pocket", queryPocket))
+
+	queryReference := validation.SanitizeReferenceWithoutError(txnReference)
+	logger.Info(fmt.Sprintf("%s - Reference sanitized", fnName), zap.String("reference", queryReference))
+
 	queryLimit, err := strconv.Atoi(txnLimit)
 	if err != nil {
 		queryLimit = 0
@@ -107,6 +184,9 @@ func (ts *TransactionService) DoFetchTransaction(ctx context.Context, txnUsernam
 		Username:     queryUsername,
 		Counterparty: queryCounterparty,
 		TxnType:      model.TxnType(queryTxnType),
+		Currency:     queryCurrency,
+		Pocket:       queryPocket,
+		Reference:    queryReference,
 		Limit:        queryLimit,
 	}
 	logger.Info(fmt.Sprintf("%s - query", fnName), zap.Any("query", query))
@@ -127,3 +207,64 @@ func (ts *TransactionService) DoFetchTransaction(ctx context.Context, txnUsernam
 	logger.Info(fmt.Sprintf("%s - Transaction fetched successfully", fnName), zap.Any("transactions", transactions))
 	return transactions, query, nil
 }
+
+// sanitizeTransactionDetails checks the optional memo, reference and
+// metadata a client attaches to a transaction. Blank values come back nil so
+// they are stored as NULL and left out of the hash.
+func sanitizeTransactionDetails(fnName string, memo *string, reference *string, metadata map[string]string) (*string, *string, map[string]string, *validation.WalletError) {
+	var cleanMemo, cleanReference *string
+	if memo != nil {
+		sanitized, err := validation.SanitizeAndValidateMemo(*memo)
+		if err != nil {
+			return nil, nil, nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_MEMO_VALIDATION_FAILED,
+				Message:   "Memo validation failed",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("memo", *memo),
+				},
+			}
+		}
+		if sanitized != "" {
+			cleanMemo = &sanitized
+		}
+	}
+
+	if reference != nil {
+		sanitized, err := validation.SanitizeAndValidateReference(*reference)
+		if err != nil {
+			return nil, nil, nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_REFERENCE_VALIDATION_FAILED,
+				Message:   "Reference validation failed",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("reference", *reference),
+				},
+			}
+		}
+		if sanitized != "" {
+			cleanReference = &sanitized
+		}
+	}
+
+	if err := validation.ValidateMetadata(metadata); err != nil {
+		return nil, nil, nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_METADATA_VALIDATION_FAILED,
+			Message:   "Metadata validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("metadata", metadata),
+			},
+		}
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	return cleanMemo, cleanReference, metadata, nil
+}
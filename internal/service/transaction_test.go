@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+func TestSanitizeTransactionDetails(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	tooManyEntries := map[string]string{}
+	for i := 0; i <= model.MaxMetadataEntries; i++ {
+		tooManyEntries[fmt.Sprintf("key%d", i)] = "value"
+	}
+
+	type testCase struct {
+		name              string
+		memo              *string
+		reference         *string
+		metadata          map[string]string
+		expectedMemo      *string
+		expectedReference *string
+		expectedMetadata  map[string]string
+		expectedErrCode   validation.WalletErrorCode
+	}
+
+	tests := []testCase{
+		{
+			name: "Valid Details - None given",
+		},
+		{
+			name:              "Valid Details - Trimmed memo and reference",
+			memo:              utils.Ptr("  Rent for June  "),
+			reference:         utils.Ptr(" INV-2025/06:01 "),
+			metadata:          map[string]string{"order_id": "1042", "note": "Paid via app 👍"},
+			expectedMemo:      utils.Ptr("Rent for June"),
+			expectedReference: utils.Ptr("INV-2025/06:01"),
+			expectedMetadata:  map[string]string{"order_id": "1042", "note": "Paid via app 👍"},
+		},
+		{
+			name:      "Valid Details - Blank values dropped",
+			memo:      utils.Ptr("   "),
+			reference: utils.Ptr(""),
+			metadata:  map[string]string{},
+		},
+		{
+			name:         "Valid Details - Memo at max length",
+			memo:         utils.Ptr(strings.Repeat("é", model.MaxMemoLength)),
+			expectedMemo: utils.Ptr(strings.Repeat("é", model.MaxMemoLength)),
+		},
+		{
+			name:            "Invalid Details - Memo too long",
+			memo:            utils.Ptr(strings.Repeat("a", model.MaxMemoLength+1)),
+			expectedErrCode: validation.ERR_MEMO_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Memo with newline",
+			memo:            utils.Ptr("line one\nline two"),
+			expectedErrCode: validation.ERR_MEMO_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Memo not UTF-8",
+			memo:            utils.Ptr("bad \xff byte"),
+			expectedErrCode: validation.ERR_MEMO_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Reference with space",
+			reference:       utils.Ptr("INV 1042"),
+			expectedErrCode: validation.ERR_REFERENCE_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Reference starts with symbol",
+			reference:       utils.Ptr("-1042"),
+			expectedErrCode: validation.ERR_REFERENCE_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Reference too long",
+			reference:       utils.Ptr(strings.Repeat("a", model.MaxReferenceLength+1)),
+			expectedErrCode: validation.ERR_REFERENCE_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Too many metadata entries",
+			metadata:        tooManyEntries,
+			expectedErrCode: validation.ERR_METADATA_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Metadata key with space",
+			metadata:        map[string]string{"order id": "1042"},
+			expectedErrCode: validation.ERR_METADATA_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Metadata value too long",
+			metadata:        map[string]string{"note": strings.Repeat("a", model.MaxMetadataValueLength+1)},
+			expectedErrCode: validation.ERR_METADATA_VALIDATION_FAILED,
+		},
+		{
+			name:            "Invalid Details - Metadata value with control character",
+			metadata:        map[string]string{"note": "tab\there"},
+			expectedErrCode: validation.ERR_METADATA_VALIDATION_FAILED,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memo, reference, metadata, appErr := sanitizeTransactionDetails(test.name, test.memo, test.reference, test.metadata)
+			if test.expectedErrCode != "" {
+				if appErr == nil {
+					t.Fatalf("expected error %s but got nil", test.expectedErrCode)
+				}
+				if appErr.Code != test.expectedErrCode {
+					t.Fatalf("expected error %s but got %s", test.expectedErrCode, appErr.Code)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %s: %v", appErr.Code, appErr.Err)
+			}
+			if !reflect.DeepEqual(memo, test.expectedMemo) {
+				t.Errorf("Memo - expected %v but got %v", test.expectedMemo, memo)
+			}
+			if !reflect.DeepEqual(reference, test.expectedReference) {
+				t.Errorf("Reference - expected %v but got %v", test.expectedReference, reference)
+			}
+			if !reflect.DeepEqual(metadata, test.expectedMetadata) {
+				t.Errorf("Metadata - expected %v but got %v", test.expectedMetadata, metadata)
+			}
+		})
+	}
+}
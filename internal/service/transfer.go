@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type TransferService struct {
+	withdraw *WithdrawService
+	deposit  *DepositService
+	journal  *JournalService
+	velocity *VelocityService
+}
+
+func NewTransferService(withdraw *WithdrawService, deposit *DepositService, journal *JournalService, velocity *VelocityService) *TransferService {
+	logger.Debug("Initializing TransferService")
+	return &TransferService{withdraw: withdraw, deposit: deposit, journal: journal, velocity: velocity}
+}
+
+// DoTransfer checks transfer velocity limits, then moves amount from the sender's pocket to the counterparty's
+// default pocket and posts both legs as a single journal entry. With a quote
+// the counterparty is credited in the quote currency and the entry goes
+// through SYSTEM_FX.
+func (s *TransferService) DoTransfer(ctx context.Context, tx *sql.Tx, username string, currencyCode string, pocketName string, amount int64, counterparty string, quote *model.FXQuote) (*model.Wallet, *model.Wallet, *model.JournalEntry, *validation.WalletError) {
+	fnName := "TransferService.DoTransfer"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode), zap.String("pocket", pocketName), zap.Int64("amount", amount), zap.String("counterparty", counterparty), zap.Any("quote", quote))
+
+	if appErr := s.velocity.DoCheckVelocity(ctx, tx, model.TypeTransfer, username, currencyCode, amount); appErr != nil {
+		return nil, nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity limits checked", fnName))
+
+	wallet, appErr := s.withdraw.DoWithdraw(ctx, tx, username, currencyCode, pocketName, amount)
+	if appErr != nil {
+		return nil, nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Transfer out successful", fnName), zap.Any("wallet", wallet))
+
+	creditCurrency, creditAmount := wallet.Currency, amount
+	if quote != nil {
+		creditCurrency, creditAmount = quote.QuoteCurrency, quote.QuoteAmount
+	}
+
+	counterpartyWallet, appErr := s.deposit.DoDeposit(ctx, tx, counterparty, creditCurrency, model.DefaultPocket, creditAmount, true)
+	if appErr != nil {
+		return nil, nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Transfer in successful", fnName), zap.Any("wallet", counterpartyWallet))
+
+	var entry *model.JournalEntry
+	if quote != nil {
+		entry, appErr = s.journal.PostFXTransfer(ctx, tx, wallet, counterpartyWallet, amount, creditAmount)
+	} else {
+		entry, appErr = s.journal.PostTransfer(ctx, tx, wallet, counterpartyWallet, amount)
+	}
+	if appErr != nil {
+		return nil, nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+	return wallet, counterpartyWallet, entry, nil
+}
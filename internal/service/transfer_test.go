@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockTransferStore struct {
+	wallets  map[string]*model.Wallet
+	postings map[int64]int64
+	entries  []model.JournalEntry
+}
+
+func (m *mockTransferStore) initializeMockLedger() {
+	m.wallets = map[string]*model.Wallet{
+		"JUAN":     {ID: 1, Username: "JUAN", Currency: "USD", Status: model.WalletActive, Limits: defaultMockLimits("USD"), Balance: 2000},
+		"MARY":     {ID: 2, Username: "MARY", Currency: "USD", Status: model.WalletActive, Limits: defaultMockLimits("USD"), Balance: 500},
+		"PEDRO":    {ID: 3, Username: "PEDRO", Currency: "EUR", Status: model.WalletActive, Limits: defaultMockLimits("EUR"), Balance: 0},
+		"J_LEGACY": {ID: 4, Username: "J_LEGACY", Currency: "USD", Status: model.WalletActive, Limits: defaultMockLimits("USD"), Balance: 900},
+	}
+	m.postings = map[int64]int64{
+		1: 2000,
+		2: 500,
+		3: 0,
+	}
+}
+
+func (m *mockTransferStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	w, ok := m.wallets[username]
+	if !ok || w.Currency != currency || pocket != model.DefaultPocket {
+		return nil, nil
+	}
+	wallet := *w
+	wallet.Pocket = model.DefaultPocket
+	wallet.AvailableBalance = w.Balance
+	return &wallet, nil
+}
+
+func (m *mockTransferStore) UpsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
+	w, ok := m.wallets[username]
+	if !ok {
+		return nil, fmt.Errorf("Test Ledger - No wallet found")
+	}
+	w.Balance += amount
+	return m.FetchWallet(ctx, username, currency, pocket)
+}
+
+func (m *mockTransferStore) WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
+	w, ok := m.wallets[username]
+	if !ok || w.Balance < amount {
+		return nil, fmt.Errorf("Test Ledger - Insufficient balance")
+	}
+	w.Balance -= amount
+	return m.FetchWallet(ctx, username, currency, pocket)
+}
+
+func (m *mockTransferStore) FetchWalletMembers(ctx context.Context, walletUsername string) ([]model.WalletMember, error) {
+	return []model.WalletMember{}, nil
+}
+
+func (m *mockTransferStore) FetchSigningRule(ctx context.Context, walletUsername string, currency string) (*model.SigningRule, error) {
+	return nil, nil
+}
+
+func (m *mockTransferStore) InsertJournalEntry(ctx context.Context, tx *sql.Tx, entry *model.JournalEntry) error {
+	entry.ID = int64(len(m.entries) + 1)
+	for _, posting := range entry.Postings {
+		if posting.WalletID == nil {
+			continue
+		}
+		switch posting.Direction {
+		case model.DirectionCredit:
+			m.postings[*posting.WalletID] += posting.Amount
+		case model.DirectionDebit:
+			m.postings[*posting.WalletID] -= posting.Amount
+		}
+	}
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *mockTransferStore) FetchWalletLedgerBalance(ctx context.Context, tx *sql.Tx, walletID int64) (int64, int64, error) {
+	for _, w := range m.wallets {
+		if w.ID == walletID {
+			return w.Balance, m.postings[walletID], nil
+		}
+	}
+	return 0, 0, fmt.Errorf("Test Ledger - No wallet %d", walletID)
+}
+
+func newMockTransferService(mock *mockTransferStore) *TransferService {
+	vs := &VelocityService{store: &mockVelocityStore{}}
+	js := &JournalService{store: mock}
+	ws := &WithdrawService{store: mock, journal: js, velocity: vs}
+	ds := &DepositService{store: mock, journal: js, config: &model.WalletConfig{}}
+	return &TransferService{withdraw: ws, deposit: ds, journal: js, velocity: vs}
+}
+
+func TestDoTransfer(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name                string
+		username            string
+		currency            string
+		amount              int64
+		counterparty        string
+		quote               *model.FXQuote
+		velocityRules       bool
+		expectedBalance     int64
+		expectedCounterpart int64
+		expectedPostings    int
+		expectedCode        validation.WalletErrorCode
+	}
+
+	tests := []testCase{
+		{
+			name:                "Transfer - Posts both legs in one entry",
+			username:            "JUAN",
+			currency:            "USD",
+			amount:              300,
+			counterparty:        "MARY",
+			expectedBalance:     1700,
+			expectedCounterpart: 800,
+			expectedPostings:    2,
+		},
+		{
+			name:                "Transfer - Quoted transfer posts through SYSTEM_FX",
+			username:            "JUAN",
+			currency:            "USD",
+			amount:              1000,
+			counterparty:        "PEDRO",
+			quote:               &model.FXQuote{QuoteCurrency: "EUR", QuoteAmount: 920},
+			expectedBalance:     1000,
+			expectedCounterpart: 920,
+			expectedPostings:    4,
+		},
+		{
+			name:         "Failed Transfer - Counterparty has no wallet",
+			username:     "JUAN",
+			currency:     "USD",
+			amount:       300,
+			counterparty: "NOBODY",
+			expectedCode: validation.ERR_WALLET_DOES_NOT_EXIST,
+		},
+		{
+			name:          "Failed Transfer - Weekly velocity limit exceeded",
+			username:      "JUAN",
+			currency:      "USD",
+			amount:        501,
+			counterparty:  "MARY",
+			velocityRules: true,
+			expectedCode:  validation.ERR_VELOCITY_LIMIT_EXCEEDED,
+		},
+		{
+			name:         "Failed Transfer - Wallet balance has no opening postings",
+			username:     "J_LEGACY",
+			currency:     "USD",
+			amount:       100,
+			counterparty: "MARY",
+			expectedCode: validation.ERR_LEDGER_BALANCE_MISMATCH,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockTransferStore{}
+			mock.initializeMockLedger()
+			s := newMockTransferService(mock)
+			if test.velocityRules {
+				s.velocity.store.(*mockVelocityStore).initializeMockData()
+			}
+
+			wallet, counterpartyWallet, entry, appErr := s.DoTransfer(context.Background(), nil, test.username, test.currency, model.DefaultPocket, test.amount, test.counterparty, test.quote)
+
+			if test.expectedCode != "" {
+				if appErr == nil {
+					t.Fatalf("expected error %s but got nil", test.expectedCode)
+				}
+				if appErr.Code != test.expectedCode {
+					t.Errorf("expected error %s but got %s instead", test.expectedCode, appErr.Code)
+				}
+				return
+			}
+
+			if appErr != nil {
+				t.Fatalf("unexpected error: %v", appErr)
+			}
+			if wallet.Balance != test.expectedBalance {
+				t.Errorf("expected balance %d but got %d instead", test.expectedBalance, wallet.Balance)
+			}
+			if counterpartyWallet.Balance != test.expectedCounterpart {
+				t.Errorf("expected counterparty balance %d but got %d instead", test.expectedCounterpart, counterpartyWallet.Balance)
+			}
+			if len(mock.entries) != 1 {
+				t.Fatalf("expected 1 journal entry but got %d instead", len(mock.entries))
+			}
+			if entry.EntryType != model.TypeTransfer {
+				t.Errorf("expected entry type %s but got %s instead", model.TypeTransfer, entry.EntryType)
+			}
+			if len(entry.Postings) != test.expectedPostings {
+				t.Errorf("expected %d postings but got %d instead", test.expectedPostings, len(entry.Postings))
+			}
+		})
+	}
+}
+
+func TestDoPostedDepositAndWithdraw(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	mock := &mockTransferStore{}
+	mock.initializeMockLedger()
+	s := newMockTransferService(mock)
+
+	wallet, entry, appErr := s.deposit.DoPostedDeposit(context.Background(), nil, "MARY", "USD", model.DefaultPocket, 250)
+	if appErr != nil {
+		t.Fatalf("unexpected deposit error: %v", appErr)
+	}
+	if wallet.Balance != 750 || entry.EntryType != model.TypeDeposit {
+		t.Errorf("expected deposit entry with balance 750 but got %s with %d instead", entry.EntryType, wallet.Balance)
+	}
+
+	wallet, entry, appErr = s.withdraw.DoPostedWithdraw(context.Background(), nil, "MARY", "USD", model.DefaultPocket, 700)
+	if appErr != nil {
+		t.Fatalf("unexpected withdraw error: %v", appErr)
+	}
+	if wallet.Balance != 50 || entry.EntryType != model.TypeWithdraw {
+		t.Errorf("expected withdraw entry with balance 50 but got %s with %d instead", entry.EntryType, wallet.Balance)
+	}
+
+	if len(mock.entries) != 2 {
+		t.Errorf("expected 2 journal entries but got %d instead", len(mock.entries))
+	}
+	if mock.postings[2] != 50 {
+		t.Errorf("expected ledger balance 50 but got %d instead", mock.postings[2])
+	}
+
+	if _, _, appErr := s.withdraw.DoPostedWithdraw(context.Background(), nil, "MARY", "USD", model.DefaultPocket, 500); appErr == nil {
+		t.Errorf("expected overdraft error but got nil")
+	}
+	if len(mock.entries) != 2 {
+		t.Errorf("expected failed withdraw to post nothing but got %d entries", len(mock.entries))
+	}
+
+	s.withdraw.velocity.store.(*mockVelocityStore).initializeMockData()
+	if _, _, appErr := s.withdraw.DoPostedWithdraw(context.Background(), nil, "JUAN", "USD", model.DefaultPocket, 501); appErr == nil || appErr.Code != validation.ERR_VELOCITY_LIMIT_EXCEEDED {
+		t.Errorf("expected velocity error but got %v", appErr)
+	}
+	if len(mock.entries) != 2 {
+		t.Errorf("expected withdraw over velocity limit to post nothing but got %d entries", len(mock.entries))
+	}
+}
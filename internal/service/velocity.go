@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/validation"
+	"go.uber.org/zap"
+)
+
+type VelocityStore interface {
+	RetireVelocityRule(ctx context.Context, tx *sql.Tx, username *string, txnType model.TxnType, currency string, window model.VelocityWindow) (int64, error)
+	InsertVelocityRule(ctx context.Context, tx *sql.Tx, rule *model.VelocityRule) error
+	FetchVelocityRules(ctx context.Context, username string, txnType string) ([]model.VelocityRule, error)
+	FetchApplicableVelocityRules(ctx context.Context, tx *sql.Tx, username string, txnType model.TxnType, currency string) ([]model.VelocityRule, error)
+	FetchVelocityUsage(ctx context.Context, tx *sql.Tx, username string, currency string, txnTypes []model.TxnType, since time.Time) ([]model.VelocityUsage, error)
+}
+
+type VelocityService struct {
+	store VelocityStore
+}
+
+type velocityBreach struct {
+	rule    model.VelocityRule
+	used    int64
+	resetAt *time.Time
+}
+
+func NewVelocityService(store VelocityStore) *VelocityService {
+	logger.Info("Initializing VelocityService")
+	return &VelocityService{store: store}
+}
+
+func (s *VelocityService) DoSetVelocityRule(ctx context.Context, tx *sql.Tx, payload *request.VelocityRulePayload) (*model.VelocityRule, *validation.WalletError) {
+	fnName := "VelocityService.DoSetVelocityRule"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	rule, err := buildVelocityRule(payload)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_VELOCITY_RULE_VALIDATION_FAILED,
+			Message:   "Velocity rule validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("payload", payload),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity rule validated", fnName), zap.Any("rule", rule))
+
+	retired, err := s.store.RetireVelocityRule(ctx, tx, rule.Username, rule.TxnType, rule.Currency, rule.Window)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_INSERT_VELOCITY_RULE_FAILED,
+			Message:   "Failed to retire active velocity rule",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("rule", rule),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Active velocity rule retired", fnName), zap.Int64("count", retired))
+
+	if payload.MaxAmount == nil {
+		logger.Info(fmt.Sprintf("%s - Velocity rule removed", fnName))
+		return nil, nil
+	}
+
+	if err := s.store.InsertVelocityRule(ctx, tx, rule); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_INSERT_VELOCITY_RULE_FAILED,
+			Message:   "Failed to insert velocity rule",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Any("rule", rule),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity rule inserted", fnName), zap.Any("rule", rule))
+	return rule, nil
+}
+
+func (s *VelocityService) DoFetchVelocityRules(ctx context.Context, username string, txnType string) ([]model.VelocityRule, *validation.WalletError) {
+	fnName := "VelocityService.DoFetchVelocityRules"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("txnType", txnType))
+
+	if sanitized, err := validation.SanitizeAndValidateUsername(username); err == nil {
+		username = sanitized
+	}
+	if !model.IsVelocityTxnTypeValid(txnType) {
+		txnType = ""
+	}
+
+	rules, err := s.store.FetchVelocityRules(ctx, username, txnType)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_VELOCITY_RULE_FAILED,
+			Message:   "Failed to fetch velocity rules",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("txnType", txnType),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity rules fetched", fnName), zap.Int("count", len(rules)))
+	return rules, nil
+}
+
+func (s *VelocityService) DoCheckVelocity(ctx context.Context, tx *sql.Tx, txnType model.TxnType, username string, currencyCode string, amount int64) *validation.WalletError {
+	fnName := "VelocityService.DoCheckVelocity"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("txnType", string(txnType)), zap.String("username", username), zap.String("currency", currencyCode), zap.Int64("amount", amount))
+
+	username, err := validation.SanitizeAndValidateUsername(username)
+	if err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Failed to sanitize username",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", currencyCode),
+			},
+		}
+	}
+
+	rules, err := s.store.FetchApplicableVelocityRules(ctx, tx, username, txnType, currency.Code)
+	if err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_VELOCITY_RULE_FAILED,
+			Message:   "Failed to fetch applicable velocity rules",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("txnType", string(txnType)),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if len(rules) == 0 {
+		logger.Info(fmt.Sprintf("%s - No velocity rules apply", fnName))
+		return nil
+	}
+
+	now := time.Now().UTC()
+	since := now
+	for _, rule := range rules {
+		if start := rule.Window.Start(now); start.Before(since) {
+			since = start
+		}
+	}
+
+	usage, err := s.store.FetchVelocityUsage(ctx, tx, username, currency.Code, model.VelocityLoggedTypes(txnType), since)
+	if err != nil {
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_VELOCITY_USAGE_FAILED,
+			Message:   "Failed to fetch velocity usage",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("txnType", string(txnType)),
+				zap.String("currency", currency.Code),
+				zap.Time("since", since),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity usage fetched", fnName), zap.Int("count", len(usage)))
+
+	for _, rule := range rules {
+		breach := checkVelocityWindow(rule, usage, amount, now)
+		if breach == nil {
+			continue
+		}
+		return &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_VELOCITY_LIMIT_EXCEEDED,
+			Message:   velocityBreachMessage(breach, amount),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("%s %s usage %d plus amount %d exceeds limit %d", rule.Window, txnType, breach.used, amount, rule.MaxAmount),
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.Any("rule", rule),
+				zap.Int64("used", breach.used),
+				zap.Int64("amount", amount),
+				zap.Timep("resetAt", breach.resetAt),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity limits validated", fnName))
+	return nil
+}
+
+func buildVelocityRule(payload *request.VelocityRulePayload) (*model.VelocityRule, error) {
+	if !model.IsVelocityTxnTypeValid(payload.TxnType) {
+		return nil, fmt.Errorf("velocity limits are only supported for withdraw and transfer, got %q", payload.TxnType)
+	}
+	if !model.IsVelocityWindowValid(payload.Window) {
+		return nil, fmt.Errorf("window must be daily, weekly or monthly, got %q", payload.Window)
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &model.VelocityRule{
+		TxnType:  model.TxnType(payload.TxnType),
+		Currency: currency.Code,
+		Window:   model.VelocityWindow(payload.Window),
+	}
+
+	if payload.Username != nil {
+		username, err := validation.SanitizeAndValidateUsername(*payload.Username)
+		if err != nil {
+			return nil, err
+		}
+		if validation.IsReservedUsername(username) {
+			return nil, fmt.Errorf("username %s is reserved", username)
+		}
+		rule.Username = &username
+	}
+
+	if payload.MaxAmount != nil {
+		if *payload.MaxAmount <= 0 {
+			return nil, fmt.Errorf("maxAmount must be greater than 0")
+		}
+		rule.MaxAmount = *payload.MaxAmount
+	}
+	return rule, nil
+}
+
+func checkVelocityWindow(rule model.VelocityRule, usage []model.VelocityUsage, amount int64, now time.Time) *velocityBreach {
+	start := rule.Window.Start(now)
+
+	var inWindow []model.VelocityUsage
+	var used int64
+	for _, u := range usage {
+		if u.Timestamp.After(start) {
+			inWindow = append(inWindow, u)
+			used += u.Amount
+		}
+	}
+	if used+amount <= rule.MaxAmount {
+		return nil
+	}
+
+	breach := &velocityBreach{rule: rule, used: used}
+	if amount > rule.MaxAmount {
+		return breach
+	}
+
+	excess := used + amount - rule.MaxAmount
+	var freed int64
+	for _, u := range inWindow {
+		freed += u.Amount
+		if freed >= excess {
+			resetAt := rule.Window.End(u.Timestamp)
+			breach.resetAt = &resetAt
+			break
+		}
+	}
+	return breach
+}
+
+func velocityBreachMessage(breach *velocityBreach, amount int64) string {
+	window := string(breach.rule.Window)
+	window = strings.ToUpper(window[:1]) + window[1:]
+	if breach.resetAt == nil {
+		return fmt.Sprintf("%s %s limit of %d %s exceeded, amount %d is above the limit", window, breach.rule.TxnType, breach.rule.MaxAmount, breach.rule.Currency, amount)
+	}
+	return fmt.Sprintf("%s %s limit of %d %s exceeded, %d used, resets at %s", window, breach.rule.TxnType, breach.rule.MaxAmount, breach.rule.Currency, breach.used, breach.resetAt.Format(time.RFC3339))
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockVelocityStore struct {
+	rules   []model.VelocityRule
+	usage   map[string][]model.VelocityUsage
+	retired int
+}
+
+func (m *mockVelocityStore) initializeMockData() {
+	now := time.Now().UTC()
+	m.rules = []model.VelocityRule{
+		{ID: 1, TxnType: model.TypeWithdraw, Currency: "USD", Window: model.VelocityDaily, MaxAmount: 1000},
+		{ID: 2, TxnType: model.TypeWithdraw, Currency: "USD", Window: model.VelocityMonthly, MaxAmount: 5000},
+		{ID: 3, Username: utils.Ptr("MARY"), TxnType: model.TypeWithdraw, Currency: "USD", Window: model.VelocityDaily, MaxAmount: 3000},
+		{ID: 4, TxnType: model.TypeTransfer, Currency: "USD", Window: model.VelocityWeekly, MaxAmount: 2000},
+	}
+	m.usage = map[string][]model.VelocityUsage{
+		"JUAN:withdraw": {
+			{Amount: 400, Timestamp: now.AddDate(0, 0, -3)},
+			{Amount: 300, Timestamp: now.Add(-2 * time.Hour)},
+			{Amount: 200, Timestamp: now.Add(-1 * time.Hour)},
+		},
+		"MARY:withdraw": {
+			{Amount: 2500, Timestamp: now.Add(-1 * time.Hour)},
+		},
+		"PAUL:withdraw": {
+			{Amount: 4800, Timestamp: now.AddDate(0, 0, -10)},
+		},
+		"JUAN:transfer_out": {
+			{Amount: 1500, Timestamp: now.AddDate(0, 0, -2)},
+		},
+		"MARY:transfer_out": {
+			{Amount: 1000, Timestamp: now.AddDate(0, 0, -1)},
+		},
+		"MARY:escrow_fund": {
+			{Amount: 800, Timestamp: now.Add(-3 * time.Hour)},
+		},
+	}
+}
+
+func (m *mockVelocityStore) RetireVelocityRule(ctx context.Context, tx *sql.Tx, username *string, txnType model.TxnType, currency string, window model.VelocityWindow) (int64, error) {
+	m.retired++
+	return 1, nil
+}
+
+func (m *mockVelocityStore) InsertVelocityRule(ctx context.Context, tx *sql.Tx, rule *model.VelocityRule) error {
+	rule.ID = int64(len(m.rules) + 1)
+	m.rules = append(m.rules, *rule)
+	return nil
+}
+
+func (m *mockVelocityStore) FetchVelocityRules(ctx context.Context, username string, txnType string) ([]model.VelocityRule, error) {
+	return m.rules, nil
+}
+
+func (m *mockVelocityStore) FetchApplicableVelocityRules(ctx context.Context, tx *sql.Tx, username string, txnType model.TxnType, currency string) ([]model.VelocityRule, error) {
+	byWindow := map[model.VelocityWindow]model.VelocityRule{}
+	for _, rule := range m.rules {
+		if rule.TxnType != txnType || rule.Currency != currency {
+			continue
+		}
+		if rule.Username != nil && *rule.Username != username {
+			continue
+		}
+		if existing, ok := byWindow[rule.Window]; ok && existing.Username != nil {
+			continue
+		}
+		byWindow[rule.Window] = rule
+	}
+
+	rules := []model.VelocityRule{}
+	for _, rule := range byWindow {
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (m *mockVelocityStore) FetchVelocityUsage(ctx context.Context, tx *sql.Tx, username string, currency string, txnTypes []model.TxnType, since time.Time) ([]model.VelocityUsage, error) {
+	usage := []model.VelocityUsage{}
+	for _, txnType := range txnTypes {
+		for _, u := range m.usage[username+":"+string(txnType)] {
+			if u.Timestamp.After(since) {
+				usage = append(usage, u)
+			}
+		}
+	}
+	return usage, nil
+}
+
+func TestDoCheckVelocity(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name      string
+		txnType   model.TxnType
+		username  string
+		currency  string
+		amount    int64
+		expectErr bool
+	}
+
+	tests := []testCase{
+		{
+			name:      "Successful Check - Within daily limit",
+			txnType:   model.TypeWithdraw,
+			username:  "juan",
+			currency:  "usd",
+			amount:    500,
+			expectErr: false,
+		},
+		{
+			name:      "Successful Check - User rule overrides default daily limit",
+			txnType:   model.TypeWithdraw,
+			username:  "MARY",
+			currency:  "USD",
+			amount:    500,
+			expectErr: false,
+		},
+		{
+			name:      "Successful Check - No rules for currency",
+			txnType:   model.TypeWithdraw,
+			username:  "JUAN",
+			currency:  "EUR",
+			amount:    99999,
+			expectErr: false,
+		},
+		{
+			name:      "Successful Check - Older usage outside daily window",
+			txnType:   model.TypeWithdraw,
+			username:  "PAUL",
+			currency:  "USD",
+			amount:    200,
+			expectErr: false,
+		},
+		{
+			name:      "Failed Check - Daily limit exceeded",
+			txnType:   model.TypeWithdraw,
+			username:  "JUAN",
+			currency:  "USD",
+			amount:    501,
+			expectErr: true,
+		},
+		{
+			name:      "Failed Check - User daily limit exceeded",
+			txnType:   model.TypeWithdraw,
+			username:  "MARY",
+			currency:  "USD",
+			amount:    501,
+			expectErr: true,
+		},
+		{
+			name:      "Failed Check - Monthly limit exceeded",
+			txnType:   model.TypeWithdraw,
+			username:  "PAUL",
+			currency:  "USD",
+			amount:    201,
+			expectErr: true,
+		},
+		{
+			name:      "Failed Check - Weekly transfer limit exceeded",
+			txnType:   model.TypeTransfer,
+			username:  "JUAN",
+			currency:  "USD",
+			amount:    501,
+			expectErr: true,
+		},
+		{
+			name:      "Successful Check - Escrow funding within weekly transfer limit",
+			txnType:   model.TypeTransfer,
+			username:  "MARY",
+			currency:  "USD",
+			amount:    200,
+			expectErr: false,
+		},
+		{
+			name:      "Failed Check - Escrow funding counts toward weekly transfer limit",
+			txnType:   model.TypeTransfer,
+			username:  "MARY",
+			currency:  "USD",
+			amount:    201,
+			expectErr: true,
+		},
+		{
+			name:      "Failed Check - Invalid username",
+			txnType:   model.TypeWithdraw,
+			username:  "",
+			currency:  "USD",
+			amount:    100,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockVelocityStore{}
+			mock.initializeMockData()
+			s := &VelocityService{store: mock}
+
+			err := s.DoCheckVelocity(context.Background(), nil, test.txnType, test.username, test.currency, test.amount)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckVelocityWindow(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC)
+	usage := []model.VelocityUsage{
+		{Amount: 400, Timestamp: time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)},
+		{Amount: 300, Timestamp: time.Date(2025, time.June, 14, 18, 0, 0, 0, time.UTC)},
+		{Amount: 200, Timestamp: time.Date(2025, time.June, 15, 8, 0, 0, 0, time.UTC)},
+	}
+
+	type testCase struct {
+		name            string
+		window          model.VelocityWindow
+		maxAmount       int64
+		amount          int64
+		expectBreach    bool
+		expectedUsed    int64
+		expectedResetAt *time.Time
+	}
+
+	tests := []testCase{
+		{
+			name:         "Within Limit - Daily usage plus amount at limit",
+			window:       model.VelocityDaily,
+			maxAmount:    1000,
+			amount:       500,
+			expectBreach: false,
+		},
+		{
+			name:            "Breached - Resets when oldest daily usage ages out",
+			window:          model.VelocityDaily,
+			maxAmount:       1000,
+			amount:          600,
+			expectBreach:    true,
+			expectedUsed:    500,
+			expectedResetAt: utils.Ptr(time.Date(2025, time.June, 15, 18, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:            "Breached - Resets when enough daily usage ages out",
+			window:          model.VelocityDaily,
+			maxAmount:       1000,
+			amount:          900,
+			expectBreach:    true,
+			expectedUsed:    500,
+			expectedResetAt: utils.Ptr(time.Date(2025, time.June, 16, 8, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:            "Breached - Monthly window counts older usage",
+			window:          model.VelocityMonthly,
+			maxAmount:       1000,
+			amount:          200,
+			expectBreach:    true,
+			expectedUsed:    900,
+			expectedResetAt: utils.Ptr(time.Date(2025, time.June, 20, 9, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:         "Breached - Amount above limit never resets",
+			window:       model.VelocityWeekly,
+			maxAmount:    1000,
+			amount:       1001,
+			expectBreach: true,
+			expectedUsed: 500,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := model.VelocityRule{TxnType: model.TypeWithdraw, Currency: "USD", Window: test.window, MaxAmount: test.maxAmount}
+
+			breach := checkVelocityWindow(rule, usage, test.amount, now)
+
+			if test.expectBreach != (breach != nil) {
+				t.Fatalf("expected breach %t but got %+v instead", test.expectBreach, breach)
+			}
+			if breach == nil {
+				return
+			}
+
+			if breach.used != test.expectedUsed {
+				t.Errorf("expected used %d but got %d instead", test.expectedUsed, breach.used)
+			}
+
+			if (test.expectedResetAt == nil) != (breach.resetAt == nil) {
+				t.Fatalf("expected reset at %v but got %v instead", test.expectedResetAt, breach.resetAt)
+			}
+
+			if test.expectedResetAt != nil && !breach.resetAt.Equal(*test.expectedResetAt) {
+				t.Errorf("expected reset at %s but got %s instead", test.expectedResetAt, breach.resetAt)
+			}
+		})
+	}
+}
+
+func TestDoSetVelocityRule(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name         string
+		payload      request.VelocityRulePayload
+		expectRule   bool
+		expectedCode validation.WalletErrorCode
+		expectErr    bool
+	}
+
+	tests := []testCase{
+		{
+			name:       "Successful Rule - Default daily withdraw limit",
+			payload:    request.VelocityRulePayload{TxnType: "withdraw", Currency: "usd", Window: "daily", MaxAmount: utils.Ptr(int64(1000))},
+			expectRule: true,
+			expectErr:  false,
+		},
+		{
+			name:       "Successful Rule - User monthly transfer limit",
+			payload:    request.VelocityRulePayload{Username: utils.Ptr("juan"), TxnType: "transfer", Currency: "USD", Window: "monthly", MaxAmount: utils.Ptr(int64(5000))},
+			expectRule: true,
+			expectErr:  false,
+		},
+		{
+			name:       "Successful Rule - Removed without max amount",
+			payload:    request.VelocityRulePayload{TxnType: "withdraw", Currency: "USD", Window: "weekly"},
+			expectRule: false,
+			expectErr:  false,
+		},
+		{
+			name:         "Failed Rule - Unsupported transaction type",
+			payload:      request.VelocityRulePayload{TxnType: "deposit", Currency: "USD", Window: "daily", MaxAmount: utils.Ptr(int64(1000))},
+			expectedCode: validation.ERR_VELOCITY_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Rule - Unsupported window",
+			payload:      request.VelocityRulePayload{TxnType: "withdraw", Currency: "USD", Window: "hourly", MaxAmount: utils.Ptr(int64(1000))},
+			expectedCode: validation.ERR_VELOCITY_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Rule - Zero max amount",
+			payload:      request.VelocityRulePayload{TxnType: "withdraw", Currency: "USD", Window: "daily", MaxAmount: utils.Ptr(int64(0))},
+			expectedCode: validation.ERR_VELOCITY_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Rule - Unsupported currency",
+			payload:      request.VelocityRulePayload{TxnType: "withdraw", Currency: "XXX", Window: "daily", MaxAmount: utils.Ptr(int64(1000))},
+			expectedCode: validation.ERR_VELOCITY_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Rule - Reserved system username",
+			payload:      request.VelocityRulePayload{Username: utils.Ptr(model.FeeWallet), TxnType: "withdraw", Currency: "USD", Window: "daily", MaxAmount: utils.Ptr(int64(1000))},
+			expectedCode: validation.ERR_VELOCITY_RULE_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockVelocityStore{}
+			s := &VelocityService{store: mock}
+
+			rule, err := s.DoSetVelocityRule(context.Background(), nil, &test.payload)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && test.expectRule != (rule != nil) {
+				t.Errorf("expected rule %t but got %+v instead", test.expectRule, rule)
+			}
+
+			if !test.expectErr && mock.retired != 1 {
+				t.Errorf("expected active rule to be retired once but got %d instead", mock.retired)
+			}
+		})
+	}
+}
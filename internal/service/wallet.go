@@ -2,32 +2,172 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
-	"github.com/ezjuanify/wallet/internal/db"
 	"github.com/ezjuanify/wallet/internal/logger"
 	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
 	"github.com/ezjuanify/wallet/internal/validation"
 	"go.uber.org/zap"
 )
 
+type WalletStore interface {
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	FetchAllWallet(ctx context.Context) ([]model.Wallet, error)
+	InsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, limits model.WalletLimits) (*model.Wallet, error)
+}
+
 type WalletService struct {
-	store *db.Store
+	store WalletStore
 }
 
-func NewWalletService(store *db.Store) *WalletService {
+func NewWalletService(store WalletStore) *WalletService {
 	return &WalletService{store: store}
 }
 
-func (s *WalletService) DoFetchWallet(ctx context.Context, username string) (*model.Wallet, *validation.WalletError) {
+func (s *WalletService) DoOpenWallet(ctx context.Context, tx *sql.Tx, payload *request.OpenWalletPayload) (*model.Wallet, *validation.WalletError) {
+	fnName := "WalletService.DoOpenWallet"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.Any("payload", payload))
+
+	username, err := validation.SanitizeAndValidateUsername(payload.Username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", payload.Username),
+			},
+		}
+	}
+	if validation.IsReservedUsername(username) {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "Username is reserved for system wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s uses reserved prefix %s", username, model.SystemUsernamePrefix),
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
+
+	currency, err := validation.SanitizeAndValidateCurrency(payload.Currency)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", payload.Currency),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	limits := model.DefaultWalletLimits(currency)
+	if payload.MinAmount != nil {
+		limits.MinAmount = *payload.MinAmount
+	}
+	if payload.MaxAmount != nil {
+		limits.MaxAmount = *payload.MaxAmount
+	}
+	if payload.MaxBalance != nil {
+		limits.MaxBalance = *payload.MaxBalance
+	}
+	if err := validateWalletLimits(limits, 0); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_LIMITS_INVALID,
+			Message:   "Wallet limits validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.Any("limits", limits),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet limits validated", fnName), zap.Any("limits", limits))
+
+	wallet, err := s.store.InsertWallet(ctx, tx, username, currency.Code, limits)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_OPEN_WALLET_FAILED,
+			Message:   "Failed to open wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if wallet == nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_ALREADY_EXISTS,
+			Message:   "Wallet already exists",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("user %s already has a %s wallet", username, currency.Code),
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Wallet opened", fnName), zap.Any("wallet", wallet))
+	return wallet, nil
+}
+
+func (s *WalletService) DoFetchWallet(ctx context.Context, username string, currencyCode string, pocketName string) (*model.Wallet, *validation.WalletError) {
 	fnName := "WalletService.DoFetchWallet"
-	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username))
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode), zap.String("pocket", pocketName))
 
 	username = validation.SanitizeUsernameWithoutError(username)
 	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
 
-	wallet, err := s.store.FetchWallet(ctx, username)
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", currencyCode),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	pocket, err := validation.SanitizeAndValidatePocket(pocketName)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", pocketName),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket validated", fnName), zap.String("pocket", pocket))
+
+	wallet, err := s.store.FetchWallet(ctx, username, currency.Code, pocket)
 	if err != nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
@@ -37,6 +177,8 @@ func (s *WalletService) DoFetchWallet(ctx context.Context, username string) (*mo
 			Err:       err,
 			Context: []zap.Field{
 				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("pocket", pocket),
 			},
 		}
 	}
@@ -50,6 +192,8 @@ func (s *WalletService) DoFetchWallet(ctx context.Context, username string) (*mo
 			Err:       nil,
 			Context: []zap.Field{
 				zap.String("username", username),
+				zap.String("currency", currency.Code),
+				zap.String("pocket", pocket),
 			},
 		}
 	}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+	"github.com/ezjuanify/wallet/internal/validation"
+)
+
+type mockWalletStore struct {
+	wallets map[string]model.Wallet
+}
+
+func (m *mockWalletStore) initializeMockWallet() {
+	m.wallets = map[string]model.Wallet{
+		"JUAN:USD": {
+			Username:  "JUAN",
+			Currency:  "USD",
+			Pocket:    model.DefaultPocket,
+			Status:    model.WalletActive,
+			OpenedVia: model.WalletOpenedLegacy,
+			Limits:    defaultMockLimits("USD"),
+			Balance:   2000,
+		},
+		"MARY:USD": {
+			Username:  "MARY",
+			Currency:  "USD",
+			Pocket:    model.DefaultPocket,
+			Status:    model.WalletClosed,
+			OpenedVia: model.WalletOpenedExplicit,
+			Limits:    defaultMockLimits("USD"),
+		},
+	}
+}
+
+func (m *mockWalletStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
+	w, ok := m.wallets[username+":"+currency]
+	if !ok || pocket != model.DefaultPocket {
+		return nil, nil
+	}
+	return &w, nil
+}
+
+func (m *mockWalletStore) FetchAllWallet(ctx context.Context) ([]model.Wallet, error) {
+	wallets := []model.Wallet{}
+	for _, w := range m.wallets {
+		wallets = append(wallets, w)
+	}
+	return wallets, nil
+}
+
+func (m *mockWalletStore) InsertWallet(ctx context.Context, tx *sql.Tx, username string, currency string, limits model.WalletLimits) (*model.Wallet, error) {
+	key := username + ":" + currency
+	if _, ok := m.wallets[key]; ok {
+		return nil, nil
+	}
+	w := model.Wallet{
+		Username:  username,
+		Currency:  currency,
+		Pocket:    model.DefaultPocket,
+		Status:    model.WalletActive,
+		OpenedVia: model.WalletOpenedExplicit,
+		Limits:    limits,
+	}
+	m.wallets[key] = w
+	return &w, nil
+}
+
+func TestDoOpenWallet(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name             string
+		payload          request.OpenWalletPayload
+		expectedUsername string
+		expectedCurrency string
+		expectedLimits   *model.WalletLimits
+		expectedCode     validation.WalletErrorCode
+		expectErr        bool
+	}
+
+	tests := []testCase{
+		{
+			name:             "Successful Open - Default currency and limits",
+			payload:          request.OpenWalletPayload{Username: " mary_ann "},
+			expectedUsername: "MARY_ANN",
+			expectedCurrency: "USD",
+			expectedLimits:   utils.Ptr(defaultMockLimits("USD")),
+			expectErr:        false,
+		},
+		{
+			name:             "Successful Open - Second currency for existing user",
+			payload:          request.OpenWalletPayload{Username: "juan", Currency: "eur"},
+			expectedUsername: "JUAN",
+			expectedCurrency: "EUR",
+			expectedLimits:   utils.Ptr(defaultMockLimits("EUR")),
+			expectErr:        false,
+		},
+		{
+			name:             "Successful Open - Custom limits",
+			payload:          request.OpenWalletPayload{Username: "pedro", Currency: "USD", MaxAmount: utils.Ptr(int64(5000)), MaxBalance: utils.Ptr(int64(20000))},
+			expectedUsername: "PEDRO",
+			expectedCurrency: "USD",
+			expectedLimits:   &model.WalletLimits{MinAmount: defaultMockLimits("USD").MinAmount, MaxAmount: 5000, MaxBalance: 20000},
+			expectErr:        false,
+		},
+		{
+			name:         "Failed Open - Wallet already exists",
+			payload:      request.OpenWalletPayload{Username: "JUAN", Currency: "USD"},
+			expectedCode: validation.ERR_WALLET_ALREADY_EXISTS,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Open - Closed wallet cannot be reopened",
+			payload:      request.OpenWalletPayload{Username: "mary"},
+			expectedCode: validation.ERR_WALLET_ALREADY_EXISTS,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Open - Invalid username",
+			payload:      request.OpenWalletPayload{Username: "j@an"},
+			expectedCode: validation.ERR_SANITIZE_USERNAME_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Open - Reserved system username",
+			payload:      request.OpenWalletPayload{Username: "sys_fees"},
+			expectedCode: validation.ERR_RESERVED_USERNAME,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Open - Unsupported currency",
+			payload:      request.OpenWalletPayload{Username: "pedro", Currency: "XYZ"},
+			expectedCode: validation.ERR_CURRENCY_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Open - Max amount below min amount",
+			payload:      request.OpenWalletPayload{Username: "pedro", MinAmount: utils.Ptr(int64(500)), MaxAmount: utils.Ptr(int64(100))},
+			expectedCode: validation.ERR_WALLET_LIMITS_INVALID,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockWalletStore{}
+			mock.initializeMockWallet()
+			s := &WalletService{store: mock}
+
+			actual, err := s.DoOpenWallet(context.Background(), nil, &test.payload)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && actual != nil && actual.Username != test.expectedUsername {
+				t.Errorf("expected username %s but got %s instead", test.expectedUsername, actual.Username)
+			}
+
+			if !test.expectErr && actual != nil && actual.Currency != test.expectedCurrency {
+				t.Errorf("expected currency %s but got %s instead", test.expectedCurrency, actual.Currency)
+			}
+
+			if !test.expectErr && actual != nil && actual.OpenedVia != model.WalletOpenedExplicit {
+				t.Errorf("expected openedVia %s but got %s instead", model.WalletOpenedExplicit, actual.OpenedVia)
+			}
+
+			if !test.expectErr && actual != nil && test.expectedLimits != nil && actual.Limits != *test.expectedLimits {
+				t.Errorf("expected limits %+v but got %+v instead", *test.expectedLimits, actual.Limits)
+			}
+		})
+	}
+}
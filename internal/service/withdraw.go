@@ -13,22 +13,51 @@ import (
 )
 
 type WithdrawStore interface {
-	WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, amount int64) (*model.Wallet, error)
-	FetchWallet(ctx context.Context, username string) (*model.Wallet, error)
+	WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error)
+	FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error)
+	FetchWalletMembers(ctx context.Context, walletUsername string) ([]model.WalletMember, error)
+	FetchSigningRule(ctx context.Context, walletUsername string, currency string) (*model.SigningRule, error)
 }
 
 type WithdrawService struct {
-	store WithdrawStore
+	store    WithdrawStore
+	journal  *JournalService
+	velocity *VelocityService
 }
 
-func NewWithdrawService(store WithdrawStore) *WithdrawService {
+func NewWithdrawService(store WithdrawStore, journal *JournalService, velocity *VelocityService) *WithdrawService {
 	logger.Debug("Initializing WithdrawService")
-	return &WithdrawService{store: store}
+	return &WithdrawService{store: store, journal: journal, velocity: velocity}
 }
 
-func (s *WithdrawService) DoWithdraw(ctx context.Context, tx *sql.Tx, username string, amount int64) (*model.Wallet, *validation.WalletError) {
+// DoPostedWithdraw checks withdraw velocity limits, debits the wallet and
+// posts it against SYSTEM_CASH in one step. DoWithdraw on its own is only for
+// callers that post the debit as one leg of a larger entry and check their
+// own limits.
+func (s *WithdrawService) DoPostedWithdraw(ctx context.Context, tx *sql.Tx, username string, currencyCode string, pocketName string, amount int64) (*model.Wallet, *model.JournalEntry, *validation.WalletError) {
+	fnName := "WithdrawService.DoPostedWithdraw"
+
+	if appErr := s.velocity.DoCheckVelocity(ctx, tx, model.TypeWithdraw, username, currencyCode, amount); appErr != nil {
+		return nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Velocity limits checked", fnName))
+
+	wallet, appErr := s.DoWithdraw(ctx, tx, username, currencyCode, pocketName, amount)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	entry, appErr := s.journal.PostWithdraw(ctx, tx, wallet, amount)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	logger.Info(fmt.Sprintf("%s - Journal entry posted", fnName), zap.Int64("entryID", entry.ID))
+	return wallet, entry, nil
+}
+
+func (s *WithdrawService) DoWithdraw(ctx context.Context, tx *sql.Tx, username string, currencyCode string, pocketName string, amount int64) (*model.Wallet, *validation.WalletError) {
 	fnName := "WithdrawService.DoWithdraw"
-	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.Int64("amount", amount))
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("username", username), zap.String("currency", currencyCode), zap.String("pocket", pocketName), zap.Int64("amount", amount))
 	username, err := validation.SanitizeAndValidateUsername(username)
 	if err != nil {
 		return nil, &validation.WalletError{
@@ -44,21 +73,50 @@ func (s *WithdrawService) DoWithdraw(ctx context.Context, tx *sql.Tx, username s
 	}
 	logger.Info(fmt.Sprintf("%s - Username sanitized", fnName), zap.String("username", username))
 
-	if err := validation.ValidateAmount(amount); err != nil {
+	if validation.IsReservedUsername(username) {
 		return nil, &validation.WalletError{
 			Name:      fnName,
-			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
-			Message:   "Amount validation failed",
+			Code:      validation.ERR_RESERVED_USERNAME,
+			Message:   "Username is reserved for system wallets",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("username %s uses reserved prefix %s", username, model.SystemUsernamePrefix),
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
 			Timestamp: time.Now().UTC(),
 			Err:       err,
 			Context: []zap.Field{
-				zap.Int64("amount", amount),
+				zap.String("currency", currencyCode),
 			},
 		}
 	}
-	logger.Info(fmt.Sprintf("%s - Amount validated", fnName), zap.Int64("amount", amount))
+	logger.Info(fmt.Sprintf("%s - Currency validated", fnName), zap.String("currency", currency.Code))
+
+	pocket, err := validation.SanitizeAndValidatePocket(pocketName)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_POCKET_VALIDATION_FAILED,
+			Message:   "Pocket validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("pocket", pocketName),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Pocket validated", fnName), zap.String("pocket", pocket))
 
-	currentWallet, err := s.store.FetchWallet(ctx, username)
+	currentWallet, err := s.store.FetchWallet(ctx, username, currency.Code, pocket)
 	if err != nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
@@ -69,6 +127,33 @@ func (s *WithdrawService) DoWithdraw(ctx context.Context, tx *sql.Tx, username s
 			Context:   nil,
 		}
 	}
+	if currentWallet != nil {
+		if appErr := checkWalletStatus(fnName, currentWallet, true); appErr != nil {
+			return nil, appErr
+		}
+		logger.Info(fmt.Sprintf("%s - Wallet status checked", fnName), zap.String("status", string(currentWallet.Status)))
+	}
+
+	limits := model.DefaultWalletLimits(currency)
+	if currentWallet != nil {
+		limits = currentWallet.Limits
+	}
+	if err := validation.ValidateWalletAmount(amount, limits, currency.Code); err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_AMOUNT_VALIDATION_FAILED,
+			Message:   "Amount validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.Int64("amount", amount),
+				zap.String("currency", currency.Code),
+				zap.Any("limits", limits),
+			},
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Amount validated", fnName), zap.Int64("amount", amount))
+
 	if currentWallet == nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
@@ -78,12 +163,13 @@ func (s *WithdrawService) DoWithdraw(ctx context.Context, tx *sql.Tx, username s
 			Err:       nil,
 			Context: []zap.Field{
 				zap.String("username", username),
+				zap.String("currency", currency.Code),
 			},
 		}
 	}
 
-	newBalance := currentWallet.Balance - amount
-	if err := validation.ValidateWalletBalance(newBalance); err != nil {
+	newBalance := currentWallet.Balance - currentWallet.HeldBalance - amount
+	if err := validation.ValidateWalletBalance(newBalance, currentWallet); err != nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
 			Code:      validation.ERR_WALLET_BALANCE_VALIDATION_FAILED,
@@ -93,6 +179,8 @@ func (s *WithdrawService) DoWithdraw(ctx context.Context, tx *sql.Tx, username s
 			Context: []zap.Field{
 				zap.String("username", username),
 				zap.Int64("balance", currentWallet.Balance),
+				zap.Int64("held", currentWallet.HeldBalance),
+				zap.Int64("creditLimit", currentWallet.CreditLimit),
 				zap.Int64("amount", amount),
 				zap.Int64("resulting", newBalance),
 			},
@@ -101,11 +189,12 @@ func (s *WithdrawService) DoWithdraw(ctx context.Context, tx *sql.Tx, username s
 	logger.Info(
 		fmt.Sprintf("%s - Wallet balance validated", fnName),
 		zap.Int64("wallet_balance", currentWallet.Balance),
+		zap.Int64("available_balance", currentWallet.AvailableBalance),
 		zap.Int64("amount", amount),
-		zap.Int64("resulting_balance", newBalance),
+		zap.Int64("resulting_available_balance", currentWallet.AvailableBalance-amount),
 	)
 
-	updatedWallet, err := s.store.WithdrawWallet(ctx, tx, username, amount)
+	updatedWallet, err := s.store.WithdrawWallet(ctx, tx, username, currency.Code, pocket, amount)
 	if err != nil {
 		return nil, &validation.WalletError{
 			Name:      fnName,
@@ -122,3 +211,136 @@ func (s *WithdrawService) DoWithdraw(ctx context.Context, tx *sql.Tx, username s
 	logger.Info(fmt.Sprintf("%s - Withdrawn from wallet", fnName), zap.Any("wallet", updatedWallet))
 	return updatedWallet, nil
 }
+
+func (s *WithdrawService) DoAuthorizeSpend(ctx context.Context, actor string, username string, currencyCode string, amount int64) (*model.SpendAuthorization, *validation.WalletError) {
+	fnName := "WithdrawService.DoAuthorizeSpend"
+	logger.Info(fmt.Sprintf("%s - Params received", fnName), zap.String("actor", actor), zap.String("username", username), zap.String("currency", currencyCode), zap.Int64("amount", amount))
+
+	username, err := validation.SanitizeAndValidateUsername(username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+			Message:   "Username validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	actingUser := username
+	if actor != "" {
+		actingUser, err = validation.SanitizeAndValidateUsername(actor)
+		if err != nil {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_SANITIZE_USERNAME_FAILED,
+				Message:   "Actor validation failed",
+				Timestamp: time.Now().UTC(),
+				Err:       err,
+				Context: []zap.Field{
+					zap.String("actor", actor),
+				},
+			}
+		}
+	}
+	logger.Info(fmt.Sprintf("%s - Acting user resolved", fnName), zap.String("actor", actingUser))
+
+	currency, err := validation.SanitizeAndValidateCurrency(currencyCode)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_CURRENCY_VALIDATION_FAILED,
+			Message:   "Currency validation failed",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("currency", currencyCode),
+			},
+		}
+	}
+
+	members, err := s.store.FetchWalletMembers(ctx, username)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_ACCESS_FAILED,
+			Message:   "Failed to fetch wallet members",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+			},
+		}
+	}
+
+	auth := &model.SpendAuthorization{Actor: actingUser, Username: username, RequiredApprovals: 1}
+	if len(members) == 0 {
+		if actingUser != username {
+			return nil, &validation.WalletError{
+				Name:      fnName,
+				Code:      validation.ERR_WALLET_ACCESS_DENIED,
+				Message:   "Only the wallet owner can spend from a personal wallet",
+				Timestamp: time.Now().UTC(),
+				Err:       fmt.Errorf("%s cannot spend from %s", actingUser, username),
+			}
+		}
+		logger.Info(fmt.Sprintf("%s - Personal wallet spend authorized", fnName), zap.Any("authorization", auth))
+		return auth, nil
+	}
+
+	auth.Joint = true
+	for _, member := range members {
+		if member.Member == actingUser {
+			auth.Role = member.Role
+		}
+	}
+	switch {
+	case actingUser == username:
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_ACCESS_DENIED,
+			Message:   "An acting member is required to spend from a joint wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("no actor given for joint wallet %s", username),
+		}
+	case auth.Role == "":
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_ACCESS_DENIED,
+			Message:   "Acting user is not a member of the joint wallet",
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("%s is not a member of %s", actingUser, username),
+		}
+	case !auth.Role.CanSpend():
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_WALLET_ACCESS_DENIED,
+			Message:   fmt.Sprintf("Members with the %s role cannot spend", auth.Role),
+			Timestamp: time.Now().UTC(),
+			Err:       fmt.Errorf("%s is a %s of %s", actingUser, auth.Role, username),
+		}
+	}
+
+	rule, err := s.store.FetchSigningRule(ctx, username, currency.Code)
+	if err != nil {
+		return nil, &validation.WalletError{
+			Name:      fnName,
+			Code:      validation.ERR_FETCH_WALLET_ACCESS_FAILED,
+			Message:   "Failed to fetch signing rule",
+			Timestamp: time.Now().UTC(),
+			Err:       err,
+			Context: []zap.Field{
+				zap.String("username", username),
+				zap.String("currency", currency.Code),
+			},
+		}
+	}
+	if rule != nil && amount > rule.ApprovalThreshold {
+		auth.RequiredApprovals = rule.RequiredApprovals
+	}
+	logger.Info(fmt.Sprintf("%s - Joint wallet spend authorized", fnName), zap.Any("authorization", auth))
+	return auth, nil
+}
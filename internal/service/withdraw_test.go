@@ -9,6 +9,7 @@ import (
 
 	"github.com/ezjuanify/wallet/internal/logger"
 	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/validation"
 )
 
 type mockWithdrawStore struct {
@@ -19,48 +20,151 @@ func (m *mockWithdrawStore) initializeMockWallet() {
 	m.wallets = map[string]model.Wallet{
 		"JUAN": {
 			Username: "JUAN",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("USD"),
 			Balance:  2000,
 		},
 		"J_U_A_N": {
 			Username: "J_U_A_N",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("USD"),
 			Balance:  7000,
 		},
 		"J123": {
 			Username: "J123",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("USD"),
 			Balance:  5000,
 		},
 		"J_123": {
 			Username: "J_123",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("USD"),
 			Balance:  999999,
 		},
+		"J_KWD": {
+			Username: "J_KWD",
+			Currency: "KWD",
+			Status:   model.WalletActive,
+			Limits:   defaultMockLimits("KWD"),
+			Balance:  200000,
+		},
+		"J_LIMIT": {
+			Username: "J_LIMIT",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Limits:   model.WalletLimits{MinAmount: 100, MaxAmount: 500, MaxBalance: 3000},
+			Balance:  2000,
+		},
+		"J_HELD": {
+			Username:    "J_HELD",
+			Currency:    "USD",
+			Status:      model.WalletActive,
+			Limits:      defaultMockLimits("USD"),
+			Balance:     5000,
+			HeldBalance: 3000,
+		},
+		"J_CREDIT": {
+			Username:    "J_CREDIT",
+			Currency:    "USD",
+			Status:      model.WalletActive,
+			Limits:      defaultMockLimits("USD"),
+			Balance:     1000,
+			CreditLimit: 500,
+		},
+		"J_POCKET": {
+			Username: "J_POCKET",
+			Currency: "USD",
+			Status:   model.WalletActive,
+			Pocket:   "SAVINGS",
+			Limits:   defaultMockLimits("USD"),
+			Balance:  400,
+		},
+		"J_FROZEN": {
+			Username: "J_FROZEN",
+			Currency: "USD",
+			Status:   model.WalletFrozenDebit,
+			Limits:   defaultMockLimits("USD"),
+			Balance:  1000,
+		},
+		"J_LOCKED": {
+			Username: "J_LOCKED",
+			Currency: "USD",
+			Status:   model.WalletFrozenAll,
+			Limits:   defaultMockLimits("USD"),
+			Balance:  1000,
+		},
+		"J_CLOSED": {
+			Username: "J_CLOSED",
+			Currency: "USD",
+			Status:   model.WalletClosed,
+			Limits:   defaultMockLimits("USD"),
+		},
 	}
 }
 
-func (m *mockWithdrawStore) WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, amount int64) (*model.Wallet, error) {
+func (m *mockWithdrawStore) WithdrawWallet(ctx context.Context, tx *sql.Tx, username string, currency string, pocket string, amount int64) (*model.Wallet, error) {
 	currentTimestamp := time.Now().UTC()
 	w, ok := m.wallets[username]
-	if !ok {
+	if !ok || w.Currency != currency || mockPocket(w) != pocket {
 		return nil, fmt.Errorf("Test Withdraw - No wallet found")
 	}
+	if w.Balance-w.HeldBalance+w.CreditLimit < amount {
+		return nil, fmt.Errorf("Test Withdraw - Insufficient available balance")
+	}
 	return &model.Wallet{
 		Username:            w.Username,
+		Currency:            w.Currency,
+		Pocket:              mockPocket(w),
 		Balance:             w.Balance - amount,
+		HeldBalance:         w.HeldBalance,
+		AvailableBalance:    w.Balance - w.HeldBalance + w.CreditLimit - amount,
+		CreditLimit:         w.CreditLimit,
 		LastWithdrawAmount:  &amount,
 		LastWithdrawUpdated: &currentTimestamp,
 	}, nil
 }
 
-func (m *mockWithdrawStore) FetchWallet(ctx context.Context, username string) (*model.Wallet, error) {
+func (m *mockWithdrawStore) FetchWallet(ctx context.Context, username string, currency string, pocket string) (*model.Wallet, error) {
 	w, ok := m.wallets[username]
-	if !ok {
+	if !ok || w.Currency != currency || mockPocket(w) != pocket {
 		return nil, nil
 	}
 	return &model.Wallet{
-		Username: w.Username,
-		Balance:  w.Balance,
+		Username:         w.Username,
+		Currency:         w.Currency,
+		Pocket:           mockPocket(w),
+		Status:           w.Status,
+		Balance:          w.Balance,
+		HeldBalance:      w.HeldBalance,
+		AvailableBalance: w.Balance - w.HeldBalance + w.CreditLimit,
+		CreditLimit:      w.CreditLimit,
+		Limits:           w.Limits,
 	}, nil
 }
 
+func (m *mockWithdrawStore) FetchWalletMembers(ctx context.Context, walletUsername string) ([]model.WalletMember, error) {
+	if walletUsername != "J_JOINT" {
+		return []model.WalletMember{}, nil
+	}
+	return []model.WalletMember{
+		{WalletUsername: "J_JOINT", Member: "JUAN", Role: model.RoleOwner},
+		{WalletUsername: "J_JOINT", Member: "MARY", Role: model.RoleSpender},
+		{WalletUsername: "J_JOINT", Member: "PEDRO", Role: model.RoleViewer},
+	}, nil
+}
+
+func (m *mockWithdrawStore) FetchSigningRule(ctx context.Context, walletUsername string, currency string) (*model.SigningRule, error) {
+	if walletUsername != "J_JOINT" || currency != "USD" {
+		return nil, nil
+	}
+	return &model.SigningRule{WalletUsername: "J_JOINT", Currency: "USD", ApprovalThreshold: 1000, RequiredApprovals: 2}, nil
+}
+
 func TestDoWithdraw(t *testing.T) {
 	logger.InitLogger()
 	defer logger.Sync()
@@ -68,6 +172,8 @@ func TestDoWithdraw(t *testing.T) {
 	type testCase struct {
 		name           string
 		username       string
+		currency       string
+		pocket         string
 		amount         int64
 		expectedWallet *model.Wallet
 		expectErr      bool
@@ -80,6 +186,7 @@ func TestDoWithdraw(t *testing.T) {
 			amount:   1000,
 			expectedWallet: &model.Wallet{
 				Username: "JUAN",
+				Currency: "USD",
 				Balance:  1000,
 			},
 			expectErr: false,
@@ -90,6 +197,7 @@ func TestDoWithdraw(t *testing.T) {
 			amount:   500,
 			expectedWallet: &model.Wallet{
 				Username: "JUAN",
+				Currency: "USD",
 				Balance:  1500,
 			},
 			expectErr: false,
@@ -100,6 +208,7 @@ func TestDoWithdraw(t *testing.T) {
 			amount:   800,
 			expectedWallet: &model.Wallet{
 				Username: "J_U_A_N",
+				Currency: "USD",
 				Balance:  6200,
 			},
 			expectErr: false,
@@ -110,6 +219,7 @@ func TestDoWithdraw(t *testing.T) {
 			amount:   4999,
 			expectedWallet: &model.Wallet{
 				Username: "J123",
+				Currency: "USD",
 				Balance:  1,
 			},
 			expectErr: false,
@@ -120,6 +230,7 @@ func TestDoWithdraw(t *testing.T) {
 			amount:   999999,
 			expectedWallet: &model.Wallet{
 				Username: "J_123",
+				Currency: "USD",
 				Balance:  0,
 			},
 			expectErr: false,
@@ -130,11 +241,125 @@ func TestDoWithdraw(t *testing.T) {
 			amount:   500,
 			expectedWallet: &model.Wallet{
 				Username: "JUAN",
+				Currency: "USD",
 				Balance:  1500,
 			},
 			expectErr: false,
 		},
-
+		{
+			name:     "Successful Withdraw - Non-default currency",
+			username: "j_kwd",
+			currency: "kwd",
+			amount:   150000,
+			expectedWallet: &model.Wallet{
+				Username: "J_KWD",
+				Currency: "KWD",
+				Balance:  50000,
+			},
+			expectErr: false,
+		},
+		{
+			name:     "Successful Withdraw - Up to available balance",
+			username: "J_HELD",
+			amount:   2000,
+			expectedWallet: &model.Wallet{
+				Username: "J_HELD",
+				Currency: "USD",
+				Balance:  3000,
+			},
+			expectErr: false,
+		},
+		{
+			name:     "Successful Withdraw - Named pocket",
+			username: "J_POCKET",
+			pocket:   "savings",
+			amount:   150,
+			expectedWallet: &model.Wallet{
+				Username: "J_POCKET",
+				Currency: "USD",
+				Pocket:   "SAVINGS",
+				Balance:  250,
+			},
+			expectErr: false,
+		},
+		{
+			name:           "Failed Withdraw - Named pocket from default pocket",
+			username:       "J_POCKET",
+			amount:         150,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Withdraw - Amount covered by balance but not available balance",
+			username:       "J_HELD",
+			amount:         2001,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:     "Successful Withdraw - Into overdraft up to credit limit",
+			username: "J_CREDIT",
+			amount:   1500,
+			expectedWallet: &model.Wallet{
+				Username: "J_CREDIT",
+				Currency: "USD",
+				Balance:  -500,
+			},
+			expectErr: false,
+		},
+		{
+			name:           "Failed Withdraw - Amount exceeding credit limit",
+			username:       "J_CREDIT",
+			amount:         1501,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:     "Successful Withdraw - Within per-wallet limits",
+			username: "J_LIMIT",
+			amount:   500,
+			expectedWallet: &model.Wallet{
+				Username: "J_LIMIT",
+				Currency: "USD",
+				Balance:  1500,
+			},
+			expectErr: false,
+		},
+		{
+			name:           "Failed Withdraw - Debit frozen wallet",
+			username:       "j_frozen",
+			amount:         100,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Withdraw - Fully frozen wallet",
+			username:       "j_locked",
+			amount:         100,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Withdraw - Closed wallet",
+			username:       "j_closed",
+			amount:         100,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Withdraw - Below per-wallet minimum amount",
+			username:       "J_LIMIT",
+			amount:         99,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Withdraw - Above per-wallet maximum amount",
+			username:       "J_LIMIT",
+			amount:         501,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
 		{
 			name:           "Failed Withdraw - Wallet not found",
 			username:       "G12345",
@@ -142,6 +367,29 @@ func TestDoWithdraw(t *testing.T) {
 			expectedWallet: nil,
 			expectErr:      true,
 		},
+		{
+			name:           "Failed Withdraw - Reserved system username",
+			username:       "SYS_ESCROW",
+			amount:         1000,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Withdraw - Wallet not found in currency",
+			username:       "JUAN",
+			currency:       "EUR",
+			amount:         1000,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
+		{
+			name:           "Failed Withdraw - Amount exceeding currency limit",
+			username:       "J_KWD",
+			currency:       "KWD",
+			amount:         300000,
+			expectedWallet: nil,
+			expectErr:      true,
+		},
 		{
 			name:           "Failed Withdraw - Username with illegal special characters",
 			username:       "J@123",
@@ -185,7 +433,7 @@ func TestDoWithdraw(t *testing.T) {
 			mock.initializeMockWallet()
 			s := &WithdrawService{store: mock}
 
-			actual, err := s.DoWithdraw(context.Background(), nil, test.username, test.amount)
+			actual, err := s.DoWithdraw(context.Background(), nil, test.username, test.currency, test.pocket, test.amount)
 
 			if test.expectErr && err == nil {
 				t.Errorf("expected error but got nil")
@@ -199,6 +447,14 @@ func TestDoWithdraw(t *testing.T) {
 				t.Errorf("expected username %s but got %s instead", test.expectedWallet.Username, actual.Username)
 			}
 
+			if !test.expectErr && actual != nil && test.expectedWallet.Currency != actual.Currency {
+				t.Errorf("expected currency %s but got %s instead", test.expectedWallet.Currency, actual.Currency)
+			}
+
+			if !test.expectErr && actual != nil && test.expectedWallet.Pocket != "" && test.expectedWallet.Pocket != actual.Pocket {
+				t.Errorf("expected pocket %s but got %s instead", test.expectedWallet.Pocket, actual.Pocket)
+			}
+
 			if !test.expectErr && actual != nil && test.expectedWallet.Balance != actual.Balance {
 				t.Errorf("expected balance %d but got %d instead", test.expectedWallet.Balance, actual.Balance)
 			}
@@ -213,3 +469,163 @@ func TestDoWithdraw(t *testing.T) {
 		})
 	}
 }
+
+func TestDoAuthorizeSpend(t *testing.T) {
+	logger.InitLogger()
+	defer logger.Sync()
+
+	type testCase struct {
+		name              string
+		actor             string
+		username          string
+		currency          string
+		amount            int64
+		expectedActor     string
+		expectedJoint     bool
+		expectedApprovals int
+		expectedCode      validation.WalletErrorCode
+		expectErr         bool
+	}
+
+	tests := []testCase{
+		{
+			name:              "Successful Authorize - Personal wallet without actor",
+			username:          "juan",
+			currency:          "USD",
+			amount:            5000,
+			expectedActor:     "JUAN",
+			expectedApprovals: 1,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Authorize - Personal wallet with self as actor",
+			actor:             " juan ",
+			username:          "JUAN",
+			currency:          "USD",
+			amount:            5000,
+			expectedActor:     "JUAN",
+			expectedApprovals: 1,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Authorize - Joint owner under threshold",
+			actor:             "juan",
+			username:          "j_joint",
+			currency:          "usd",
+			amount:            1000,
+			expectedActor:     "JUAN",
+			expectedJoint:     true,
+			expectedApprovals: 1,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Authorize - Joint spender above threshold",
+			actor:             "MARY",
+			username:          "J_JOINT",
+			currency:          "USD",
+			amount:            1001,
+			expectedActor:     "MARY",
+			expectedJoint:     true,
+			expectedApprovals: 2,
+			expectErr:         false,
+		},
+		{
+			name:              "Successful Authorize - Joint wallet without rule for currency",
+			actor:             "MARY",
+			username:          "J_JOINT",
+			currency:          "EUR",
+			amount:            100000,
+			expectedActor:     "MARY",
+			expectedJoint:     true,
+			expectedApprovals: 1,
+			expectErr:         false,
+		},
+		{
+			name:         "Failed Authorize - Other user on personal wallet",
+			actor:        "MARY",
+			username:     "JUAN",
+			currency:     "USD",
+			amount:       100,
+			expectedCode: validation.ERR_WALLET_ACCESS_DENIED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Authorize - Joint wallet without actor",
+			username:     "J_JOINT",
+			currency:     "USD",
+			amount:       100,
+			expectedCode: validation.ERR_WALLET_ACCESS_DENIED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Authorize - Viewer cannot spend",
+			actor:        "PEDRO",
+			username:     "J_JOINT",
+			currency:     "USD",
+			amount:       100,
+			expectedCode: validation.ERR_WALLET_ACCESS_DENIED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Authorize - Non member",
+			actor:        "ROSA",
+			username:     "J_JOINT",
+			currency:     "USD",
+			amount:       100,
+			expectedCode: validation.ERR_WALLET_ACCESS_DENIED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Authorize - Invalid actor",
+			actor:        "mary!",
+			username:     "J_JOINT",
+			currency:     "USD",
+			amount:       100,
+			expectedCode: validation.ERR_SANITIZE_USERNAME_FAILED,
+			expectErr:    true,
+		},
+		{
+			name:         "Failed Authorize - Invalid currency",
+			actor:        "JUAN",
+			username:     "J_JOINT",
+			currency:     "XYZ",
+			amount:       100,
+			expectedCode: validation.ERR_CURRENCY_VALIDATION_FAILED,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := &mockWithdrawStore{}
+			mock.initializeMockWallet()
+			s := &WithdrawService{store: mock}
+
+			actual, err := s.DoAuthorizeSpend(context.Background(), test.actor, test.username, test.currency, test.amount)
+
+			if test.expectErr && err == nil {
+				t.Errorf("expected error but got nil")
+			}
+
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.expectErr && err != nil && err.Code != test.expectedCode {
+				t.Errorf("expected code %s but got %s instead", test.expectedCode, err.Code)
+			}
+
+			if !test.expectErr && actual != nil && actual.Actor != test.expectedActor {
+				t.Errorf("expected actor %s but got %s instead", test.expectedActor, actual.Actor)
+			}
+
+			if !test.expectErr && actual != nil && actual.Joint != test.expectedJoint {
+				t.Errorf("expected joint %t but got %t instead", test.expectedJoint, actual.Joint)
+			}
+
+			if !test.expectErr && actual != nil && actual.RequiredApprovals != test.expectedApprovals {
+				t.Errorf("expected required approvals %d but got %d instead", test.expectedApprovals, actual.RequiredApprovals)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/ezjuanify/wallet/internal/db"
 	"github.com/ezjuanify/wallet/internal/logger"
@@ -78,30 +79,523 @@ func GetPGConfig() (*db.PGConfig, error) {
 }
 
 func DecodeRequest(r *http.Request) (*request.RequestPayload, error) {
-	var req *request.RequestPayload
+	return DecodeJSON[request.RequestPayload](r)
+}
+
+func DecodeJSON[T any](r *http.Request) (*T, error) {
+	var req *T
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields()
 	err := decoder.Decode(&req)
 	if err != nil {
 		return nil, err
 	}
+	if req == nil {
+		return nil, fmt.Errorf("request body cannot be null")
+	}
 	return req, nil
 }
 
-func GenerateTransactionHash(txUser string, txType model.TxnType, txAmount int64, txCounterparty *string, timestamp string) string {
-	var counterparty string
-	if txCounterparty != nil {
-		counterparty = *txCounterparty
+func GetFXConfig() (*model.FXConfig, error) {
+	fxconfig := &model.FXConfig{
+		QuoteTTL:         30 * time.Second,
+		DefaultSpreadBps: 50,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for fxconfig",
+		zap.String("FX_QUOTE_TTL", env("FX_QUOTE_TTL")),
+		zap.String("FX_SPREAD_BPS", env("FX_SPREAD_BPS")),
+	)
+
+	if val := env("FX_QUOTE_TTL"); val != "" {
+		ttl, err := time.ParseDuration(val)
+		if err != nil {
+			return fxconfig, err
+		}
+		fxconfig.QuoteTTL = ttl
+	}
+
+	if val := env("FX_SPREAD_BPS"); val != "" {
+		spread, err := strconv.Atoi(val)
+		if err != nil {
+			return fxconfig, err
+		}
+		fxconfig.DefaultSpreadBps = spread
+	}
+
+	logger.Debug("Final fxconfig built",
+		zap.Duration("quote_ttl", fxconfig.QuoteTTL),
+		zap.Int("default_spread_bps", fxconfig.DefaultSpreadBps),
+	)
+
+	return fxconfig, nil
+}
+
+// transactionDetailsForHash encodes the memo, reference and metadata as JSON
+// so free text cannot be confused with the field separator. It is empty when
+// none are set, so a HashVersionDetails row without them hashes the same as a
+// HashVersionPocket row.
+func transactionDetailsForHash(txn *model.Transaction) string {
+	if txn.Memo == nil && txn.Reference == nil && len(txn.Metadata) == 0 {
+		return ""
+	}
+	details, _ := json.Marshal(struct {
+		Memo      *string           `json:"memo"`
+		Reference *string           `json:"reference"`
+		Metadata  map[string]string `json:"metadata"`
+	}{txn.Memo, txn.Reference, txn.Metadata})
+	return string(details)
+}
+
+// GenerateTransactionHash hashes txn in the preimage format named by its
+// HashVersion. Versions before HashVersionChained predate the chain and do
+// not include prevHash; their link to the previous row is checked through
+// the stored prev_hash alone. An unknown version hashes to an empty string,
+// which never matches a stored hash.
+func GenerateTransactionHash(prevHash string, txn *model.Transaction) string {
+	var counterparty, fxRate, quoteID, journalEntryID, reversalOf string
+	if txn.Counterparty != nil {
+		counterparty = *txn.Counterparty
+	}
+	if txn.FXRate != nil {
+		fxRate = *txn.FXRate
+	}
+	if txn.QuoteID != nil {
+		quoteID = *txn.QuoteID
+	}
+	if txn.JournalEntryID != nil {
+		journalEntryID = strconv.FormatInt(*txn.JournalEntryID, 10)
+	}
+	if txn.ReversalOf != nil {
+		reversalOf = strconv.FormatInt(*txn.ReversalOf, 10)
 	}
+	timestamp := txn.Timestamp.UTC().Format(time.RFC3339Nano)
 	logger.Debug("Hashing with values",
-		zap.String("username", txUser),
-		zap.String("type", string(txType)),
-		zap.Int64("amount", txAmount),
+		zap.Int("hash_version", txn.HashVersion),
+		zap.String("prev_hash", prevHash),
+		zap.String("username", txn.Username),
+		zap.String("type", string(txn.TxnType)),
+		zap.String("direction", string(txn.Direction)),
+		zap.String("currency", txn.Currency),
+		zap.String("pocket", txn.Pocket),
+		zap.Int64("amount", txn.Amount),
 		zap.String("counterparty", counterparty),
+		zap.String("fx_rate", fxRate),
+		zap.String("quote_id", quoteID),
+		zap.String("journal_entry_id", journalEntryID),
+		zap.String("reversal_of", reversalOf),
 		zap.String("timestamp", timestamp),
 	)
-	raw := fmt.Sprintf("%s|%s|%d|%s|%s", txUser, txType, txAmount, counterparty, timestamp)
+
+	var raw string
+	switch txn.HashVersion {
+	case model.HashVersionOriginal:
+		raw = fmt.Sprintf("%s|%s|%d|%s|%s", txn.Username, txn.TxnType, txn.Amount, counterparty, txn.Timestamp.UTC().Format(time.RFC3339))
+	case model.HashVersionCurrency:
+		raw = fmt.Sprintf("%s|%s|%s|%d|%s|%s", txn.Username, txn.TxnType, txn.Currency, txn.Amount, counterparty, txn.Timestamp.UTC().Format(time.RFC3339))
+	case model.HashVersionChained:
+		raw = fmt.Sprintf("%s|%s|%s|%s|%d|%s|%s|%s|%s", prevHash, txn.Username, txn.TxnType, txn.Currency, txn.Amount, counterparty, fxRate, quoteID, timestamp)
+	case model.HashVersionJournal:
+		raw = fmt.Sprintf("%s|%s|%s|%s|%s|%d|%s|%s|%s|%s|%s|%s", prevHash, txn.Username, txn.TxnType, txn.Direction, txn.Currency, txn.Amount, counterparty, fxRate, quoteID, journalEntryID, reversalOf, timestamp)
+	case model.HashVersionPocket, model.HashVersionDetails:
+		raw = fmt.Sprintf("%s|%s|%s|%s|%s|%s|%d|%s|%s|%s|%s|%s|%s", prevHash, txn.Username, txn.TxnType, txn.Direction, txn.Currency, txn.Pocket, txn.Amount, counterparty, fxRate, quoteID, journalEntryID, reversalOf, timestamp)
+		if txn.HashVersion == model.HashVersionDetails {
+			if details := transactionDetailsForHash(txn); details != "" {
+				raw += "|" + details
+			}
+		}
+	default:
+		logger.Warn("Unknown hash version", zap.Int("hash_version", txn.HashVersion))
+		return ""
+	}
 	logger.Debug("Hashing string", zap.String("raw", raw))
 	hash := sha256.Sum256([]byte(raw))
 	return hex.EncodeToString(hash[:])
 }
+
+func GetReconciliationConfig() (*model.ReconciliationConfig, error) {
+	reconconfig := &model.ReconciliationConfig{
+		Interval: 0,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for reconconfig",
+		zap.String("RECON_INTERVAL", env("RECON_INTERVAL")),
+	)
+
+	if val := env("RECON_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return reconconfig, err
+		}
+		reconconfig.Interval = interval
+	}
+
+	logger.Debug("Final reconconfig built",
+		zap.Duration("interval", reconconfig.Interval),
+	)
+
+	return reconconfig, nil
+}
+
+func GetHoldConfig() (*model.HoldConfig, error) {
+	holdconfig := &model.HoldConfig{
+		DefaultTTL:    7 * 24 * time.Hour,
+		SweepInterval: time.Minute,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for holdconfig",
+		zap.String("HOLD_DEFAULT_TTL", env("HOLD_DEFAULT_TTL")),
+		zap.String("HOLD_SWEEP_INTERVAL", env("HOLD_SWEEP_INTERVAL")),
+	)
+
+	if val := env("HOLD_DEFAULT_TTL"); val != "" {
+		ttl, err := time.ParseDuration(val)
+		if err != nil {
+			return holdconfig, err
+		}
+		holdconfig.DefaultTTL = ttl
+	}
+
+	if val := env("HOLD_SWEEP_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return holdconfig, err
+		}
+		holdconfig.SweepInterval = interval
+	}
+
+	logger.Debug("Final holdconfig built",
+		zap.Duration("default_ttl", holdconfig.DefaultTTL),
+		zap.Duration("sweep_interval", holdconfig.SweepInterval),
+	)
+
+	return holdconfig, nil
+}
+
+func GetInterestConfig() (*model.InterestConfig, error) {
+	interestconfig := &model.InterestConfig{
+		AnnualRateBps: 0,
+		DayCount:      365,
+		Rounding:      model.RoundDown,
+		RunInterval:   time.Hour,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for interestconfig",
+		zap.String("INTEREST_ANNUAL_RATE_BPS", env("INTEREST_ANNUAL_RATE_BPS")),
+		zap.String("INTEREST_DAY_COUNT", env("INTEREST_DAY_COUNT")),
+		zap.String("INTEREST_ROUNDING", env("INTEREST_ROUNDING")),
+		zap.String("INTEREST_RUN_INTERVAL", env("INTEREST_RUN_INTERVAL")),
+	)
+
+	if val := env("INTEREST_ANNUAL_RATE_BPS"); val != "" {
+		rate, err := strconv.Atoi(val)
+		if err != nil {
+			return interestconfig, err
+		}
+		if rate < 0 {
+			return interestconfig, fmt.Errorf("INTEREST_ANNUAL_RATE_BPS cannot be negative")
+		}
+		interestconfig.AnnualRateBps = rate
+	}
+
+	if val := env("INTEREST_DAY_COUNT"); val != "" {
+		dayCount, err := strconv.Atoi(val)
+		if err != nil {
+			return interestconfig, err
+		}
+		if dayCount != 360 && dayCount != 365 {
+			return interestconfig, fmt.Errorf("INTEREST_DAY_COUNT must be 360 or 365")
+		}
+		interestconfig.DayCount = dayCount
+	}
+
+	if val := env("INTEREST_ROUNDING"); val != "" {
+		if !model.IsInterestRoundingValid(val) {
+			return interestconfig, fmt.Errorf("INTEREST_ROUNDING must be one of down, half_up or half_even")
+		}
+		interestconfig.Rounding = model.InterestRounding(val)
+	}
+
+	if val := env("INTEREST_RUN_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return interestconfig, err
+		}
+		interestconfig.RunInterval = interval
+	}
+
+	logger.Debug("Final interestconfig built",
+		zap.Int("annual_rate_bps", interestconfig.AnnualRateBps),
+		zap.Int("day_count", interestconfig.DayCount),
+		zap.String("rounding", string(interestconfig.Rounding)),
+		zap.Duration("run_interval", interestconfig.RunInterval),
+	)
+
+	return interestconfig, nil
+}
+
+func GetOverdraftConfig() (*model.OverdraftConfig, error) {
+	overdraftconfig := &model.OverdraftConfig{
+		RunInterval: time.Hour,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for overdraftconfig",
+		zap.String("OVERDRAFT_RUN_INTERVAL", env("OVERDRAFT_RUN_INTERVAL")),
+	)
+
+	if val := env("OVERDRAFT_RUN_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return overdraftconfig, err
+		}
+		overdraftconfig.RunInterval = interval
+	}
+
+	logger.Debug("Final overdraftconfig built",
+		zap.Duration("run_interval", overdraftconfig.RunInterval),
+	)
+
+	return overdraftconfig, nil
+}
+
+func GetStatementConfig() (*model.StatementConfig, error) {
+	statementconfig := &model.StatementConfig{
+		RunInterval: time.Hour,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for statementconfig",
+		zap.String("STATEMENT_RUN_INTERVAL", env("STATEMENT_RUN_INTERVAL")),
+	)
+
+	if val := env("STATEMENT_RUN_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return statementconfig, err
+		}
+		statementconfig.RunInterval = interval
+	}
+
+	logger.Debug("Final statementconfig built",
+		zap.Duration("run_interval", statementconfig.RunInterval),
+	)
+
+	return statementconfig, nil
+}
+
+func GetApprovalConfig() (*model.ApprovalConfig, error) {
+	approvalconfig := &model.ApprovalConfig{
+		TTL: 72 * time.Hour,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for approvalconfig",
+		zap.String("APPROVAL_TTL", env("APPROVAL_TTL")),
+	)
+
+	if val := env("APPROVAL_TTL"); val != "" {
+		ttl, err := time.ParseDuration(val)
+		if err != nil {
+			return approvalconfig, err
+		}
+		approvalconfig.TTL = ttl
+	}
+
+	logger.Debug("Final approvalconfig built",
+		zap.Duration("ttl", approvalconfig.TTL),
+	)
+
+	return approvalconfig, nil
+}
+
+func GetEscrowConfig() (*model.EscrowConfig, error) {
+	escrowconfig := &model.EscrowConfig{
+		DefaultTTL:    30 * 24 * time.Hour,
+		SweepInterval: time.Minute,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for escrowconfig",
+		zap.String("ESCROW_DEFAULT_TTL", env("ESCROW_DEFAULT_TTL")),
+		zap.String("ESCROW_SWEEP_INTERVAL", env("ESCROW_SWEEP_INTERVAL")),
+	)
+
+	if val := env("ESCROW_DEFAULT_TTL"); val != "" {
+		ttl, err := time.ParseDuration(val)
+		if err != nil {
+			return escrowconfig, err
+		}
+		escrowconfig.DefaultTTL = ttl
+	}
+
+	if val := env("ESCROW_SWEEP_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return escrowconfig, err
+		}
+		escrowconfig.SweepInterval = interval
+	}
+
+	logger.Debug("Final escrowconfig built",
+		zap.Duration("default_ttl", escrowconfig.DefaultTTL),
+		zap.Duration("sweep_interval", escrowconfig.SweepInterval),
+	)
+
+	return escrowconfig, nil
+}
+
+func GetScheduledTransferConfig() (*model.ScheduledTransferConfig, error) {
+	scheduledconfig := &model.ScheduledTransferConfig{
+		PollInterval: 30 * time.Second,
+		RetryDelay:   5 * time.Minute,
+		MaxAttempts:  3,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for scheduledconfig",
+		zap.String("SCHEDULED_POLL_INTERVAL", env("SCHEDULED_POLL_INTERVAL")),
+		zap.String("SCHEDULED_RETRY_DELAY", env("SCHEDULED_RETRY_DELAY")),
+		zap.String("SCHEDULED_MAX_ATTEMPTS", env("SCHEDULED_MAX_ATTEMPTS")),
+	)
+
+	if val := env("SCHEDULED_POLL_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return scheduledconfig, err
+		}
+		scheduledconfig.PollInterval = interval
+	}
+
+	if val := env("SCHEDULED_RETRY_DELAY"); val != "" {
+		delay, err := time.ParseDuration(val)
+		if err != nil {
+			return scheduledconfig, err
+		}
+		scheduledconfig.RetryDelay = delay
+	}
+
+	if val := env("SCHEDULED_MAX_ATTEMPTS"); val != "" {
+		attempts, err := strconv.Atoi(val)
+		if err != nil {
+			return scheduledconfig, err
+		}
+		if attempts < 1 {
+			return scheduledconfig, fmt.Errorf("SCHEDULED_MAX_ATTEMPTS must be at least 1, got %d", attempts)
+		}
+		scheduledconfig.MaxAttempts = attempts
+	}
+
+	logger.Debug("Final scheduledconfig built",
+		zap.Duration("poll_interval", scheduledconfig.PollInterval),
+		zap.Duration("retry_delay", scheduledconfig.RetryDelay),
+		zap.Int("max_attempts", scheduledconfig.MaxAttempts),
+	)
+
+	return scheduledconfig, nil
+}
+
+func GetBalanceSnapshotConfig() (*model.BalanceSnapshotConfig, error) {
+	snapshotconfig := &model.BalanceSnapshotConfig{
+		RunInterval: time.Hour,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for snapshotconfig",
+		zap.String("BALANCE_SNAPSHOT_INTERVAL", env("BALANCE_SNAPSHOT_INTERVAL")),
+	)
+
+	if val := env("BALANCE_SNAPSHOT_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return snapshotconfig, err
+		}
+		snapshotconfig.RunInterval = interval
+	}
+
+	logger.Debug("Final snapshotconfig built",
+		zap.Duration("run_interval", snapshotconfig.RunInterval),
+	)
+
+	return snapshotconfig, nil
+}
+
+func GetWalletConfig() (*model.WalletConfig, error) {
+	walletconfig := &model.WalletConfig{
+		AutoCreate: false,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for walletconfig",
+		zap.String("WALLET_AUTO_CREATE", env("WALLET_AUTO_CREATE")),
+	)
+
+	if val := env("WALLET_AUTO_CREATE"); val != "" {
+		autoCreate, err := strconv.ParseBool(val)
+		if err != nil {
+			return walletconfig, err
+		}
+		walletconfig.AutoCreate = autoCreate
+	}
+
+	logger.Debug("Final walletconfig built",
+		zap.Bool("auto_create", walletconfig.AutoCreate),
+	)
+
+	return walletconfig, nil
+}
+
+func GetIdempotencyConfig() (*model.IdempotencyConfig, error) {
+	idempotencyconfig := &model.IdempotencyConfig{
+		Retention:     24 * time.Hour,
+		SweepInterval: time.Hour,
+	}
+
+	env := func(key string) string { return os.Getenv(key) }
+
+	logger.Debug("Loading env overrides for idempotencyconfig",
+		zap.String("IDEMPOTENCY_RETENTION", env("IDEMPOTENCY_RETENTION")),
+		zap.String("IDEMPOTENCY_SWEEP_INTERVAL", env("IDEMPOTENCY_SWEEP_INTERVAL")),
+	)
+
+	if val := env("IDEMPOTENCY_RETENTION"); val != "" {
+		retention, err := time.ParseDuration(val)
+		if err != nil {
+			return idempotencyconfig, err
+		}
+		if retention <= 0 {
+			return idempotencyconfig, fmt.Errorf("IDEMPOTENCY_RETENTION must be positive, got %s", val)
+		}
+		idempotencyconfig.Retention = retention
+	}
+
+	if val := env("IDEMPOTENCY_SWEEP_INTERVAL"); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return idempotencyconfig, err
+		}
+		idempotencyconfig.SweepInterval = interval
+	}
+
+	logger.Debug("Final idempotencyconfig built",
+		zap.Duration("retention", idempotencyconfig.Retention),
+		zap.Duration("sweep_interval", idempotencyconfig.SweepInterval),
+	)
+
+	return idempotencyconfig, nil
+}
@@ -2,40 +2,75 @@ package validation
 
 import (
 	"fmt"
-)
+	"regexp"
+	"strconv"
+	"strings"
 
-const (
-	UPPER_LIMIT = 999999
+	"github.com/ezjuanify/wallet/internal/model"
 )
 
+var decimalAmountPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
 func isAmountTooLowInc(amount int64) bool {
 	return amount <= 0
 }
 func isAmountTooLow(amount int64) bool {
 	return amount < 0
 }
-func isAmountTooHigh(amount int64) bool {
-	return amount > UPPER_LIMIT
+func isAmountTooHigh(amount int64, limit int64) bool {
+	return amount > limit
+}
+
+func describeAmount(amount int64, currencyCode string) string {
+	if currency, ok := model.LookupCurrency(currencyCode); ok {
+		return fmt.Sprintf("%s %s", currency.FormatAmount(amount), currencyCode)
+	}
+	return fmt.Sprintf("%d %s", amount, currencyCode)
+}
+
+func ValidateAmount(amount int64, currency model.Currency) error {
+	return ValidateWalletAmount(amount, model.DefaultWalletLimits(currency), currency.Code)
 }
 
-func ValidateAmount(amount int64) error {
+func ValidateWalletAmount(amount int64, limits model.WalletLimits, currencyCode string) error {
 	switch {
 	case isAmountTooLowInc(amount):
 		return fmt.Errorf("amount must be greater than 0")
-	case isAmountTooHigh(amount):
-		return fmt.Errorf("amount must not exceed %d", UPPER_LIMIT)
+	case isAmountTooLow(amount - limits.MinAmount):
+		return fmt.Errorf("amount must be at least %s", describeAmount(limits.MinAmount, currencyCode))
+	case isAmountTooHigh(amount, limits.MaxAmount):
+		return fmt.Errorf("amount must not exceed %s", describeAmount(limits.MaxAmount, currencyCode))
 	default:
 		return nil
 	}
 }
 
-func ValidateWalletBalance(amount int64) error {
+func ValidateWalletBalance(amount int64, wallet *model.Wallet) error {
 	switch {
-	case isAmountTooLow(amount):
-		return fmt.Errorf("insufficient funds in wallet %d", amount)
-	case isAmountTooHigh(amount):
-		return fmt.Errorf("wallet balance %d exceeds %d", amount, UPPER_LIMIT)
+	case isAmountTooLow(amount + wallet.CreditLimit):
+		if wallet.CreditLimit > 0 {
+			return fmt.Errorf("wallet balance %s exceeds credit limit %s", describeAmount(amount, wallet.Currency), describeAmount(wallet.CreditLimit, wallet.Currency))
+		}
+		return fmt.Errorf("insufficient funds in wallet %s", describeAmount(amount, wallet.Currency))
+	case isAmountTooHigh(amount, wallet.Limits.MaxBalance):
+		return fmt.Errorf("wallet balance %s exceeds %s", describeAmount(amount, wallet.Currency), describeAmount(wallet.Limits.MaxBalance, wallet.Currency))
 	default:
 		return nil
 	}
 }
+
+func ParseDecimalAmount(raw string, currency model.Currency) (int64, error) {
+	if !decimalAmountPattern.MatchString(raw) {
+		return 0, fmt.Errorf("%q is not a decimal number", raw)
+	}
+	whole, fraction, _ := strings.Cut(raw, ".")
+	if len(fraction) > currency.Exponent {
+		return 0, fmt.Errorf("%q has more than %d decimal places for %s", raw, currency.Exponent, currency.Code)
+	}
+	fraction += strings.Repeat("0", currency.Exponent-len(fraction))
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is out of range", raw)
+	}
+	return amount, nil
+}
@@ -0,0 +1,29 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ezjuanify/wallet/internal/model"
+)
+
+func SanitizeAndValidateCurrency(raw string) (model.Currency, error) {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	if code == "" {
+		code = model.DefaultCurrency
+	}
+
+	currency, ok := model.LookupCurrency(code)
+	if !ok {
+		return model.Currency{}, fmt.Errorf("currency %q is not supported", code)
+	}
+	return currency, nil
+}
+
+func SanitizeCurrencyWithoutError(raw string) string {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	if _, ok := model.LookupCurrency(code); !ok {
+		return ""
+	}
+	return code
+}
@@ -22,25 +22,162 @@ type WalletError struct {
 type WalletErrorCode string
 
 const (
-	ERR_TRANSACTION_START_FAILED         WalletErrorCode = "ERR_TRANSACTION_START_FAILED"
-	ERR_TRANSACTION_COMMIT_FAILED        WalletErrorCode = "ERR_TRANSACTION_COMMIT_FAILED"
-	ERR_INVALID_JSON_BODY                WalletErrorCode = "ERR_INVALID_JSON_BODY"
-	ERR_DEPOSIT_FAILED                   WalletErrorCode = "ERR_DEPOSIT_FAILED"
-	ERR_WITHDRAW_FAILED                  WalletErrorCode = "ERR_WITHDRAW_FAILED"
-	ERR_TRANSFER_OUT_FAILED              WalletErrorCode = "ERR_TRANSFER_OUT_FAILED"
-	ERR_TRANSFER_IN_FAILED               WalletErrorCode = "ERR_TRANSFER_IN_FAILED"
-	ERR_FETCH_TRANSACTION_FAILED         WalletErrorCode = "ERR_FETCH_TRANSACTION_FAILED"
-	ERR_LOG_TRANSACTION_FAILED           WalletErrorCode = "ERR_LOG_TRANSACTION_FAILED"
-	ERR_SANITIZE_USERNAME_FAILED         WalletErrorCode = "ERR_SANITIZE_USERNAME_FAILED"
-	ERR_AMOUNT_VALIDATION_FAILED         WalletErrorCode = "ERR_AMOUNT_VALIDATION_FAILED"
-	ERR_WALLET_BALANCE_VALIDATION_FAILED WalletErrorCode = "ERR_WALLET_BALANCE_VALIDATION_FAILED"
-	ERR_INSUFFICIENT_WALLET_BALANCE      WalletErrorCode = "ERR_INSUFFICIENT_WALLET_BALANCE"
-	ERR_FETCH_WALLET_FAILED              WalletErrorCode = "ERR_FETCH_WALLET_FAILED"
-	ERR_DB_UPSERT_FAILED                 WalletErrorCode = "ERR_DB_UPSERT_FAILED"
-	ERR_DB_WITHDRAW_FAILED               WalletErrorCode = "ERR_DB_WITHDRAW_FAILED"
-	ERR_WALLET_DOES_NOT_EXIST            WalletErrorCode = "ERR_WALLET_DOES_NOT_EXIST"
-	ERR_ZERO_AMOUNT                      WalletErrorCode = "ERR_ZERO_AMOUNT"
-	ERR_PANIC_OCCURED                    WalletErrorCode = "ERR_PANIC_OCCURED"
+	ERR_TRANSACTION_START_FAILED          WalletErrorCode = "ERR_TRANSACTION_START_FAILED"
+	ERR_TRANSACTION_COMMIT_FAILED         WalletErrorCode = "ERR_TRANSACTION_COMMIT_FAILED"
+	ERR_INVALID_JSON_BODY                 WalletErrorCode = "ERR_INVALID_JSON_BODY"
+	ERR_REQUEST_BODY_TOO_LARGE            WalletErrorCode = "ERR_REQUEST_BODY_TOO_LARGE"
+	ERR_DEPOSIT_FAILED                    WalletErrorCode = "ERR_DEPOSIT_FAILED"
+	ERR_WITHDRAW_FAILED                   WalletErrorCode = "ERR_WITHDRAW_FAILED"
+	ERR_TRANSFER_OUT_FAILED               WalletErrorCode = "ERR_TRANSFER_OUT_FAILED"
+	ERR_TRANSFER_IN_FAILED                WalletErrorCode = "ERR_TRANSFER_IN_FAILED"
+	ERR_FETCH_TRANSACTION_FAILED          WalletErrorCode = "ERR_FETCH_TRANSACTION_FAILED"
+	ERR_LOG_TRANSACTION_FAILED            WalletErrorCode = "ERR_LOG_TRANSACTION_FAILED"
+	ERR_MEMO_VALIDATION_FAILED            WalletErrorCode = "ERR_MEMO_VALIDATION_FAILED"
+	ERR_REFERENCE_VALIDATION_FAILED       WalletErrorCode = "ERR_REFERENCE_VALIDATION_FAILED"
+	ERR_METADATA_VALIDATION_FAILED        WalletErrorCode = "ERR_METADATA_VALIDATION_FAILED"
+	ERR_SANITIZE_USERNAME_FAILED          WalletErrorCode = "ERR_SANITIZE_USERNAME_FAILED"
+	ERR_AMOUNT_VALIDATION_FAILED          WalletErrorCode = "ERR_AMOUNT_VALIDATION_FAILED"
+	ERR_AMOUNT_FORMAT_INVALID             WalletErrorCode = "ERR_AMOUNT_FORMAT_INVALID"
+	ERR_WALLET_BALANCE_VALIDATION_FAILED  WalletErrorCode = "ERR_WALLET_BALANCE_VALIDATION_FAILED"
+	ERR_INSUFFICIENT_WALLET_BALANCE       WalletErrorCode = "ERR_INSUFFICIENT_WALLET_BALANCE"
+	ERR_FETCH_WALLET_FAILED               WalletErrorCode = "ERR_FETCH_WALLET_FAILED"
+	ERR_DB_UPSERT_FAILED                  WalletErrorCode = "ERR_DB_UPSERT_FAILED"
+	ERR_DB_WITHDRAW_FAILED                WalletErrorCode = "ERR_DB_WITHDRAW_FAILED"
+	ERR_WALLET_DOES_NOT_EXIST             WalletErrorCode = "ERR_WALLET_DOES_NOT_EXIST"
+	ERR_ZERO_AMOUNT                       WalletErrorCode = "ERR_ZERO_AMOUNT"
+	ERR_PANIC_OCCURED                     WalletErrorCode = "ERR_PANIC_OCCURED"
+	ERR_JOURNAL_ENTRY_UNBALANCED          WalletErrorCode = "ERR_JOURNAL_ENTRY_UNBALANCED"
+	ERR_POST_JOURNAL_FAILED               WalletErrorCode = "ERR_POST_JOURNAL_FAILED"
+	ERR_FETCH_LEDGER_BALANCE_FAILED       WalletErrorCode = "ERR_FETCH_LEDGER_BALANCE_FAILED"
+	ERR_LEDGER_BALANCE_MISMATCH           WalletErrorCode = "ERR_LEDGER_BALANCE_MISMATCH"
+	ERR_CURRENCY_VALIDATION_FAILED        WalletErrorCode = "ERR_CURRENCY_VALIDATION_FAILED"
+	ERR_CROSS_CURRENCY_TRANSFER           WalletErrorCode = "ERR_CROSS_CURRENCY_TRANSFER"
+	ERR_FX_RATE_VALIDATION_FAILED         WalletErrorCode = "ERR_FX_RATE_VALIDATION_FAILED"
+	ERR_INSERT_FX_RATE_FAILED             WalletErrorCode = "ERR_INSERT_FX_RATE_FAILED"
+	ERR_FETCH_FX_RATE_FAILED              WalletErrorCode = "ERR_FETCH_FX_RATE_FAILED"
+	ERR_FX_RATE_NOT_FOUND                 WalletErrorCode = "ERR_FX_RATE_NOT_FOUND"
+	ERR_FX_CONVERSION_FAILED              WalletErrorCode = "ERR_FX_CONVERSION_FAILED"
+	ERR_CREATE_FX_QUOTE_FAILED            WalletErrorCode = "ERR_CREATE_FX_QUOTE_FAILED"
+	ERR_FETCH_FX_QUOTE_FAILED             WalletErrorCode = "ERR_FETCH_FX_QUOTE_FAILED"
+	ERR_FX_QUOTE_NOT_FOUND                WalletErrorCode = "ERR_FX_QUOTE_NOT_FOUND"
+	ERR_FX_QUOTE_EXPIRED                  WalletErrorCode = "ERR_FX_QUOTE_EXPIRED"
+	ERR_FX_QUOTE_ALREADY_USED             WalletErrorCode = "ERR_FX_QUOTE_ALREADY_USED"
+	ERR_FX_QUOTE_MISMATCH                 WalletErrorCode = "ERR_FX_QUOTE_MISMATCH"
+	ERR_FETCH_CHAIN_HEAD_FAILED           WalletErrorCode = "ERR_FETCH_CHAIN_HEAD_FAILED"
+	ERR_FETCH_TRANSACTION_CHAIN_FAILED    WalletErrorCode = "ERR_FETCH_TRANSACTION_CHAIN_FAILED"
+	ERR_FETCH_RECONCILIATION_FAILED       WalletErrorCode = "ERR_FETCH_RECONCILIATION_FAILED"
+	ERR_UNKNOWN_TRANSACTION_TYPE          WalletErrorCode = "ERR_UNKNOWN_TRANSACTION_TYPE"
+	ERR_INVALID_TRANSACTION_ID            WalletErrorCode = "ERR_INVALID_TRANSACTION_ID"
+	ERR_TRANSACTION_NOT_FOUND             WalletErrorCode = "ERR_TRANSACTION_NOT_FOUND"
+	ERR_REVERSAL_NOT_ALLOWED              WalletErrorCode = "ERR_REVERSAL_NOT_ALLOWED"
+	ERR_TRANSACTION_ALREADY_REVERSED      WalletErrorCode = "ERR_TRANSACTION_ALREADY_REVERSED"
+	ERR_REVERSAL_AMOUNT_INVALID           WalletErrorCode = "ERR_REVERSAL_AMOUNT_INVALID"
+	ERR_INVALID_HOLD_ID                   WalletErrorCode = "ERR_INVALID_HOLD_ID"
+	ERR_HOLD_NOT_FOUND                    WalletErrorCode = "ERR_HOLD_NOT_FOUND"
+	ERR_HOLD_NOT_ACTIVE                   WalletErrorCode = "ERR_HOLD_NOT_ACTIVE"
+	ERR_HOLD_EXPIRED                      WalletErrorCode = "ERR_HOLD_EXPIRED"
+	ERR_HOLD_EXPIRY_INVALID               WalletErrorCode = "ERR_HOLD_EXPIRY_INVALID"
+	ERR_HOLD_AMOUNT_INVALID               WalletErrorCode = "ERR_HOLD_AMOUNT_INVALID"
+	ERR_PLACE_HOLD_FAILED                 WalletErrorCode = "ERR_PLACE_HOLD_FAILED"
+	ERR_RELEASE_HOLD_FAILED               WalletErrorCode = "ERR_RELEASE_HOLD_FAILED"
+	ERR_FETCH_HOLD_FAILED                 WalletErrorCode = "ERR_FETCH_HOLD_FAILED"
+	ERR_EXPIRE_HOLDS_FAILED               WalletErrorCode = "ERR_EXPIRE_HOLDS_FAILED"
+	ERR_INVALID_SCHEDULED_TRANSFER_ID     WalletErrorCode = "ERR_INVALID_SCHEDULED_TRANSFER_ID"
+	ERR_SCHEDULED_TRANSFER_NOT_FOUND      WalletErrorCode = "ERR_SCHEDULED_TRANSFER_NOT_FOUND"
+	ERR_SCHEDULED_TRANSFER_NOT_PENDING    WalletErrorCode = "ERR_SCHEDULED_TRANSFER_NOT_PENDING"
+	ERR_SCHEDULE_TIME_INVALID             WalletErrorCode = "ERR_SCHEDULE_TIME_INVALID"
+	ERR_CREATE_SCHEDULED_TRANSFER_FAILED  WalletErrorCode = "ERR_CREATE_SCHEDULED_TRANSFER_FAILED"
+	ERR_FETCH_SCHEDULED_TRANSFER_FAILED   WalletErrorCode = "ERR_FETCH_SCHEDULED_TRANSFER_FAILED"
+	ERR_UPDATE_SCHEDULED_TRANSFER_FAILED  WalletErrorCode = "ERR_UPDATE_SCHEDULED_TRANSFER_FAILED"
+	ERR_EXECUTE_SCHEDULED_TRANSFER_FAILED WalletErrorCode = "ERR_EXECUTE_SCHEDULED_TRANSFER_FAILED"
+	ERR_INVALID_STANDING_ORDER_ID         WalletErrorCode = "ERR_INVALID_STANDING_ORDER_ID"
+	ERR_STANDING_ORDER_NOT_FOUND          WalletErrorCode = "ERR_STANDING_ORDER_NOT_FOUND"
+	ERR_STANDING_ORDER_NOT_ACTIVE         WalletErrorCode = "ERR_STANDING_ORDER_NOT_ACTIVE"
+	ERR_RECURRENCE_INVALID                WalletErrorCode = "ERR_RECURRENCE_INVALID"
+	ERR_CREATE_STANDING_ORDER_FAILED      WalletErrorCode = "ERR_CREATE_STANDING_ORDER_FAILED"
+	ERR_FETCH_STANDING_ORDER_FAILED       WalletErrorCode = "ERR_FETCH_STANDING_ORDER_FAILED"
+	ERR_UPDATE_STANDING_ORDER_FAILED      WalletErrorCode = "ERR_UPDATE_STANDING_ORDER_FAILED"
+	ERR_MATERIALIZE_STANDING_ORDER_FAILED WalletErrorCode = "ERR_MATERIALIZE_STANDING_ORDER_FAILED"
+	ERR_BATCH_INVALID                     WalletErrorCode = "ERR_BATCH_INVALID"
+	ERR_BATCH_EXECUTION_FAILED            WalletErrorCode = "ERR_BATCH_EXECUTION_FAILED"
+	ERR_RESERVED_USERNAME                 WalletErrorCode = "ERR_RESERVED_USERNAME"
+	ERR_INVALID_ESCROW_ID                 WalletErrorCode = "ERR_INVALID_ESCROW_ID"
+	ERR_ESCROW_NOT_FOUND                  WalletErrorCode = "ERR_ESCROW_NOT_FOUND"
+	ERR_ESCROW_NOT_FUNDED                 WalletErrorCode = "ERR_ESCROW_NOT_FUNDED"
+	ERR_ESCROW_EXPIRED                    WalletErrorCode = "ERR_ESCROW_EXPIRED"
+	ERR_ESCROW_EXPIRY_INVALID             WalletErrorCode = "ERR_ESCROW_EXPIRY_INVALID"
+	ERR_ESCROW_AMOUNT_INVALID             WalletErrorCode = "ERR_ESCROW_AMOUNT_INVALID"
+	ERR_ESCROW_PARTIES_INVALID            WalletErrorCode = "ERR_ESCROW_PARTIES_INVALID"
+	ERR_CREATE_ESCROW_FAILED              WalletErrorCode = "ERR_CREATE_ESCROW_FAILED"
+	ERR_FETCH_ESCROW_FAILED               WalletErrorCode = "ERR_FETCH_ESCROW_FAILED"
+	ERR_SETTLE_ESCROW_FAILED              WalletErrorCode = "ERR_SETTLE_ESCROW_FAILED"
+	ERR_FEE_RULE_VALIDATION_FAILED        WalletErrorCode = "ERR_FEE_RULE_VALIDATION_FAILED"
+	ERR_INSERT_FEE_RULE_FAILED            WalletErrorCode = "ERR_INSERT_FEE_RULE_FAILED"
+	ERR_FETCH_FEE_RULE_FAILED             WalletErrorCode = "ERR_FETCH_FEE_RULE_FAILED"
+	ERR_CHARGE_FEE_FAILED                 WalletErrorCode = "ERR_CHARGE_FEE_FAILED"
+	ERR_INTEREST_DATE_INVALID             WalletErrorCode = "ERR_INTEREST_DATE_INVALID"
+	ERR_ACCRUE_INTEREST_FAILED            WalletErrorCode = "ERR_ACCRUE_INTEREST_FAILED"
+	ERR_BOOK_INTEREST_FAILED              WalletErrorCode = "ERR_BOOK_INTEREST_FAILED"
+	ERR_FETCH_INTEREST_FAILED             WalletErrorCode = "ERR_FETCH_INTEREST_FAILED"
+	ERR_CREDIT_LIMIT_INVALID              WalletErrorCode = "ERR_CREDIT_LIMIT_INVALID"
+	ERR_UPDATE_CREDIT_LIMIT_FAILED        WalletErrorCode = "ERR_UPDATE_CREDIT_LIMIT_FAILED"
+	ERR_FETCH_OVERDRAFT_FAILED            WalletErrorCode = "ERR_FETCH_OVERDRAFT_FAILED"
+	ERR_CHARGE_OVERDRAFT_FAILED           WalletErrorCode = "ERR_CHARGE_OVERDRAFT_FAILED"
+	ERR_WALLET_LIMITS_INVALID             WalletErrorCode = "ERR_WALLET_LIMITS_INVALID"
+	ERR_UPDATE_WALLET_LIMITS_FAILED       WalletErrorCode = "ERR_UPDATE_WALLET_LIMITS_FAILED"
+	ERR_VELOCITY_RULE_VALIDATION_FAILED   WalletErrorCode = "ERR_VELOCITY_RULE_VALIDATION_FAILED"
+	ERR_INSERT_VELOCITY_RULE_FAILED       WalletErrorCode = "ERR_INSERT_VELOCITY_RULE_FAILED"
+	ERR_FETCH_VELOCITY_RULE_FAILED        WalletErrorCode = "ERR_FETCH_VELOCITY_RULE_FAILED"
+	ERR_FETCH_VELOCITY_USAGE_FAILED       WalletErrorCode = "ERR_FETCH_VELOCITY_USAGE_FAILED"
+	ERR_VELOCITY_LIMIT_EXCEEDED           WalletErrorCode = "ERR_VELOCITY_LIMIT_EXCEEDED"
+	ERR_STATEMENT_PERIOD_INVALID          WalletErrorCode = "ERR_STATEMENT_PERIOD_INVALID"
+	ERR_STATEMENT_NOT_FOUND               WalletErrorCode = "ERR_STATEMENT_NOT_FOUND"
+	ERR_FETCH_STATEMENT_FAILED            WalletErrorCode = "ERR_FETCH_STATEMENT_FAILED"
+	ERR_GENERATE_STATEMENT_FAILED         WalletErrorCode = "ERR_GENERATE_STATEMENT_FAILED"
+	ERR_AS_OF_INVALID                     WalletErrorCode = "ERR_AS_OF_INVALID"
+	ERR_BALANCE_HISTORY_UNAVAILABLE       WalletErrorCode = "ERR_BALANCE_HISTORY_UNAVAILABLE"
+	ERR_FETCH_BALANCE_HISTORY_FAILED      WalletErrorCode = "ERR_FETCH_BALANCE_HISTORY_FAILED"
+	ERR_BALANCE_SNAPSHOT_FAILED           WalletErrorCode = "ERR_BALANCE_SNAPSHOT_FAILED"
+	ERR_POCKET_VALIDATION_FAILED          WalletErrorCode = "ERR_POCKET_VALIDATION_FAILED"
+	ERR_POCKET_ALREADY_EXISTS             WalletErrorCode = "ERR_POCKET_ALREADY_EXISTS"
+	ERR_POCKET_NOT_FOUND                  WalletErrorCode = "ERR_POCKET_NOT_FOUND"
+	ERR_POCKET_MOVE_INVALID               WalletErrorCode = "ERR_POCKET_MOVE_INVALID"
+	ERR_CREATE_POCKET_FAILED              WalletErrorCode = "ERR_CREATE_POCKET_FAILED"
+	ERR_FETCH_POCKET_FAILED               WalletErrorCode = "ERR_FETCH_POCKET_FAILED"
+	ERR_MOVE_POCKET_FUNDS_FAILED          WalletErrorCode = "ERR_MOVE_POCKET_FUNDS_FAILED"
+	ERR_WALLET_ACCESS_DENIED              WalletErrorCode = "ERR_WALLET_ACCESS_DENIED"
+	ERR_FETCH_WALLET_ACCESS_FAILED        WalletErrorCode = "ERR_FETCH_WALLET_ACCESS_FAILED"
+	ERR_JOINT_WALLET_EXISTS               WalletErrorCode = "ERR_JOINT_WALLET_EXISTS"
+	ERR_JOINT_WALLET_NOT_FOUND            WalletErrorCode = "ERR_JOINT_WALLET_NOT_FOUND"
+	ERR_JOINT_MEMBER_INVALID              WalletErrorCode = "ERR_JOINT_MEMBER_INVALID"
+	ERR_SIGNING_RULE_INVALID              WalletErrorCode = "ERR_SIGNING_RULE_INVALID"
+	ERR_CREATE_JOINT_WALLET_FAILED        WalletErrorCode = "ERR_CREATE_JOINT_WALLET_FAILED"
+	ERR_UPDATE_WALLET_MEMBER_FAILED       WalletErrorCode = "ERR_UPDATE_WALLET_MEMBER_FAILED"
+	ERR_UPDATE_SIGNING_RULE_FAILED        WalletErrorCode = "ERR_UPDATE_SIGNING_RULE_FAILED"
+	ERR_APPROVAL_REQUIRED                 WalletErrorCode = "ERR_APPROVAL_REQUIRED"
+	ERR_INVALID_APPROVAL_ID               WalletErrorCode = "ERR_INVALID_APPROVAL_ID"
+	ERR_APPROVAL_NOT_FOUND                WalletErrorCode = "ERR_APPROVAL_NOT_FOUND"
+	ERR_APPROVAL_NOT_PENDING              WalletErrorCode = "ERR_APPROVAL_NOT_PENDING"
+	ERR_APPROVAL_ALREADY_GIVEN            WalletErrorCode = "ERR_APPROVAL_ALREADY_GIVEN"
+	ERR_CREATE_APPROVAL_FAILED            WalletErrorCode = "ERR_CREATE_APPROVAL_FAILED"
+	ERR_FETCH_APPROVAL_FAILED             WalletErrorCode = "ERR_FETCH_APPROVAL_FAILED"
+	ERR_UPDATE_APPROVAL_FAILED            WalletErrorCode = "ERR_UPDATE_APPROVAL_FAILED"
+	ERR_WALLET_DEBIT_FROZEN               WalletErrorCode = "ERR_WALLET_DEBIT_FROZEN"
+	ERR_WALLET_FROZEN                     WalletErrorCode = "ERR_WALLET_FROZEN"
+	ERR_WALLET_CLOSED                     WalletErrorCode = "ERR_WALLET_CLOSED"
+	ERR_WALLET_STATUS_TRANSITION_INVALID  WalletErrorCode = "ERR_WALLET_STATUS_TRANSITION_INVALID"
+	ERR_WALLET_STATUS_REASON_INVALID      WalletErrorCode = "ERR_WALLET_STATUS_REASON_INVALID"
+	ERR_WALLET_NOT_EMPTY                  WalletErrorCode = "ERR_WALLET_NOT_EMPTY"
+	ERR_UPDATE_WALLET_STATUS_FAILED       WalletErrorCode = "ERR_UPDATE_WALLET_STATUS_FAILED"
+	ERR_FETCH_WALLET_STATUS_FAILED        WalletErrorCode = "ERR_FETCH_WALLET_STATUS_FAILED"
+	ERR_WALLET_ALREADY_EXISTS             WalletErrorCode = "ERR_WALLET_ALREADY_EXISTS"
+	ERR_OPEN_WALLET_FAILED                WalletErrorCode = "ERR_OPEN_WALLET_FAILED"
+	ERR_IDEMPOTENCY_KEY_INVALID           WalletErrorCode = "ERR_IDEMPOTENCY_KEY_INVALID"
+	ERR_IDEMPOTENCY_KEY_REUSED            WalletErrorCode = "ERR_IDEMPOTENCY_KEY_REUSED"
+	ERR_IDEMPOTENCY_KEY_IN_PROGRESS       WalletErrorCode = "ERR_IDEMPOTENCY_KEY_IN_PROGRESS"
+	ERR_CLAIM_IDEMPOTENCY_KEY_FAILED      WalletErrorCode = "ERR_CLAIM_IDEMPOTENCY_KEY_FAILED"
+	ERR_SAVE_IDEMPOTENT_RESPONSE_FAILED   WalletErrorCode = "ERR_SAVE_IDEMPOTENT_RESPONSE_FAILED"
+	ERR_PURGE_IDEMPOTENCY_KEYS_FAILED     WalletErrorCode = "ERR_PURGE_IDEMPOTENCY_KEYS_FAILED"
 )
 
 type AppErrors struct {
@@ -0,0 +1,17 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ezjuanify/wallet/internal/model"
+)
+
+var validIdempotencyKey = regexp.MustCompile(fmt.Sprintf(`^[\x21-\x7E]{1,%d}$`, model.MaxIdempotencyKeyLength))
+
+func ValidateIdempotencyKey(key string) error {
+	if !validIdempotencyKey.MatchString(key) {
+		return fmt.Errorf("idempotency key must be printable ASCII without spaces, up to %d characters", model.MaxIdempotencyKeyLength)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ezjuanify/wallet/internal/model"
+)
+
+var validPocket = regexp.MustCompile(`^[A-Z0-9_]{1,32}$`)
+
+func SanitizeAndValidatePocket(raw string) (string, error) {
+	pocket := strings.ToUpper(strings.TrimSpace(raw))
+	if pocket == "" {
+		return model.DefaultPocket, nil
+	}
+
+	if !validPocket.MatchString(pocket) {
+		return "", fmt.Errorf("pocket can only be alphanumeric and underscore, up to 32 characters")
+	}
+	return pocket, nil
+}
+
+func SanitizePocketWithoutError(raw string) string {
+	pocket := strings.ToUpper(strings.TrimSpace(raw))
+	if !validPocket.MatchString(pocket) {
+		return ""
+	}
+	return pocket
+}
@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ezjuanify/wallet/internal/model"
+)
+
+var (
+	validReference   = regexp.MustCompile(fmt.Sprintf(`^[A-Za-z0-9][A-Za-z0-9._:/-]{0,%d}$`, model.MaxReferenceLength-1))
+	validMetadataKey = regexp.MustCompile(fmt.Sprintf(`^[A-Za-z][A-Za-z0-9_.-]{0,%d}$`, model.MaxMetadataKeyLength-1))
+)
+
+func validateFreeText(text string, maxLength int) error {
+	if !utf8.ValidString(text) {
+		return fmt.Errorf("must be valid UTF-8")
+	}
+	if length := utf8.RuneCountInString(text); length > maxLength {
+		return fmt.Errorf("is %d characters, up to %d allowed", length, maxLength)
+	}
+	if strings.IndexFunc(text, unicode.IsControl) >= 0 {
+		return fmt.Errorf("cannot contain control characters")
+	}
+	return nil
+}
+
+func SanitizeAndValidateMemo(raw string) (string, error) {
+	memo := strings.TrimSpace(raw)
+	if err := validateFreeText(memo, model.MaxMemoLength); err != nil {
+		return "", fmt.Errorf("memo %w", err)
+	}
+	return memo, nil
+}
+
+func SanitizeAndValidateReference(raw string) (string, error) {
+	reference := strings.TrimSpace(raw)
+	if reference == "" {
+		return "", nil
+	}
+
+	if !validReference.MatchString(reference) {
+		return "", fmt.Errorf("reference can only be alphanumeric and . _ : / -, starting alphanumeric, up to %d characters", model.MaxReferenceLength)
+	}
+	return reference, nil
+}
+
+func SanitizeReferenceWithoutError(raw string) string {
+	reference, err := SanitizeAndValidateReference(raw)
+	if err != nil {
+		return ""
+	}
+	return reference
+}
+
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > model.MaxMetadataEntries {
+		return fmt.Errorf("metadata has %d entries, up to %d allowed", len(metadata), model.MaxMetadataEntries)
+	}
+	for key, value := range metadata {
+		if !validMetadataKey.MatchString(key) {
+			return fmt.Errorf("metadata key %q can only be alphanumeric and . _ -, starting with a letter, up to %d characters", key, model.MaxMetadataKeyLength)
+		}
+		if err := validateFreeText(value, model.MaxMetadataValueLength); err != nil {
+			return fmt.Errorf("metadata value for %q %w", key, err)
+		}
+	}
+	return nil
+}
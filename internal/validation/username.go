@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/ezjuanify/wallet/internal/model"
 )
 
 var validUsername = regexp.MustCompile(`^[A-Z0-9_]+$`)
@@ -29,3 +31,7 @@ func SanitizeUsernameWithoutError(username string) string {
 	username = validUsername.ReplaceAllString(username, "")
 	return strings.ToUpper(username)
 }
+
+func IsReservedUsername(username string) bool {
+	return strings.HasPrefix(username, model.SystemUsernamePrefix)
+}
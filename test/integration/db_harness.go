@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -35,7 +36,27 @@ func (h *DBTestHarness) DoTestResetDBState() error {
 	query := `
 		TRUNCATE TABLE
 			wallets,
-			transactions
+			transactions,
+			journal_entries,
+			journal_postings,
+			scheduled_transfers,
+			scheduled_transfer_attempts,
+			standing_orders,
+			escrows,
+			fee_rules,
+			interest_accruals,
+			interest_postings,
+			statement_lines,
+			statements,
+			balance_snapshots,
+			overdraft_charges,
+			velocity_rules,
+			wallet_members,
+			signing_rules,
+			approval_requests,
+			approval_votes,
+			wallet_status_changes,
+			idempotency_keys
 		RESTART IDENTITY 
 		CASCADE;
 	`
@@ -48,35 +69,84 @@ func (h *DBTestHarness) DoTestResetDBState() error {
 }
 
 func (h *DBTestHarness) DoTestInsertInitialWallet(wallet *model.Wallet) error {
+	tx, err := h.store.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if wallet.Currency == "" {
+		wallet.Currency = model.DefaultCurrency
+	}
+
+	currency, ok := model.LookupCurrency(wallet.Currency)
+	if !ok {
+		return fmt.Errorf("unsupported currency %s", wallet.Currency)
+	}
+	limits := model.DefaultWalletLimits(currency)
+
 	query := `
-		INSERT INTO wallets (username, balance, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated)
-		VALUES ($1, $2, $3, $4, $5, $6);
+		INSERT INTO wallets (username, currency, balance, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated, min_amount, max_amount, max_balance)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id;
 	`
-	_, err := h.store.DB.Exec(
+	err = tx.QueryRow(
 		query,
 		wallet.Username,
+		wallet.Currency,
 		wallet.Balance,
 		wallet.LastDepositAmount,
 		wallet.LastDepositUpdated,
 		wallet.LastWithdrawAmount,
 		wallet.LastWithdrawUpdated,
-	)
-	return err
+		limits.MinAmount,
+		limits.MaxAmount,
+		limits.MaxBalance,
+	).Scan(&wallet.ID)
+	if err != nil {
+		return err
+	}
+
+	if wallet.Balance > 0 {
+		var entryID int64
+		entryQuery := `
+			INSERT INTO journal_entries (type)
+			VALUES ($1)
+			RETURNING id;
+		`
+		if err := tx.QueryRow(entryQuery, model.TypeOpeningBalance).Scan(&entryID); err != nil {
+			return err
+		}
+
+		postingQuery := `
+			INSERT INTO journal_postings (entry_id, wallet_id, system_account, currency, direction, amount)
+			VALUES ($1, NULL, $2, $7, $3, $5), ($1, $4, NULL, $7, $6, $5);
+		`
+		_, err := tx.Exec(postingQuery, entryID, model.AccountCash, model.DirectionDebit, wallet.ID, wallet.Balance, model.DirectionCredit, wallet.Currency)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-func (h *DBTestHarness) DoTestFetchWalletFromDB(username string) (*model.Wallet, error) {
+func (h *DBTestHarness) DoTestFetchWalletFromDB(username string, currency string) (*model.Wallet, error) {
 	query := `
-		SELECT username, balance, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated
+		SELECT username, currency, balance, held_balance, balance - held_balance + credit_limit, last_deposit_amount, last_deposit_updated, last_withdraw_amount, last_withdraw_updated
 		FROM wallets
-		WHERE username = $1;
+		WHERE username = $1
+		AND currency = $2;
 	`
 
-	row := h.store.DB.QueryRow(query, username)
+	row := h.store.DB.QueryRow(query, username, currency)
 
 	var w model.Wallet
 	err := row.Scan(
 		&w.Username,
+		&w.Currency,
 		&w.Balance,
+		&w.HeldBalance,
+		&w.AvailableBalance,
 		&w.LastDepositAmount,
 		&w.LastDepositUpdated,
 		&w.LastWithdrawAmount,
@@ -88,28 +158,61 @@ func (h *DBTestHarness) DoTestFetchWalletFromDB(username string) (*model.Wallet,
 	return &w, nil
 }
 
-func (h *DBTestHarness) DoTestFetchTransaction(username string) (*model.Transaction, error) {
+func (h *DBTestHarness) DoTestFetchTransaction(username string, currency string) (*model.Transaction, error) {
 	query := `
-		SELECT username, type, amount, counterparty, timestamp, hash
+		SELECT id, username, type, direction, currency, pocket, amount, counterparty, fx_rate::TEXT, quote_id, journal_entry_id, reversal_of, memo, reference, timestamp, prev_hash, hash, hash_version
 		FROM transactions
 		WHERE username = $1
+		AND currency = $2
 		ORDER BY timestamp DESC
 		LIMIT 1;
 	`
 
-	row := h.store.DB.QueryRow(query, username)
+	row := h.store.DB.QueryRow(query, username, currency)
 
 	var t model.Transaction
 	err := row.Scan(
+		&t.ID,
 		&t.Username,
 		&t.TxnType,
+		&t.Direction,
+		&t.Currency,
+		&t.Pocket,
 		&t.Amount,
 		&t.Counterparty,
+		&t.FXRate,
+		&t.QuoteID,
+		&t.JournalEntryID,
+		&t.ReversalOf,
+		&t.Memo,
+		&t.Reference,
 		&t.Timestamp,
+		&t.PrevHash,
 		&t.Hash,
+		&t.HashVersion,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &t, nil
 }
+
+func (h *DBTestHarness) DoTestFetchVelocityUsage(username string, currency string, txnType model.TxnType, since time.Time) (int64, error) {
+	ctx := context.Background()
+	tx, err := h.store.BeginTransaction(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	usage, err := h.store.FetchVelocityUsage(ctx, tx, username, currency, model.VelocityLoggedTypes(txnType), since)
+	if err != nil {
+		return 0, err
+	}
+
+	var used int64
+	for _, u := range usage {
+		used += u.Amount
+	}
+	return used, nil
+}
@@ -53,7 +53,7 @@ func TestIntegration(t *testing.T) {
 				vErrs.Add("API Wallet Validation", err)
 			}
 
-			dbWallet, err := dbTestHarness.DoTestFetchWalletFromDB(test.ExpectedWallet.Username)
+			dbWallet, err := dbTestHarness.DoTestFetchWalletFromDB(test.ExpectedWallet.Username, test.ExpectedWallet.Currency)
 			if !test.ExpectErr && err != nil {
 				vErrs.Add("Fetch DB Wallet", err)
 			}
@@ -62,7 +62,7 @@ func TestIntegration(t *testing.T) {
 				vErrs.Add("DB Wallet Validation", err)
 			}
 
-			dbTransaction, err := dbTestHarness.DoTestFetchTransaction(test.ExpectedWallet.Username)
+			dbTransaction, err := dbTestHarness.DoTestFetchTransaction(test.ExpectedWallet.Username, test.ExpectedWallet.Currency)
 			if !test.ExpectErr && err != nil {
 				vErrs.Add("Fetch Transaction", err)
 			}
@@ -75,7 +75,7 @@ func TestIntegration(t *testing.T) {
 				return
 			}
 
-			counterpartyWallet, err := dbTestHarness.DoTestFetchWalletFromDB(test.ExpectedCounterpartyWallet.Username)
+			counterpartyWallet, err := dbTestHarness.DoTestFetchWalletFromDB(test.ExpectedCounterpartyWallet.Username, test.ExpectedCounterpartyWallet.Currency)
 			if !test.ExpectErr && err != nil {
 				vErrs.Add("Fetch Counterparty Wallet", err)
 			}
@@ -84,7 +84,7 @@ func TestIntegration(t *testing.T) {
 				vErrs.Add("Counterparty Wallet Validation", err)
 			}
 
-			counterpartyTransaction, err := dbTestHarness.DoTestFetchTransaction(test.ExpectedCounterpartyWallet.Username)
+			counterpartyTransaction, err := dbTestHarness.DoTestFetchTransaction(test.ExpectedCounterpartyWallet.Username, test.ExpectedCounterpartyWallet.Currency)
 			if !test.ExpectErr && err != nil {
 				vErrs.Add("Fetch Counterparty Transaction", err)
 			}
@@ -10,6 +10,7 @@ import (
 	"github.com/ezjuanify/wallet/internal/db"
 	"github.com/ezjuanify/wallet/internal/handler"
 	"github.com/ezjuanify/wallet/internal/logger"
+	"github.com/ezjuanify/wallet/internal/model"
 	"github.com/ezjuanify/wallet/internal/service"
 )
 
@@ -42,10 +43,32 @@ func TestMain(m *testing.M) {
 	}
 
 	s := service.NewWalletService(store)
-	ds := service.NewDepositService(store)
-	ws := service.NewWithdrawService(store)
+	js := service.NewJournalService(store)
+	vs := service.NewVelocityService(store)
+	ds := service.NewDepositService(store, js, &model.WalletConfig{AutoCreate: true})
+	ws := service.NewWithdrawService(store, js, vs)
+	trs := service.NewTransferService(ws, ds, js, vs)
 	ts := service.NewTransactionService(store)
-	wh := handler.NewWalletHandler(store, s, ds, ws, ts)
+	fxs := service.NewFXService(store, &model.FXConfig{QuoteTTL: 30 * time.Second})
+	ls := service.NewLedgerService(store)
+	rs := service.NewReconciliationService(store)
+	rvs := service.NewReversalService(store)
+	hs := service.NewHoldService(store, &model.HoldConfig{DefaultTTL: time.Hour})
+	sts := service.NewScheduledTransferService(store, &model.ScheduledTransferConfig{RetryDelay: time.Minute, MaxAttempts: 1})
+	sos := service.NewStandingOrderService(store)
+	bts := service.NewBatchTransferService(store)
+	es := service.NewEscrowService(store, &model.EscrowConfig{DefaultTTL: time.Hour})
+	fs := service.NewFeeService(store, js)
+	is := service.NewInterestService(store, &model.InterestConfig{DayCount: 365, Rounding: model.RoundDown})
+	ods := service.NewOverdraftService(store)
+	lms := service.NewLimitService(store)
+	sms := service.NewStatementService(store)
+	bss := service.NewSnapshotService(store)
+	ps := service.NewPocketService(store)
+	jws := service.NewJointWalletService(store, &model.ApprovalConfig{TTL: time.Hour})
+	lcs := service.NewLifecycleService(store)
+	ids := service.NewIdempotencyService(store, &model.IdempotencyConfig{Retention: time.Hour})
+	wh := handler.NewWalletHandler(store, s, ds, ws, trs, ts, js, fxs, ls, rs, rvs, hs, sts, sos, bts, es, fs, is, ods, lms, vs, sms, bss, ps, jws, lcs, ids)
 	dbTestHarness = NewDbHarness(store)
 
 	mux := http.NewServeMux()
@@ -53,6 +76,7 @@ func TestMain(m *testing.M) {
 	mux.HandleFunc("/deposit", wh.DepositHandler)
 	mux.HandleFunc("/withdraw", wh.WithdrawHandler)
 	mux.HandleFunc("/transfer", wh.TransferHandler)
+	mux.HandleFunc(http.MethodPost+" /transactions/{id}/reverse", wh.ReversalHandler)
 
 	go func() {
 		log.Printf("Integration server starting on :%s\n", TEST_WALLET_PORT)
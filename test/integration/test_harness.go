@@ -46,6 +46,25 @@ func DoTestRequest(txnType model.TxnType, payload *request.RequestPayload, host
 	return resp, nil
 }
 
+func DoTestReverseRequest(id int64, amount int64, host string, port string) (*http.Response, error) {
+	body, err := json.Marshal(&request.ReversalPayload{Amount: &amount})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %s", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s/transactions/%d/reverse", host, port, id), bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %s", err)
+	}
+	return resp, nil
+}
+
 func DoTestStatusValidation(expectErr bool, resp *http.Response) error {
 	if expectErr && resp.StatusCode == http.StatusOK {
 		return fmt.Errorf("expected error, got success")
@@ -79,6 +98,10 @@ func DoTestWalletValidation(test_name string, txnType model.TxnType, expected *m
 		return fmt.Errorf("%s: Username - expected %s but got %s", test_name, expected.Username, actual.Username)
 	}
 
+	if expected.Currency != actual.Currency {
+		return fmt.Errorf("%s: Currency - expected %s but got %s", test_name, expected.Currency, actual.Currency)
+	}
+
 	if expected.Balance != actual.Balance {
 		return fmt.Errorf("%s: Balance - expected %d but got %d", test_name, expected.Balance, actual.Balance)
 	}
@@ -137,6 +160,10 @@ func DoTestTransactionValidation(test_name string, txnType model.TxnType, expect
 		return fmt.Errorf("%s: Username - expected %s but got %s", test_name, expected.Username, transaction.Username)
 	}
 
+	if expected.Currency != transaction.Currency {
+		return fmt.Errorf("%s: Currency - expected %s but got %s", test_name, expected.Currency, transaction.Currency)
+	}
+
 	var amount int64
 	var counterpartyUsername *string
 	if counterparty != nil {
@@ -202,13 +229,24 @@ func DoTestTransactionValidation(test_name string, txnType model.TxnType, expect
 		}
 	}
 
-	if payloadHash := utils.GenerateTransactionHash(
-		expected.Username,
-		transaction.TxnType,
-		amount,
-		counterpartyUsername,
-		transaction.Timestamp.UTC().Format(time.RFC3339),
-	); payloadHash != transaction.Hash {
+	if payloadHash := utils.GenerateTransactionHash(transaction.PrevHash, &model.Transaction{
+		Username:       expected.Username,
+		TxnType:        transaction.TxnType,
+		Direction:      transaction.Direction,
+		Currency:       expected.Currency,
+		Pocket:         transaction.Pocket,
+		Amount:         amount,
+		Counterparty:   counterpartyUsername,
+		FXRate:         transaction.FXRate,
+		QuoteID:        transaction.QuoteID,
+		JournalEntryID: transaction.JournalEntryID,
+		ReversalOf:     transaction.ReversalOf,
+		Memo:           transaction.Memo,
+		Reference:      transaction.Reference,
+		Metadata:       transaction.Metadata,
+		Timestamp:      transaction.Timestamp,
+		HashVersion:    transaction.HashVersion,
+	}); payloadHash != transaction.Hash {
 		return fmt.Errorf("%s: Calculated payload hash %s does not match %s", test_name, payloadHash[:10], transaction.Hash[:10])
 	}
 	return nil
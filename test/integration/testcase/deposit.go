@@ -38,6 +38,40 @@ func AddDepositTestCases() []TestCase {
 			ExpectedWallet: &model.Wallet{},
 			ExpectErr:      false,
 		},
+		{
+			Name:    "Integration Test: Successful Deposit - New currency wallet for existing user",
+			TxnType: model.TypeDeposit,
+			InitialWallets: []model.Wallet{
+				{
+					Username:            "JUAN",
+					Currency:            "USD",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+			},
+			Payload: &request.RequestPayload{
+				Username: "juan",
+				Amount:   500,
+				Currency: "eur",
+			},
+			ExpectedWallet: &model.Wallet{},
+			ExpectErr:      false,
+		},
+		{
+			Name:           "Integration Test: Fail Deposit - Unsupported currency",
+			TxnType:        model.TypeDeposit,
+			InitialWallets: nil,
+			Payload: &request.RequestPayload{
+				Username: "juan",
+				Amount:   500,
+				Currency: "XYZ",
+			},
+			ExpectedWallet: &model.Wallet{},
+			ExpectErr:      true,
+		},
 		{
 			Name:    "Integration Test: Successful Deposit - Username case insensitivity",
 			TxnType: model.TypeDeposit,
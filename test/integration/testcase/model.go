@@ -18,11 +18,15 @@ type TestCase struct {
 
 func (tc *TestCase) BuildExpectedWallet(username string, isCounterparty bool) {
 	username, _ = validation.SanitizeAndValidateUsername(username)
+	currency, _ := validation.SanitizeAndValidateCurrency(tc.Payload.Currency)
 
 	var expected *model.Wallet
 
 	for i := range tc.InitialWallets {
-		if tc.InitialWallets[i].Username == username {
+		if tc.InitialWallets[i].Currency == "" {
+			tc.InitialWallets[i].Currency = model.DefaultCurrency
+		}
+		if tc.InitialWallets[i].Username == username && tc.InitialWallets[i].Currency == currency.Code {
 			expected = &tc.InitialWallets[i]
 			break
 		}
@@ -31,6 +35,7 @@ func (tc *TestCase) BuildExpectedWallet(username string, isCounterparty bool) {
 	if expected == nil {
 		expected = &model.Wallet{
 			Username: username,
+			Currency: currency.Code,
 			Balance:  0,
 		}
 	}
@@ -227,6 +227,132 @@ func AddTransferTestCases() []TestCase {
 			},
 			ExpectErr: true,
 		},
+		{
+			Name:    "Integration Test: Successful Transfer - Non-default currency",
+			TxnType: model.TypeTransfer,
+			InitialWallets: []model.Wallet{
+				{
+					Username:            "JUAN",
+					Currency:            "EUR",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+				{
+					Username:            "MARY",
+					Currency:            "EUR",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+			},
+			Payload: &request.RequestPayload{
+				Username:     "juan",
+				Amount:       500,
+				Currency:     "EUR",
+				Counterparty: utils.Ptr("mary"),
+			},
+			ExpectErr: false,
+		},
+		{
+			Name:    "Integration Test: Fail Transfer - Counterparty wallet in different currency only",
+			TxnType: model.TypeTransfer,
+			InitialWallets: []model.Wallet{
+				{
+					Username:            "JUAN",
+					Currency:            "USD",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+				{
+					Username:            "MARY",
+					Currency:            "EUR",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+			},
+			Payload: &request.RequestPayload{
+				Username:     "juan",
+				Amount:       500,
+				Counterparty: utils.Ptr("mary"),
+			},
+			ExpectErr: true,
+		},
+		{
+			Name:    "Integration Test: Fail Transfer - Cross-currency without conversion",
+			TxnType: model.TypeTransfer,
+			InitialWallets: []model.Wallet{
+				{
+					Username:            "JUAN",
+					Currency:            "USD",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+				{
+					Username:            "MARY",
+					Currency:            "EUR",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+			},
+			Payload: &request.RequestPayload{
+				Username:             "juan",
+				Amount:               500,
+				Currency:             "USD",
+				Counterparty:         utils.Ptr("mary"),
+				CounterpartyCurrency: utils.Ptr("EUR"),
+			},
+			ExpectErr: true,
+		},
+		{
+			Name:    "Integration Test: Fail Transfer - Unknown FX quote",
+			TxnType: model.TypeTransfer,
+			InitialWallets: []model.Wallet{
+				{
+					Username:            "JUAN",
+					Currency:            "USD",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+				{
+					Username:            "MARY",
+					Currency:            "EUR",
+					Balance:             1000,
+					LastDepositAmount:   nil,
+					LastDepositUpdated:  nil,
+					LastWithdrawAmount:  nil,
+					LastWithdrawUpdated: nil,
+				},
+			},
+			Payload: &request.RequestPayload{
+				Username:             "juan",
+				Amount:               500,
+				Currency:             "USD",
+				Counterparty:         utils.Ptr("mary"),
+				CounterpartyCurrency: utils.Ptr("EUR"),
+				QuoteID:              utils.Ptr("does-not-exist"),
+			},
+			ExpectErr: true,
+		},
 		{
 			Name:    "Integration Test: Fail Transfer - Symbol in counterparty",
 			TxnType: model.TypeTransfer,
@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezjuanify/wallet/internal/model"
+	"github.com/ezjuanify/wallet/internal/model/request"
+	"github.com/ezjuanify/wallet/internal/utils"
+)
+
+func TestVelocityUsageAfterPartialReversal(t *testing.T) {
+	var vErrs ValidationErrors
+	defer vErrs.Report(t)
+
+	if err := dbTestHarness.DoTestResetDBState(); err != nil {
+		vErrs.Add("Reset DB State", err)
+		return
+	}
+
+	for _, v := range []model.Wallet{{Username: "JUAN", Balance: 20000}, {Username: "MARY", Balance: 1000}} {
+		if err := dbTestHarness.DoTestInsertInitialWallet(&v); err != nil {
+			vErrs.Add("Insert Initial Wallet", err)
+			return
+		}
+	}
+
+	since := time.Now().UTC().Add(-time.Hour)
+	payload := &request.RequestPayload{
+		Username:     "juan",
+		Amount:       10000,
+		Counterparty: utils.Ptr("mary"),
+	}
+	resp, err := DoTestRequest(model.TypeTransfer, payload, TEST_WALLET_HOST, TEST_WALLET_PORT)
+	if err != nil {
+		vErrs.Add("Do Transfer Request", err)
+		return
+	}
+	resp.Body.Close()
+	if err := DoTestStatusValidation(false, resp); err != nil {
+		vErrs.Add("Transfer Status Validation", err)
+		return
+	}
+
+	used, err := dbTestHarness.DoTestFetchVelocityUsage("JUAN", model.DefaultCurrency, model.TypeTransfer, since)
+	if err != nil {
+		vErrs.Add("Fetch Velocity Usage", err)
+		return
+	}
+	if used != 10000 {
+		vErrs.Add("Usage Before Reversal", fmt.Errorf("expected 10000, got %d", used))
+	}
+
+	original, err := dbTestHarness.DoTestFetchTransaction("JUAN", model.DefaultCurrency)
+	if err != nil {
+		vErrs.Add("Fetch Transaction", err)
+		return
+	}
+
+	resp, err = DoTestReverseRequest(original.ID, 1, TEST_WALLET_HOST, TEST_WALLET_PORT)
+	if err != nil {
+		vErrs.Add("Do Reverse Request", err)
+		return
+	}
+	resp.Body.Close()
+	if err := DoTestStatusValidation(false, resp); err != nil {
+		vErrs.Add("Reverse Status Validation", err)
+		return
+	}
+
+	used, err = dbTestHarness.DoTestFetchVelocityUsage("JUAN", model.DefaultCurrency, model.TypeTransfer, since)
+	if err != nil {
+		vErrs.Add("Fetch Velocity Usage", err)
+		return
+	}
+	if used != 9999 {
+		vErrs.Add("Usage After Partial Reversal", fmt.Errorf("expected 9999, got %d", used))
+	}
+}